@@ -1,17 +1,25 @@
 package app
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
-
-	"github.com/ftl/hamradio/cwclient"
+	"time"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandmap"
+	"github.com/ftl/hellocontest/core/bandmemory"
+	"github.com/ftl/hellocontest/core/callbook"
+	"github.com/ftl/hellocontest/core/callhistory"
 	"github.com/ftl/hellocontest/core/callinfo"
+	"github.com/ftl/hellocontest/core/cat"
 	"github.com/ftl/hellocontest/core/cfg"
+	"github.com/ftl/hellocontest/core/cwdaemon"
+	"github.com/ftl/hellocontest/core/dvk"
 	"github.com/ftl/hellocontest/core/dxcc"
 	"github.com/ftl/hellocontest/core/entry"
 	"github.com/ftl/hellocontest/core/export/adif"
@@ -19,13 +27,20 @@ import (
 	"github.com/ftl/hellocontest/core/export/csv"
 	"github.com/ftl/hellocontest/core/hamlib"
 	"github.com/ftl/hellocontest/core/keyer"
+	"github.com/ftl/hellocontest/core/livescore"
 	"github.com/ftl/hellocontest/core/logbook"
+	"github.com/ftl/hellocontest/core/n1mm"
+	"github.com/ftl/hellocontest/core/network"
 	"github.com/ftl/hellocontest/core/rate"
+	"github.com/ftl/hellocontest/core/rbn"
 	"github.com/ftl/hellocontest/core/score"
 	"github.com/ftl/hellocontest/core/scp"
 	"github.com/ftl/hellocontest/core/settings"
+	"github.com/ftl/hellocontest/core/so2r"
 	"github.com/ftl/hellocontest/core/store"
 	"github.com/ftl/hellocontest/core/tci"
+	"github.com/ftl/hellocontest/core/txhistory"
+	"github.com/ftl/hellocontest/core/winkeyer"
 )
 
 // NewController returns a new instance of the AppController interface.
@@ -36,6 +51,7 @@ func NewController(version string, clock core.Clock, quitter Quitter, asyncRunne
 		quitter:       quitter,
 		asyncRunner:   asyncRunner,
 		configuration: configuration,
+		ptts:          make(map[so2r.Radio]dvk.PTT),
 	}
 }
 
@@ -50,19 +66,34 @@ type Controller struct {
 	quitter       Quitter
 	asyncRunner   core.AsyncRunner
 	store         *store.FileStore
-	tciClient     *tci.Client
-	cwclient      *cwclient.Client
-	hamlibClient  *hamlib.Client
+	tciClients    []*tci.Client
+	ptts          map[so2r.Radio]dvk.PTT
+	cwclient      *cwdaemon.Client
+	winkeyer      *winkeyer.Client
+	hamlibClients []*hamlib.Client
+	catClients    []*cat.Client
+	bandMemories  []*bandmemory.Memory
+	rbnClient     *rbn.Client
+	networkSync   *network.Sync
+	n1mm          *n1mm.Broadcaster
+	scoreReporter *livescore.Reporter
 	dxccFinder    *dxcc.Finder
 	scpFinder     *scp.Finder
+	callHistory   *callhistory.Finder
 
 	Logbook       *logbook.Logbook
 	QSOList       *logbook.QSOList
 	Entry         *entry.Controller
+	Entry2        *entry.Controller
+	SO2R          *so2r.Controller
 	Keyer         *keyer.Keyer
+	VoiceKeyer    *dvk.Keyer
+	TXHistory     *txhistory.History
 	Callinfo      *callinfo.Callinfo
 	Score         *score.Counter
 	Rate          *rate.Counter
+	Bandmap       *bandmap.Bandmap
+	RBNMonitor    *rbn.Monitor
 	ServiceStatus *ServiceStatus
 	Settings      *settings.Settings
 
@@ -87,8 +118,24 @@ type Configuration interface {
 
 	KeyerHost() string
 	KeyerPort() int
+	WinkeyerPort() string
+	ESM() bool
+	TXHistory() bool
+	Offline() bool
+	DXCCOverrides() string
+	DVK() core.DVK
+	MacroSets() []core.MacroSet
+	CallHistory() []core.CallHistory
+	Callbook() core.Callbook
 	HamlibAddress() string
+	CAT() core.CAT
 	TCIAddress() string
+	RBNAddress() string
+	Network() core.Network
+	N1MMBroadcast() []string
+	ScoreReportingURL() string
+	ScoreReportingInterval() time.Duration
+	SO2R() core.SO2R
 }
 
 // Quitter allows to quit the application. This interface is used to call the actual application framework to quit.
@@ -111,8 +158,15 @@ func (c *Controller) Startup() {
 
 	c.ServiceStatus = newServiceStatus(c.asyncRunner)
 
-	c.dxccFinder = dxcc.New()
-	c.scpFinder = scp.New()
+	c.dxccFinder = dxcc.New(c.configuration.Offline())
+	c.dxccFinder.SetOverridesFile(c.configuration.DXCCOverrides())
+	c.dxccFinder.Notify(c.ServiceStatus)
+	c.dxccFinder.Start()
+	c.scpFinder = scp.New(c.configuration.Offline())
+	c.scpFinder.Notify(c.ServiceStatus)
+	c.scpFinder.Start()
+	c.callHistory = callhistory.New(c.configuration.CallHistory())
+	c.callHistory.ContestChanged(c.Settings.Contest())
 
 	c.QSOList = logbook.NewQSOList(c.Settings)
 	c.QSOList.Notify(logbook.QSOFillerFunc(c.fillQSO))
@@ -122,39 +176,56 @@ func (c *Controller) Startup() {
 		c.QSOList,
 		c.asyncRunner,
 	)
-	c.QSOList.Notify(c.Entry)
 
-	tciAddress := c.configuration.TCIAddress()
-	hamlibAddress := c.configuration.HamlibAddress()
-	var keyerCWClient keyer.CWClient
-	if tciAddress != "" {
-		tciClient, err := tci.NewClient(tciAddress)
-		if err != nil {
-			log.Printf("cannot open TCI connection: %v", err)
-		} else {
-			c.tciClient = tciClient
-			c.tciClient.Notify(c.ServiceStatus)
-			c.Entry.SetVFO(c.tciClient)
-			c.tciClient.SetVFOController(c.Entry)
-			keyerCWClient = c.tciClient
-		}
-	} else if hamlibAddress != "" {
-		c.hamlibClient = hamlib.New(hamlibAddress)
-		c.hamlibClient.Notify(c.ServiceStatus)
-		c.hamlibClient.KeepOpen()
-		c.Entry.SetVFO(c.hamlibClient)
-		c.hamlibClient.SetVFOController(c.Entry)
+	so2rConfig := c.configuration.SO2R()
+	if so2rConfig.TCIAddress != "" || so2rConfig.HamlibAddress != "" || so2rConfig.CAT.Port != "" {
+		c.Entry2 = entry.NewController(
+			c.Settings,
+			c.clock,
+			c.QSOList,
+			c.asyncRunner,
+		)
+		c.SO2R = so2r.New(c.Entry, c.Entry2)
+	} else {
+		c.SO2R = so2r.New(c.Entry, nil)
 	}
+	c.QSOList.Notify(c.SO2R)
 
-	if keyerCWClient == nil {
-		c.cwclient, _ = cwclient.New(c.configuration.KeyerHost(), c.configuration.KeyerPort())
-		keyerCWClient = c.cwclient
-	}
+	c.forEachEntry(func(radio so2r.Radio, e *entry.Controller) {
+		c.setupBandMemory(radio, e)
+
+		var cwClient keyer.CWClient
+		if radio == so2r.Radio1 {
+			cwClient = c.setupRadio(radio, e, c.configuration.TCIAddress(), 0, c.configuration.HamlibAddress(), c.configuration.CAT())
+		} else {
+			cwClient = c.setupRadio(radio, e, so2rConfig.TCIAddress, so2rConfig.TCITRX, so2rConfig.HamlibAddress, so2rConfig.CAT)
+		}
+		if cwClient == nil {
+			cwClient = c.sharedCWClient()
+		}
+		c.SO2R.SetCWClient(radio, cwClient)
+	})
 
-	c.Keyer = keyer.New(c.Settings, keyerCWClient, c.configuration.Keyer())
-	c.Keyer.SetValues(c.Entry.CurrentValues)
+	c.Keyer = keyer.New(c.Settings, c.SO2R.CWClient(), c.configuration.Keyer(), c.asyncRunner)
+	c.Keyer.SetValues(c.SO2R.CurrentValues)
+	c.Keyer.SetMacroSets(c.configuration.MacroSets())
+	c.Keyer.SetActions(c.SO2R)
 	c.Keyer.Notify(c.ServiceStatus)
-	c.Entry.SetKeyer(c.Keyer)
+	c.Keyer.Notify(c.SO2R)
+	c.setupVoiceKeyer()
+	c.TXHistory = txhistory.New()
+	c.TXHistory.SetRadio(c.SO2R.TX().String())
+	c.Keyer.Notify(c.TXHistory)
+	c.SO2R.Notify(so2r.TXChangedListenerFunc(func(radio so2r.Radio) {
+		c.TXHistory.SetRadio(radio.String())
+	}))
+	c.SO2R.SetKeyer(c.Keyer)
+	c.forEachEntry(func(radio so2r.Radio, e *entry.Controller) {
+		e.SetKeyer(c.SO2R.Keyer(radio))
+		e.SetESM(c.configuration.ESM())
+		e.SetXchangePredictor(c.callHistory)
+		e.Notify(c.SO2R.RadioListener(radio))
+	})
 
 	c.Score = score.NewCounter(c.Settings, c.dxccFinder)
 	c.QSOList.Notify(logbook.QSOsClearedListenerFunc(c.Score.Clear))
@@ -165,14 +236,73 @@ func (c *Controller) Startup() {
 	c.QSOList.Notify(logbook.QSOsClearedListenerFunc(c.Rate.Clear))
 	c.QSOList.Notify(logbook.QSOAddedListenerFunc(c.Rate.Add))
 	c.QSOList.Notify(logbook.QSOUpdatedListenerFunc(func(_ int, o, n core.QSO) { c.Rate.Update(o, n) }))
+	c.Rate.Notify(c.Keyer)
+
+	c.QSOList.Notify(c.scpFinder)
+	c.Callinfo = callinfo.New(c.dxccFinder, c.scpFinder, c.QSOList, c.Score, c.asyncRunner)
+	c.Callinfo.SetXchangePredictor(c.callHistory)
+	callbookConfig := c.configuration.Callbook()
+	if callbookConfig.Username != "" && !c.configuration.Offline() {
+		client := callbook.NewClient(callbookConfig.URL, callbookConfig.Username, callbookConfig.Password)
+		c.Callinfo.SetCallbook(callbook.NewCache(callbookConfig.CacheFile, callbookConfig.MaxAge, c.clock, client))
+	}
+	c.SO2R.SetCallinfo(c.Callinfo)
+
+	c.Bandmap = bandmap.New(c.clock, c.dxccFinder, c.QSOList, c.Score)
+	c.Bandmap.StationChanged(c.Settings.Station())
+	c.QSOList.Notify(c.Bandmap)
+	c.SO2R.Notify(c.Bandmap)
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		e.SetHunter(c.Bandmap)
+	})
+
+	c.RBNMonitor = rbn.NewMonitor(c.clock, c.asyncRunner, c.Settings.Station())
+	c.SO2R.Notify(c.RBNMonitor)
+	rbnAddress := c.configuration.RBNAddress()
+	if rbnAddress != "" {
+		c.rbnClient = rbn.NewClient(rbnAddress, c.Settings.Station().Callsign, c.asyncRunner)
+		c.rbnClient.Notify(c.ServiceStatus)
+		c.rbnClient.Notify(c.RBNMonitor)
+		c.rbnClient.Notify(rbn.AddSpotsTo(c.Bandmap))
+		c.rbnClient.KeepOpen()
+	}
+
+	c.setupNetwork()
+
+	n1mmDestinations := c.configuration.N1MMBroadcast()
+	if len(n1mmDestinations) > 0 {
+		stationName, _ := os.Hostname()
+		c.n1mm = n1mm.NewBroadcaster(c.clock, c.Settings, stationName, n1mmDestinations...)
+		c.n1mm.SetRadios(c.SO2R)
+		c.QSOList.Notify(c.n1mm)
+		c.Score.Notify(c.n1mm)
+		c.SO2R.Notify(c.n1mm)
+		c.Settings.Notify(c.n1mm)
+	}
 
-	c.Callinfo = callinfo.New(c.dxccFinder, c.scpFinder, c.QSOList, c.Score)
-	c.Entry.SetCallinfo(c.Callinfo)
+	scoreReportingURL := c.configuration.ScoreReportingURL()
+	if scoreReportingURL != "" {
+		interval := c.configuration.ScoreReportingInterval()
+		if interval <= 0 {
+			interval = livescore.DefaultInterval
+		}
+		c.scoreReporter = livescore.NewReporter(scoreReportingURL, interval, c.version, c.clock, c.Settings, c.dxccFinder, c.asyncRunner)
+		c.scoreReporter.Notify(c.ServiceStatus)
+		c.QSOList.Notify(c.scoreReporter)
+		c.Score.Notify(c.scoreReporter)
+		c.Settings.Notify(c.scoreReporter)
+		c.scoreReporter.Start()
+	}
 
-	c.Settings.Notify(c.Entry)
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		c.Settings.Notify(e)
+	})
 	c.Settings.Notify(c.Keyer)
+	c.Settings.Notify(c.callHistory)
 	c.Settings.Notify(c.QSOList)
 	c.Settings.Notify(c.Score)
+	c.Settings.Notify(c.RBNMonitor)
+	c.Settings.Notify(c.Bandmap)
 	c.Settings.Notify(settings.SettingsListenerFunc(func(s core.Settings) {
 		if !c.dxccFinder.Available() {
 			return
@@ -193,14 +323,13 @@ func (c *Controller) Startup() {
 			}
 			c.Refresh()
 		})
-		c.ServiceStatus.StatusChanged(core.DXCCService, true)
-	})
-	c.scpFinder.WhenAvailable(func() {
-		c.ServiceStatus.StatusChanged(core.SCPService, true)
 	})
 
-	c.Entry.StartAutoRefresh()
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		e.StartAutoRefresh()
+	})
 	c.Rate.StartAutoRefresh()
+	c.RBNMonitor.StartAutoRefresh()
 
 	err := c.openCurrentLog()
 	if err != nil {
@@ -208,6 +337,175 @@ func (c *Controller) Startup() {
 	}
 }
 
+// setupRadio connects the given entry with the radio at the given TCI or Hamlib address or at the given CAT port.
+// If the radio is also able to send CW, its CW client is returned.
+func (c *Controller) setupRadio(radio so2r.Radio, e *entry.Controller, tciAddress string, trx int, hamlibAddress string, catConfig core.CAT) keyer.CWClient {
+	if tciAddress != "" {
+		tciClient, err := tci.NewClientForTRX(tciAddress, trx)
+		if err != nil {
+			log.Printf("cannot open TCI connection for %s: %v", radio, err)
+			return nil
+		}
+		c.tciClients = append(c.tciClients, tciClient)
+		c.ptts[radio] = tciClient
+		tciClient.Notify(c.ServiceStatus)
+		tciClient.StationChanged(c.Settings.Station())
+		c.Settings.Notify(tciClient)
+		e.SetVFO(tciClient)
+		tciClient.SetVFOController(e)
+		return tciClient
+	} else if hamlibAddress != "" {
+		hamlibClient := hamlib.New(hamlibAddress)
+		c.hamlibClients = append(c.hamlibClients, hamlibClient)
+		c.ptts[radio] = hamlibClient
+		hamlibClient.Notify(c.ServiceStatus)
+		hamlibClient.StationChanged(c.Settings.Station())
+		c.Settings.Notify(hamlibClient)
+		hamlibClient.KeepOpen()
+		e.SetVFO(hamlibClient)
+		hamlibClient.SetVFOController(e)
+	} else if catConfig.Port != "" {
+		catClient, err := cat.New(catConfig)
+		if err != nil {
+			log.Printf("cannot open CAT connection for %s: %v", radio, err)
+			return nil
+		}
+		c.catClients = append(c.catClients, catClient)
+		c.ptts[radio] = catClient
+		catClient.Notify(c.ServiceStatus)
+		catClient.StationChanged(c.Settings.Station())
+		c.Settings.Notify(catClient)
+		catClient.KeepOpen()
+		e.SetVFO(catClient)
+		catClient.SetVFOController(e)
+	}
+	return nil
+}
+
+// setupVoiceKeyer sets up the voice keyer that plays the recordings on the local audio device. The transmitting radio
+// is keyed through TCI, Hamlib or CAT while the recording is played. With TCI, the audio must be routed to the VAC.
+func (c *Controller) setupVoiceKeyer() {
+	config := c.configuration.DVK()
+	c.VoiceKeyer = dvk.New(config, dvk.NewCommandSink(config.PlayCommand))
+	c.VoiceKeyer.SetPTT(dvk.PTTFunc(func(on bool) {
+		if ptt, ok := c.ptts[c.SO2R.TX()]; ok {
+			ptt.SetPTT(on)
+		}
+	}))
+	c.Keyer.SetVoiceKeyer(c.VoiceKeyer)
+}
+
+// ImportVoiceRecording imports a WAV file as recording of the voice macro with the given index.
+func (c *Controller) ImportVoiceRecording(workmode core.Workmode, index int) {
+	filename, ok, err := c.view.SelectOpenFile(fmt.Sprintf("Import Voice Recording for F%d", index+1), "*.wav")
+	if !ok {
+		return
+	}
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot select a file: %v", err)
+		return
+	}
+
+	err = c.VoiceKeyer.RecordFromFile(workmode, index, filename)
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot import %s: %v", filepath.Base(filename), err)
+	}
+}
+
+// setupBandMemory restores the last used frequencies of the given radio from its file in the configuration directory.
+func (c *Controller) setupBandMemory(radio so2r.Radio, e *entry.Controller) {
+	filename := filepath.Join(cfg.Directory(), fmt.Sprintf("hellocontest.%s.frequencies", strings.ToLower(radio.String())))
+	memory, err := bandmemory.Load(filename)
+	if err != nil {
+		log.Printf("cannot load the last used frequencies for %s: %v", radio, err)
+	}
+	c.bandMemories = append(c.bandMemories, memory)
+	e.SetBandMemory(memory)
+}
+
+// sharedCWClient returns the connection to the CW keyer that is used by all radios that cannot send CW on their own.
+// This is the Winkeyer, if a Winkeyer port is configured, otherwise cwdaemon.
+func (c *Controller) sharedCWClient() keyer.CWClient {
+	winkeyerPort := c.configuration.WinkeyerPort()
+	if winkeyerPort != "" {
+		if c.winkeyer == nil {
+			c.winkeyer = c.setupWinkeyer(winkeyerPort)
+		}
+		return c.winkeyer
+	}
+	if c.cwclient == nil {
+		var err error
+		c.cwclient, err = cwdaemon.New(c.configuration.KeyerHost(), c.configuration.KeyerPort())
+		if err != nil {
+			log.Printf("cannot create the cwdaemon client: %v", err)
+			return nil
+		}
+	}
+	return c.cwclient
+}
+
+// setupWinkeyer connects the Winkeyer at the given serial port with the keyer. The progress of sending and the speed pot
+// are shown through the keyer.
+func (c *Controller) setupWinkeyer(port string) *winkeyer.Client {
+	result := winkeyer.New(port)
+	result.Notify(c.ServiceStatus)
+	result.Notify(winkeyer.EchoListenerFunc(func(r rune) {
+		c.asyncRunner(func() {
+			c.Keyer.CharacterSent(r)
+		})
+	}))
+	result.Notify(winkeyer.SpeedPotListenerFunc(func(wpm int) {
+		c.asyncRunner(func() {
+			c.Keyer.SetSpeed(wpm)
+		})
+	}))
+	return result
+}
+
+// forEachEntry calls the given function for the entry of every available radio.
+func (c *Controller) forEachEntry(f func(so2r.Radio, *entry.Controller)) {
+	f(so2r.Radio1, c.Entry)
+	if c.Entry2 != nil {
+		f(so2r.Radio2, c.Entry2)
+	}
+}
+
+// FocusedEntry returns the entry of the radio that has the focus.
+func (c *Controller) FocusedEntry() *entry.Controller {
+	if c.SO2R.Focus() == so2r.Radio2 && c.Entry2 != nil {
+		return c.Entry2
+	}
+	return c.Entry
+}
+
+func (c *Controller) setupNetwork() {
+	config := c.configuration.Network()
+	if config.ListenAddress == "" && len(config.Peers) == 0 {
+		return
+	}
+	stationID := config.StationID
+	if stationID == "" {
+		stationID = c.Settings.Station().Operator.String()
+	}
+
+	c.networkSync = network.New(stationID, config.FirstNumber, config.LastNumber, c.asyncRunner)
+	c.networkSync.Notify(c.ServiceStatus)
+	c.networkSync.Notify(network.ConnectionRefusedListenerFunc(func(err error) {
+		if c.view != nil {
+			c.view.ShowErrorDialog("Cannot share the log with the other station: %v", err)
+		}
+	}))
+	if config.ListenAddress != "" {
+		err := c.networkSync.Listen(config.ListenAddress)
+		if err != nil {
+			log.Printf("cannot listen for other stations on %s: %v", config.ListenAddress, err)
+		}
+	}
+	for _, peer := range config.Peers {
+		c.networkSync.Connect(peer)
+	}
+}
+
 func (c *Controller) openCurrentLog() error {
 	filename := "current.log"
 	store := store.NewFileStore(filename)
@@ -271,8 +569,20 @@ func (c *Controller) changeLogbook(filename string, store *store.FileStore, logb
 	c.store = store
 	c.Logbook = logbook
 	c.Logbook.SetWriter(c.store)
+	networkConfig := c.configuration.Network()
+	c.Logbook.SetNumberRange(networkConfig.FirstNumber, networkConfig.LastNumber)
 	c.Logbook.OnRowAdded(c.QSOList.Put)
-	c.Entry.SetLogbook(c.Logbook)
+	if c.networkSync != nil {
+		c.Logbook.OnRowAdded(c.networkSync.RowAdded)
+		c.networkSync.SetLogbook(c.Logbook)
+	}
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		e.SetLogbook(c.Logbook)
+	})
+	err := c.TXHistory.Load(c.txHistoryFilename())
+	if err != nil {
+		log.Printf("Cannot load the transmit history: %v", err)
+	}
 
 	if c.view != nil {
 		c.view.ShowFilename(c.filename)
@@ -281,25 +591,58 @@ func (c *Controller) changeLogbook(filename string, store *store.FileStore, logb
 		c.OnLogbookChanged()
 	}
 
-	if c.tciClient != nil {
-		c.tciClient.Refresh()
+	for _, tciClient := range c.tciClients {
+		tciClient.Refresh()
 	}
-	if c.hamlibClient != nil {
-		c.hamlibClient.Refresh()
+	for _, hamlibClient := range c.hamlibClients {
+		hamlibClient.Refresh()
 	}
-	c.Entry.Clear()
+	for _, catClient := range c.catClients {
+		catClient.Refresh()
+	}
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		e.Clear()
+	})
 }
 
 func (c *Controller) Shutdown() {
-	if c.tciClient != nil {
-		c.tciClient.Disconnect()
+	if c.VoiceKeyer != nil {
+		c.VoiceKeyer.Stop()
+	}
+	c.Keyer.Save()
+	for _, tciClient := range c.tciClients {
+		tciClient.Disconnect()
 	}
-	if c.hamlibClient != nil {
-		c.hamlibClient.Disconnect()
+	for _, hamlibClient := range c.hamlibClients {
+		hamlibClient.Disconnect()
+	}
+	for _, catClient := range c.catClients {
+		catClient.Close()
+	}
+	for _, bandMemory := range c.bandMemories {
+		err := bandMemory.Save()
+		if err != nil {
+			log.Printf("cannot save the last used frequencies: %v", err)
+		}
 	}
 	if c.cwclient != nil {
 		c.cwclient.Disconnect()
 	}
+	if c.winkeyer != nil {
+		c.winkeyer.Disconnect()
+	}
+	if c.rbnClient != nil {
+		c.rbnClient.Disconnect()
+	}
+	if c.networkSync != nil {
+		c.networkSync.Close()
+	}
+	if c.n1mm != nil {
+		c.n1mm.Close()
+	}
+	if c.scoreReporter != nil {
+		c.scoreReporter.Stop()
+	}
 }
 
 func (c *Controller) About() {
@@ -439,6 +782,12 @@ func (c *Controller) SaveAs() {
 	c.store = store
 	c.Settings.SetWriter(store)
 	c.Logbook.SetWriter(store)
+	if c.configuration.TXHistory() {
+		err = c.TXHistory.SaveAs(c.txHistoryFilename())
+		if err != nil {
+			c.view.ShowErrorDialog("Cannot save the transmit history: %v", err)
+		}
+	}
 
 	c.view.ShowFilename(c.filename)
 }
@@ -528,6 +877,137 @@ func (c *Controller) ExportCSV() {
 	}
 }
 
+func (c *Controller) ExportTXHistory() {
+	filename, ok, err := c.view.SelectSaveFile("Export Transmit History", "*.csv")
+	if !ok {
+		return
+	}
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot select a file: %v", err)
+		return
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot open file %s: %v", filename, err)
+		return
+	}
+	defer file.Close()
+	err = c.TXHistory.Export(file)
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot export the transmit history to %s: %v", filename, err)
+		return
+	}
+}
+
+// txHistoryFilename returns the name of the file next to the logfile that contains the transmit history. If the
+// transmit history is not persisted, the filename is empty.
+func (c *Controller) txHistoryFilename() string {
+	if !c.configuration.TXHistory() {
+		return ""
+	}
+	return txhistory.Filename(c.filename)
+}
+
+// UpdateDatabases downloads the current DXCC prefixes and the current Supercheck database in the background.
+func (c *Controller) UpdateDatabases() {
+	go func() {
+		dxccErr := c.dxccFinder.Update()
+		scpErr := c.scpFinder.Update()
+		c.asyncRunner(func() {
+			if dxccErr == nil {
+				c.Refresh()
+			}
+			if dxccErr != nil || scpErr != nil {
+				c.view.ShowErrorDialog("Cannot update the databases:\n%v\n%v", errorText(dxccErr), errorText(scpErr))
+			}
+		})
+	}()
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return "OK"
+	}
+	return err.Error()
+}
+
+// ImportDXCCPrefixes replaces the local copy of the DXCC prefixes with the content of a cty.dat or cty.csv file.
+func (c *Controller) ImportDXCCPrefixes() {
+	filename, ok, err := c.view.SelectOpenFile("Import DXCC Prefixes", "*.dat", "*.csv")
+	if !ok {
+		return
+	}
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot select a file: %v", err)
+		return
+	}
+
+	err = c.dxccFinder.Import(filename)
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot import %s: %v", filepath.Base(filename), err)
+		return
+	}
+	c.Refresh()
+}
+
+// ReloadDXCCOverrides reads the DXCC overrides file again, e.g. after it was edited.
+func (c *Controller) ReloadDXCCOverrides() {
+	err := c.dxccFinder.ReloadOverrides()
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot reload the DXCC overrides: %v", err)
+	}
+	c.Refresh()
+}
+
+// ImportSupercheckDatabase replaces the local copy of the Supercheck database with the content of a MASTER.SCP file.
+func (c *Controller) ImportSupercheckDatabase() {
+	filename, ok, err := c.view.SelectOpenFile("Import Supercheck Database", "*.scp", "*.SCP")
+	if !ok {
+		return
+	}
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot select a file: %v", err)
+		return
+	}
+
+	err = c.scpFinder.Import(filename)
+	if err != nil {
+		c.view.ShowErrorDialog("Cannot import %s: %v", filepath.Base(filename), err)
+	}
+}
+
+// ShowXchangeConsistency shows all stations that sent different exchanges in their QSOs.
+func (c *Controller) ShowXchangeConsistency() {
+	inconsistencies := c.QSOList.InconsistentXchanges()
+	if len(inconsistencies) == 0 {
+		c.view.ShowInfoDialog("All stations sent consistent exchanges.")
+		return
+	}
+
+	lines := make([]string, 0, len(inconsistencies))
+	for _, inconsistency := range inconsistencies {
+		xchanges := make([]string, 0, len(inconsistency.QSOs))
+		for _, qso := range inconsistency.QSOs {
+			xchanges = append(xchanges, fmt.Sprintf("%s (%s #%s)", qso.TheirXchange, qso.Band, qso.MyNumber.String()))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", inconsistency.Callsign, strings.Join(xchanges, ", ")))
+	}
+	c.view.ShowInfoDialog("Stations with inconsistent exchanges:\n\n%s", strings.Join(lines, "\n"))
+}
+
+func (c *Controller) MacroSets() []string {
+	return c.Keyer.MacroSets()
+}
+
+func (c *Controller) ActiveMacroSet() string {
+	return c.Keyer.ActiveMacroSet()
+}
+
+func (c *Controller) SelectMacroSet(name string) {
+	c.Keyer.SelectMacroSet(name)
+}
+
 func (c *Controller) ShowCallinfo() {
 	c.Callinfo.Show()
 	c.view.BringToFront()
@@ -543,24 +1023,39 @@ func (c *Controller) ShowRate() {
 	c.view.BringToFront()
 }
 
+func (c *Controller) ShowHuntingList() {
+	c.Bandmap.Show()
+	c.view.BringToFront()
+}
+
+func (c *Controller) ShowTXHistory() {
+	c.TXHistory.Show()
+	c.view.BringToFront()
+}
+
 func (c *Controller) Refresh() {
 	c.QSOList.Clear()
 	c.Logbook.ReplayAll()
-	c.Entry.Clear()
+	if c.n1mm != nil {
+		c.n1mm.ReplayFinished()
+	}
+	c.forEachEntry(func(_ so2r.Radio, e *entry.Controller) {
+		e.Clear()
+	})
 }
 
 func (c *Controller) ClearEntryFields() {
-	c.Entry.Clear()
+	c.FocusedEntry().Clear()
 }
 
 func (c *Controller) GotoEntryFields() {
-	c.Entry.Activate()
+	c.FocusedEntry().Activate()
 }
 
 func (c *Controller) EditLastQSO() {
-	c.Entry.EditLastQSO()
+	c.FocusedEntry().EditLastQSO()
 }
 
 func (c *Controller) LogQSO() {
-	c.Entry.Log()
+	c.FocusedEntry().Log()
 }
@@ -8,12 +8,14 @@ func newServiceStatus(asyncRunner core.AsyncRunner) *ServiceStatus {
 	return &ServiceStatus{
 		asyncRunner: asyncRunner,
 		status:      make(map[core.Service]bool),
+		databases:   make(map[core.Service]core.DatabaseInfo),
 	}
 }
 
 type ServiceStatus struct {
 	asyncRunner core.AsyncRunner
 	status      map[core.Service]bool
+	databases   map[core.Service]core.DatabaseInfo
 	listeners   []interface{}
 }
 
@@ -24,6 +26,11 @@ func (s *ServiceStatus) Notify(listener interface{}) {
 			serviceStatusListener.StatusChanged(service, available)
 		}
 	}
+	if databaseInfoListener, ok := listener.(core.DatabaseInfoListener); ok {
+		for _, info := range s.databases {
+			databaseInfoListener.DatabaseInfoChanged(info)
+		}
+	}
 }
 
 func (s *ServiceStatus) StatusChanged(service core.Service, available bool) {
@@ -36,3 +43,16 @@ func (s *ServiceStatus) StatusChanged(service core.Service, available bool) {
 		}
 	}
 }
+
+// DatabaseInfoChanged is called by the finders from their background goroutines, the info is recorded and emitted
+// on the UI goroutine.
+func (s *ServiceStatus) DatabaseInfoChanged(info core.DatabaseInfo) {
+	s.asyncRunner(func() {
+		s.databases[info.Service] = info
+		for _, listener := range s.listeners {
+			if databaseInfoListener, ok := listener.(core.DatabaseInfoListener); ok {
+				databaseInfoListener.DatabaseInfoChanged(info)
+			}
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package bandmap
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/ftl/hamradio/callsign"
+	"github.com/ftl/hamradio/dxcc"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
+)
+
+// DefaultMaxAge is the default time after which a spot is removed from the bandmap.
+const DefaultMaxAge = 30 * time.Minute
+
+// DXCCFinder returns a list of matching prefixes for the given string and indicates if there was a match at all.
+type DXCCFinder interface {
+	Find(string) (dxcc.Prefix, bool)
+}
+
+// DupeChecker can be used to find out if the given callsign was already worked, according to the contest rules.
+type DupeChecker interface {
+	FindWorkedQSOs(callsign.Callsign, core.Band, core.Mode) ([]core.QSO, bool)
+}
+
+// Valuer provides the points and multis of a QSO based on the given information.
+type Valuer interface {
+	Value(callsign callsign.Callsign, entity dxcc.Prefix, band core.Band, mode core.Mode, xchange string) (points, multis int)
+}
+
+// View defines the visual part of the hunting list.
+type View interface {
+	Show()
+	Hide()
+
+	ShowHuntingList([]core.AnnotatedSpot)
+}
+
+type HuntingListUpdatedListener interface {
+	HuntingListUpdated([]core.AnnotatedSpot)
+}
+
+type HuntingListUpdatedListenerFunc func([]core.AnnotatedSpot)
+
+func (f HuntingListUpdatedListenerFunc) HuntingListUpdated(entries []core.AnnotatedSpot) {
+	f(entries)
+}
+
+// New returns a new empty Bandmap.
+func New(clock core.Clock, entities DXCCFinder, dupeChecker DupeChecker, valuer Valuer) *Bandmap {
+	return &Bandmap{
+		clock:       clock,
+		view:        new(nullView),
+		entities:    entities,
+		dupeChecker: dupeChecker,
+		valuer:      valuer,
+		bandplan:    bandplan.IARURegion1,
+		maxAge:      DefaultMaxAge,
+		spots:       make(map[spotKey]core.Spot),
+	}
+}
+
+// Bandmap collects spots and ranks them by the value they would add to the current score.
+type Bandmap struct {
+	clock       core.Clock
+	view        View
+	entities    DXCCFinder
+	dupeChecker DupeChecker
+	valuer      Valuer
+	bandplan    bandplan.Bandplan
+	maxAge      time.Duration
+
+	listeners []interface{}
+
+	spots       map[spotKey]core.Spot
+	huntingList []core.AnnotatedSpot
+
+	callsign     callsign.Callsign
+	frequency    core.Frequency
+	band         core.Band
+	mode         core.Mode
+	lastSelected callsign.Callsign
+}
+
+type spotKey struct {
+	callsign callsign.Callsign
+	band     core.Band
+}
+
+func (m *Bandmap) SetView(view View) {
+	if view == nil {
+		m.view = new(nullView)
+		return
+	}
+	m.view = view
+	m.view.ShowHuntingList(m.huntingList)
+}
+
+func (m *Bandmap) Show() {
+	m.view.Show()
+	m.view.ShowHuntingList(m.huntingList)
+}
+
+func (m *Bandmap) Hide() {
+	m.view.Hide()
+}
+
+func (m *Bandmap) Notify(listener interface{}) {
+	m.listeners = append(m.listeners, listener)
+}
+
+// StationChanged selects the bandplan of the station's IARU region. Spots of the own station are ignored.
+func (m *Bandmap) StationChanged(station core.Station) {
+	m.bandplan = bandplans.ForRegion(station.Region)
+	m.callsign = station.Callsign
+}
+
+// Add adds the given spot to the bandmap. A previous spot of the same callsign on the same band is replaced.
+func (m *Bandmap) Add(spot core.Spot) {
+	if spot.Callsign == m.callsign {
+		return
+	}
+	if spot.Band == core.NoBand {
+		band := m.bandplan.ByFrequency(hamradio.Frequency(spot.Frequency))
+		if band.Name == bandplan.BandUnknown {
+			log.Printf("spot of %s on %s is outside of the bandplan", spot.Callsign, spot.Frequency)
+			return
+		}
+		spot.Band = core.Band(band.Name)
+	}
+	if spot.Time.IsZero() {
+		spot.Time = m.clock.Now()
+	}
+	m.spots[spotKey{spot.Callsign, spot.Band}] = spot
+	m.Update()
+}
+
+// Clear removes all spots from the bandmap.
+func (m *Bandmap) Clear() {
+	m.spots = make(map[spotKey]core.Spot)
+	m.lastSelected = callsign.Callsign{}
+	m.Update()
+}
+
+func (m *Bandmap) FrequencyChanged(frequency core.Frequency) {
+	if m.frequency == frequency {
+		return
+	}
+	m.frequency = frequency
+	m.Update()
+}
+
+func (m *Bandmap) BandChanged(band core.Band) {
+	if m.band == band {
+		return
+	}
+	m.band = band
+	m.lastSelected = callsign.Callsign{}
+	m.Update()
+}
+
+func (m *Bandmap) ModeChanged(mode core.Mode) {
+	if m.mode == mode {
+		return
+	}
+	m.mode = mode
+	m.Update()
+}
+
+func (m *Bandmap) QSOsCleared() {
+	m.Update()
+}
+
+func (m *Bandmap) QSOAdded(core.QSO) {
+	m.Update()
+}
+
+func (m *Bandmap) QSOUpdated(int, core.QSO, core.QSO) {
+	m.Update()
+}
+
+// HuntingList returns the spots on the current band that are not yet worked, the most valuable first.
+func (m *Bandmap) HuntingList() []core.AnnotatedSpot {
+	return m.huntingList
+}
+
+// NextBest returns the next spot from the hunting list, starting over with the most valuable one
+// when the end of the list is reached.
+func (m *Bandmap) NextBest() (core.AnnotatedSpot, bool) {
+	if len(m.huntingList) == 0 {
+		return core.AnnotatedSpot{}, false
+	}
+	next := 0
+	for i, entry := range m.huntingList {
+		if entry.Callsign == m.lastSelected {
+			next = (i + 1) % len(m.huntingList)
+			break
+		}
+	}
+	result := m.huntingList[next]
+	m.lastSelected = result.Callsign
+	return result, true
+}
+
+// Update removes outdated spots and ranks the remaining spots according to the current score and frequency.
+func (m *Bandmap) Update() {
+	m.removeOutdatedSpots()
+
+	huntingList := make([]core.AnnotatedSpot, 0, len(m.spots))
+	for _, spot := range m.spots {
+		if m.band != core.NoBand && spot.Band != m.band {
+			continue
+		}
+		entry := m.annotate(spot)
+		if entry.Duplicate {
+			continue
+		}
+		huntingList = append(huntingList, entry)
+	}
+	sort.Slice(huntingList, func(i, j int) bool {
+		return higherRanked(huntingList[i], huntingList[j])
+	})
+	m.huntingList = huntingList
+
+	m.emitHuntingListUpdated(m.huntingList)
+}
+
+func (m *Bandmap) removeOutdatedSpots() {
+	deadline := m.clock.Now().Add(-m.maxAge)
+	for key, spot := range m.spots {
+		if spot.Time.Before(deadline) {
+			delete(m.spots, key)
+		}
+	}
+}
+
+func (m *Bandmap) annotate(spot core.Spot) core.AnnotatedSpot {
+	result := core.AnnotatedSpot{
+		Spot:     spot,
+		Distance: core.Frequency(math.Abs(float64(spot.Frequency - m.frequency))),
+	}
+	mode := spot.Mode
+	if mode == core.NoMode {
+		mode = m.mode
+	}
+
+	_, result.Duplicate = m.dupeChecker.FindWorkedQSOs(spot.Callsign, spot.Band, mode)
+
+	entity, found := m.entities.Find(spot.Callsign.String())
+	if found {
+		result.Points, result.Multis = m.valuer.Value(spot.Callsign, entity, spot.Band, mode, "")
+	}
+	return result
+}
+
+// higherRanked orders the spots by multis, then by points, then by the distance from the current frequency.
+func higherRanked(a, b core.AnnotatedSpot) bool {
+	if a.Multis != b.Multis {
+		return a.Multis > b.Multis
+	}
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.Callsign.String() < b.Callsign.String()
+}
+
+func (m *Bandmap) emitHuntingListUpdated(huntingList []core.AnnotatedSpot) {
+	m.view.ShowHuntingList(huntingList)
+	for _, listener := range m.listeners {
+		if huntingListUpdatedListener, ok := listener.(HuntingListUpdatedListener); ok {
+			huntingListUpdatedListener.HuntingListUpdated(huntingList)
+		}
+	}
+}
+
+type nullView struct{}
+
+func (v *nullView) Show()                                {}
+func (v *nullView) Hide()                                {}
+func (v *nullView) ShowHuntingList([]core.AnnotatedSpot) {}
@@ -0,0 +1,127 @@
+package bandmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/ftl/hamradio/dxcc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/clock"
+)
+
+var now = time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+func TestHuntingList_OnlyCurrentBandAndNotWorked(t *testing.T) {
+	bandmap, dupes, _ := setupBandmapTest()
+	dupes.worked["DL1ABC"] = true
+	bandmap.BandChanged(core.Band40m)
+
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL1ABC"), Frequency: 7010000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL2ABC"), Frequency: 7012000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL3ABC"), Frequency: 14012000})
+
+	huntingList := bandmap.HuntingList()
+	if assert.Equal(t, 1, len(huntingList)) {
+		assert.Equal(t, "DL2ABC", huntingList[0].Callsign.String())
+		assert.Equal(t, core.Band40m, huntingList[0].Band)
+	}
+}
+
+func TestHuntingList_RankedByValueAndDistance(t *testing.T) {
+	bandmap, _, valuer := setupBandmapTest()
+	valuer.values["DL1ABC"] = value{points: 1, multis: 0}
+	valuer.values["DL2ABC"] = value{points: 1, multis: 1}
+	valuer.values["DL3ABC"] = value{points: 3, multis: 0}
+	valuer.values["DL4ABC"] = value{points: 3, multis: 0}
+	bandmap.BandChanged(core.Band40m)
+	bandmap.FrequencyChanged(7020000)
+
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL1ABC"), Frequency: 7020000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL2ABC"), Frequency: 7030000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL3ABC"), Frequency: 7010000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL4ABC"), Frequency: 7025000})
+
+	huntingList := bandmap.HuntingList()
+	actual := make([]string, len(huntingList))
+	for i, entry := range huntingList {
+		actual[i] = entry.Callsign.String()
+	}
+	assert.Equal(t, []string{"DL2ABC", "DL4ABC", "DL3ABC", "DL1ABC"}, actual)
+	assert.Equal(t, core.Frequency(5000), huntingList[1].Distance)
+}
+
+func TestHuntingList_RemovesOutdatedSpots(t *testing.T) {
+	bandmap, _, _ := setupBandmapTest()
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL1ABC"), Frequency: 7010000, Time: now.Add(-DefaultMaxAge - time.Second)})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL2ABC"), Frequency: 7012000})
+
+	huntingList := bandmap.HuntingList()
+	if assert.Equal(t, 1, len(huntingList)) {
+		assert.Equal(t, "DL2ABC", huntingList[0].Callsign.String())
+	}
+}
+
+func TestNextBest(t *testing.T) {
+	bandmap, _, valuer := setupBandmapTest()
+	valuer.values["DL1ABC"] = value{points: 1, multis: 1}
+	valuer.values["DL2ABC"] = value{points: 1, multis: 0}
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL1ABC"), Frequency: 7010000})
+	bandmap.Add(core.Spot{Callsign: callsign.MustParse("DL2ABC"), Frequency: 7012000})
+
+	first, ok := bandmap.NextBest()
+	assert.True(t, ok)
+	assert.Equal(t, "DL1ABC", first.Callsign.String())
+	second, _ := bandmap.NextBest()
+	assert.Equal(t, "DL2ABC", second.Callsign.String())
+	third, _ := bandmap.NextBest()
+	assert.Equal(t, "DL1ABC", third.Callsign.String())
+}
+
+func TestNextBest_Empty(t *testing.T) {
+	bandmap, _, _ := setupBandmapTest()
+
+	_, ok := bandmap.NextBest()
+
+	assert.False(t, ok)
+}
+
+func setupBandmapTest() (*Bandmap, *testDupeChecker, *testValuer) {
+	dupes := &testDupeChecker{worked: make(map[string]bool)}
+	valuer := &testValuer{values: make(map[string]value)}
+	result := New(clock.Static(now), new(testEntities), dupes, valuer)
+	return result, dupes, valuer
+}
+
+type testEntities struct{}
+
+func (e *testEntities) Find(string) (dxcc.Prefix, bool) {
+	return dxcc.Prefix{Prefix: "DL", PrimaryPrefix: "DL", Continent: "EU", CQZone: 14, ITUZone: 28}, true
+}
+
+type testDupeChecker struct {
+	worked map[string]bool
+}
+
+func (d *testDupeChecker) FindWorkedQSOs(call callsign.Callsign, _ core.Band, _ core.Mode) ([]core.QSO, bool) {
+	if d.worked[call.String()] {
+		return []core.QSO{{Callsign: call}}, true
+	}
+	return []core.QSO{}, false
+}
+
+type value struct {
+	points int
+	multis int
+}
+
+type testValuer struct {
+	values map[string]value
+}
+
+func (v *testValuer) Value(call callsign.Callsign, _ dxcc.Prefix, _ core.Band, _ core.Mode, _ string) (int, int) {
+	result := v.values[call.String()]
+	return result.points, result.multis
+}
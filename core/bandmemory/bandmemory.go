@@ -0,0 +1,142 @@
+// Package bandmemory remembers the last used frequency for each band and mode, and whether we were running there.
+package bandmemory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// New returns a new empty memory that is not persisted.
+func New() *Memory {
+	return &Memory{
+		entries: make(map[key]entry),
+	}
+}
+
+// Load returns a memory that is persisted in the given file. If the file does not exist yet, the memory is empty.
+func Load(filename string) (*Memory, error) {
+	result := New()
+	result.filename = filename
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	var records []record
+	err = json.Unmarshal(data, &records)
+	if err != nil {
+		return result, err
+	}
+	for _, r := range records {
+		result.entries[key{r.Band, r.Mode}] = entry{frequency: r.Frequency, running: r.Running}
+	}
+	return result, nil
+}
+
+// Memory holds the last used frequency for each band and mode. It is safe for concurrent use.
+type Memory struct {
+	filename string
+
+	lock    sync.Mutex
+	entries map[key]entry
+	dirty   bool
+}
+
+type key struct {
+	band core.Band
+	mode core.Mode
+}
+
+type entry struct {
+	frequency core.Frequency
+	running   bool
+}
+
+type record struct {
+	Band      core.Band      `json:"band"`
+	Mode      core.Mode      `json:"mode"`
+	Frequency core.Frequency `json:"frequency"`
+	Running   bool           `json:"running"`
+}
+
+// Remember stores the given frequency and workmode as last used on the given band and mode.
+func (m *Memory) Remember(band core.Band, mode core.Mode, frequency core.Frequency, workmode core.Workmode) {
+	if band == core.NoBand || mode == core.NoMode || frequency == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e := entry{frequency: frequency, running: workmode == core.Run}
+	if m.entries[key{band, mode}] == e {
+		return
+	}
+	m.entries[key{band, mode}] = e
+	m.dirty = true
+}
+
+// Recall returns the last used frequency and workmode on the given band and mode.
+func (m *Memory) Recall(band core.Band, mode core.Mode) (core.Frequency, core.Workmode, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e, ok := m.entries[key{band, mode}]
+	if !ok {
+		return 0, core.SearchPounce, false
+	}
+	if e.running {
+		return e.frequency, core.Run, true
+	}
+	return e.frequency, core.SearchPounce, true
+}
+
+// Save writes the memory to its file, if anything changed since it was loaded or saved the last time.
+func (m *Memory) Save() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.filename == "" || !m.dirty {
+		return nil
+	}
+
+	records := make([]record, 0, len(m.entries))
+	for k, e := range m.entries {
+		records = append(records, record{Band: k.band, Mode: k.mode, Frequency: e.frequency, Running: e.running})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Band != records[j].Band {
+			return bandIndex(records[i].Band) < bandIndex(records[j].Band)
+		}
+		return records[i].Mode < records[j].Mode
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(m.filename, data, 0644)
+	if err != nil {
+		return err
+	}
+	log.Printf("Frequencies saved to %s", m.filename)
+	m.dirty = false
+	return nil
+}
+
+func bandIndex(band core.Band) int {
+	for i, b := range core.Bands {
+		if b == band {
+			return i
+		}
+	}
+	return len(core.Bands)
+}
@@ -0,0 +1,55 @@
+package bandmemory
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestRememberAndRecall(t *testing.T) {
+	memory := New()
+
+	_, _, ok := memory.Recall(core.Band40m, core.ModeCW)
+	assert.False(t, ok)
+
+	memory.Remember(core.Band40m, core.ModeCW, 7025000, core.Run)
+	memory.Remember(core.Band40m, core.ModeSSB, 7150000, core.SearchPounce)
+	memory.Remember(core.NoBand, core.ModeCW, 7030000, core.Run)
+
+	frequency, workmode, ok := memory.Recall(core.Band40m, core.ModeCW)
+	assert.True(t, ok)
+	assert.Equal(t, core.Frequency(7025000), frequency)
+	assert.Equal(t, core.Run, workmode)
+
+	frequency, workmode, ok = memory.Recall(core.Band40m, core.ModeSSB)
+	assert.True(t, ok)
+	assert.Equal(t, core.Frequency(7150000), frequency)
+	assert.Equal(t, core.SearchPounce, workmode)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "frequencies")
+	memory, err := Load(filename)
+	require.NoError(t, err)
+
+	memory.Remember(core.Band20m, core.ModeCW, 14025000, core.Run)
+	memory.Remember(core.Band80m, core.ModeSSB, 3750000, core.SearchPounce)
+	require.NoError(t, memory.Save())
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+
+	frequency, workmode, ok := loaded.Recall(core.Band20m, core.ModeCW)
+	assert.True(t, ok)
+	assert.Equal(t, core.Frequency(14025000), frequency)
+	assert.Equal(t, core.Run, workmode)
+
+	frequency, workmode, ok = loaded.Recall(core.Band80m, core.ModeSSB)
+	assert.True(t, ok)
+	assert.Equal(t, core.Frequency(3750000), frequency)
+	assert.Equal(t, core.SearchPounce, workmode)
+}
@@ -0,0 +1,110 @@
+// Package bandplans provides the HF bandplans of all three IARU regions. The bandplan of region 1 comes with the
+// hamradio library, the bandplans of region 2 and 3 are defined here.
+package bandplans
+
+import (
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// ForRegion returns the bandplan of the given IARU region. If the region is unknown, the bandplan of region 1 is used.
+func ForRegion(region core.IARURegion) bandplan.Bandplan {
+	switch region {
+	case core.IARURegion2:
+		return IARURegion2
+	case core.IARURegion3:
+		return IARURegion3
+	default:
+		return bandplan.IARURegion1
+	}
+}
+
+// ModePortionCenter returns the center of the first portion of the given band that is dedicated to the given mode.
+// If there is no such portion, the center of the band is returned.
+func ModePortionCenter(band bandplan.Band, mode bandplan.Mode) core.Frequency {
+	for _, portion := range band.Portions {
+		if portion.Mode == mode {
+			return core.Frequency(portion.Center())
+		}
+	}
+	return core.Frequency(band.Center())
+}
+
+// IARURegion2 is the HF bandplan of IARU region 2 (the Americas).
+var IARURegion2 = bandplan.Bandplan{
+	bandplan.Band160m: band(bandplan.Band160m, 1800000, 2000000,
+		portion(bandplan.ModeCW, 1800000, 1840000),
+		portion(bandplan.ModeDigital, 1840000, 1850000),
+		portion(bandplan.ModePhone, 1850000, 2000000),
+	),
+	bandplan.Band80m: band(bandplan.Band80m, 3500000, 4000000,
+		portion(bandplan.ModeCW, 3500000, 3570000),
+		portion(bandplan.ModeDigital, 3570000, 3600000),
+		portion(bandplan.ModePhone, 3600000, 4000000),
+	),
+	bandplan.Band60m: bandplan.IARURegion1[bandplan.Band60m],
+	bandplan.Band40m: band(bandplan.Band40m, 7000000, 7300000,
+		portion(bandplan.ModeCW, 7000000, 7040000),
+		portion(bandplan.ModeDigital, 7040000, 7053000),
+		portion(bandplan.ModePhone, 7053000, 7300000),
+	),
+	bandplan.Band30m: bandplan.IARURegion1[bandplan.Band30m],
+	bandplan.Band20m: bandplan.IARURegion1[bandplan.Band20m],
+	bandplan.Band17m: bandplan.IARURegion1[bandplan.Band17m],
+	bandplan.Band15m: bandplan.IARURegion1[bandplan.Band15m],
+	bandplan.Band12m: bandplan.IARURegion1[bandplan.Band12m],
+	bandplan.Band10m: band(bandplan.Band10m, 28000000, 29700000,
+		portion(bandplan.ModeCW, 28000000, 28070000),
+		portion(bandplan.ModeDigital, 28070000, 28190000),
+		portion(bandplan.ModeBeacon, 28190000, 28300000),
+		portion(bandplan.ModePhone, 28300000, 29700000),
+	),
+}
+
+// IARURegion3 is the HF bandplan of IARU region 3 (Asia and the Pacific).
+var IARURegion3 = bandplan.Bandplan{
+	bandplan.Band160m: band(bandplan.Band160m, 1800000, 2000000,
+		portion(bandplan.ModeCW, 1800000, 1830000),
+		portion(bandplan.ModeDigital, 1830000, 1840000),
+		portion(bandplan.ModePhone, 1840000, 2000000),
+	),
+	bandplan.Band80m: band(bandplan.Band80m, 3500000, 3900000,
+		portion(bandplan.ModeCW, 3500000, 3535000),
+		portion(bandplan.ModeDigital, 3535000, 3600000),
+		portion(bandplan.ModePhone, 3600000, 3900000),
+	),
+	bandplan.Band60m: bandplan.IARURegion1[bandplan.Band60m],
+	bandplan.Band40m: band(bandplan.Band40m, 7000000, 7300000,
+		portion(bandplan.ModeCW, 7000000, 7025000),
+		portion(bandplan.ModeDigital, 7025000, 7040000),
+		portion(bandplan.ModePhone, 7040000, 7300000),
+	),
+	bandplan.Band30m: bandplan.IARURegion1[bandplan.Band30m],
+	bandplan.Band20m: bandplan.IARURegion1[bandplan.Band20m],
+	bandplan.Band17m: bandplan.IARURegion1[bandplan.Band17m],
+	bandplan.Band15m: bandplan.IARURegion1[bandplan.Band15m],
+	bandplan.Band12m: bandplan.IARURegion1[bandplan.Band12m],
+	bandplan.Band10m: band(bandplan.Band10m, 28000000, 29700000,
+		portion(bandplan.ModeCW, 28000000, 28050000),
+		portion(bandplan.ModeDigital, 28050000, 28190000),
+		portion(bandplan.ModeBeacon, 28190000, 28200000),
+		portion(bandplan.ModePhone, 28200000, 29700000),
+	),
+}
+
+func band(name bandplan.BandName, from, to hamradio.Frequency, portions ...bandplan.Portion) bandplan.Band {
+	return bandplan.Band{
+		Name:           name,
+		FrequencyRange: hamradio.FrequencyRange{From: from, To: to},
+		Portions:       portions,
+	}
+}
+
+func portion(mode bandplan.Mode, from, to hamradio.Frequency) bandplan.Portion {
+	return bandplan.Portion{
+		Mode:           mode,
+		FrequencyRange: hamradio.FrequencyRange{From: from, To: to},
+	}
+}
@@ -0,0 +1,55 @@
+package bandplans
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestForRegion(t *testing.T) {
+	testCases := []struct {
+		region    core.IARURegion
+		frequency core.Frequency
+		expected  bandplan.BandName
+	}{
+		{core.NoRegion, 7150000, bandplan.Band40m},
+		{core.NoRegion, 7250000, bandplan.BandUnknown},
+		{core.IARURegion1, 3850000, bandplan.BandUnknown},
+		{core.IARURegion2, 7250000, bandplan.Band40m},
+		{core.IARURegion2, 3850000, bandplan.Band80m},
+		{core.IARURegion3, 3850000, bandplan.Band80m},
+		{core.IARURegion3, 3950000, bandplan.BandUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.region.String()+" "+tc.frequency.String(), func(t *testing.T) {
+			band := ForRegion(tc.region).ByFrequency(hamradio.Frequency(tc.frequency))
+			assert.Equal(t, tc.expected, band.Name)
+		})
+	}
+}
+
+func TestModePortionCenter(t *testing.T) {
+	testCases := []struct {
+		region   core.IARURegion
+		band     bandplan.BandName
+		mode     bandplan.Mode
+		expected core.Frequency
+	}{
+		{core.IARURegion1, bandplan.Band40m, bandplan.ModeCW, 7020000},
+		{core.IARURegion2, bandplan.Band40m, bandplan.ModeCW, 7020000},
+		{core.IARURegion3, bandplan.Band40m, bandplan.ModeCW, 7012500},
+		{core.IARURegion1, bandplan.Band80m, bandplan.ModePhone, 3710000},
+		{core.IARURegion2, bandplan.Band80m, bandplan.ModePhone, 3800000},
+		{core.IARURegion1, bandplan.Band10m, bandplan.ModeCW, 28850000},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.region.String()+" "+string(tc.band)+" "+string(tc.mode), func(t *testing.T) {
+			actual := ModePortionCenter(ForRegion(tc.region)[tc.band], tc.mode)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package callbook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// DefaultMaxAge is the default time how long a cached entry is used before it is looked up again.
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+// notFoundMaxAge is the time how long a callsign that was not found is not looked up again. Callsigns that were not
+// found are only kept in memory.
+const notFoundMaxAge = time.Hour
+
+// failedLookupDelay is the time how long no callsign is looked up after a lookup failed, e.g. because the callbook
+// cannot be reached.
+const failedLookupDelay = time.Minute
+
+// Lookup looks up the callbook entry for a callsign and indicates if the callsign was found at all.
+type Lookup interface {
+	Lookup(call string) (core.CallbookEntry, bool, error)
+}
+
+// NewCache returns a new cache for the given lookup that keeps the found entries in the given file. The cached entries
+// are used until they are older than maxAge.
+func NewCache(filename string, maxAge time.Duration, clock core.Clock, lookup Lookup) *Cache {
+	result := &Cache{
+		filename: filename,
+		maxAge:   maxAge,
+		clock:    clock,
+		lookup:   lookup,
+		entries:  make(map[string]cacheEntry),
+		notFound: make(map[string]time.Time),
+	}
+
+	err := result.load()
+	if err != nil {
+		log.Printf("Cannot load the callbook cache from %s: %v", filename, err)
+	}
+
+	return result
+}
+
+// Cache caches the results of a lookup on disk.
+type Cache struct {
+	filename string
+	maxAge   time.Duration
+	clock    core.Clock
+	lookup   Lookup
+
+	lock       sync.Mutex
+	entries    map[string]cacheEntry
+	notFound   map[string]time.Time
+	lastFailed time.Time
+}
+
+type cacheEntry struct {
+	Entry   core.CallbookEntry
+	Updated time.Time
+}
+
+// Lookup returns the cached entry for the given callsign. If there is no current entry in the cache, the callsign is
+// looked up and the result is stored in the cache. After a lookup failed, callsigns that are not cached are reported
+// as not found for a while without looking them up.
+func (c *Cache) Lookup(call string) (core.CallbookEntry, bool, error) {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	now := c.clock.Now()
+	if entry, found, cached := c.cached(call, now); cached {
+		return entry, found, nil
+	}
+
+	entry, found, err := c.lookup.Lookup(call)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		c.lastFailed = now
+		return core.CallbookEntry{}, false, err
+	}
+	if !found {
+		c.notFound[call] = now
+		return core.CallbookEntry{}, false, nil
+	}
+	c.entries[call] = cacheEntry{Entry: entry, Updated: now}
+	err = c.save()
+	if err != nil {
+		log.Printf("Cannot save the callbook cache to %s: %v", c.filename, err)
+	}
+	return entry, true, nil
+}
+
+func (c *Cache) cached(call string, now time.Time) (core.CallbookEntry, bool, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[call]; ok && now.Sub(entry.Updated) < c.maxAge {
+		return entry.Entry, true, true
+	}
+	if lookedUp, ok := c.notFound[call]; ok && now.Sub(lookedUp) < notFoundMaxAge {
+		return core.CallbookEntry{}, false, true
+	}
+	if !c.lastFailed.IsZero() && now.Sub(c.lastFailed) < failedLookupDelay {
+		return core.CallbookEntry{}, false, true
+	}
+	return core.CallbookEntry{}, false, false
+}
+
+func (c *Cache) load() error {
+	if c.filename == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.entries)
+}
+
+func (c *Cache) save() error {
+	if c.filename == "" {
+		return nil
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(c.filename), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmpFilename := c.filename + ".tmp"
+	err = ioutil.WriteFile(tmpFilename, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, c.filename)
+}
@@ -0,0 +1,128 @@
+package callbook
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/clock"
+)
+
+var now = time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+var testEntry = core.CallbookEntry{
+	Callsign: "DL1ABC",
+	Name:     "Hans",
+	QTH:      "Muenchen",
+	Country:  "Germany",
+	Grid:     "JN58TD",
+	QSLVia:   "DL2ABC",
+	LoTW:     true,
+	Direct:   true,
+}
+
+func TestClient_Lookup(t *testing.T) {
+	server := setupStubServer(t)
+	client := NewClient(server.URL(), "user", "secret")
+
+	entry, found, err := client.Lookup("dl1abc")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, testEntry, entry)
+
+	_, found, err = client.Lookup("DL0XYZ")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestClient_RenewsExpiredSession(t *testing.T) {
+	server := setupStubServer(t)
+	client := NewClient(server.URL(), "user", "secret")
+	_, _, err := client.Lookup("DL1ABC")
+	require.NoError(t, err)
+
+	server.ExpireSessions()
+	_, found, err := client.Lookup("DL1ABC")
+
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2, server.Lookups())
+}
+
+func TestClient_WrongPassword(t *testing.T) {
+	server := setupStubServer(t)
+	client := NewClient(server.URL(), "user", "wrong")
+
+	_, found, err := client.Lookup("DL1ABC")
+
+	assert.Error(t, err)
+	assert.False(t, found)
+	assert.Equal(t, 0, server.Lookups())
+}
+
+func TestCache_LooksUpOnlyOnce(t *testing.T) {
+	server := setupStubServer(t)
+	filename := filepath.Join(t.TempDir(), "callbook.json")
+	cache := NewCache(filename, DefaultMaxAge, clock.Static(now), NewClient(server.URL(), "user", "secret"))
+
+	for i := 0; i < 2; i++ {
+		entry, found, err := cache.Lookup("DL1ABC")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, testEntry, entry)
+		_, found, err = cache.Lookup("DL0XYZ")
+		require.NoError(t, err)
+		assert.False(t, found)
+	}
+
+	assert.Equal(t, 2, server.Lookups())
+}
+
+func TestCache_WaitsAfterFailedLookup(t *testing.T) {
+	server := setupStubServer(t)
+	cache := NewCache("", DefaultMaxAge, clock.Static(now), NewClient(server.URL(), "user", "secret"))
+	server.Close()
+
+	_, _, err := cache.Lookup("DL1ABC")
+	assert.Error(t, err)
+	_, found, err := cache.Lookup("DL2ABC")
+	assert.NoError(t, err, "no lookup right after a failed lookup")
+	assert.False(t, found)
+
+	cache.clock = clock.Static(now.Add(failedLookupDelay))
+	_, _, err = cache.Lookup("DL2ABC")
+	assert.Error(t, err)
+}
+
+func TestCache_PersistsEntries(t *testing.T) {
+	server := setupStubServer(t)
+	filename := filepath.Join(t.TempDir(), "callbook.json")
+	client := NewClient(server.URL(), "user", "secret")
+	_, _, err := NewCache(filename, DefaultMaxAge, clock.Static(now), client).Lookup("DL1ABC")
+	require.NoError(t, err)
+
+	cache := NewCache(filename, DefaultMaxAge, clock.Static(now.Add(DefaultMaxAge-time.Minute)), client)
+	entry, found, err := cache.Lookup("DL1ABC")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, testEntry, entry)
+	assert.Equal(t, 1, server.Lookups())
+
+	cache = NewCache(filename, DefaultMaxAge, clock.Static(now.Add(DefaultMaxAge)), client)
+	_, found, err = cache.Lookup("DL1ABC")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2, server.Lookups())
+}
+
+func setupStubServer(t *testing.T) *StubServer {
+	t.Helper()
+	server, err := NewStubServer("", "user", "secret", testEntry)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server
+}
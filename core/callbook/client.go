@@ -0,0 +1,163 @@
+// Package callbook looks up information about stations in an online callbook that provides an XML API in the style
+// of HamQTH.com. The results are cached on disk.
+package callbook
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// DefaultURL is the URL of the XML API of HamQTH.com.
+const DefaultURL = "https://www.hamqth.com/xml.php"
+
+// The error messages of the XML API that need special treatment.
+const (
+	notFoundError       = "Callsign not found"
+	sessionExpiredError = "Session does not exist or expired"
+)
+
+// Program is the name of the program that is sent to the XML API with every lookup.
+const Program = "hellocontest"
+
+// NewClient returns a new client for the XML API at the given URL. If the URL is empty, DefaultURL is used.
+func NewClient(baseURL, username, password string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	return &Client{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		baseURL:  baseURL,
+		username: username,
+		password: password,
+	}
+}
+
+// Client looks up callsigns using the XML API of an online callbook. The client logs in automatically and renews the
+// session when it expires.
+type Client struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	password string
+
+	lock      sync.Mutex
+	sessionID string
+}
+
+// response is the XML document returned by the API, both for the login and the lookup.
+type response struct {
+	XMLName xml.Name        `xml:"HamQTH"`
+	Session responseSession `xml:"session"`
+	Search  responseSearch  `xml:"search"`
+}
+
+type responseSession struct {
+	SessionID string `xml:"session_id"`
+	Error     string `xml:"error"`
+}
+
+type responseSearch struct {
+	Callsign string `xml:"callsign"`
+	Nick     string `xml:"nick"`
+	QTH      string `xml:"qth"`
+	Country  string `xml:"country"`
+	Grid     string `xml:"grid"`
+	QSLVia   string `xml:"qsl_via"`
+	LoTW     string `xml:"lotw"`
+	EQSL     string `xml:"eqsl"`
+	QSL      string `xml:"qsldirect"`
+}
+
+func (s responseSearch) toCore() core.CallbookEntry {
+	return core.CallbookEntry{
+		Callsign: strings.ToUpper(s.Callsign),
+		Name:     s.Nick,
+		QTH:      s.QTH,
+		Country:  s.Country,
+		Grid:     strings.ToUpper(s.Grid),
+		QSLVia:   strings.ToUpper(s.QSLVia),
+		LoTW:     s.LoTW == "Y",
+		EQSL:     s.EQSL == "Y",
+		Direct:   s.QSL == "Y",
+	}
+}
+
+// Lookup returns the callbook entry for the given callsign and indicates if the callsign was found at all.
+func (c *Client) Lookup(call string) (core.CallbookEntry, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	call = strings.ToUpper(strings.TrimSpace(call))
+	for attempt := 0; attempt < 2; attempt++ {
+		if c.sessionID == "" {
+			err := c.login()
+			if err != nil {
+				return core.CallbookEntry{}, false, err
+			}
+		}
+
+		resp, err := c.get(url.Values{
+			"id":       {c.sessionID},
+			"callsign": {call},
+			"prg":      {Program},
+		})
+		if err != nil {
+			return core.CallbookEntry{}, false, err
+		}
+		switch resp.Session.Error {
+		case "":
+			return resp.Search.toCore(), true, nil
+		case notFoundError:
+			return core.CallbookEntry{}, false, nil
+		case sessionExpiredError:
+			c.sessionID = ""
+			continue
+		default:
+			return core.CallbookEntry{}, false, fmt.Errorf("lookup of %s failed: %s", call, resp.Session.Error)
+		}
+	}
+	return core.CallbookEntry{}, false, fmt.Errorf("lookup of %s failed: cannot open a session", call)
+}
+
+func (c *Client) login() error {
+	resp, err := c.get(url.Values{
+		"u": {c.username},
+		"p": {c.password},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Session.Error != "" {
+		return fmt.Errorf("login failed: %s", resp.Session.Error)
+	}
+	if resp.Session.SessionID == "" {
+		return fmt.Errorf("login failed: no session")
+	}
+	c.sessionID = resp.Session.SessionID
+	return nil
+}
+
+func (c *Client) get(values url.Values) (response, error) {
+	resp, err := c.client.Get(c.baseURL + "?" + values.Encode())
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return response{}, fmt.Errorf("%s: %s", c.baseURL, resp.Status)
+	}
+
+	var result response
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return response{}, fmt.Errorf("invalid response from %s: %v", c.baseURL, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package callbook
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// NewStubServer starts a local server that provides the given entries through the XML API. It is meant to exercise the
+// callbook integration without an online callbook, e.g. in tests. The server listens on the given address. If the
+// address is empty, a random local port is used.
+func NewStubServer(address, username, password string, entries ...core.CallbookEntry) (*StubServer, error) {
+	if address == "" {
+		address = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StubServer{
+		listener: listener,
+		username: username,
+		password: password,
+		entries:  make(map[string]core.CallbookEntry),
+		sessions: make(map[string]bool),
+	}
+	for _, entry := range entries {
+		result.entries[strings.ToUpper(entry.Callsign)] = entry
+	}
+	result.server = &http.Server{Handler: http.HandlerFunc(result.serve)}
+	go result.server.Serve(listener)
+
+	return result, nil
+}
+
+// StubServer is a local server that simulates the XML API of an online callbook.
+type StubServer struct {
+	listener net.Listener
+	server   *http.Server
+	username string
+	password string
+	entries  map[string]core.CallbookEntry
+
+	lock        sync.Mutex
+	sessions    map[string]bool
+	lastSession int
+	lookups     int
+}
+
+// URL returns the URL of the XML API of the server.
+func (s *StubServer) URL() string {
+	return fmt.Sprintf("http://%s/xml.php", s.listener.Addr())
+}
+
+// Close shuts the server down.
+func (s *StubServer) Close() {
+	s.server.Close()
+}
+
+// ExpireSessions expires all open sessions, the clients have to log in again.
+func (s *StubServer) ExpireSessions() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// Lookups returns the number of lookups that were requested from the server.
+func (s *StubServer) Lookups() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lookups
+}
+
+func (s *StubServer) serve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var resp response
+	if sessionID := query.Get("id"); sessionID != "" {
+		resp = s.lookup(sessionID, query.Get("callsign"))
+	} else {
+		resp = s.login(query.Get("u"), query.Get("p"))
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(resp)
+}
+
+func (s *StubServer) login(username, password string) response {
+	if username != s.username || password != s.password {
+		return response{Session: responseSession{Error: "Wrong user name or password"}}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastSession++
+	sessionID := fmt.Sprintf("session%d", s.lastSession)
+	s.sessions[sessionID] = true
+	return response{Session: responseSession{SessionID: sessionID}}
+}
+
+func (s *StubServer) lookup(sessionID, call string) response {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.sessions[sessionID] {
+		return response{Session: responseSession{Error: sessionExpiredError}}
+	}
+
+	s.lookups++
+	entry, ok := s.entries[strings.ToUpper(call)]
+	if !ok {
+		return response{Session: responseSession{Error: notFoundError}}
+	}
+	return response{Search: responseSearch{
+		Callsign: strings.ToLower(entry.Callsign),
+		Nick:     entry.Name,
+		QTH:      entry.QTH,
+		Country:  entry.Country,
+		Grid:     strings.ToLower(entry.Grid),
+		QSLVia:   entry.QSLVia,
+		LoTW:     yesNo(entry.LoTW),
+		EQSL:     yesNo(entry.EQSL),
+		QSL:      yesNo(entry.Direct),
+	}}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
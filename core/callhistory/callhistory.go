@@ -0,0 +1,149 @@
+// Package callhistory predicts the exchange of a station from a call history file in the format of N1MM+.
+package callhistory
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+const (
+	orderPrefix  = "!!Order!!"
+	commentStart = "#"
+	callColumn   = "call"
+)
+
+// defaultColumns are used if the file does not define the order of its columns.
+var defaultColumns = []string{"call", "name", "state", "cqzone", "exch1"}
+
+// defaultFields make up the predicted exchange if no fields are configured.
+var defaultFields = []string{"exch1"}
+
+// Entry contains the values of all columns for one callsign, the column names are in lower case.
+type Entry map[string]string
+
+// New returns a new finder that uses the given call history files, depending on the current contest.
+func New(files []core.CallHistory) *Finder {
+	return &Finder{
+		files: files,
+	}
+}
+
+// Finder provides the entries of the call history file that is configured for the current contest.
+type Finder struct {
+	files   []core.CallHistory
+	fields  []string
+	entries map[string]Entry
+}
+
+// ContestChanged loads the call history file that is configured for the given contest.
+func (f *Finder) ContestChanged(contest core.Contest) {
+	f.entries = nil
+	f.fields = nil
+
+	file, ok := f.fileFor(contest.Name)
+	if !ok {
+		return
+	}
+	entries, err := Load(file.Filename)
+	if err != nil {
+		log.Printf("cannot load the call history from %s: %v", file.Filename, err)
+		return
+	}
+	f.entries = entries
+	f.fields = file.Fields
+	log.Printf("call history for %s loaded from %s: %d entries", contest.Name, file.Filename, len(entries))
+}
+
+func (f *Finder) fileFor(contestName string) (core.CallHistory, bool) {
+	for _, file := range f.files {
+		if file.Filename != "" && strings.EqualFold(file.Contest, contestName) {
+			return file, true
+		}
+	}
+	return core.CallHistory{}, false
+}
+
+// Find returns the entry for the given callsign.
+func (f *Finder) Find(call string) (Entry, bool) {
+	entry, ok := f.entries[normalize(call)]
+	return entry, ok
+}
+
+// PredictXchange returns the exchange that is expected from the given callsign, according to the call history.
+func (f *Finder) PredictXchange(call string) (string, bool) {
+	entry, ok := f.Find(call)
+	if !ok {
+		return "", false
+	}
+	fields := f.fields
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := entry[strings.ToLower(field)]
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return "", false
+	}
+	return strings.Join(values, " "), true
+}
+
+// Load reads the call history file with the given name.
+func Load(filename string) (map[string]Entry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read reads call history entries from the given reader. Lines starting with # are comments. The !!Order!! line
+// defines the names of the columns, otherwise the columns are call, name, state, cqzone, exch1.
+func Read(r io.Reader) (map[string]Entry, error) {
+	result := make(map[string]Entry)
+	columns := defaultColumns
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentStart) {
+			continue
+		}
+		values := strings.Split(line, ",")
+		if strings.EqualFold(values[0], orderPrefix) {
+			columns = make([]string, len(values)-1)
+			for i, column := range values[1:] {
+				columns[i] = strings.ToLower(strings.TrimSpace(column))
+			}
+			continue
+		}
+
+		entry := make(Entry)
+		for i, value := range values {
+			if i >= len(columns) {
+				break
+			}
+			entry[columns[i]] = strings.TrimSpace(value)
+		}
+		call := normalize(entry[callColumn])
+		if call == "" {
+			continue
+		}
+		entry[callColumn] = call
+		result[call] = entry
+	}
+	return result, scanner.Err()
+}
+
+func normalize(call string) string {
+	return strings.ToUpper(strings.TrimSpace(call))
+}
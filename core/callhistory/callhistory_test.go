@@ -0,0 +1,73 @@
+package callhistory
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+const testFile = `# call history for testing
+!!Order!!,Call,Name,State,CQZone,Exch1
+dl1abc,Flo,,14,Hans
+K1AB,Bob,MA,5,
+W1XYZ,,,5,
+`
+
+func TestRead(t *testing.T) {
+	entries, err := Read(strings.NewReader(testFile))
+	require.NoError(t, err)
+
+	assert.Len(t, entries, 3)
+	assert.Equal(t, Entry{"call": "DL1ABC", "name": "Flo", "state": "", "cqzone": "14", "exch1": "Hans"}, entries["DL1ABC"])
+}
+
+func TestRead_DefaultColumns(t *testing.T) {
+	entries, err := Read(strings.NewReader("dl1abc,Flo,,14,Hans\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hans", entries["DL1ABC"]["exch1"])
+}
+
+func TestPredictXchange(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.txt")
+	err := ioutil.WriteFile(filename, []byte(testFile), 0644)
+	require.NoError(t, err)
+	finder := New([]core.CallHistory{
+		{Contest: "Other", Filename: "unknown.txt"},
+		{Contest: "NA Sprint", Filename: filename, Fields: []string{"Name", "State"}},
+		{Contest: "CQ WW", Filename: filename, Fields: []string{"CQZone"}},
+		{Contest: "DARC 10m", Filename: filename},
+	})
+
+	testCases := []struct {
+		contest  string
+		call     string
+		expected string
+		found    bool
+	}{
+		{"NA Sprint", "k1ab", "Bob MA", true},
+		{"NA Sprint", "DL1ABC", "Flo", true},
+		{"NA Sprint", "W1XYZ", "", false},
+		{"NA Sprint", "DL2ABC", "", false},
+		{"cq ww", "W1XYZ", "5", true},
+		{"DARC 10m", "DL1ABC", "Hans", true},
+		{"Other", "DL1ABC", "", false},
+		{"Unknown", "DL1ABC", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.contest+" "+tc.call, func(t *testing.T) {
+			finder.ContestChanged(core.Contest{Name: tc.contest})
+
+			actual, found := finder.PredictXchange(tc.call)
+
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.found, found)
+		})
+	}
+}
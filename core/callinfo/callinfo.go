@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/ftl/hamradio/callsign"
 	"github.com/ftl/hamradio/dxcc"
@@ -11,13 +12,21 @@ import (
 	"github.com/ftl/hellocontest/core"
 )
 
-func New(entities DXCCFinder, callsigns CallsignFinder, dupeChecker DupeChecker, valuer Valuer) *Callinfo {
+// DefaultCallbookDelay is the time a callsign must stay unchanged before it is looked up in the callbook. This avoids
+// a lookup for every keystroke while the callsign is entered.
+const DefaultCallbookDelay = 500 * time.Millisecond
+
+func New(entities DXCCFinder, callsigns CallsignFinder, dupeChecker DupeChecker, valuer Valuer, asyncRunner core.AsyncRunner) *Callinfo {
 	result := &Callinfo{
 		view:        new(nullView),
 		entities:    entities,
 		callsigns:   callsigns,
 		dupeChecker: dupeChecker,
 		valuer:      valuer,
+		predictor:   new(nullXchangePredictor),
+		asyncRunner: asyncRunner,
+
+		callbookDelay: DefaultCallbookDelay,
 	}
 
 	return result
@@ -30,11 +39,17 @@ type Callinfo struct {
 	callsigns   CallsignFinder
 	dupeChecker DupeChecker
 	valuer      Valuer
+	predictor   XchangePredictor
+	asyncRunner core.AsyncRunner
+
+	callbookDelay   time.Duration
+	callbookLookups chan string
 
 	lastCallsign string
 	lastBand     core.Band
 	lastMode     core.Mode
 	lastXchange  string
+	callbookCall string
 }
 
 // DXCCFinder returns a list of matching prefixes for the given string and indicates if there was a match at all.
@@ -42,9 +57,11 @@ type DXCCFinder interface {
 	Find(string) (dxcc.Prefix, bool)
 }
 
-// CallsignFinder returns a list of matching callsigns for the given partial string.
+// CallsignFinder returns a list of matching callsigns for the given partial string. FindSimilar returns a list of
+// callsigns that differ from the given string in one character, the most similar first.
 type CallsignFinder interface {
 	Find(string) ([]string, error)
+	FindSimilar(string) ([]string, error)
 }
 
 // DupeChecker can be used to find out if the given callsign was already worked, according to the contest rules.
@@ -57,6 +74,17 @@ type Valuer interface {
 	Value(callsign callsign.Callsign, entity dxcc.Prefix, band core.Band, mode core.Mode, xchange string) (points, multis int)
 }
 
+// XchangePredictor predicts the exchange of a station, e.g. from a call history file.
+type XchangePredictor interface {
+	PredictXchange(call string) (string, bool)
+}
+
+// Callbook looks up the information about a station in a callbook and indicates if the callsign was found at all.
+// A lookup may take a while, it is always done in the background.
+type Callbook interface {
+	Lookup(call string) (core.CallbookEntry, bool, error)
+}
+
 // View defines the visual part of the call information window.
 type View interface {
 	Show()
@@ -66,6 +94,8 @@ type View interface {
 	SetDXCC(string, string, int, int, bool)
 	SetValue(points, multis int)
 	SetSupercheck(callsigns []core.AnnotatedCallsign)
+	SetSimilarCallsigns(callsigns []core.AnnotatedCallsign)
+	SetCallbookEntry(entry core.CallbookEntry)
 }
 
 func (c *Callinfo) SetView(view View) {
@@ -76,6 +106,29 @@ func (c *Callinfo) SetView(view View) {
 	c.view = view
 }
 
+// SetXchangePredictor sets the predictor that provides the exchange to estimate the value of a callsign if no exchange
+// was entered yet.
+func (c *Callinfo) SetXchangePredictor(predictor XchangePredictor) {
+	if predictor == nil {
+		c.predictor = new(nullXchangePredictor)
+		return
+	}
+	c.predictor = predictor
+}
+
+// SetCallbook sets the callbook that provides additional information about the current callsign.
+func (c *Callinfo) SetCallbook(callbook Callbook) {
+	if c.callbookLookups != nil {
+		close(c.callbookLookups)
+		c.callbookLookups = nil
+	}
+	if callbook == nil {
+		return
+	}
+	c.callbookLookups = make(chan string, 1)
+	go c.lookupCallbookEntries(callbook, c.callbookLookups, c.callbookDelay)
+}
+
 func (c *Callinfo) Show() {
 	c.view.Show()
 	c.ShowInfo(c.lastCallsign, c.lastBand, c.lastMode, c.lastXchange)
@@ -101,6 +154,8 @@ func (c *Callinfo) ShowInfo(call string, band core.Band, mode core.Mode, xchange
 	c.view.SetCallsign(call, worked, duplicate)
 	c.showDXCCAndValue(call, band, mode, xchange)
 	c.showSupercheck(call)
+	c.showSimilarCallsigns(call)
+	c.showCallbookEntry(call)
 }
 
 func (c *Callinfo) showDXCCAndValue(call string, band core.Band, mode core.Mode, xchange string) {
@@ -122,18 +177,99 @@ func (c *Callinfo) showDXCCAndValue(call string, band core.Band, mode core.Mode,
 
 	dxccName := fmt.Sprintf("%s (%s)", entity.Name, entity.PrimaryPrefix)
 	c.view.SetDXCC(dxccName, entity.Continent, int(entity.ITUZone), int(entity.CQZone), !entity.NotARRLCompliant)
+	if xchange == "" {
+		xchange, _ = c.predictor.PredictXchange(call)
+	}
 	points, multis := c.valuer.Value(parsedCall, entity, band, mode, xchange)
 	c.view.SetValue(points, multis)
 }
 
 func (c *Callinfo) showSupercheck(s string) {
-	normalizedInput := strings.TrimSpace(strings.ToUpper(s))
 	matches, err := c.callsigns.Find(s)
 	if err != nil {
 		log.Printf("Callsign search for failed for %s: %v", s, err)
 		return
 	}
 
+	c.view.SetSupercheck(c.annotate(s, matches))
+}
+
+func (c *Callinfo) showSimilarCallsigns(s string) {
+	matches, err := c.callsigns.FindSimilar(s)
+	if err != nil {
+		log.Printf("Search for similar callsigns failed for %s: %v", s, err)
+		return
+	}
+
+	c.view.SetSimilarCallsigns(c.annotate(s, matches))
+}
+
+// showCallbookEntry requests the lookup of the given callsign in the background. The result is only shown if the
+// callsign is still the current one, so the lookup never blocks the input of the callsign.
+func (c *Callinfo) showCallbookEntry(s string) {
+	call := strings.TrimSpace(strings.ToUpper(s))
+	if call == c.callbookCall {
+		return
+	}
+	c.callbookCall = call
+	c.view.SetCallbookEntry(core.CallbookEntry{})
+	if c.callbookLookups == nil {
+		return
+	}
+
+	// a requested lookup that did not start yet is stale now
+	select {
+	case <-c.callbookLookups:
+	default:
+	}
+	c.callbookLookups <- call
+}
+
+// lookupCallbookEntries looks up the requested callsigns one after the other. A callsign is only looked up if no other
+// lookup was requested within the given delay.
+func (c *Callinfo) lookupCallbookEntries(callbook Callbook, lookups <-chan string, delay time.Duration) {
+	for call := range lookups {
+		timeout := time.After(delay)
+		for waiting := true; waiting; {
+			select {
+			case next, ok := <-lookups:
+				if !ok {
+					return
+				}
+				call = next
+				timeout = time.After(delay)
+			case <-timeout:
+				waiting = false
+			}
+		}
+		c.lookupCallbookEntry(callbook, call)
+	}
+}
+
+func (c *Callinfo) lookupCallbookEntry(callbook Callbook, call string) {
+	if _, err := callsign.Parse(call); err != nil {
+		return
+	}
+
+	entry, found, err := callbook.Lookup(call)
+	if err != nil {
+		log.Printf("Callbook lookup failed for %s: %v", call, err)
+		return
+	}
+	if !found {
+		return
+	}
+	c.asyncRunner(func() {
+		if call != c.callbookCall {
+			return
+		}
+		c.view.SetCallbookEntry(entry)
+	})
+}
+
+// annotate adds the information about duplicates, worked stations, points and multis to the matches of the given input.
+func (c *Callinfo) annotate(s string, matches []string) []core.AnnotatedCallsign {
+	normalizedInput := strings.TrimSpace(strings.ToUpper(s))
 	annotatedMatches := make([]core.AnnotatedCallsign, len(matches))
 	for i, match := range matches {
 		cs, err := callsign.Parse(match)
@@ -144,7 +280,8 @@ func (c *Callinfo) showSupercheck(s string) {
 		entity, entityFound := c.entities.Find(match)
 		var points, multis int
 		if entityFound {
-			points, multis = c.valuer.Value(cs, entity, c.lastBand, c.lastMode, "") // TODO predict exchange
+			xchange, _ := c.predictor.PredictXchange(match)
+			points, multis = c.valuer.Value(cs, entity, c.lastBand, c.lastMode, xchange)
 		}
 		qsos, duplicate := c.dupeChecker.FindWorkedQSOs(cs, c.lastBand, c.lastMode)
 		exactMatch := (match == normalizedInput)
@@ -158,7 +295,7 @@ func (c *Callinfo) showSupercheck(s string) {
 		}
 	}
 
-	c.view.SetSupercheck(annotatedMatches)
+	return annotatedMatches
 }
 
 type nullView struct{}
@@ -169,3 +306,9 @@ func (v *nullView) SetCallsign(callsign string, worked, duplicate bool) {}
 func (v *nullView) SetDXCC(string, string, int, int, bool)              {}
 func (v *nullView) SetValue(points, multis int)                         {}
 func (v *nullView) SetSupercheck(callsigns []core.AnnotatedCallsign)    {}
+func (v *nullView) SetSimilarCallsigns([]core.AnnotatedCallsign)        {}
+func (v *nullView) SetCallbookEntry(core.CallbookEntry)                 {}
+
+type nullXchangePredictor struct{}
+
+func (p *nullXchangePredictor) PredictXchange(string) (string, bool) { return "", false }
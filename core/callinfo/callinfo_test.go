@@ -0,0 +1,90 @@
+package callinfo
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+const (
+	waitFor = time.Second
+	tick    = 10 * time.Millisecond
+)
+
+func TestShowCallbookEntry_LooksUpOnlyTheCompleteCallsign(t *testing.T) {
+	callinfo, callbook, view := setupCallbookTest()
+
+	for _, call := range []string{"D", "DL", "DL1", "DL1A", "DL1AB", "DL1ABC"} {
+		callinfo.showCallbookEntry(call)
+	}
+
+	assert.Eventually(t, func() bool { return view.entry().Callsign == "DL1ABC" }, waitFor, tick)
+	assert.Equal(t, []string{"DL1ABC"}, callbook.lookups())
+}
+
+func TestShowCallbookEntry_DropsStaleLookups(t *testing.T) {
+	callinfo, callbook, view := setupCallbookTest()
+
+	callinfo.showCallbookEntry("DL1ABC")
+	callinfo.showCallbookEntry("")
+	time.Sleep(5 * callinfo.callbookDelay)
+
+	assert.Empty(t, callbook.lookups())
+	assert.Equal(t, core.CallbookEntry{}, view.entry())
+}
+
+func setupCallbookTest() (*Callinfo, *testCallbook, *testView) {
+	var lock sync.Mutex
+	asyncRunner := func(f func()) {
+		lock.Lock()
+		defer lock.Unlock()
+		f()
+	}
+	callbook := new(testCallbook)
+	view := new(testView)
+	callinfo := New(nil, nil, nil, nil, asyncRunner)
+	callinfo.callbookDelay = 20 * time.Millisecond
+	callinfo.SetView(view)
+	callinfo.SetCallbook(callbook)
+	return callinfo, callbook, view
+}
+
+type testCallbook struct {
+	lock   sync.Mutex
+	called []string
+}
+
+func (c *testCallbook) Lookup(call string) (core.CallbookEntry, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.called = append(c.called, call)
+	return core.CallbookEntry{Callsign: call}, true, nil
+}
+
+func (c *testCallbook) lookups() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.called
+}
+
+type testView struct {
+	nullView
+	lock          sync.Mutex
+	callbookEntry core.CallbookEntry
+}
+
+func (v *testView) SetCallbookEntry(entry core.CallbookEntry) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.callbookEntry = entry
+}
+
+func (v *testView) entry() core.CallbookEntry {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.callbookEntry
+}
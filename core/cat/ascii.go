@@ -0,0 +1,156 @@
+package cat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// asciiDialect implements the ASCII based CAT protocols of Kenwood and of the newer Yaesu radios. Both use
+// the same two letter commands terminated by a semicolon, but differ in the number of frequency digits and
+// in the encoding of the modes.
+type asciiDialect struct {
+	frequencyDigits int
+	modeCommand     string
+	modes           map[string]core.Mode
+	lsb, usb        string
+	modeCodes       map[core.Mode]string
+	txCommand       string
+	rxCommand       string
+}
+
+func newKenwood() *asciiDialect {
+	return &asciiDialect{
+		frequencyDigits: 11,
+		modeCommand:     "MD",
+		txCommand:       "TX1",
+		rxCommand:       "RX",
+		modes: map[string]core.Mode{
+			"1": core.ModeSSB,
+			"2": core.ModeSSB,
+			"3": core.ModeCW,
+			"4": core.ModeFM,
+			"6": core.ModeRTTY,
+			"7": core.ModeCW,
+			"9": core.ModeRTTY,
+		},
+		lsb: "1",
+		usb: "2",
+		modeCodes: map[core.Mode]string{
+			core.ModeCW:      "3",
+			core.ModeFM:      "4",
+			core.ModeRTTY:    "6",
+			core.ModeDigital: "2",
+		},
+	}
+}
+
+func newYaesu() *asciiDialect {
+	return &asciiDialect{
+		frequencyDigits: 9,
+		modeCommand:     "MD0",
+		txCommand:       "TX1",
+		rxCommand:       "TX0",
+		modes: map[string]core.Mode{
+			"1": core.ModeSSB,
+			"2": core.ModeSSB,
+			"3": core.ModeCW,
+			"4": core.ModeFM,
+			"6": core.ModeRTTY,
+			"7": core.ModeCW,
+			"8": core.ModeDigital,
+			"9": core.ModeRTTY,
+			"A": core.ModeDigital,
+			"B": core.ModeFM,
+			"C": core.ModeDigital,
+		},
+		lsb: "1",
+		usb: "2",
+		modeCodes: map[core.Mode]string{
+			core.ModeCW:      "3",
+			core.ModeFM:      "4",
+			core.ModeRTTY:    "6",
+			core.ModeDigital: "C",
+		},
+	}
+}
+
+func (d *asciiDialect) readFrame(r *bufio.Reader) ([]byte, error) {
+	frame, err := r.ReadBytes(';')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(frame), nil
+}
+
+func (d *asciiDialect) decode(frame []byte) (value, bool) {
+	s := strings.TrimSuffix(string(frame), ";")
+	switch {
+	case strings.HasPrefix(s, "FA"):
+		frequency, ok := d.parseFrequency(s[2:])
+		return value{field: frequencyField, frequency: frequency}, ok
+	case strings.HasPrefix(s, "FB"):
+		frequency, ok := d.parseFrequency(s[2:])
+		return value{field: splitFrequencyField, frequency: frequency}, ok
+	case strings.HasPrefix(s, d.modeCommand):
+		mode, ok := d.modes[s[len(d.modeCommand):]]
+		if !ok {
+			mode = core.NoMode
+		}
+		return value{field: modeField, mode: mode}, len(s) > len(d.modeCommand)
+	case strings.HasPrefix(s, "FT"):
+		return value{field: splitField, split: s[2:] == "1"}, len(s) > 2
+	default:
+		return value{}, false
+	}
+}
+
+func (d *asciiDialect) parseFrequency(digits string) (core.Frequency, bool) {
+	if len(digits) != d.frequencyDigits {
+		return 0, false
+	}
+	frequency, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return core.Frequency(frequency), true
+}
+
+func (d *asciiDialect) pollRequests() [][]byte {
+	return [][]byte{
+		[]byte("FA;"),
+		[]byte("FB;"),
+		[]byte(d.modeCommand + ";"),
+		[]byte("FT;"),
+	}
+}
+
+func (d *asciiDialect) setFrequency(frequency core.Frequency) []byte {
+	return []byte(fmt.Sprintf("FA%0*d;", d.frequencyDigits, int(frequency)))
+}
+
+func (d *asciiDialect) setMode(mode core.Mode, frequency core.Frequency) []byte {
+	code, ok := d.modeCodes[mode]
+	if mode == core.ModeSSB && lowerSideband(frequency) {
+		code, ok = d.lsb, true
+	} else if mode == core.ModeSSB {
+		code, ok = d.usb, true
+	}
+	if !ok {
+		return nil
+	}
+	return []byte(d.modeCommand + code + ";")
+}
+
+// setPTT keys the transmitter. Kenwood radios transmit the audio of the data input (USB or ACC) with TX1, Yaesu
+// radios use the audio source that is configured for CAT keying.
+func (d *asciiDialect) setPTT(on bool) []byte {
+	if on {
+		return []byte(d.txCommand + ";")
+	}
+	return []byte(d.rxCommand + ";")
+}
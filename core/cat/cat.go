@@ -0,0 +1,482 @@
+// Package cat controls a radio directly through its serial CAT interface, without the need for rigctld or a
+// TCI server. It supports the ASCII protocols of Kenwood and of the newer Yaesu radios, and Icom's CI-V protocol.
+package cat
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
+	"github.com/ftl/hellocontest/core/serial"
+)
+
+func New(config core.CAT) (*Client, error) {
+	dialect, err := newDialect(config)
+	if err != nil {
+		return nil, err
+	}
+	baudRate := config.BaudRate
+	if baudRate == 0 {
+		baudRate = defaultBaudRate
+	}
+
+	return &Client{
+		port:            config.Port,
+		baudRate:        baudRate,
+		dialect:         dialect,
+		pollingInterval: 500 * time.Millisecond,
+		pollingTimeout:  2 * time.Second,
+		retryInterval:   5 * time.Second,
+		done:            make(chan struct{}),
+		bandplan:        bandplan.IARURegion1,
+		controller:      new(nullController),
+	}, nil
+}
+
+type Client struct {
+	port     string
+	baudRate int
+	dialect  dialect
+
+	listeners []interface{}
+
+	pollingInterval time.Duration
+	pollingTimeout  time.Duration
+	retryInterval   time.Duration
+	done            chan struct{}
+
+	connLock  sync.Mutex
+	conn      *conn
+	available bool
+
+	bandplanLock sync.RWMutex
+	bandplan     bandplan.Bandplan
+	controller   VFOController
+
+	incomingLock sync.Mutex
+	incoming     vfoSettings
+	outgoing     vfoSettings
+
+	vfosLock sync.RWMutex
+	vfos     core.VFOs
+}
+
+type VFOController interface {
+	SetFrequency(core.Frequency)
+	SetBand(core.Band)
+	SetMode(core.Mode)
+}
+
+type vfoSettings struct {
+	frequency core.Frequency
+	band      core.Band
+	mode      core.Mode
+}
+
+// conn is an open connection to the serial port.
+type conn struct {
+	port      io.ReadWriteCloser
+	writeLock sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	receivedLock sync.Mutex
+	lastReceived time.Time
+}
+
+func (c *conn) write(data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	_, err := c.port.Write(data)
+	return err
+}
+
+func (c *conn) close() {
+	c.closeOnce.Do(func() {
+		c.port.Close()
+		close(c.closed)
+	})
+}
+
+func (c *conn) received() {
+	c.receivedLock.Lock()
+	defer c.receivedLock.Unlock()
+	c.lastReceived = time.Now()
+}
+
+func (c *conn) silentSince() time.Duration {
+	c.receivedLock.Lock()
+	defer c.receivedLock.Unlock()
+	return time.Since(c.lastReceived)
+}
+
+func (c *Client) SetVFOController(controller VFOController) {
+	if controller == nil {
+		c.controller = new(nullController)
+		return
+	}
+	c.controller = controller
+}
+
+func (c *Client) KeepOpen() {
+	go func() {
+		for {
+			closed, err := c.connect()
+			if err == nil {
+				select {
+				case <-closed:
+					log.Print("Connection lost to the radio, waiting for retry.")
+				case <-c.done:
+					log.Print("Connection to the radio closed.")
+					c.Disconnect()
+					return
+				}
+			} else {
+				log.Printf("Cannot open the CAT port, waiting for retry: %v", err)
+			}
+
+			select {
+			case <-time.After(c.retryInterval):
+				log.Print("Retrying to open the CAT port")
+			case <-c.done:
+				log.Print("Connection to the radio closed.")
+				return
+			}
+		}
+	}()
+}
+
+func (c *Client) Connect() error {
+	_, err := c.connect()
+	return err
+}
+
+// connect opens the serial port and starts polling the radio. The returned channel is closed when the connection
+// is closed, either because the port was closed or because the radio did not respond in time.
+func (c *Client) connect() (<-chan struct{}, error) {
+	port, err := serial.Open(c.port, c.baudRate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &conn{
+		port:         port,
+		closed:       make(chan struct{}),
+		lastReceived: time.Now(),
+	}
+	c.connLock.Lock()
+	c.conn = conn
+	c.connLock.Unlock()
+
+	go c.readLoop(conn)
+	go c.pollLoop(conn)
+	go func() {
+		<-conn.closed
+		c.setAvailable(false)
+	}()
+
+	return conn.closed, nil
+}
+
+func (c *Client) Disconnect() {
+	c.connLock.Lock()
+	conn := c.conn
+	c.connLock.Unlock()
+	if conn != nil {
+		conn.close()
+	}
+}
+
+// Close disconnects from the radio and stops reconnecting.
+func (c *Client) Close() {
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+	c.Disconnect()
+}
+
+// Active indicates that the radio responds to CAT requests.
+func (c *Client) Active() bool {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+	return c.available
+}
+
+func (c *Client) setAvailable(available bool) {
+	c.connLock.Lock()
+	changed := c.available != available
+	c.available = available
+	c.connLock.Unlock()
+
+	if changed {
+		c.emitStatusChanged(available)
+	}
+}
+
+func (c *Client) readLoop(conn *conn) {
+	defer conn.close()
+	r := bufio.NewReader(conn.port)
+	for {
+		frame, err := c.dialect.readFrame(r)
+		if err != nil {
+			select {
+			case <-conn.closed:
+			default:
+				log.Printf("Cannot read from the CAT port: %v", err)
+			}
+			return
+		}
+		v, ok := c.dialect.decode(frame)
+		if !ok {
+			continue
+		}
+		conn.received()
+		c.setAvailable(true)
+		c.setIncoming(v)
+	}
+}
+
+func (c *Client) pollLoop(conn *conn) {
+	ticker := time.NewTicker(c.pollingInterval)
+	defer ticker.Stop()
+	for {
+		for _, request := range c.dialect.pollRequests() {
+			err := conn.write(request)
+			if err != nil {
+				log.Printf("Cannot write to the CAT port: %v", err)
+				conn.close()
+				return
+			}
+		}
+
+		select {
+		case <-conn.closed:
+			return
+		case <-ticker.C:
+		}
+
+		if conn.silentSince() > c.pollingTimeout {
+			log.Print("The radio does not respond to CAT requests.")
+			conn.close()
+			return
+		}
+	}
+}
+
+func (c *Client) write(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	c.connLock.Lock()
+	conn := c.conn
+	c.connLock.Unlock()
+	if conn == nil {
+		return
+	}
+	err := conn.write(data)
+	if err != nil {
+		log.Printf("Cannot write to the CAT port: %v", err)
+	}
+}
+
+// VFOs returns the current state of VFO A and B and the split mode.
+func (c *Client) VFOs() core.VFOs {
+	c.vfosLock.RLock()
+	defer c.vfosLock.RUnlock()
+	return c.vfos
+}
+
+func (c *Client) updateVFOs(update func(*core.VFOs)) {
+	c.vfosLock.Lock()
+	defer c.vfosLock.Unlock()
+	update(&c.vfos)
+}
+
+func (c *Client) setIncoming(v value) {
+	switch v.field {
+	case frequencyField:
+		c.setIncomingFrequency(v.frequency)
+	case splitFrequencyField:
+		c.updateVFOs(func(vfos *core.VFOs) {
+			vfos.B = v.frequency
+		})
+	case splitField:
+		c.updateVFOs(func(vfos *core.VFOs) {
+			if vfos.Split != v.split {
+				log.Printf("incoming split: %t", v.split)
+			}
+			vfos.Split = v.split
+		})
+	case modeField:
+		c.setIncomingMode(v.mode)
+	}
+}
+
+func (c *Client) setIncomingFrequency(incomingFrequency core.Frequency) {
+	c.updateVFOs(func(vfos *core.VFOs) {
+		vfos.A = incomingFrequency
+	})
+
+	c.incomingLock.Lock()
+	frequencyChanged := incomingFrequency != c.incoming.frequency
+	c.incoming.frequency = incomingFrequency
+	incomingBand := toCoreBand(c.currentBandplan().ByFrequency(hamradio.Frequency(incomingFrequency)).Name)
+	bandChanged := frequencyChanged && incomingBand != c.incoming.band
+	c.incoming.band = incomingBand
+	c.incomingLock.Unlock()
+
+	if frequencyChanged {
+		c.controller.SetFrequency(incomingFrequency)
+		log.Printf("incoming frequency: %s", incomingFrequency)
+	}
+	if bandChanged {
+		c.controller.SetBand(incomingBand)
+		log.Printf("incoming band: %v", incomingBand)
+	}
+}
+
+func (c *Client) setIncomingMode(incomingMode core.Mode) {
+	c.incomingLock.Lock()
+	modeChanged := incomingMode != c.incoming.mode
+	c.incoming.mode = incomingMode
+	c.incomingLock.Unlock()
+
+	if modeChanged {
+		c.controller.SetMode(incomingMode)
+		log.Printf("incoming mode %v", incomingMode)
+	}
+}
+
+func (c *Client) currentIncoming() vfoSettings {
+	c.incomingLock.Lock()
+	defer c.incomingLock.Unlock()
+	return c.incoming
+}
+
+func (c *Client) SetFrequency(f core.Frequency) {
+	if f == c.outgoing.frequency {
+		return
+	}
+	c.outgoing.frequency = f
+	c.write(c.dialect.setFrequency(f))
+
+	log.Printf("outgoing frequency: %s", f)
+}
+
+// SetBand tunes the radio to the center of the portion of the given band that is dedicated to the current mode.
+func (c *Client) SetBand(band core.Band) {
+	if band == c.outgoing.band {
+		return
+	}
+	c.outgoing.band = band
+
+	outgoingBand, ok := c.currentBandplan()[toBandplanBandName(band)]
+	if !ok {
+		log.Printf("unknown band %v", band)
+		return
+	}
+	incoming := c.currentIncoming()
+	if outgoingBand.Contains(hamradio.Frequency(incoming.frequency)) {
+		return
+	}
+	frequency := bandplans.ModePortionCenter(outgoingBand, toBandplanMode(incoming.mode))
+	c.write(c.dialect.setFrequency(frequency))
+
+	log.Printf("outgoing band: %v", band)
+}
+
+// StationChanged selects the bandplan of the station's IARU region.
+func (c *Client) StationChanged(station core.Station) {
+	c.bandplanLock.Lock()
+	defer c.bandplanLock.Unlock()
+	c.bandplan = bandplans.ForRegion(station.Region)
+}
+
+func (c *Client) currentBandplan() bandplan.Bandplan {
+	c.bandplanLock.RLock()
+	defer c.bandplanLock.RUnlock()
+	return c.bandplan
+}
+
+func (c *Client) SetMode(mode core.Mode) {
+	if mode == c.outgoing.mode {
+		return
+	}
+	c.outgoing.mode = mode
+	c.write(c.dialect.setMode(mode, c.currentIncoming().frequency))
+
+	log.Printf("outgoing mode: %v", mode)
+}
+
+// SetPTT keys or releases the transmitter. The voice keyer uses this to transmit its recordings.
+func (c *Client) SetPTT(on bool) {
+	c.write(c.dialect.setPTT(on))
+}
+
+func (c *Client) Refresh() {
+	incoming := c.currentIncoming()
+	if incoming.frequency != 0 {
+		log.Printf("Refreshing VFO frequency")
+		c.controller.SetFrequency(incoming.frequency)
+	}
+	if incoming.band != core.NoBand {
+		log.Printf("Refreshing VFO band")
+		c.controller.SetBand(incoming.band)
+	}
+	if incoming.mode != core.NoMode {
+		log.Printf("Refreshing VFO mode")
+		c.controller.SetMode(incoming.mode)
+	}
+}
+
+func (c *Client) Notify(listener interface{}) {
+	c.listeners = append(c.listeners, listener)
+}
+
+func (c *Client) emitStatusChanged(available bool) {
+	for _, listener := range c.listeners {
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.CATService, available)
+		}
+	}
+}
+
+func toCoreBand(bandName bandplan.BandName) core.Band {
+	if bandName == bandplan.BandUnknown {
+		return core.NoBand
+	}
+	return core.Band(bandName)
+}
+
+func toBandplanBandName(band core.Band) bandplan.BandName {
+	if band == core.NoBand {
+		return bandplan.BandUnknown
+	}
+	return bandplan.BandName(band)
+}
+
+func toBandplanMode(mode core.Mode) bandplan.Mode {
+	switch mode {
+	case core.ModeCW:
+		return bandplan.ModeCW
+	case core.ModeSSB, core.ModeFM:
+		return bandplan.ModePhone
+	default:
+		return bandplan.ModeDigital
+	}
+}
+
+type nullController struct{}
+
+func (c *nullController) SetFrequency(core.Frequency) {}
+func (c *nullController) SetBand(core.Band)           {}
+func (c *nullController) SetMode(core.Mode)           {}
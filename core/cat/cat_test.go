@@ -0,0 +1,253 @@
+package cat
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/rigsim"
+)
+
+const (
+	waitFor = 2 * time.Second
+	tick    = 10 * time.Millisecond
+)
+
+var protocols = []core.CATProtocol{core.KenwoodCAT, core.YaesuCAT, core.IcomCIV}
+
+func TestClient_IncomingFrequencyAndBand(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			rig, _, client, controller := setupCAT(t, protocol, 14010000, rigsim.ModeCW)
+			assert.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+			<-rigsim.Play(
+				rigsim.Do(func() { rig.SetFrequency(14020000) }),
+				rigsim.Wait(50*time.Millisecond),
+				rigsim.Do(func() { rig.SetFrequency(7020000) }),
+			)
+
+			assert.Eventually(t, controller.has(7020000, core.Band40m, core.ModeCW), waitFor, tick)
+			assert.Equal(t, []core.Band{core.Band20m, core.Band40m}, controller.bands(), "the band is only emitted when it changes")
+			assert.Equal(t, core.Frequency(7020000), client.VFOs().A)
+		})
+	}
+}
+
+func TestClient_IncomingModeMapping(t *testing.T) {
+	testCases := []struct {
+		protocol core.CATProtocol
+		rigMode  string
+		expected core.Mode
+	}{
+		{core.KenwoodCAT, rigsim.ModeUSB, core.ModeSSB},
+		{core.KenwoodCAT, rigsim.ModeLSB, core.ModeSSB},
+		{core.KenwoodCAT, rigsim.ModeFM, core.ModeFM},
+		{core.KenwoodCAT, rigsim.ModeRTTY, core.ModeRTTY},
+		{core.YaesuCAT, rigsim.ModeUSB, core.ModeSSB},
+		{core.YaesuCAT, rigsim.ModeFM, core.ModeFM},
+		{core.YaesuCAT, rigsim.ModeRTTY, core.ModeRTTY},
+		{core.YaesuCAT, rigsim.ModePKTUSB, core.ModeDigital},
+		{core.IcomCIV, rigsim.ModeUSB, core.ModeSSB},
+		{core.IcomCIV, rigsim.ModeLSB, core.ModeSSB},
+		{core.IcomCIV, rigsim.ModeFM, core.ModeFM},
+		{core.IcomCIV, rigsim.ModeRTTY, core.ModeRTTY},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.protocol)+" "+tc.rigMode, func(t *testing.T) {
+			rig, _, _, controller := setupCAT(t, tc.protocol, 14010000, rigsim.ModeCW)
+			require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+			rig.SetMode(tc.rigMode)
+
+			assert.Eventually(t, func() bool { return controller.currentMode() == tc.expected }, waitFor, tick)
+		})
+	}
+}
+
+func TestClient_IncomingSplit(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			rig, _, client, _ := setupCAT(t, protocol, 14010000, rigsim.ModeCW)
+
+			rig.SetSplitFrequency(14012000)
+			rig.SetSplit(true)
+
+			expected := core.VFOs{A: 14010000, B: 14012000, Split: true}
+			assert.Eventually(t, func() bool { return client.VFOs() == expected }, waitFor, tick)
+		})
+	}
+}
+
+func TestClient_OutgoingBandAndMode(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			rig, _, client, controller := setupCAT(t, protocol, 14010000, rigsim.ModeCW)
+			require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+			client.SetBand(core.Band40m)
+			assert.Eventually(t, controller.has(7020000, core.Band40m, core.ModeCW), waitFor, tick)
+			assert.Equal(t, core.Frequency(7020000), rig.Frequency(), "center of the CW portion")
+
+			client.SetMode(core.ModeSSB)
+			assert.Eventually(t, func() bool { return rig.Mode() == rigsim.ModeLSB }, waitFor, tick)
+
+			client.SetFrequency(7150000)
+			assert.Eventually(t, controller.has(7150000, core.Band40m, core.ModeSSB), waitFor, tick)
+		})
+	}
+}
+
+func TestClient_PTT(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			rig, _, client, _ := setupCAT(t, protocol, 14200000, rigsim.ModeUSB)
+			require.Eventually(t, client.Active, waitFor, tick)
+
+			client.SetPTT(true)
+			assert.Eventually(t, rig.PTT, waitFor, tick)
+
+			client.SetPTT(false)
+			assert.Eventually(t, func() bool { return !rig.PTT() }, waitFor, tick)
+		})
+	}
+}
+
+func TestClient_ReconnectsWhenRadioIsBack(t *testing.T) {
+	for _, protocol := range protocols {
+		t.Run(string(protocol), func(t *testing.T) {
+			rig, server, client, controller := setupCAT(t, protocol, 14010000, rigsim.ModeCW)
+			require.Eventually(t, client.Active, waitFor, tick)
+
+			<-rigsim.Play(
+				rigsim.Do(server.PowerOff),
+				rigsim.Wait(200*time.Millisecond),
+				rigsim.Do(func() { rig.SetFrequency(3510000) }),
+				rigsim.Do(server.PowerOn),
+			)
+
+			assert.Eventually(t, func() bool { return len(controller.statuses()) == 3 }, waitFor, tick)
+			assert.Equal(t, []bool{true, false, true}, controller.statuses())
+			assert.Eventually(t, controller.has(3510000, core.Band80m, core.ModeCW), waitFor, tick)
+		})
+	}
+}
+
+func TestClient_SlowRadio(t *testing.T) {
+	rig, server, client, controller := setupCAT(t, core.IcomCIV, 14010000, rigsim.ModeCW)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	server.SetLatency(20 * time.Millisecond)
+	rig.SetFrequency(21010000)
+
+	assert.Eventually(t, controller.has(21010000, core.Band15m, core.ModeCW), waitFor, tick)
+	assert.True(t, client.Active())
+}
+
+func TestNew_UnknownProtocol(t *testing.T) {
+	_, err := New(core.CAT{Protocol: "elecraft", Port: "/dev/ttyUSB0"})
+	assert.Error(t, err)
+}
+
+func TestBCDFrequency(t *testing.T) {
+	encoded := encodeBCDFrequency(14074123)
+	assert.Equal(t, []byte{0x23, 0x41, 0x07, 0x14, 0x00}, encoded)
+
+	decoded, ok := decodeBCDFrequency(encoded)
+	assert.True(t, ok)
+	assert.Equal(t, core.Frequency(14074123), decoded)
+
+	_, ok = decodeBCDFrequency([]byte{0x23, 0x4A, 0x07, 0x14, 0x00})
+	assert.False(t, ok)
+}
+
+func setupCAT(t *testing.T, protocol core.CATProtocol, frequency core.Frequency, mode string) (*rigsim.Rig, *rigsim.CATServer, *Client, *testController) {
+	t.Helper()
+	rig := rigsim.NewRig(frequency, mode)
+	server, err := rigsim.NewCATServer(protocol, 0x94, rig)
+	if err != nil {
+		t.Skipf("cannot simulate a serial port: %v", err)
+	}
+
+	client, err := New(core.CAT{Protocol: protocol, Port: server.Port(), CIVAddress: 0x94})
+	require.NoError(t, err)
+	client.pollingInterval = 20 * time.Millisecond
+	client.pollingTimeout = 100 * time.Millisecond
+	client.retryInterval = 50 * time.Millisecond
+	controller := new(testController)
+	client.SetVFOController(controller)
+	client.Notify(core.ServiceStatusListenerFunc(controller.statusChanged))
+	client.KeepOpen()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return rig, server, client, controller
+}
+
+type testController struct {
+	lock          sync.Mutex
+	frequency     core.Frequency
+	bandHistory   []core.Band
+	mode          core.Mode
+	statusHistory []bool
+}
+
+func (c *testController) SetFrequency(frequency core.Frequency) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.frequency = frequency
+}
+
+func (c *testController) SetBand(band core.Band) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.bandHistory = append(c.bandHistory, band)
+}
+
+func (c *testController) SetMode(mode core.Mode) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.mode = mode
+}
+
+func (c *testController) statusChanged(_ core.Service, available bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.statusHistory = append(c.statusHistory, available)
+}
+
+func (c *testController) has(frequency core.Frequency, band core.Band, mode core.Mode) func() bool {
+	return func() bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		currentBand := core.NoBand
+		if len(c.bandHistory) > 0 {
+			currentBand = c.bandHistory[len(c.bandHistory)-1]
+		}
+		return c.frequency == frequency && currentBand == band && c.mode == mode
+	}
+}
+
+func (c *testController) currentMode() core.Mode {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.mode
+}
+
+func (c *testController) bands() []core.Band {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]core.Band{}, c.bandHistory...)
+}
+
+func (c *testController) statuses() []bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]bool{}, c.statusHistory...)
+}
@@ -0,0 +1,192 @@
+package cat
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// CI-V frame bytes
+const (
+	civPreamble   byte = 0xFE
+	civEndOfFrame byte = 0xFD
+	civController byte = 0xE0
+	civBroadcast  byte = 0x00
+)
+
+// CI-V commands
+const (
+	civTransceiveFrequency byte = 0x00
+	civTransceiveMode      byte = 0x01
+	civReadFrequency       byte = 0x03
+	civReadMode            byte = 0x04
+	civSetFrequency        byte = 0x05
+	civSetMode             byte = 0x06
+	civSplit               byte = 0x0F
+	civVFOFrequency        byte = 0x25
+	civTransmit            byte = 0x1C
+)
+
+// civPTT is the sub command of civTransmit that keys or releases the transmitter.
+const civPTT byte = 0x00
+
+// civUnselectedVFO is the sub command of civVFOFrequency that selects the VFO that is currently not used for receiving.
+const civUnselectedVFO byte = 0x01
+
+var civModes = map[byte]core.Mode{
+	0x00: core.ModeSSB,
+	0x01: core.ModeSSB,
+	0x03: core.ModeCW,
+	0x04: core.ModeRTTY,
+	0x05: core.ModeFM,
+	0x07: core.ModeCW,
+	0x08: core.ModeRTTY,
+}
+
+var civModeCodes = map[core.Mode]byte{
+	core.ModeCW:      0x03,
+	core.ModeRTTY:    0x04,
+	core.ModeFM:      0x05,
+	core.ModeDigital: 0x01,
+}
+
+const (
+	civLSB byte = 0x00
+	civUSB byte = 0x01
+)
+
+// civDialect implements Icom's binary CI-V protocol. Frames sent by the controller itself are ignored, since
+// every frame on a CI-V bus is echoed back to the sender.
+type civDialect struct {
+	address byte
+}
+
+func newCIV(address byte) *civDialect {
+	return &civDialect{address: address}
+}
+
+func (d *civDialect) readFrame(r *bufio.Reader) ([]byte, error) {
+	for {
+		frame, err := r.ReadBytes(civEndOfFrame)
+		if err != nil {
+			return nil, err
+		}
+		start := bytes.Index(frame, []byte{civPreamble, civPreamble})
+		if start == -1 {
+			continue
+		}
+		frame = frame[start:]
+		for len(frame) > 0 && frame[0] == civPreamble {
+			frame = frame[1:]
+		}
+		return frame[:len(frame)-1], nil
+	}
+}
+
+func (d *civDialect) decode(frame []byte) (value, bool) {
+	if len(frame) < 3 {
+		return value{}, false
+	}
+	to, from, command, data := frame[0], frame[1], frame[2], frame[3:]
+	if from != d.address || (to != civController && to != civBroadcast) {
+		return value{}, false
+	}
+
+	switch command {
+	case civReadFrequency, civTransceiveFrequency:
+		frequency, ok := decodeBCDFrequency(data)
+		return value{field: frequencyField, frequency: frequency}, ok
+	case civReadMode, civTransceiveMode:
+		if len(data) == 0 {
+			return value{}, false
+		}
+		mode, ok := civModes[data[0]]
+		if !ok {
+			mode = core.NoMode
+		}
+		return value{field: modeField, mode: mode}, true
+	case civSplit:
+		if len(data) == 0 {
+			return value{}, false
+		}
+		return value{field: splitField, split: data[0] == 0x01}, true
+	case civVFOFrequency:
+		if len(data) == 0 || data[0] != civUnselectedVFO {
+			return value{}, false
+		}
+		frequency, ok := decodeBCDFrequency(data[1:])
+		return value{field: splitFrequencyField, frequency: frequency}, ok
+	default:
+		return value{}, false
+	}
+}
+
+func (d *civDialect) frame(command byte, data ...byte) []byte {
+	result := []byte{civPreamble, civPreamble, d.address, civController, command}
+	result = append(result, data...)
+	return append(result, civEndOfFrame)
+}
+
+func (d *civDialect) pollRequests() [][]byte {
+	return [][]byte{
+		d.frame(civReadFrequency),
+		d.frame(civVFOFrequency, civUnselectedVFO),
+		d.frame(civReadMode),
+		d.frame(civSplit),
+	}
+}
+
+func (d *civDialect) setFrequency(frequency core.Frequency) []byte {
+	return d.frame(civSetFrequency, encodeBCDFrequency(frequency)...)
+}
+
+func (d *civDialect) setMode(mode core.Mode, frequency core.Frequency) []byte {
+	code, ok := civModeCodes[mode]
+	if mode == core.ModeSSB && lowerSideband(frequency) {
+		code, ok = civLSB, true
+	} else if mode == core.ModeSSB {
+		code, ok = civUSB, true
+	}
+	if !ok {
+		return nil
+	}
+	return d.frame(civSetMode, code)
+}
+
+func (d *civDialect) setPTT(on bool) []byte {
+	if on {
+		return d.frame(civTransmit, civPTT, 0x01)
+	}
+	return d.frame(civTransmit, civPTT, 0x00)
+}
+
+// decodeBCDFrequency decodes the five bytes of a CI-V frequency. The digits are BCD encoded, starting with the
+// least significant byte: 10Hz and 1Hz in the first byte, 1GHz and 100MHz in the last byte.
+func decodeBCDFrequency(data []byte) (core.Frequency, bool) {
+	if len(data) < 5 {
+		return 0, false
+	}
+	var result int
+	for i := 4; i >= 0; i-- {
+		high, low := int(data[i]>>4), int(data[i]&0x0F)
+		if high > 9 || low > 9 {
+			return 0, false
+		}
+		result = result*100 + high*10 + low
+	}
+	return core.Frequency(result), true
+}
+
+func encodeBCDFrequency(frequency core.Frequency) []byte {
+	result := make([]byte, 5)
+	f := int(frequency)
+	for i := 0; i < 5; i++ {
+		low := f % 10
+		f /= 10
+		high := f % 10
+		f /= 10
+		result[i] = byte(high<<4 | low)
+	}
+	return result
+}
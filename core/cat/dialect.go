@@ -0,0 +1,65 @@
+package cat
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// dialect encodes the commands for and decodes the responses of a specific CAT protocol.
+type dialect interface {
+	// readFrame reads the next complete frame from the radio.
+	readFrame(*bufio.Reader) ([]byte, error)
+	// decode interprets the given frame. It returns false if the frame does not contain any relevant value.
+	decode(frame []byte) (value, bool)
+	// pollRequests returns the requests to read the current state of the radio.
+	pollRequests() [][]byte
+	setFrequency(core.Frequency) []byte
+	setMode(mode core.Mode, frequency core.Frequency) []byte
+	setPTT(on bool) []byte
+}
+
+type field int
+
+const (
+	frequencyField field = iota
+	splitFrequencyField
+	modeField
+	splitField
+)
+
+// value is one value of the radio's state that was reported by the radio.
+type value struct {
+	field     field
+	frequency core.Frequency
+	mode      core.Mode
+	split     bool
+}
+
+const (
+	defaultBaudRate   = 9600
+	defaultCIVAddress = 0x94
+)
+
+func newDialect(config core.CAT) (dialect, error) {
+	switch config.Protocol {
+	case core.KenwoodCAT:
+		return newKenwood(), nil
+	case core.YaesuCAT:
+		return newYaesu(), nil
+	case core.IcomCIV:
+		address := config.CIVAddress
+		if address == 0 {
+			address = defaultCIVAddress
+		}
+		return newCIV(byte(address)), nil
+	default:
+		return nil, fmt.Errorf("unknown CAT protocol %q", config.Protocol)
+	}
+}
+
+// lowerSideband returns true if the lower sideband is used on the given frequency.
+func lowerSideband(frequency core.Frequency) bool {
+	return frequency != 0 && frequency < 10000000
+}
@@ -3,6 +3,7 @@ package cfg
 import (
 	"log"
 	"path/filepath"
+	"time"
 
 	"github.com/ftl/hamradio/cfg"
 	"github.com/pkg/errors"
@@ -13,11 +14,15 @@ import (
 
 const Filename = "hellocontest.json"
 
+// esmDefault is used if the configuration file does not contain the ESM setting.
+var esmDefault = true
+
 var Default = Data{
 	Station: pb.Station{
 		Callsign: "DL0ABC",
 		Operator: "DL1ABC",
 		Locator:  "AA00zz",
+		Region:   1,
 	},
 	Contest: pb.Contest{
 		Name:                    "Default",
@@ -41,24 +46,90 @@ var Default = Data{
 		CabrilloQsoTemplate: "{{.QRG}} {{.Mode}} {{.Date}} {{.Time}} {{.MyCall}} {{.MyReport}} {{.MyNumber}} {{.MyXchange}} {{.TheirCall}} {{.TheirReport}} {{.TheirNumber}} {{.TheirXchange}}",
 	},
 	Keyer: pb.Keyer{
-		Wpm: 25,
+		Wpm:       25,
+		AutoCqGap: 5,
 		SpMacros: []string{
 			"{{.MyCall}}",
 			"rr {{.MyReport}} {{.MyNumber}} {{.MyXchange}}",
 			"tu gl",
 			"nr {{.MyNumber}} {{.MyXchange}} {{.MyNumber}} {{.MyXchange}}",
 		},
+		SpLabels: []string{"MyCall", "Exch", "TU", "Nr"},
 		RunMacros: []string{
 			"cq {{.MyCall}} test",
 			"{{.TheirCall}} {{.MyReport}} {{.MyNumber}} {{.MyXchange}}",
 			"tu {{.MyCall}} test",
 			"nr {{.MyNumber}} {{.MyXchange}} {{.MyNumber}} {{.MyXchange}}",
 		},
+		RunLabels: []string{"CQ", "Exch", "TU", "Nr"},
+	},
+	MacroSets: []MacroSet{
+		{
+			Name: "Serial Number",
+			SPMacros: []string{
+				"{{.MyCall}}",
+				"rr {{.MyReport}} {{.MyNumber}}",
+				"tu gl",
+				"nr {{.MyNumber}} {{.MyNumber}}",
+				"{{.MyCall}} {{.MyCall}}",
+			},
+			SPLabels: []string{"MyCall", "Exch", "TU", "Nr", "MyCall 2x"},
+			RunMacros: []string{
+				"cq {{.MyCall}} test",
+				"{{.TheirCall}} {{.MyReport}} {{.MyNumber}}",
+				"tu {{.MyCall}} test",
+				"nr {{.MyNumber}} {{.MyNumber}}",
+				"{{.TheirCall}}?",
+				"qrz?",
+			},
+			RunLabels: []string{"CQ", "Exch", "TU", "Nr", "Call?", "QRZ"},
+		},
+		{
+			Name: "Exchange",
+			SPMacros: []string{
+				"{{.MyCall}}",
+				"rr {{.MyReport}} {{.MyXchange}}",
+				"tu gl",
+				"{{.MyXchange}} {{.MyXchange}}",
+				"{{.MyCall}} {{.MyCall}}",
+			},
+			SPLabels: []string{"MyCall", "Exch", "TU", "Xchg", "MyCall 2x"},
+			RunMacros: []string{
+				"cq {{.MyCall}} test",
+				"{{.TheirCall}} {{.MyReport}} {{.MyXchange}}",
+				"tu {{.MyCall}} test",
+				"{{.MyXchange}} {{.MyXchange}}",
+				"{{.TheirCall}}?",
+				"qrz?",
+			},
+			RunLabels: []string{"CQ", "Exch", "TU", "Xchg", "Call?", "QRZ"},
+		},
 	},
 	TCIAddress:    "localhost:40001",
 	HamlibAddress: "localhost:4532",
 	KeyerHost:     "localhost",
 	KeyerPort:     6789,
+	ESM:           &esmDefault,
+	RBNAddress:    "",
+	DVK: DVK{
+		PlayCommand: []string{"aplay", "-q", "-"},
+		SPMacros: []string{
+			"mycall.wav",
+			"exchange.wav #",
+			"thanks.wav",
+			"number.wav # #",
+		},
+		RunMacros: []string{
+			"cq.wav",
+			"exchange.wav #",
+			"thanks.wav",
+			"number.wav # #",
+		},
+	},
+	ScoreReporting: ScoreReporting{
+		URL:             "",
+		IntervalSeconds: 120,
+	},
 }
 
 // Load loads the configuration from the default location (see github.com/ftl/cfg/LoadJSON()).
@@ -95,13 +166,172 @@ func AbsoluteFilename() string {
 }
 
 type Data struct {
-	Station       pb.Station
-	Contest       pb.Contest
-	Keyer         pb.Keyer
-	KeyerHost     string `json:"keyer_host"`
-	KeyerPort     int    `json:"keyer_port"`
-	HamlibAddress string `json:"hamlib_address"`
+	Station        pb.Station
+	Contest        pb.Contest
+	Keyer          pb.Keyer
+	KeyerHost      string         `json:"keyer_host"`
+	KeyerPort      int            `json:"keyer_port"`
+	WinkeyerPort   string         `json:"winkeyer_port"`
+	ESM            *bool          `json:"esm"`
+	TXHistory      bool           `json:"tx_history"`
+	Offline        bool           `json:"offline"`
+	DXCCOverrides  string         `json:"dxcc_overrides"`
+	HamlibAddress  string         `json:"hamlib_address"`
+	TCIAddress     string         `json:"tci_address"`
+	RBNAddress     string         `json:"rbn_address"`
+	Network        Network        `json:"network"`
+	N1MMBroadcast  []string       `json:"n1mm_broadcast"`
+	ScoreReporting ScoreReporting `json:"score_reporting"`
+	SO2R           SO2R           `json:"so2r"`
+	CAT            CAT            `json:"cat"`
+	DVK            DVK            `json:"dvk"`
+	MacroSets      []MacroSet     `json:"macro_sets"`
+	CallHistory    []CallHistory  `json:"call_history"`
+	Callbook       Callbook       `json:"callbook"`
+}
+
+// SO2R contains the settings of the second radio for single operator two radio operation.
+// If no TCI address, no Hamlib address and no CAT port is configured, only one radio is used.
+type SO2R struct {
 	TCIAddress    string `json:"tci_address"`
+	TCITRX        int    `json:"tci_trx"`
+	HamlibAddress string `json:"hamlib_address"`
+	CAT           CAT    `json:"cat"`
+}
+
+// CAT contains the settings to control a radio directly through its serial CAT interface.
+// The protocol is one of "kenwood", "yaesu" or "icom". If no port is configured, CAT is not used.
+type CAT struct {
+	Protocol   string `json:"protocol"`
+	Port       string `json:"port"`
+	BaudRate   int    `json:"baud_rate"`
+	CIVAddress int    `json:"civ_address"`
+}
+
+func (c CAT) toCore() core.CAT {
+	return core.CAT{
+		Protocol:   core.CATProtocol(c.Protocol),
+		Port:       c.Port,
+		BaudRate:   c.BaudRate,
+		CIVAddress: c.CIVAddress,
+	}
+}
+
+// DVK contains the settings of the digital voice keyer. If no directory is configured, the recordings are stored in
+// the directory "dvk" within the configuration directory.
+type DVK struct {
+	Directory   string   `json:"directory"`
+	PlayCommand []string `json:"play_command"`
+	SPMacros    []string `json:"sp_macros"`
+	RunMacros   []string `json:"run_macros"`
+}
+
+func (d DVK) toCore() core.DVK {
+	directory := d.Directory
+	if directory == "" {
+		directory = filepath.Join(Directory(), "dvk")
+	}
+	return core.DVK{
+		Directory:   directory,
+		PlayCommand: d.PlayCommand,
+		SPMacros:    d.SPMacros,
+		RunMacros:   d.RunMacros,
+	}
+}
+
+// CallHistory contains the call history file that is used for the contest with the given name. A relative filename
+// is resolved within the configuration directory. The fields are the columns of the file that make up the
+// predicted exchange, e.g. ["name", "state"]. Without fields, the Exch1 column is used.
+type CallHistory struct {
+	Contest  string   `json:"contest"`
+	Filename string   `json:"filename"`
+	Fields   []string `json:"fields"`
+}
+
+func (h CallHistory) toCore() core.CallHistory {
+	filename := h.Filename
+	if filename != "" && !filepath.IsAbs(filename) {
+		filename = filepath.Join(Directory(), filename)
+	}
+	return core.CallHistory{
+		Contest:  h.Contest,
+		Filename: filename,
+		Fields:   h.Fields,
+	}
+}
+
+// Callbook contains the settings of the callbook lookup through an XML API in the style of HamQTH.com. Without a
+// username, the callbook is not used. Without a URL, HamQTH.com is used. The cache file is resolved within the
+// configuration directory, by default it is "callbook_cache.json". Without a maximum age, cached entries are kept for
+// 30 days.
+type Callbook struct {
+	URL        string `json:"url"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	CacheFile  string `json:"cache_file"`
+	MaxAgeDays int    `json:"max_age_days"`
+}
+
+func (c Callbook) toCore() core.Callbook {
+	cacheFile := c.CacheFile
+	if cacheFile == "" {
+		cacheFile = "callbook_cache.json"
+	}
+	if !filepath.IsAbs(cacheFile) {
+		cacheFile = filepath.Join(Directory(), cacheFile)
+	}
+	maxAgeDays := c.MaxAgeDays
+	if maxAgeDays <= 0 {
+		maxAgeDays = 30
+	}
+	return core.Callbook{
+		URL:       c.URL,
+		Username:  c.Username,
+		Password:  c.Password,
+		CacheFile: cacheFile,
+		MaxAge:    time.Duration(maxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// MacroSet contains a named set of keyer macros and their labels that can be selected e.g. for a specific contest.
+// The speeds are optional offsets in WPM that are added to the keyer speed when the corresponding macro is sent.
+type MacroSet struct {
+	Name      string   `json:"name"`
+	SPMacros  []string `json:"sp_macros"`
+	SPLabels  []string `json:"sp_labels"`
+	SPSpeeds  []int    `json:"sp_speeds,omitempty"`
+	RunMacros []string `json:"run_macros"`
+	RunLabels []string `json:"run_labels"`
+	RunSpeeds []int    `json:"run_speeds,omitempty"`
+}
+
+func (m MacroSet) toCore() core.MacroSet {
+	return core.MacroSet{
+		Name:      m.Name,
+		SPMacros:  m.SPMacros,
+		SPLabels:  m.SPLabels,
+		SPSpeeds:  m.SPSpeeds,
+		RunMacros: m.RunMacros,
+		RunLabels: m.RunLabels,
+		RunSpeeds: m.RunSpeeds,
+	}
+}
+
+// ScoreReporting contains the settings to report the score to a live score server.
+// If no URL is configured, the score is not reported.
+type ScoreReporting struct {
+	URL             string `json:"url"`
+	IntervalSeconds int    `json:"interval_seconds"`
+}
+
+// Network contains the settings to share the log with other stations in the local network.
+// If no listen address and no peers are configured, the log is not shared.
+type Network struct {
+	StationID     string   `json:"station_id"`
+	ListenAddress string   `json:"listen_address"`
+	Peers         []string `json:"peers"`
+	FirstNumber   int      `json:"first_number"`
+	LastNumber    int      `json:"last_number"`
 }
 
 type LoadedConfiguration struct {
@@ -135,6 +365,42 @@ func (c *LoadedConfiguration) KeyerPort() int {
 	return c.data.KeyerPort
 }
 
+func (c *LoadedConfiguration) WinkeyerPort() string {
+	return c.data.WinkeyerPort
+}
+
+// ESM indicates if the ESM (Enter Sends Message) mode is enabled. If the configuration file does not contain the
+// setting, ESM is enabled.
+func (c *LoadedConfiguration) ESM() bool {
+	if c.data.ESM == nil {
+		return esmDefault
+	}
+	return *c.data.ESM
+}
+
+// TXHistory indicates if the transmit history is persisted in a file next to the logfile.
+func (c *LoadedConfiguration) TXHistory() bool {
+	return c.data.TXHistory
+}
+
+// Offline indicates that the DXCC prefixes and the Supercheck database are never updated automatically.
+func (c *LoadedConfiguration) Offline() bool {
+	return c.data.Offline
+}
+
+// DXCCOverrides returns the name of the file with the overrides for the DXCC prefixes. Relative filenames are resolved
+// against the configuration directory.
+func (c *LoadedConfiguration) DXCCOverrides() string {
+	filename := c.data.DXCCOverrides
+	if filename == "" {
+		filename = "dxcc_overrides.csv"
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(Directory(), filename)
+	}
+	return filename
+}
+
 func (c *LoadedConfiguration) HamlibAddress() string {
 	return c.data.HamlibAddress
 }
@@ -142,3 +408,66 @@ func (c *LoadedConfiguration) HamlibAddress() string {
 func (c *LoadedConfiguration) TCIAddress() string {
 	return c.data.TCIAddress
 }
+
+func (c *LoadedConfiguration) RBNAddress() string {
+	return c.data.RBNAddress
+}
+
+func (c *LoadedConfiguration) ScoreReportingURL() string {
+	return c.data.ScoreReporting.URL
+}
+
+func (c *LoadedConfiguration) ScoreReportingInterval() time.Duration {
+	return time.Duration(c.data.ScoreReporting.IntervalSeconds) * time.Second
+}
+
+func (c *LoadedConfiguration) N1MMBroadcast() []string {
+	return c.data.N1MMBroadcast
+}
+
+func (c *LoadedConfiguration) Network() core.Network {
+	return core.Network{
+		StationID:     c.data.Network.StationID,
+		ListenAddress: c.data.Network.ListenAddress,
+		Peers:         c.data.Network.Peers,
+		FirstNumber:   core.QSONumber(c.data.Network.FirstNumber),
+		LastNumber:    core.QSONumber(c.data.Network.LastNumber),
+	}
+}
+
+func (c *LoadedConfiguration) SO2R() core.SO2R {
+	return core.SO2R{
+		TCIAddress:    c.data.SO2R.TCIAddress,
+		TCITRX:        c.data.SO2R.TCITRX,
+		HamlibAddress: c.data.SO2R.HamlibAddress,
+		CAT:           c.data.SO2R.CAT.toCore(),
+	}
+}
+
+func (c *LoadedConfiguration) CAT() core.CAT {
+	return c.data.CAT.toCore()
+}
+
+func (c *LoadedConfiguration) DVK() core.DVK {
+	return c.data.DVK.toCore()
+}
+
+func (c *LoadedConfiguration) MacroSets() []core.MacroSet {
+	result := make([]core.MacroSet, len(c.data.MacroSets))
+	for i, macroSet := range c.data.MacroSets {
+		result[i] = macroSet.toCore()
+	}
+	return result
+}
+
+func (c *LoadedConfiguration) CallHistory() []core.CallHistory {
+	result := make([]core.CallHistory, len(c.data.CallHistory))
+	for i, callHistory := range c.data.CallHistory {
+		result[i] = callHistory.toCore()
+	}
+	return result
+}
+
+func (c *LoadedConfiguration) Callbook() core.Callbook {
+	return c.data.Callbook.toCore()
+}
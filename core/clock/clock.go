@@ -29,3 +29,8 @@ type StaticClock struct {
 func (c *StaticClock) Now() time.Time {
 	return c.time
 }
+
+// Set sets the static value of the clock.
+func (c *StaticClock) Set(time time.Time) {
+	c.time = time
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ftl/hamradio/callsign"
@@ -15,6 +16,7 @@ type QSO struct {
 	Callsign     callsign.Callsign
 	Time         time.Time
 	Frequency    Frequency
+	TXFrequency  Frequency
 	Band         Band
 	Mode         Mode
 	MyReport     RST
@@ -33,6 +35,26 @@ func (qso *QSO) String() string {
 	return fmt.Sprintf("%s|%-10s|%5.0fkHz|%4s|%-4s|%s|%s|%s|%s|%2d|%t", qso.Time.Format("15:04"), qso.Callsign.String(), qso.Frequency/1000.0, qso.Band, qso.Mode, qso.MyReport, qso.MyNumber.String(), qso.TheirReport, qso.TheirNumber.String(), qso.Points, qso.Duplicate)
 }
 
+// TransmitFrequency returns the frequency on which the QSO was transmitted. It differs from the frequency only
+// if the QSO was made in split mode.
+func (qso QSO) TransmitFrequency() Frequency {
+	if qso.TXFrequency == 0 {
+		return qso.Frequency
+	}
+	return qso.TXFrequency
+}
+
+// Split indicates if the QSO was made with different frequencies for receiving and transmitting.
+func (qso QSO) Split() bool {
+	return qso.TXFrequency != 0 && qso.TXFrequency != qso.Frequency
+}
+
+// NormalizeXchange returns the given exchange in upper case with single spaces between its parts. This allows to
+// compare exchanges that were entered in different ways.
+func NormalizeXchange(xchange string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(xchange), " "))
+}
+
 // Frequency in Hz.
 type Frequency float64
 
@@ -131,10 +153,13 @@ const (
 
 // KeyerValues contains the values that can be used as variables in the keyer templates.
 type KeyerValues struct {
-	TheirCall string
-	MyNumber  QSONumber
-	MyReport  RST
-	MyXchange string
+	TheirCall    string
+	TheirXchange string
+	MyNumber     QSONumber
+	MyReport     RST
+	MyXchange    string
+	Band         Band
+	Mode         Mode
 }
 
 // AnnotatedCallsign contains a callsign with additional information retrieved from databases and the logbook.
@@ -147,6 +172,46 @@ type AnnotatedCallsign struct {
 	Multis     int
 }
 
+// CallbookEntry contains the information about a station retrieved from a callbook.
+type CallbookEntry struct {
+	Callsign string
+	Name     string
+	QTH      string
+	Country  string
+	Grid     string
+	QSLVia   string
+	LoTW     bool
+	EQSL     bool
+	Direct   bool
+}
+
+// Spot represents a station that was spotted on a certain frequency, e.g. by a cluster or a skimmer.
+type Spot struct {
+	Callsign  callsign.Callsign
+	Frequency Frequency
+	Band      Band
+	Mode      Mode
+	Time      time.Time
+}
+
+// AnnotatedSpot contains a spot with additional information about its value for the current score.
+type AnnotatedSpot struct {
+	Spot
+	Duplicate bool
+	Points    int
+	Multis    int
+	Distance  Frequency
+}
+
+// SkimmerReport summarizes how the own signal is received by the skimmers on one band.
+type SkimmerReport struct {
+	Band       Band
+	Skimmers   int
+	AverageSNR float64
+	AverageWPM float64
+	LastHeard  time.Time
+}
+
 type Settings interface {
 	Station() Station
 	Contest() Contest
@@ -156,6 +221,113 @@ type Station struct {
 	Callsign callsign.Callsign
 	Operator callsign.Callsign
 	Locator  locator.Locator
+	Region   IARURegion
+}
+
+// IARURegion is one of the three regions of the International Amateur Radio Union. The region defines the bandplan.
+type IARURegion int
+
+// All IARU regions.
+const (
+	NoRegion    IARURegion = 0
+	IARURegion1 IARURegion = 1
+	IARURegion2 IARURegion = 2
+	IARURegion3 IARURegion = 3
+)
+
+// IARURegions are all IARU regions.
+var IARURegions = []IARURegion{IARURegion1, IARURegion2, IARURegion3}
+
+func (r IARURegion) String() string {
+	if r == NoRegion {
+		return ""
+	}
+	return fmt.Sprintf("IARU Region %d", int(r))
+}
+
+// Network contains the settings to share the log with other stations in the local network.
+type Network struct {
+	StationID     string
+	ListenAddress string
+	Peers         []string
+	FirstNumber   QSONumber
+	LastNumber    QSONumber
+}
+
+// VFOs describes the state of the two VFOs of a radio. In split mode, the radio receives on VFO A and transmits on VFO B.
+type VFOs struct {
+	A     Frequency
+	B     Frequency
+	Split bool
+}
+
+// RX returns the receive frequency.
+func (v VFOs) RX() Frequency {
+	return v.A
+}
+
+// TX returns the transmit frequency.
+func (v VFOs) TX() Frequency {
+	if v.Split && v.B != 0 {
+		return v.B
+	}
+	return v.A
+}
+
+// SO2R contains the settings of the second radio for single operator two radio operation.
+// The TRX selects the transceiver of the TCI host.
+type SO2R struct {
+	TCIAddress    string
+	TCITRX        int
+	HamlibAddress string
+	CAT           CAT
+}
+
+// CATProtocol is the protocol that is used to control a radio directly through its serial CAT interface.
+type CATProtocol string
+
+const (
+	NoCAT      CATProtocol = ""
+	KenwoodCAT CATProtocol = "kenwood"
+	YaesuCAT   CATProtocol = "yaesu"
+	IcomCIV    CATProtocol = "icom"
+)
+
+// CAT contains the settings to control a radio directly through its serial CAT interface.
+// The CIVAddress is only used with the Icom CI-V protocol.
+type CAT struct {
+	Protocol   CATProtocol
+	Port       string
+	BaudRate   int
+	CIVAddress int
+}
+
+// CallHistory contains the settings of a call history file for a specific contest. The file uses the format of N1MM+.
+// The predicted exchange consists of the values of the given fields (columns), separated by spaces.
+type CallHistory struct {
+	Contest  string
+	Filename string
+	Fields   []string
+}
+
+// Callbook contains the settings of the callbook lookup. Without a username, the callbook is not used. Found entries
+// are cached in the cache file and looked up again when they are older than MaxAge.
+type Callbook struct {
+	URL       string
+	Username  string
+	Password  string
+	CacheFile string
+	MaxAge    time.Duration
+}
+
+// DVK contains the settings of the digital voice keyer. The voice macros consist of the names of WAV files in the
+// directory, separated by spaces. The # stands for the serial number, composed of the recordings of the digits.
+// The recordings are played with the play command, which reads the WAV data from stdin.
+type DVK struct {
+	Directory   string
+	PlayCommand []string
+	SPMacros    []string
+	RunMacros   []string
 }
 
 type Contest struct {
@@ -177,6 +349,14 @@ type Contest struct {
 	CountPerBand        bool
 
 	CabrilloQSOTemplate string
+
+	CategoryOperator    string
+	CategoryAssisted    string
+	CategoryBand        string
+	CategoryMode        string
+	CategoryPower       string
+	CategoryTransmitter string
+	CategoryOverlay     string
 }
 
 type Multis struct {
@@ -186,11 +366,63 @@ type Multis struct {
 }
 
 type Keyer struct {
+	SPMacros   []string
+	RunMacros  []string
+	WPM        int
+	ReportCut  CutStyle
+	NumberCut  CutStyle
+	XchangeCut CutStyle
+	// AutoCQGap is the time to listen between two CQs when the CQ is repeated automatically.
+	AutoCQGap time.Duration
+	SPLabels  []string
+	RunLabels []string
+	// MacroSet is the name of the macro set that was selected last.
+	MacroSet string
+	// Weight is the weighting of the CW in percent, 50 is the standard weighting. 0 uses the weighting of the CW client.
+	Weight int
+	// Farnsworth is the speed in WpM that is used for the characters, while the spacing is stretched to keep the
+	// overall speed. 0 disables the Farnsworth spacing.
+	Farnsworth int
+	// SPSpeeds and RunSpeeds contain the speed offset in WpM for each macro, relative to the current speed.
+	SPSpeeds  []int
+	RunSpeeds []int
+}
+
+// MacroSet is a named set of keyer macros and their labels, e.g. for a specific contest.
+type MacroSet struct {
+	Name      string
 	SPMacros  []string
+	SPLabels  []string
 	RunMacros []string
-	WPM       int
+	RunLabels []string
+	SPSpeeds  []int
+	RunSpeeds []int
 }
 
+// Transmission is a message that was sent by the keyer.
+type Transmission struct {
+	Time  time.Time
+	Text  string
+	WPM   int
+	Radio string
+	// Macro is the number of the macro that was sent (1 for F1), 0 if the message was not sent by a macro.
+	Macro int
+}
+
+// CutStyle defines which digits of a value are replaced with cut numbers when the value is sent in CW.
+type CutStyle string
+
+const (
+	// DefaultCut uses the default style of the value: SoftCut for the report and the number, NoCut for the exchange.
+	DefaultCut CutStyle = ""
+	// NoCut sends all digits.
+	NoCut CutStyle = "none"
+	// SoftCut replaces only 0 and 9 with t and n.
+	SoftCut CutStyle = "soft"
+	// FullCut replaces all digits that have a common cut number.
+	FullCut CutStyle = "full"
+)
+
 type Score struct {
 	ScorePerBand map[Band]BandScore
 	TotalScore   BandScore
@@ -328,6 +560,10 @@ const (
 	CWDaemonService
 	DXCCService
 	SCPService
+	RBNService
+	NetworkService
+	ScoreReportingService
+	CATService
 )
 
 type ServiceStatusListener interface {
@@ -340,4 +576,24 @@ func (f ServiceStatusListenerFunc) StatusChanged(service Service, available bool
 	f(service, available)
 }
 
+// DatabaseInfo describes the local copy of the database that is used by a service, e.g. the DXCC prefixes. The
+// version is taken from the content of the database, if available. The date is the time of the last update of the
+// local copy. If the database could not be loaded or updated, Err describes the failure.
+type DatabaseInfo struct {
+	Service Service
+	Version string
+	Date    time.Time
+	Err     error
+}
+
+type DatabaseInfoListener interface {
+	DatabaseInfoChanged(DatabaseInfo)
+}
+
+type DatabaseInfoListenerFunc func(DatabaseInfo)
+
+func (f DatabaseInfoListenerFunc) DatabaseInfoChanged(info DatabaseInfo) {
+	f(info)
+}
+
 type AsyncRunner func(func())
@@ -0,0 +1,52 @@
+// Package cwdaemon sends CW through a cwdaemon server.
+package cwdaemon
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ftl/hamradio/cwclient"
+)
+
+// speedStep is the change of the speed in WpM for every + or - within a message.
+const speedStep = 2
+
+// New returns a new client for the cwdaemon server at the given host and UDP port.
+func New(host string, port int) (*Client, error) {
+	client, err := cwclient.New(host, port)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: client}, nil
+}
+
+// Client is a cwclient.Client that uses the same weighting as the other CW clients and is able to change the speed
+// within a message.
+type Client struct {
+	*cwclient.Client
+	wpm int
+}
+
+// Speed sets the current speed in WpM.
+func (c *Client) Speed(wpm int) {
+	c.wpm = wpm
+	c.Client.Speed(wpm)
+}
+
+// SendWithSpeed sends the given text with the given speed. After the text, cwdaemon returns to the current speed.
+// cwdaemon changes the speed within a message in steps of 2 WpM, the given speed is rounded to the nearest step.
+func (c *Client) SendWithSpeed(text string, wpm int) {
+	steps := int(math.Round(float64(wpm-c.wpm) / speedStep))
+	change, restore := "+", "-"
+	if steps < 0 {
+		change, restore = "-", "+"
+		steps = -steps
+	}
+	c.Client.Send(strings.Repeat(change, steps) + text + strings.Repeat(restore, steps))
+}
+
+// Weight sets the weighting in percent, 50 is the standard weighting. cwdaemon uses a weighting from -50 to 50
+// with 0 as standard weighting.
+func (c *Client) Weight(percent int) {
+	c.Client.Weight(percent - 50)
+}
@@ -0,0 +1,45 @@
+package cwdaemon
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SendWithSpeed(t *testing.T) {
+	server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer server.Close()
+	client, err := New("127.0.0.1", server.LocalAddr().(*net.UDPAddr).Port)
+	require.NoError(t, err)
+	require.NoError(t, client.Connect())
+	defer client.Disconnect()
+
+	client.Speed(24)
+	client.SendWithSpeed("5nn", 28)
+	client.SendWithSpeed("tu", 20)
+
+	assert.Equal(t, []string{"++5nn--", "--tu++"}, receiveTexts(t, server, 2))
+}
+
+// receiveTexts returns the given number of texts that were received by the server, commands are ignored.
+func receiveTexts(t *testing.T, server *net.UDPConn, count int) []string {
+	t.Helper()
+	var result []string
+	buffer := make([]byte, 256)
+	for len(result) < count {
+		server.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := server.Read(buffer)
+		require.NoError(t, err)
+		message := string(buffer[:n])
+		if strings.HasPrefix(message, "\x1B") {
+			continue
+		}
+		result = append(result, message)
+	}
+	return result
+}
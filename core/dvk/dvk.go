@@ -0,0 +1,238 @@
+// Package dvk implements a digital voice keyer that plays recorded messages for the keyer macros in phone modes.
+package dvk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// NumberToken stands for the serial number in a voice macro. The number is composed of the recordings of its digits.
+const NumberToken = "#"
+
+// AudioSink plays audio clips.
+type AudioSink interface {
+	// Play plays the given clip. It returns when the clip was played completely or when the context is done. If the
+	// context is already done, nothing is played.
+	Play(context.Context, Clip) error
+}
+
+// PTT keys the transmitter. The voice keyer does not know how the audio gets into the transmitter, the PTT
+// implementation has to select the proper audio source of the radio.
+type PTT interface {
+	SetPTT(bool)
+}
+
+type PTTFunc func(bool)
+
+func (f PTTFunc) SetPTT(on bool) {
+	f(on)
+}
+
+// New returns a new voice keyer that plays the recordings of the given voice macros through the given sink.
+func New(config core.DVK, sink AudioSink) *Keyer {
+	result := &Keyer{
+		directory: config.Directory,
+		spMacros:  config.SPMacros,
+		runMacros: config.RunMacros,
+		sink:      sink,
+		ptt:       new(nullPTT),
+	}
+	if result.sink == nil {
+		result.sink = new(nullSink)
+	}
+	return result
+}
+
+// Keyer plays the voice macros. Only one voice macro is played at a time.
+type Keyer struct {
+	directory string
+	spMacros  []string
+	runMacros []string
+	sink      AudioSink
+	ptt       PTT
+	playing   chan struct{}
+	cancel    context.CancelFunc
+}
+
+// SetPTT sets the PTT that is keyed while a voice macro is played.
+func (k *Keyer) SetPTT(ptt PTT) {
+	if ptt == nil {
+		k.ptt = new(nullPTT)
+		return
+	}
+	k.ptt = ptt
+}
+
+// Play starts to play the voice macro with the given index. The current voice macro is stopped before. Play returns
+// the time it takes to play the voice macro.
+func (k *Keyer) Play(workmode core.Workmode, index int, values core.KeyerValues) (time.Duration, error) {
+	macro, err := k.macro(workmode, index)
+	if err != nil {
+		return 0, err
+	}
+	clip, err := k.compose(macro, values)
+	if err != nil {
+		return 0, fmt.Errorf("cannot play voice macro F%d: %v", index+1, err)
+	}
+
+	k.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	previous := k.playing
+	playing := make(chan struct{})
+	k.playing = playing
+	k.cancel = cancel
+	go func() {
+		defer close(playing)
+		if previous != nil {
+			<-previous
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		k.ptt.SetPTT(true)
+		defer k.ptt.SetPTT(false)
+		err := k.sink.Play(ctx, clip)
+		if err != nil {
+			log.Printf("cannot play voice macro F%d: %v", index+1, err)
+		}
+	}()
+	return clip.Duration(), nil
+}
+
+// Stop stops playing the current voice macro. It does not wait until the PTT is released.
+func (k *Keyer) Stop() {
+	if k.cancel == nil {
+		return
+	}
+	k.cancel()
+	k.cancel = nil
+}
+
+// RecordFromFile imports the given WAV file as recording of the voice macro with the given index. The recording
+// is stored as the first WAV file of the voice macro.
+func (k *Keyer) RecordFromFile(workmode core.Workmode, index int, filename string) error {
+	macro, err := k.macro(workmode, index)
+	if err != nil {
+		return err
+	}
+	for _, token := range strings.Fields(macro) {
+		if token != NumberToken {
+			return k.importWAV(filename, token)
+		}
+	}
+	return fmt.Errorf("the voice macro F%d contains no recording", index+1)
+}
+
+// RecordDigitFromFile imports the given WAV file as recording of the given digit.
+func (k *Keyer) RecordDigitFromFile(digit int, filename string) error {
+	if digit < 0 || digit > 9 {
+		return fmt.Errorf("%d is not a digit", digit)
+	}
+	return k.importWAV(filename, digitFilename(digit))
+}
+
+func (k *Keyer) importWAV(source string, target string) error {
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	clip, err := ReadWAV(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cannot import %s: %v", source, err)
+	}
+
+	err = os.MkdirAll(k.directory, 0755)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(k.path(target))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return WriteWAV(out, clip)
+}
+
+func (k *Keyer) macro(workmode core.Workmode, index int) (string, error) {
+	macros := k.spMacros
+	if workmode == core.Run {
+		macros = k.runMacros
+	}
+	if index < 0 || index >= len(macros) || strings.TrimSpace(macros[index]) == "" {
+		return "", fmt.Errorf("the voice macro F%d is not defined", index+1)
+	}
+	return macros[index], nil
+}
+
+// compose concatenates the recordings of the given voice macro.
+func (k *Keyer) compose(macro string, values core.KeyerValues) (Clip, error) {
+	var clips []Clip
+	for _, token := range strings.Fields(macro) {
+		var filenames []string
+		if token == NumberToken {
+			filenames = numberFilenames(values.MyNumber.String())
+		} else {
+			filenames = []string{token}
+		}
+		for _, filename := range filenames {
+			clip, err := k.load(filename)
+			if err != nil {
+				return Clip{}, err
+			}
+			clips = append(clips, clip)
+		}
+	}
+	return Concat(clips...)
+}
+
+func (k *Keyer) load(filename string) (Clip, error) {
+	f, err := os.Open(k.path(filename))
+	if err != nil {
+		return Clip{}, err
+	}
+	defer f.Close()
+	clip, err := ReadWAV(f)
+	if err != nil {
+		return Clip{}, fmt.Errorf("%s: %v", filename, err)
+	}
+	return clip, nil
+}
+
+func (k *Keyer) path(filename string) string {
+	if filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(k.directory, filename)
+}
+
+func numberFilenames(number string) []string {
+	result := make([]string, 0, len(number))
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			continue
+		}
+		result = append(result, digitFilename(int(r-'0')))
+	}
+	return result
+}
+
+func digitFilename(digit int) string {
+	return fmt.Sprintf("digit_%d.wav", digit)
+}
+
+type nullSink struct{}
+
+func (*nullSink) Play(context.Context, Clip) error { return nil }
+
+type nullPTT struct{}
+
+func (*nullPTT) SetPTT(bool) {}
@@ -0,0 +1,223 @@
+package dvk
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestWAV_WriteAndRead(t *testing.T) {
+	clip := Clip{SampleRate: 8000, Channels: 1, Data: []byte{1, 0, 2, 0, 3, 0, 4, 0}}
+	buffer := new(bytes.Buffer)
+
+	err := WriteWAV(buffer, clip)
+	require.NoError(t, err)
+	actual, err := ReadWAV(buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, clip, actual)
+	assert.Equal(t, 500*time.Microsecond, actual.Duration())
+}
+
+func TestReadWAV_Invalid(t *testing.T) {
+	_, err := ReadWAV(bytes.NewBufferString("this is not a WAV file"))
+	assert.Error(t, err)
+}
+
+func TestConcat_DifferentFormats(t *testing.T) {
+	_, err := Concat(Clip{SampleRate: 8000, Channels: 1}, Clip{SampleRate: 48000, Channels: 1})
+	assert.Error(t, err)
+}
+
+func TestPlay_ComposesTheSerialNumberFromDigits(t *testing.T) {
+	dir := t.TempDir()
+	writeTestWAV(t, dir, "exchange.wav", 'x')
+	for digit := 0; digit < 10; digit++ {
+		writeTestWAV(t, dir, digitFilename(digit), byte('0'+digit))
+	}
+	sink := newTestSink()
+	ptt := new(testPTT)
+	keyer := New(core.DVK{Directory: dir, RunMacros: []string{"cq.wav", "exchange.wav #"}}, sink)
+	keyer.SetPTT(ptt)
+
+	duration, err := keyer.Play(core.Run, 1, core.KeyerValues{MyNumber: 42})
+	require.NoError(t, err)
+	played := <-sink.played
+	keyer.Stop()
+
+	assert.Equal(t, []byte("x\x00"+"0\x00"+"4\x00"+"2\x00"), played.Data)
+	assert.Equal(t, 4*time.Second/8000, duration)
+	assert.Eventually(t, ptt.released(1), waitFor, tick)
+}
+
+func TestStop_BeforePlayingStarted(t *testing.T) {
+	dir := t.TempDir()
+	writeTestWAV(t, dir, "cq.wav", 'c')
+	sink := newTestSink()
+	ptt := newBlockingPTT()
+	keyer := New(core.DVK{Directory: dir, RunMacros: []string{"cq.wav"}}, sink)
+	keyer.SetPTT(ptt)
+
+	_, err := keyer.Play(core.Run, 0, core.KeyerValues{})
+	require.NoError(t, err)
+	<-ptt.keying
+	keyer.Stop()
+	close(ptt.release)
+
+	assert.Eventually(t, ptt.released(1), waitFor, tick)
+	assert.Empty(t, sink.played, "nothing is played after the keyer was stopped")
+}
+
+func TestPlay_WaitsForThePreviousMacro(t *testing.T) {
+	dir := t.TempDir()
+	writeTestWAV(t, dir, "cq.wav", 'c')
+	writeTestWAV(t, dir, "call.wav", 'm')
+	sink := newTestSink()
+	ptt := new(testPTT)
+	keyer := New(core.DVK{Directory: dir, RunMacros: []string{"cq.wav", "call.wav"}}, sink)
+	keyer.SetPTT(ptt)
+
+	_, err := keyer.Play(core.Run, 0, core.KeyerValues{})
+	require.NoError(t, err)
+	<-sink.played
+	_, err = keyer.Play(core.Run, 1, core.KeyerValues{})
+	require.NoError(t, err)
+	played := <-sink.played
+	keyer.Stop()
+
+	assert.Equal(t, []byte("m\x00"), played.Data)
+	assert.Eventually(t, ptt.released(2), waitFor, tick)
+	assert.Equal(t, []bool{true, false, true, false}, ptt.currentStates())
+}
+
+func TestCommandSink_DoesNotStartWhenStopped(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "played")
+	sink := NewCommandSink([]string{"touch", filename})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := sink.Play(ctx, Clip{SampleRate: 8000, Channels: 1})
+
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filename)
+}
+
+func TestPlay_MissingRecording(t *testing.T) {
+	keyer := New(core.DVK{Directory: t.TempDir(), RunMacros: []string{"cq.wav"}}, newTestSink())
+
+	_, err := keyer.Play(core.Run, 0, core.KeyerValues{})
+	assert.Error(t, err)
+	_, err = keyer.Play(core.SearchPounce, 0, core.KeyerValues{})
+	assert.Error(t, err, "the voice macro is not defined")
+}
+
+func TestRecordFromFile(t *testing.T) {
+	dir := t.TempDir()
+	source := t.TempDir()
+	writeTestWAV(t, source, "recording.wav", 'r')
+	keyer := New(core.DVK{Directory: dir, SPMacros: []string{"mycall.wav", "# exchange.wav", "#"}}, newTestSink())
+
+	err := keyer.RecordFromFile(core.SearchPounce, 1, filepath.Join(source, "recording.wav"))
+	require.NoError(t, err)
+	err = keyer.RecordDigitFromFile(7, filepath.Join(source, "recording.wav"))
+	require.NoError(t, err)
+	err = keyer.RecordFromFile(core.SearchPounce, 2, filepath.Join(source, "recording.wav"))
+	assert.Error(t, err, "the macro contains only the number")
+
+	assert.FileExists(t, filepath.Join(dir, "exchange.wav"))
+	assert.FileExists(t, filepath.Join(dir, "digit_7.wav"))
+}
+
+func writeTestWAV(t *testing.T, dir string, filename string, sample byte) {
+	f, err := os.Create(filepath.Join(dir, filename))
+	require.NoError(t, err)
+	defer f.Close()
+	err = WriteWAV(f, Clip{SampleRate: 8000, Channels: 1, Data: []byte{sample, 0}})
+	require.NoError(t, err)
+}
+
+const (
+	waitFor = 1 * time.Second
+	tick    = 10 * time.Millisecond
+)
+
+// testSink reports the played clips and blocks until it is stopped.
+type testSink struct {
+	played chan Clip
+}
+
+func newTestSink() *testSink {
+	return &testSink{
+		played: make(chan Clip, 1),
+	}
+}
+
+func (s *testSink) Play(ctx context.Context, clip Clip) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	s.played <- clip
+	<-ctx.Done()
+	return nil
+}
+
+type testPTT struct {
+	lock   sync.Mutex
+	states []bool
+}
+
+func (p *testPTT) SetPTT(on bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.states = append(p.states, on)
+}
+
+func (p *testPTT) currentStates() []bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]bool{}, p.states...)
+}
+
+// released returns a condition that is true when the PTT was released the given number of times.
+func (p *testPTT) released(count int) func() bool {
+	return func() bool {
+		var released int
+		for _, on := range p.currentStates() {
+			if !on {
+				released++
+			}
+		}
+		return released == count
+	}
+}
+
+// blockingPTT blocks when the PTT is keyed until it is released by the test.
+type blockingPTT struct {
+	testPTT
+	keying  chan struct{}
+	release chan struct{}
+}
+
+func newBlockingPTT() *blockingPTT {
+	return &blockingPTT{
+		keying:  make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (p *blockingPTT) SetPTT(on bool) {
+	if on {
+		close(p.keying)
+		<-p.release
+	}
+	p.testPTT.SetPTT(on)
+}
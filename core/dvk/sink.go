@@ -0,0 +1,41 @@
+package dvk
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+)
+
+// DefaultPlayCommand plays the WAV data from stdin with ALSA.
+var DefaultPlayCommand = []string{"aplay", "-q", "-"}
+
+// NewCommandSink returns a sink that plays the clips on the local audio device with the given command. The command
+// reads the WAV data from stdin.
+func NewCommandSink(command []string) *CommandSink {
+	if len(command) == 0 {
+		command = DefaultPlayCommand
+	}
+	return &CommandSink{command: command}
+}
+
+// CommandSink plays the clips with an external command.
+type CommandSink struct {
+	command []string
+}
+
+// Play runs the command to play the given clip. The command is killed when the context is done.
+func (s *CommandSink) Play(ctx context.Context, clip Clip) error {
+	buffer := new(bytes.Buffer)
+	err := WriteWAV(buffer, clip)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdin = buffer
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package dvk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// Clip contains 16 bit PCM audio data.
+type Clip struct {
+	SampleRate int
+	Channels   int
+	// Data contains the interleaved 16 bit little endian samples.
+	Data []byte
+}
+
+// Duration returns the time it takes to play the clip.
+func (c Clip) Duration() time.Duration {
+	bytesPerSecond := c.SampleRate * c.Channels * 2
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(len(c.Data)) * time.Second / time.Duration(bytesPerSecond)
+}
+
+func (c Clip) sameFormat(other Clip) bool {
+	return c.SampleRate == other.SampleRate && c.Channels == other.Channels
+}
+
+// Concat appends the given clips. All clips must have the same format.
+func Concat(clips ...Clip) (Clip, error) {
+	if len(clips) == 0 {
+		return Clip{}, errors.New("no audio to concatenate")
+	}
+	result := Clip{SampleRate: clips[0].SampleRate, Channels: clips[0].Channels}
+	for _, clip := range clips {
+		if !result.sameFormat(clip) {
+			return Clip{}, fmt.Errorf("cannot concatenate %d Hz/%d channels with %d Hz/%d channels, all recordings must have the same format", clip.SampleRate, clip.Channels, result.SampleRate, result.Channels)
+		}
+		result.Data = append(result.Data, clip.Data...)
+	}
+	return result, nil
+}
+
+type wavFormat struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+const wavPCM = 1
+
+// ReadWAV reads a WAV file with 16 bit PCM audio data.
+func ReadWAV(r io.Reader) (Clip, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Clip{}, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return Clip{}, errors.New("not a WAV file")
+	}
+
+	var format *wavFormat
+	var result Clip
+	chunks := data[12:]
+	for len(chunks) >= 8 {
+		id := string(chunks[0:4])
+		size := int(binary.LittleEndian.Uint32(chunks[4:8]))
+		chunks = chunks[8:]
+		if size > len(chunks) {
+			size = len(chunks)
+		}
+		chunk := chunks[:size]
+		switch id {
+		case "fmt ":
+			format = new(wavFormat)
+			err = binary.Read(bytes.NewReader(chunk), binary.LittleEndian, format)
+			if err != nil {
+				return Clip{}, fmt.Errorf("invalid WAV format: %v", err)
+			}
+			if format.AudioFormat != wavPCM || format.BitsPerSample != 16 {
+				return Clip{}, fmt.Errorf("unsupported WAV format %d with %d bits per sample, only 16 bit PCM is supported", format.AudioFormat, format.BitsPerSample)
+			}
+			result.SampleRate = int(format.SampleRate)
+			result.Channels = int(format.Channels)
+		case "data":
+			if format == nil {
+				return Clip{}, errors.New("the WAV data comes before the format")
+			}
+			result.Data = append([]byte{}, chunk...)
+			return result, nil
+		}
+		// chunks are aligned to two bytes
+		if size%2 == 1 && size < len(chunks) {
+			size++
+		}
+		chunks = chunks[size:]
+	}
+	return Clip{}, errors.New("the WAV file contains no audio data")
+}
+
+// WriteWAV writes the given clip as WAV file with 16 bit PCM audio data.
+func WriteWAV(w io.Writer, clip Clip) error {
+	format := wavFormat{
+		AudioFormat:   wavPCM,
+		Channels:      uint16(clip.Channels),
+		SampleRate:    uint32(clip.SampleRate),
+		ByteRate:      uint32(clip.SampleRate * clip.Channels * 2),
+		BlockAlign:    uint16(clip.Channels * 2),
+		BitsPerSample: 16,
+	}
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("RIFF")
+	binary.Write(buffer, binary.LittleEndian, uint32(4+8+binary.Size(format)+8+len(clip.Data)))
+	buffer.WriteString("WAVE")
+	buffer.WriteString("fmt ")
+	binary.Write(buffer, binary.LittleEndian, uint32(binary.Size(format)))
+	binary.Write(buffer, binary.LittleEndian, format)
+	buffer.WriteString("data")
+	binary.Write(buffer, binary.LittleEndian, uint32(len(clip.Data)))
+	buffer.Write(clip.Data)
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,54 @@
+package dxcc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// The columns of the cty.csv format.
+const (
+	csvPrimaryPrefix = iota
+	csvName
+	csvDXCCNumber
+	csvContinent
+	csvCQZone
+	csvITUZone
+	csvLatitude
+	csvLongitude
+	csvTimeOffset
+	csvPrefixes
+	csvColumns
+)
+
+// convertCSV converts the given data from the cty.csv format into the cty.dat format. In the cty.csv format, the
+// prefixes of an entity are separated by spaces, in the cty.dat format by commas.
+func convertCSV(data []byte) ([]byte, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(bytes.Buffer)
+	for i, record := range records {
+		if len(record) < csvColumns {
+			return nil, fmt.Errorf("line %d: expected %d columns, got %d", i+1, csvColumns, len(record))
+		}
+		fmt.Fprintf(result, "%s: %s: %s: %s: %s: %s: %s: %s:\n",
+			record[csvName],
+			record[csvCQZone],
+			record[csvITUZone],
+			record[csvContinent],
+			record[csvLatitude],
+			record[csvLongitude],
+			record[csvTimeOffset],
+			record[csvPrimaryPrefix],
+		)
+		prefixes := strings.Fields(strings.TrimSuffix(strings.TrimSpace(record[csvPrefixes]), ";"))
+		fmt.Fprintf(result, "    %s;\n", strings.Join(prefixes, ","))
+	}
+	return result.Bytes(), nil
+}
@@ -1,28 +1,78 @@
 package dxcc
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/ftl/hamradio/dxcc"
+
+	"github.com/ftl/hellocontest/core"
 )
 
-func New() *Finder {
+// New returns a new finder for DXCC prefixes. If offline is true, the local copy of the prefixes is never updated
+// automatically. Use Start to load the prefixes in the background.
+func New(offline bool) *Finder {
 	result := &Finder{
+		offline:   offline,
 		available: make(chan struct{}),
 	}
 
-	go func() {
-		result.entities = setupEntities()
-		log.Print("DXCC prefix database available")
-		close(result.available)
-	}()
+	localFilename, err := dxcc.LocalFilename()
+	if err != nil {
+		log.Print(err)
+	}
+	result.localFilename = localFilename
 
 	return result
 }
 
 type Finder struct {
-	entities  *dxcc.Prefixes
-	available chan struct{}
+	offline           bool
+	localFilename     string
+	overridesFilename string
+	entities          *dxcc.Prefixes
+	overrides         *overrides
+	lock              sync.RWMutex
+	fileLock          sync.Mutex // serializes the loading, updating and importing of the local copy
+	available         chan struct{}
+	listeners         []interface{}
+}
+
+// SetOverridesFile sets the file that contains the overrides for exact callsigns and prefixes (see readOverrides). The
+// overrides are loaded together with the prefixes and take precedence over them. Use ReloadOverrides to apply changes
+// of the file without loading the prefixes again.
+func (f *Finder) SetOverridesFile(filename string) {
+	f.overridesFilename = filename
+}
+
+func (f *Finder) Notify(listener interface{}) {
+	f.listeners = append(f.listeners, listener)
+}
+
+// Start loads the prefixes in the background. Unless the finder is offline, the local copy is updated first.
+func (f *Finder) Start() {
+	go func() {
+		f.fileLock.Lock()
+		defer f.fileLock.Unlock()
+
+		var updateErr error
+		if !f.offline {
+			updateErr = f.update(false)
+		}
+		err := f.load()
+		if err == nil {
+			err = updateErr
+		}
+		f.emitDatabaseInfo(err)
+		close(f.available)
+	}()
 }
 
 func (f *Finder) Available() bool {
@@ -50,31 +100,208 @@ func (f *Finder) Find(s string) (entity dxcc.Prefix, found bool) {
 }
 
 func (f *Finder) FindAll(s string) []dxcc.Prefix {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
 	if f.entities == nil {
 		return []dxcc.Prefix{}
 	}
+	if override, ok := f.overrides.find(s); ok {
+		if entity, ok := f.overriddenEntity(override, s); ok {
+			return []dxcc.Prefix{entity}
+		}
+	}
 	result, _ := f.entities.Find(s)
 	return result
 }
 
-func setupEntities() *dxcc.Prefixes {
-	localFilename, err := dxcc.LocalFilename()
-	if err != nil {
-		log.Print(err)
+// overriddenEntity returns the entity of the given override with the zones and continent of the override. Without an
+// entity, the override only changes the values of the entity of the given callsign.
+func (f *Finder) overriddenEntity(override Override, call string) (dxcc.Prefix, bool) {
+	lookup := override.Entity
+	if lookup == "" {
+		lookup = call
+	}
+	candidates, _ := f.entities.Find(strings.TrimPrefix(lookup, "*"))
+	if len(candidates) == 0 {
+		return dxcc.Prefix{}, false
+	}
+	entity := candidates[0]
+	for _, candidate := range candidates {
+		if candidate.PrimaryPrefix == override.Entity {
+			entity = candidate
+			break
+		}
+	}
+	return override.apply(entity), true
+}
+
+// ReloadOverrides reads the overrides file again and replaces the current overrides.
+func (f *Finder) ReloadOverrides() error {
+	f.lock.RLock()
+	entities := f.entities
+	f.lock.RUnlock()
+	if entities == nil {
+		return fmt.Errorf("the DXCC prefixes are not loaded yet")
+	}
+
+	overrides, err := f.loadOverrides(entities)
+	f.lock.Lock()
+	f.overrides = overrides
+	f.lock.Unlock()
+	f.emitDatabaseInfo(err)
+	return err
+}
+
+// Update downloads the current prefixes and replaces the local copy. This is an explicit action, it also works if the
+// finder is offline.
+func (f *Finder) Update() error {
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	err := f.update(true)
+	if err == nil {
+		err = f.load()
+	}
+	f.emitDatabaseInfo(err)
+	return err
+}
+
+// Import replaces the local copy with the prefixes from the given file. The file is either in the cty.dat format
+// or in the cty.csv format.
+func (f *Finder) Import(filename string) error {
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	err := f.importFile(filename)
+	if err == nil {
+		err = f.load()
+	}
+	f.emitDatabaseInfo(err)
+	return err
+}
+
+func (f *Finder) update(force bool) error {
+	if f.localFilename == "" {
+		return fmt.Errorf("no local filename for the DXCC prefixes")
+	}
+	if force {
+		err := dxcc.Download(dxcc.DefaultURL, f.localFilename)
+		if err != nil {
+			return fmt.Errorf("update of local copy of DXCC prefixes failed: %v", err)
+		}
+		log.Printf("downloaded local copy of DXCC prefixes: %v", f.localFilename)
 		return nil
 	}
-	updated, err := dxcc.Update(dxcc.DefaultURL, localFilename)
+	updated, err := dxcc.Update(dxcc.DefaultURL, f.localFilename)
 	if err != nil {
-		log.Printf("update of local copy of DXCC prefixes failed: %v", err)
+		return fmt.Errorf("update of local copy of DXCC prefixes failed: %v", err)
 	}
 	if updated {
-		log.Printf("updated local copy of DXCC prefixes: %v", localFilename)
+		log.Printf("updated local copy of DXCC prefixes: %v", f.localFilename)
 	}
+	return nil
+}
 
-	result, err := dxcc.LoadLocal(localFilename)
+func (f *Finder) importFile(filename string) error {
+	if f.localFilename == "" {
+		return fmt.Errorf("no local filename for the DXCC prefixes")
+	}
+	data, err := ioutil.ReadFile(filename)
 	if err != nil {
-		log.Printf("cannot load DXCC prefixes: %v", err)
-		return nil
+		return err
 	}
-	return result
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		data, err = convertCSV(data)
+		if err != nil {
+			return fmt.Errorf("cannot convert %s: %v", filepath.Base(filename), err)
+		}
+	}
+	_, err = dxcc.Read(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s does not contain valid DXCC prefixes: %v", filepath.Base(filename), err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(f.localFilename), 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(f.localFilename, data, 0644)
+	if err != nil {
+		return err
+	}
+	log.Printf("imported DXCC prefixes from %s into %s", filename, f.localFilename)
+	return nil
+}
+
+func (f *Finder) load() error {
+	if f.localFilename == "" {
+		return fmt.Errorf("no local filename for the DXCC prefixes")
+	}
+	entities, err := dxcc.LoadLocal(f.localFilename)
+	if err != nil {
+		return fmt.Errorf("cannot load DXCC prefixes: %v", err)
+	}
+
+	overrides, overridesErr := f.loadOverrides(entities)
+
+	f.lock.Lock()
+	f.entities = entities
+	f.overrides = overrides
+	f.lock.Unlock()
+	log.Print("DXCC prefix database available")
+	return overridesErr
+}
+
+// loadOverrides loads the overrides file, if there is any. Overrides with an entity that is unknown in the given
+// prefixes are reported as error, all other overrides are used anyway.
+func (f *Finder) loadOverrides(entities *dxcc.Prefixes) (*overrides, error) {
+	if f.overridesFilename == "" {
+		return nil, nil
+	}
+	overrides, err := loadOverrides(f.overridesFilename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load DXCC overrides from %s: %v", f.overridesFilename, err)
+	}
+	unknown := overrides.unknownEntities(entities)
+	if len(unknown) > 0 {
+		return overrides, fmt.Errorf("unknown entities in the DXCC overrides from %s: %s", f.overridesFilename, strings.Join(unknown, ", "))
+	}
+	return overrides, nil
+}
+
+func (f *Finder) emitDatabaseInfo(err error) {
+	f.lock.RLock()
+	available := f.entities != nil
+	f.lock.RUnlock()
+
+	info := core.DatabaseInfo{
+		Service: core.DXCCService,
+		Err:     err,
+	}
+	if stat, statErr := os.Stat(f.localFilename); statErr == nil {
+		info.Date = stat.ModTime()
+	}
+	if data, readErr := ioutil.ReadFile(f.localFilename); readErr == nil {
+		info.Version = readVersion(data)
+	}
+
+	for _, listener := range f.listeners {
+		if databaseInfoListener, ok := listener.(core.DatabaseInfoListener); ok {
+			databaseInfoListener.DatabaseInfoChanged(info)
+		}
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.DXCCService, available)
+		}
+	}
+}
+
+// versionExpression matches the pseudo prefix that contains the version of a cty.dat file, e.g. =VER20201102.
+var versionExpression = regexp.MustCompile(`=VER(\d{8})\b`)
+
+func readVersion(data []byte) string {
+	match := versionExpression.FindSubmatch(data)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
 }
@@ -0,0 +1,76 @@
+package dxcc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+const testCSV = `1A,Sov Mil Order of Malta,246,EU,15,28,41.90,-12.43,-1.0,1A;
+DL,Fed. Rep. of Germany,230,EU,14,28,51.00,-10.00,-1.0,DA DB DC DD DE DF DG DH DI DJ DK DL DM DN DO DP DQ DR Y2 Y3 Y4 Y5 Y6 Y7 Y8 Y9 =DL0VER20201102;
+`
+
+func TestConvertCSV(t *testing.T) {
+	data, err := convertCSV([]byte(testCSV))
+	require.NoError(t, err)
+
+	assert.Equal(t, `Sov Mil Order of Malta: 15: 28: EU: 41.90: -12.43: -1.0: 1A:
+    1A;
+Fed. Rep. of Germany: 14: 28: EU: 51.00: -10.00: -1.0: DL:
+    DA,DB,DC,DD,DE,DF,DG,DH,DI,DJ,DK,DL,DM,DN,DO,DP,DQ,DR,Y2,Y3,Y4,Y5,Y6,Y7,Y8,Y9,=DL0VER20201102;
+`, string(data))
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "cty.csv")
+	err := ioutil.WriteFile(importFilename, []byte(testCSV), 0644)
+	require.NoError(t, err)
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "cty.dat")
+	var infos []core.DatabaseInfo
+	var available []bool
+	finder.Notify(core.DatabaseInfoListenerFunc(func(info core.DatabaseInfo) {
+		infos = append(infos, info)
+	}))
+	finder.Notify(core.ServiceStatusListenerFunc(func(_ core.Service, a bool) {
+		available = append(available, a)
+	}))
+
+	err = finder.Import(importFilename)
+	require.NoError(t, err)
+
+	entity, found := finder.Find("DL1ABC")
+	assert.True(t, found)
+	assert.Equal(t, "Fed. Rep. of Germany", entity.Name)
+	require.Len(t, infos, 1)
+	assert.Equal(t, core.DXCCService, infos[0].Service)
+	assert.NoError(t, infos[0].Err)
+	assert.False(t, infos[0].Date.IsZero())
+	assert.Equal(t, []bool{true}, available)
+}
+
+func TestImport_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "cty.dat")
+	var available []bool
+	finder.Notify(core.ServiceStatusListenerFunc(func(_ core.Service, a bool) {
+		available = append(available, a)
+	}))
+
+	err := finder.Import(filepath.Join(dir, "missing.dat"))
+
+	assert.Error(t, err)
+	assert.Equal(t, []bool{false}, available)
+}
+
+func TestReadVersion(t *testing.T) {
+	assert.Equal(t, "20201102", readVersion([]byte("    XL2[4],XO2,=VER20201102,\n")))
+	assert.Equal(t, "", readVersion([]byte("    P5,P6,=VERSION;\n")))
+}
@@ -0,0 +1,183 @@
+package dxcc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/hamradio/dxcc"
+)
+
+// The columns of the overrides file.
+const (
+	overrideCall = iota
+	overrideEntity
+	overrideCQZone
+	overrideITUZone
+	overrideContinent
+)
+
+// Override assigns an exact callsign or all callsigns with a prefix to an entity. The entity is given by its primary
+// prefix in the main database. Zones and continent are optional and override the values of the entity.
+type Override struct {
+	Call      string
+	Exact     bool
+	Entity    string
+	CQZone    dxcc.CQZone
+	ITUZone   dxcc.ITUZone
+	Continent string
+}
+
+type overrides struct {
+	exact    map[string]Override
+	prefixes []Override
+}
+
+// readOverrides reads the overrides in CSV format: call or prefix, entity, CQ zone, ITU zone, continent. Exact callsigns
+// start with =, lines starting with # are comments. Only the first column is required.
+func readOverrides(r io.Reader) (*overrides, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &overrides{
+		exact: make(map[string]Override),
+	}
+	for i, record := range records {
+		override, err := parseOverride(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if override.Exact {
+			result.exact[override.Call] = override
+		} else {
+			result.prefixes = append(result.prefixes, override)
+		}
+	}
+
+	// the longest prefix comes first
+	sort.Slice(result.prefixes, func(i, j int) bool {
+		return len(result.prefixes[i].Call) > len(result.prefixes[j].Call)
+	})
+	return result, nil
+}
+
+func parseOverride(record []string) (Override, error) {
+	field := func(column int) string {
+		if column >= len(record) {
+			return ""
+		}
+		return strings.ToUpper(strings.TrimSpace(record[column]))
+	}
+
+	var result Override
+	result.Call = field(overrideCall)
+	if strings.HasPrefix(result.Call, "=") {
+		result.Exact = true
+		result.Call = result.Call[1:]
+	}
+	if result.Call == "" {
+		return Override{}, fmt.Errorf("the callsign or prefix is missing")
+	}
+	result.Entity = field(overrideEntity)
+	if s := field(overrideCQZone); s != "" {
+		zone, err := strconv.Atoi(s)
+		if err != nil {
+			return Override{}, fmt.Errorf("invalid CQ zone %q: %v", s, err)
+		}
+		result.CQZone = dxcc.CQZone(zone)
+	}
+	if s := field(overrideITUZone); s != "" {
+		zone, err := strconv.Atoi(s)
+		if err != nil {
+			return Override{}, fmt.Errorf("invalid ITU zone %q: %v", s, err)
+		}
+		result.ITUZone = dxcc.ITUZone(zone)
+	}
+	result.Continent = field(overrideContinent)
+	return result, nil
+}
+
+func loadOverrides(filename string) (*overrides, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readOverrides(file)
+}
+
+// find returns the override for the given callsign. Exact callsigns take precedence over prefixes.
+func (o *overrides) find(s string) (Override, bool) {
+	if o == nil {
+		return Override{}, false
+	}
+	call := strings.ToUpper(strings.TrimSpace(s))
+	if override, ok := o.exact[call]; ok {
+		return override, true
+	}
+	for _, override := range o.prefixes {
+		if strings.HasPrefix(call, override.Call) {
+			return override, true
+		}
+	}
+	return Override{}, false
+}
+
+// apply returns the given entity with the values of the override.
+func (o Override) apply(entity dxcc.Prefix) dxcc.Prefix {
+	entity.Prefix = o.Call
+	entity.NeedsExactMatch = o.Exact
+	if o.CQZone != 0 {
+		entity.CQZone = o.CQZone
+	}
+	if o.ITUZone != 0 {
+		entity.ITUZone = o.ITUZone
+	}
+	if o.Continent != "" {
+		entity.Continent = o.Continent
+	}
+	return entity
+}
+
+// unknownEntities returns the entities of the overrides that cannot be found in the given prefixes, each with the
+// callsign or prefix of its override, e.g. "XX9 (=DL1ABC)".
+func (o *overrides) unknownEntities(entities *dxcc.Prefixes) []string {
+	if o == nil {
+		return nil
+	}
+	all := make([]Override, 0, len(o.exact)+len(o.prefixes))
+	for _, override := range o.exact {
+		all = append(all, override)
+	}
+	all = append(all, o.prefixes...)
+
+	result := make([]string, 0)
+	for _, override := range all {
+		if override.Entity == "" {
+			continue
+		}
+		if candidates, _ := entities.Find(strings.TrimPrefix(override.Entity, "*")); len(candidates) > 0 {
+			continue
+		}
+		call := override.Call
+		if override.Exact {
+			call = "=" + call
+		}
+		result = append(result, fmt.Sprintf("%s (%s)", override.Entity, call))
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,125 @@
+package dxcc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOverrides = `# call or prefix, entity, cq zone, itu zone, continent
+=DL0MALTA, 1A
+DA0, , 15, , AF
+=dl1abc, dl, 40
+`
+
+func TestReadOverrides(t *testing.T) {
+	actual, err := readOverrides(strings.NewReader(testOverrides))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Override{
+		"DL0MALTA": {Call: "DL0MALTA", Exact: true, Entity: "1A"},
+		"DL1ABC":   {Call: "DL1ABC", Exact: true, Entity: "DL", CQZone: 40},
+	}, actual.exact)
+	assert.Equal(t, []Override{
+		{Call: "DA0", CQZone: 15, Continent: "AF"},
+	}, actual.prefixes)
+}
+
+func TestReadOverrides_InvalidZone(t *testing.T) {
+	_, err := readOverrides(strings.NewReader("DL1ABC, DL, fourteen\n"))
+	assert.Error(t, err)
+}
+
+func TestFinder_FindOverridden(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "cty.csv")
+	require.NoError(t, ioutil.WriteFile(importFilename, []byte(testCSV), 0644))
+	overridesFilename := filepath.Join(dir, "dxcc_overrides.csv")
+	require.NoError(t, ioutil.WriteFile(overridesFilename, []byte(testOverrides), 0644))
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "cty.dat")
+	finder.SetOverridesFile(overridesFilename)
+	require.NoError(t, finder.Import(importFilename))
+
+	testCases := []struct {
+		call      string
+		name      string
+		prefix    string
+		cqZone    int
+		continent string
+	}{
+		{"DL0MALTA", "Sov Mil Order of Malta", "DL0MALTA", 15, "EU"},
+		{"DL0MALTA/P", "Fed. Rep. of Germany", "DL", 14, "EU"},
+		{"DL1ABC", "Fed. Rep. of Germany", "DL1ABC", 40, "EU"},
+		{"DA0XYZ", "Fed. Rep. of Germany", "DA0", 15, "AF"},
+		{"DA1XYZ", "Fed. Rep. of Germany", "DA", 14, "EU"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.call, func(t *testing.T) {
+			entity, found := finder.Find(tc.call)
+			require.True(t, found)
+			assert.Equal(t, tc.name, entity.Name)
+			assert.Equal(t, tc.prefix, entity.Prefix)
+			assert.Equal(t, tc.cqZone, int(entity.CQZone))
+			assert.Equal(t, tc.continent, entity.Continent)
+		})
+	}
+}
+
+func TestFinder_MissingOverridesFile(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "cty.csv")
+	require.NoError(t, ioutil.WriteFile(importFilename, []byte(testCSV), 0644))
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "cty.dat")
+	finder.SetOverridesFile(filepath.Join(dir, "missing.csv"))
+
+	err := finder.Import(importFilename)
+
+	assert.NoError(t, err)
+	_, found := finder.Find("DL1ABC")
+	assert.True(t, found)
+}
+
+func TestFinder_UnknownOverriddenEntity(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "cty.csv")
+	require.NoError(t, ioutil.WriteFile(importFilename, []byte(testCSV), 0644))
+	overridesFilename := filepath.Join(dir, "dxcc_overrides.csv")
+	require.NoError(t, ioutil.WriteFile(overridesFilename, []byte("=DL0ABC, XX9\nDL1ABC, DL, 40\n"), 0644))
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "cty.dat")
+	finder.SetOverridesFile(overridesFilename)
+
+	err := finder.Import(importFilename)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "XX9 (=DL0ABC)")
+	entity, found := finder.Find("DL1ABC")
+	require.True(t, found)
+	assert.Equal(t, 40, int(entity.CQZone), "the valid overrides are used anyway")
+}
+
+func TestFinder_ReloadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "cty.csv")
+	require.NoError(t, ioutil.WriteFile(importFilename, []byte(testCSV), 0644))
+	overridesFilename := filepath.Join(dir, "dxcc_overrides.csv")
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "cty.dat")
+	finder.SetOverridesFile(overridesFilename)
+	require.NoError(t, finder.Import(importFilename))
+	entity, _ := finder.Find("DL0MALTA")
+	require.Equal(t, "Fed. Rep. of Germany", entity.Name)
+
+	require.NoError(t, ioutil.WriteFile(overridesFilename, []byte(testOverrides), 0644))
+	err := finder.ReloadOverrides()
+
+	require.NoError(t, err)
+	entity, _ = finder.Find("DL0MALTA")
+	assert.Equal(t, "Sov Mil Order of Malta", entity.Name)
+}
@@ -0,0 +1,79 @@
+// Package dynamicresults provides the score breakdown of the dynamicresults XML document of N1MM+. The same format is
+// used to report the score to live score servers.
+package dynamicresults
+
+import (
+	"strings"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// Breakdown is the breakdown of the score into QSOs, multis and points per band.
+type Breakdown struct {
+	QSOs   []Value `xml:"qso"`
+	Mults  []Value `xml:"mult"`
+	Points []Value `xml:"point"`
+}
+
+// Value is one value of the score breakdown.
+type Value struct {
+	Band  string `xml:"band,attr"`
+	Mode  string `xml:"mode,attr"`
+	Value int    `xml:",chardata"`
+}
+
+// BandMode is the key to count the QSOs per band and mode.
+type BandMode struct {
+	Band core.Band
+	Mode core.Mode
+}
+
+// Score returns the resulting total score of the given contest.
+func Score(contest core.Contest, score core.Score) int {
+	result := total(contest, score)
+	return result.Result()
+}
+
+// total returns the total score of the given contest. If the QSOs are counted per band, the total is the sum of all
+// bands, otherwise it is the overall score.
+func total(contest core.Contest, score core.Score) core.BandScore {
+	if contest.CountPerBand {
+		return score.TotalScore
+	}
+	return score.OverallScore
+}
+
+// NewBreakdown returns the breakdown of the given score, beginning with the total values. If the number of QSOs per
+// band and mode is given, the QSOs are broken down by band and mode, otherwise only by band.
+func NewBreakdown(contest core.Contest, score core.Score, qsos map[BandMode]int) Breakdown {
+	total := total(contest, score)
+	result := Breakdown{
+		QSOs:   []Value{{Band: "total", Mode: "ALL", Value: total.QSOs()}},
+		Mults:  []Value{{Band: "total", Mode: "ALL", Value: total.Multis}},
+		Points: []Value{{Band: "total", Mode: "ALL", Value: total.Points}},
+	}
+
+	for _, band := range core.Bands {
+		bandName := strings.TrimSuffix(string(band), "m")
+		if qsos != nil {
+			for _, mode := range core.Modes {
+				count := qsos[BandMode{band, mode}]
+				if count == 0 {
+					continue
+				}
+				result.QSOs = append(result.QSOs, Value{Band: bandName, Mode: string(mode), Value: count})
+			}
+		}
+		bandScore, ok := score.ScorePerBand[band]
+		if !ok {
+			continue
+		}
+		if qsos == nil {
+			result.QSOs = append(result.QSOs, Value{Band: bandName, Mode: "ALL", Value: bandScore.QSOs()})
+		}
+		result.Mults = append(result.Mults, Value{Band: bandName, Mode: "ALL", Value: bandScore.Multis})
+		result.Points = append(result.Points, Value{Band: bandName, Mode: "ALL", Value: bandScore.Points})
+	}
+
+	return result
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
 	"github.com/ftl/hamradio/callsign"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
 	"github.com/ftl/hellocontest/core/parse"
 	"github.com/ftl/hellocontest/core/ticker"
 )
@@ -27,6 +31,7 @@ type View interface {
 	SetActiveField(core.EntryField)
 	SetDuplicateMarker(bool)
 	SetEditingMarker(bool)
+	SetXchangeMismatchMarker(bool)
 	ShowMessage(...interface{})
 	ClearMessage()
 	ShowKeyerSpeed(int)
@@ -69,7 +74,11 @@ type Keyer interface {
 	DecreaseSpeed()
 	IncreaseSpeed()
 	Send(int)
+	SendSlower(int)
+	ResendSlower()
 	WorkmodeChanged(core.Workmode)
+	AutoCQ()
+	StopAutoCQ()
 }
 
 // Callinfo functionality used for QSO entry.
@@ -77,14 +86,61 @@ type Callinfo interface {
 	ShowInfo(call string, band core.Band, mode core.Mode, xchange string)
 }
 
+// XchangePredictor predicts the exchange of a station, e.g. from a call history file.
+type XchangePredictor interface {
+	PredictXchange(call string) (string, bool)
+}
+
 // VFO functionality used for QSO entry.
 type VFO interface {
 	Active() bool
+	VFOs() core.VFOs
 	SetFrequency(core.Frequency)
 	SetBand(core.Band)
 	SetMode(core.Mode)
 }
 
+// BandMemory remembers the last used frequency and workmode for each band and mode.
+type BandMemory interface {
+	Remember(core.Band, core.Mode, core.Frequency, core.Workmode)
+	Recall(core.Band, core.Mode) (core.Frequency, core.Workmode, bool)
+}
+
+// Hunter provides the next station to hunt for in search&pounce.
+type Hunter interface {
+	NextBest() (core.AnnotatedSpot, bool)
+}
+
+type FrequencyChangedListener interface {
+	FrequencyChanged(core.Frequency)
+}
+
+type FrequencyChangedListenerFunc func(core.Frequency)
+
+func (f FrequencyChangedListenerFunc) FrequencyChanged(frequency core.Frequency) {
+	f(frequency)
+}
+
+type BandChangedListener interface {
+	BandChanged(core.Band)
+}
+
+type BandChangedListenerFunc func(core.Band)
+
+func (f BandChangedListenerFunc) BandChanged(band core.Band) {
+	f(band)
+}
+
+type ModeChangedListener interface {
+	ModeChanged(core.Mode)
+}
+
+type ModeChangedListenerFunc func(core.Mode)
+
+func (f ModeChangedListenerFunc) ModeChanged(mode core.Mode) {
+	f(mode)
+}
+
 // NewController returns a new entry controller.
 func NewController(settings core.Settings, clock core.Clock, qsoList QSOList, asyncRunner core.AsyncRunner) *Controller {
 	result := &Controller{
@@ -93,10 +149,14 @@ func NewController(settings core.Settings, clock core.Clock, qsoList QSOList, as
 		logbook:     new(nullLogbook),
 		callinfo:    new(nullCallinfo),
 		vfo:         new(nullVFO),
+		hunter:      new(nullHunter),
+		bandMemory:  new(nullBandMemory),
+		predictor:   new(nullXchangePredictor),
 		asyncRunner: asyncRunner,
 		qsoList:     qsoList,
 
 		stationCallsign:     settings.Station().Callsign.String(),
+		bandplan:            bandplans.ForRegion(settings.Station().Region),
 		enableTheirNumber:   settings.Contest().EnterTheirNumber,
 		enableTheirXchange:  settings.Contest().EnterTheirXchange,
 		requireTheirXchange: settings.Contest().RequireTheirXchange,
@@ -113,11 +173,18 @@ type Controller struct {
 	keyer    Keyer
 	callinfo Callinfo
 	vfo      VFO
+	hunter   Hunter
+
+	bandMemory BandMemory
+	predictor  XchangePredictor
+
+	listeners []interface{}
 
 	asyncRunner   core.AsyncRunner
 	refreshTicker *ticker.Ticker
 
 	stationCallsign     string
+	bandplan            bandplan.Bandplan
 	enableTheirNumber   bool
 	enableTheirXchange  bool
 	requireTheirXchange bool
@@ -131,6 +198,11 @@ type Controller struct {
 	editQSO            core.QSO
 	ignoreQSOSelection bool
 	workmode           core.Workmode
+	esm                bool
+	autoCQ             bool
+	autoCQRemaining    time.Duration
+	predictedXchange   string
+	xchangeMismatch    bool
 }
 
 func (c *Controller) SetView(view View) {
@@ -144,6 +216,33 @@ func (c *Controller) SetView(view View) {
 	c.view.EnableExchangeFields(c.enableTheirNumber, c.enableTheirXchange)
 }
 
+// SwitchView sets the view and shows the current state of the entry without clearing it. This allows to switch
+// one view between the entry controllers of two radios.
+func (c *Controller) SwitchView(view View) {
+	if view == nil {
+		c.view = &nullView{}
+		return
+	}
+	c.view = view
+	c.showInput()
+	c.refreshUTC()
+	c.view.EnableExchangeFields(c.enableTheirNumber, c.enableTheirXchange)
+	c.view.SetFrequency(c.selectedFrequency)
+	c.view.SetActiveField(c.activeField)
+	c.view.SetEditingMarker(c.editing)
+	c.view.ShowWorkmode(c.workmodeText())
+	c.view.ClearMessage()
+	c.enterCallsign(c.input.callsign)
+
+	call, err := callsign.Parse(c.input.callsign)
+	if err != nil {
+		c.view.SetDuplicateMarker(false)
+		return
+	}
+	_, duplicate := c.isDuplicate(call)
+	c.view.SetDuplicateMarker(duplicate && !c.editing)
+}
+
 func (c *Controller) SetLogbook(logbook Logbook) {
 	c.logbook = logbook
 	if c.selectedBand == core.NoBand || !c.vfo.Active() {
@@ -169,27 +268,74 @@ func (c *Controller) SetLogbook(logbook Logbook) {
 	c.input.myXchange = c.logbook.LastXchange()
 
 	c.showInput()
+	c.emitBandChanged(c.selectedBand)
+	c.emitModeChanged(c.selectedMode)
 }
 
 func (c *Controller) ToggleWorkmode() {
 	fmt.Printf("Toggle workmode\n")
 	switch c.workmode {
 	case core.SearchPounce:
-		c.view.ShowWorkmode("Run")
-		c.workmode = core.Run
-		c.keyer.WorkmodeChanged(core.Run)
+		c.SetWorkmode(core.Run)
 	case core.Run:
-		c.view.ShowWorkmode("SP")
-		c.workmode = core.SearchPounce
-		c.keyer.WorkmodeChanged(core.SearchPounce)
+		c.SetWorkmode(core.SearchPounce)
+	}
+}
+
+func (c *Controller) SetWorkmode(workmode core.Workmode) {
+	if c.workmode == workmode {
+		return
+	}
+	c.workmode = workmode
+	c.view.ShowWorkmode(c.workmodeText())
+	if c.keyer != nil {
+		c.keyer.WorkmodeChanged(c.workmode)
+	}
+	c.rememberFrequency()
+}
+
+// workmodeText returns the text that shows the workmode. While the auto-CQ is active, it contains the countdown
+// until the next CQ.
+func (c *Controller) workmodeText() string {
+	result := "SP"
+	if c.workmode == core.Run {
+		result = "Run"
+	}
+	if c.autoCQ {
+		result += fmt.Sprintf(" CQ %ds", int(c.autoCQRemaining.Round(time.Second)/time.Second))
+	}
+	return result
+}
+
+// AutoCQ starts to repeat the CQ macro automatically.
+func (c *Controller) AutoCQ() {
+	if c.keyer == nil || c.editing {
+		return
 	}
+	c.keyer.AutoCQ()
+}
+
+// AutoCQChanged shows the countdown of the auto-CQ.
+func (c *Controller) AutoCQChanged(active bool, remaining time.Duration) {
+	c.autoCQ = active
+	c.autoCQRemaining = remaining
+	c.view.ShowWorkmode(c.workmodeText())
 }
 
 func (c *Controller) SetKeyer(keyer Keyer) {
 	c.keyer = keyer
 }
 
+// SetESM enables or disables the ESM (Enter Sends Message) mode.
+func (c *Controller) SetESM(enabled bool) {
+	c.esm = enabled
+}
+
 func (c *Controller) SetCallinfo(callinfo Callinfo) {
+	if callinfo == nil {
+		c.callinfo = new(nullCallinfo)
+		return
+	}
 	c.callinfo = callinfo
 }
 
@@ -200,7 +346,49 @@ func (c *Controller) SetVFO(vfo VFO) {
 	c.vfo = vfo
 }
 
+// VFOs returns the current state of the VFOs of the radio.
+func (c *Controller) VFOs() core.VFOs {
+	return c.vfo.VFOs()
+}
+
+func (c *Controller) SetHunter(hunter Hunter) {
+	if hunter == nil {
+		c.hunter = new(nullHunter)
+		return
+	}
+	c.hunter = hunter
+}
+
+func (c *Controller) SetBandMemory(bandMemory BandMemory) {
+	if bandMemory == nil {
+		c.bandMemory = new(nullBandMemory)
+		return
+	}
+	c.bandMemory = bandMemory
+}
+
+// SetXchangePredictor sets the predictor that is used to pre-fill their exchange when a callsign is entered.
+func (c *Controller) SetXchangePredictor(predictor XchangePredictor) {
+	if predictor == nil {
+		c.predictor = new(nullXchangePredictor)
+		return
+	}
+	c.predictor = predictor
+}
+
+func (c *Controller) Notify(listener interface{}) {
+	c.listeners = append(c.listeners, listener)
+}
+
+// GotoNextField handles the Enter key. In ESM mode, Enter sends the keyer macro that fits the current state of
+// the QSO (see esm.go). Otherwise, Enter sends the exchange (F2) and moves to the next field, or sends TU (F3) and
+// logs the QSO from the exchange field.
 func (c *Controller) GotoNextField() core.EntryField {
+	if c.esm && !c.editing {
+		c.enterSendsMessage()
+		return c.activeField
+	}
+
 	transitions := map[core.EntryField]core.EntryField{
 		core.CallsignField: core.TheirXchangeField,
 		core.MyReportField: core.CallsignField,
@@ -231,6 +419,14 @@ func (c *Controller) GotoNextField() core.EntryField {
 	return c.activeField
 }
 
+// lastExchangeField returns the field that completes the exchange with the other station.
+func (c *Controller) lastExchangeField() core.EntryField {
+	if c.enableTheirXchange {
+		return core.TheirXchangeField
+	}
+	return core.TheirReportField
+}
+
 func (c *Controller) TabNextField() core.EntryField {
 	switch c.activeField {
 	case core.CallsignField:
@@ -363,6 +559,9 @@ func (c *Controller) SetActiveField(field core.EntryField) {
 }
 
 func (c *Controller) Enter(text string) {
+	if c.keyer != nil {
+		c.keyer.StopAutoCQ()
+	}
 	switch c.activeField {
 	case core.CallsignField:
 		c.input.callsign = text
@@ -398,30 +597,78 @@ func (c *Controller) FButton(fkey int) {
 	}
 }
 
+// FButtonSlower sends the macro of the given function key at a lower speed.
+func (c *Controller) FButtonSlower(fkey int) {
+	if c.keyer == nil {
+		return
+	}
+	if c.editing == false {
+		c.keyer.SendSlower(fkey)
+	}
+}
+
+// ResendSlower sends the last message again at a lower speed.
+func (c *Controller) ResendSlower() {
+	if c.keyer == nil {
+		return
+	}
+	c.keyer.ResendSlower()
+}
+
 func (c *Controller) frequencySelected(frequency core.Frequency) {
 	log.Printf("Frequency selected: %s", frequency)
 	c.selectedFrequency = frequency
 	c.vfo.SetFrequency(frequency)
+	c.rememberFrequency()
 	c.input.callsign = ""
 	c.enterCallsign(c.input.callsign)
 	c.view.SetCallsign(c.input.callsign)
 	c.view.SetFrequency(frequency)
+	c.emitFrequencyChanged(frequency)
 }
 
+// SetFrequency, SetBand and SetMode are called by the radio clients from their own goroutines. The listeners are
+// always notified through the async runner.
 func (c *Controller) SetFrequency(frequency core.Frequency) {
 	if c.selectedFrequency == frequency {
 		return
 	}
 	c.selectedFrequency = frequency
+	c.rememberFrequency()
 	c.view.SetFrequency(c.selectedFrequency)
+	c.asyncRunner(func() {
+		c.emitFrequencyChanged(frequency)
+	})
+}
+
+// GotoNextSpot tunes to the next station from the hunting list and enters its callsign.
+func (c *Controller) GotoNextSpot() {
+	spot, ok := c.hunter.NextBest()
+	if !ok {
+		c.view.ShowMessage("no more stations to hunt on this band")
+		return
+	}
+	log.Printf("Next spot: %s on %s", spot.Callsign, spot.Frequency)
+
+	c.selectedFrequency = spot.Frequency
+	c.vfo.SetFrequency(spot.Frequency)
+	c.view.SetFrequency(spot.Frequency)
+	c.emitFrequencyChanged(spot.Frequency)
+
+	c.activeField = core.CallsignField
+	c.input.callsign = spot.Callsign.String()
+	c.view.SetCallsign(c.input.callsign)
+	c.view.SetActiveField(c.activeField)
+	c.enterCallsign(c.input.callsign)
 }
 
 func (c *Controller) bandSelected(s string) {
 	if band, err := parse.Band(s); err == nil {
 		log.Printf("Band selected: %v", band)
 		c.selectedBand = band
-		c.vfo.SetBand(band)
+		c.tuneToBand(band)
 		c.enterCallsign(c.input.callsign)
+		c.emitBandChanged(band)
 	}
 }
 
@@ -430,8 +677,12 @@ func (c *Controller) SetBand(band core.Band) {
 		return
 	}
 	c.selectedBand = band
+	c.rememberFrequency()
 	c.input.band = c.selectedBand.String()
 	c.view.SetBand(c.input.band)
+	c.asyncRunner(func() {
+		c.emitBandChanged(band)
+	})
 }
 
 func (c *Controller) modeSelected(s string) {
@@ -449,6 +700,7 @@ func (c *Controller) modeSelected(s string) {
 		}
 
 		c.enterCallsign(c.input.callsign)
+		c.emitModeChanged(mode)
 	}
 }
 
@@ -457,8 +709,60 @@ func (c *Controller) SetMode(mode core.Mode) {
 		return
 	}
 	c.selectedMode = mode
+	c.rememberFrequency()
 	c.input.mode = c.selectedMode.String()
 	c.view.SetMode(c.input.mode)
+	c.asyncRunner(func() {
+		c.emitModeChanged(mode)
+	})
+}
+
+// tuneToBand tunes the VFO to the frequency that was used last on the given band with the current mode and restores
+// the workmode that was used there. Without a last used frequency, the VFO decides where to go on the given band.
+func (c *Controller) tuneToBand(band core.Band) {
+	frequency, workmode, ok := c.bandMemory.Recall(band, c.selectedMode)
+	if !ok {
+		c.vfo.SetBand(band)
+		return
+	}
+	log.Printf("Last frequency on %s: %s", band, frequency)
+	c.SetWorkmode(workmode)
+	c.vfo.SetFrequency(frequency)
+	c.SetFrequency(frequency)
+}
+
+// rememberFrequency stores the selected frequency and the workmode for the selected band and mode. Frequencies
+// that are not within the selected band are ignored, they come from a band change that is still in progress.
+func (c *Controller) rememberFrequency() {
+	band, ok := c.bandplan[bandplan.BandName(c.selectedBand)]
+	if !ok || !band.Contains(hamradio.Frequency(c.selectedFrequency)) {
+		return
+	}
+	c.bandMemory.Remember(c.selectedBand, c.selectedMode, c.selectedFrequency, c.workmode)
+}
+
+func (c *Controller) emitFrequencyChanged(frequency core.Frequency) {
+	for _, listener := range c.listeners {
+		if frequencyChangedListener, ok := listener.(FrequencyChangedListener); ok {
+			frequencyChangedListener.FrequencyChanged(frequency)
+		}
+	}
+}
+
+func (c *Controller) emitBandChanged(band core.Band) {
+	for _, listener := range c.listeners {
+		if bandChangedListener, ok := listener.(BandChangedListener); ok {
+			bandChangedListener.BandChanged(band)
+		}
+	}
+}
+
+func (c *Controller) emitModeChanged(mode core.Mode) {
+	for _, listener := range c.listeners {
+		if modeChangedListener, ok := listener.(ModeChangedListener); ok {
+			modeChangedListener.ModeChanged(mode)
+		}
+	}
 }
 
 func (c *Controller) SendQuestion() {
@@ -475,6 +779,7 @@ func (c *Controller) SendQuestion() {
 }
 
 func (c *Controller) enterCallsign(s string) {
+	c.predictXchange(s)
 	if c.callinfo != nil {
 		c.callinfo.ShowInfo(c.input.callsign, c.selectedBand, c.selectedMode, c.input.theirXchange)
 	}
@@ -494,11 +799,61 @@ func (c *Controller) enterCallsign(s string) {
 }
 
 func (c *Controller) enterTheirXchange(s string) {
+	c.updateXchangeMismatch()
 	if c.callinfo != nil {
 		c.callinfo.ShowInfo(c.input.callsign, c.selectedBand, c.selectedMode, c.input.theirXchange)
 	}
 }
 
+// predictXchange pre-fills their exchange with the predicted exchange of the given callsign. An exchange that was
+// entered by the operator is kept.
+func (c *Controller) predictXchange(call string) {
+	if c.editing || !c.enableTheirXchange {
+		return
+	}
+	predicted := c.xchangePrediction(call)
+	if c.input.theirXchange == "" || c.input.theirXchange == c.predictedXchange {
+		if c.input.theirXchange != predicted {
+			c.input.theirXchange = predicted
+			c.view.SetTheirXchange(c.input.theirXchange)
+		}
+	}
+	c.predictedXchange = predicted
+	c.updateXchangeMismatch()
+}
+
+// xchangePrediction returns the exchange that is expected from the given callsign. The exchange from the latest
+// previous QSO with this callsign on any band comes first, then the prediction of the predictor.
+func (c *Controller) xchangePrediction(call string) string {
+	if parsedCall, err := callsign.Parse(call); err == nil {
+		qsos := c.qsoList.Find(parsedCall, core.NoBand, core.NoMode)
+		for i := len(qsos) - 1; i >= 0; i-- {
+			if c.editing && qsos[i].MyNumber == c.editQSO.MyNumber {
+				continue
+			}
+			if qsos[i].TheirXchange != "" {
+				return qsos[i].TheirXchange
+			}
+		}
+	}
+	predicted, _ := c.predictor.PredictXchange(call)
+	return predicted
+}
+
+// updateXchangeMismatch marks their exchange if it differs from the predicted exchange.
+func (c *Controller) updateXchangeMismatch() {
+	mismatch := c.predictedXchange != "" && c.input.theirXchange != "" && !sameXchange(c.predictedXchange, c.input.theirXchange)
+	if mismatch == c.xchangeMismatch {
+		return
+	}
+	c.xchangeMismatch = mismatch
+	c.view.SetXchangeMismatchMarker(mismatch)
+}
+
+func sameXchange(a, b string) bool {
+	return core.NormalizeXchange(a) == core.NormalizeXchange(b)
+}
+
 func (c *Controller) QSOSelected(qso core.QSO) {
 	if c.ignoreQSOSelection {
 		return
@@ -509,6 +864,8 @@ func (c *Controller) QSOSelected(qso core.QSO) {
 	c.editQSO = qso
 
 	c.showQSO(qso)
+	c.predictedXchange = c.xchangePrediction(c.input.callsign)
+	c.updateXchangeMismatch()
 	c.view.SetActiveField(core.CallsignField)
 	c.view.SetEditingMarker(true)
 	c.callinfo.ShowInfo(qso.Callsign.String(), qso.Band, qso.Mode, qso.TheirXchange)
@@ -535,6 +892,11 @@ func (c *Controller) Log() {
 	}
 
 	qso.Frequency = c.selectedFrequency
+	if c.editing {
+		qso.TXFrequency = c.editQSO.TXFrequency
+	} else if vfos := c.vfo.VFOs(); vfos.Split && vfos.RX() == c.selectedFrequency {
+		qso.TXFrequency = vfos.TX()
+	}
 
 	qso.Band, err = parse.Band(c.input.band)
 	if err != nil {
@@ -542,6 +904,12 @@ func (c *Controller) Log() {
 		return
 	}
 
+	err = c.checkFrequencies(qso)
+	if err != nil {
+		c.view.ShowMessage(err)
+		return
+	}
+
 	qso.Mode, err = parse.Mode(c.input.mode)
 	if err != nil {
 		c.view.ShowMessage(err)
@@ -574,6 +942,10 @@ func (c *Controller) Log() {
 		return
 	}
 	qso.MyNumber = core.QSONumber(myNumber)
+	if qso.MyNumber == 0 && !c.editing && c.logbook.NextNumber() == 0 {
+		c.showErrorOnField(errors.New("no QSO number left in the number range of this station"), core.MyNumberField)
+		return
+	}
 
 	qso.MyXchange = c.input.myXchange
 
@@ -581,6 +953,20 @@ func (c *Controller) Log() {
 	c.Clear()
 }
 
+// checkFrequencies verifies that the frequencies of the given QSO are within its band.
+func (c *Controller) checkFrequencies(qso core.QSO) error {
+	band, ok := c.bandplan[bandplan.BandName(qso.Band)]
+	if !ok {
+		return nil
+	}
+	for _, frequency := range []core.Frequency{qso.Frequency, qso.TXFrequency} {
+		if frequency != 0 && !band.Contains(hamradio.Frequency(frequency)) {
+			return fmt.Errorf("%s is outside of the %s band", frequency, qso.Band)
+		}
+	}
+	return nil
+}
+
 func parseKilohertz(s string) (core.Frequency, bool) {
 	kHz, err := strconv.Atoi(s)
 	if err != nil {
@@ -611,6 +997,7 @@ func (c *Controller) Clear() {
 	}
 	c.input.theirNumber = ""
 	c.input.theirXchange = ""
+	c.predictedXchange = ""
 	if c.selectedBand != core.NoBand {
 		c.input.band = c.selectedBand.String()
 	}
@@ -624,6 +1011,7 @@ func (c *Controller) Clear() {
 	c.view.SetActiveField(c.activeField)
 	c.view.SetDuplicateMarker(false)
 	c.view.SetEditingMarker(false)
+	c.updateXchangeMismatch()
 	c.view.ClearMessage()
 	c.selectLastQSO()
 	if c.callinfo != nil {
@@ -666,12 +1054,16 @@ func (c *Controller) CurrentValues() core.KeyerValues {
 	values.MyNumber = core.QSONumber(myNumber)
 	values.MyXchange = c.input.myXchange
 	values.TheirCall = c.input.callsign
+	values.TheirXchange = c.input.theirXchange
+	values.Band = c.selectedBand
+	values.Mode = c.selectedMode
 
 	return values
 }
 
 func (c *Controller) StationChanged(station core.Station) {
 	c.stationCallsign = station.Callsign.String()
+	c.bandplan = bandplans.ForRegion(station.Region)
 }
 
 func (c *Controller) ContestChanged(contest core.Contest) {
@@ -697,6 +1089,7 @@ func (n *nullView) EnableExchangeFields(bool, bool) {}
 func (n *nullView) SetActiveField(core.EntryField)  {}
 func (n *nullView) SetDuplicateMarker(bool)         {}
 func (n *nullView) SetEditingMarker(bool)           {}
+func (n *nullView) SetXchangeMismatchMarker(bool)   {}
 func (n *nullView) ShowMessage(...interface{})      {}
 func (n *nullView) ClearMessage()                   {}
 func (n *nullView) ShowKeyerSpeed(int)              {}
@@ -706,9 +1099,25 @@ type nullVFO struct{}
 
 func (n *nullVFO) Active() bool                { return false }
 func (n *nullVFO) SetFrequency(core.Frequency) {}
+func (n *nullVFO) VFOs() core.VFOs             { return core.VFOs{} }
 func (n *nullVFO) SetBand(core.Band)           {}
 func (n *nullVFO) SetMode(core.Mode)           {}
 
+type nullHunter struct{}
+
+func (n *nullHunter) NextBest() (core.AnnotatedSpot, bool) { return core.AnnotatedSpot{}, false }
+
+type nullXchangePredictor struct{}
+
+func (n *nullXchangePredictor) PredictXchange(string) (string, bool) { return "", false }
+
+type nullBandMemory struct{}
+
+func (n *nullBandMemory) Remember(core.Band, core.Mode, core.Frequency, core.Workmode) {}
+func (n *nullBandMemory) Recall(core.Band, core.Mode) (core.Frequency, core.Workmode, bool) {
+	return 0, core.SearchPounce, false
+}
+
 type nullLogbook struct{}
 
 func (n *nullLogbook) NextNumber() core.QSONumber { return 0 }
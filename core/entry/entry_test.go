@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandmemory"
 	"github.com/ftl/hellocontest/core/clock"
 	"github.com/ftl/hellocontest/core/mocked"
 )
@@ -108,9 +109,99 @@ func TestEntryController_GotoNextField(t *testing.T) {
 	view.AssertExpectations(t)
 }
 
+func TestEntryController_EnterSendsMessage(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		esm           bool
+		workmode      core.Workmode
+		active        core.EntryField
+		callsign      string
+		theirReport   string
+		theirXchange  string
+		expectedSend  int
+		expectedQuery string
+		expectedLog   bool
+		expectedNext  core.EntryField
+	}{
+		{desc: "run, empty callsign sends CQ", esm: true, workmode: core.Run, active: core.CallsignField, theirReport: "599", expectedSend: 0, expectedNext: core.CallsignField},
+		{desc: "run, callsign sends exchange", esm: true, workmode: core.Run, active: core.CallsignField, callsign: "DL1ABC", theirReport: "599", expectedSend: 1, expectedNext: core.TheirXchangeField},
+		{desc: "run, invalid callsign asks for repeat", esm: true, workmode: core.Run, active: core.CallsignField, callsign: "DL", theirReport: "599", expectedSend: -1, expectedQuery: "DL", expectedNext: core.CallsignField},
+		{desc: "run, exchange sends TU and logs", esm: true, workmode: core.Run, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", theirXchange: "012", expectedSend: 2, expectedLog: true, expectedNext: core.CallsignField},
+		{desc: "run, missing exchange asks for repeat", esm: true, workmode: core.Run, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", expectedSend: -1, expectedQuery: "nr", expectedNext: core.TheirXchangeField},
+		{desc: "run, missing report asks for repeat", esm: true, workmode: core.Run, active: core.TheirXchangeField, callsign: "DL1ABC", theirXchange: "012", expectedSend: -1, expectedQuery: "nr", expectedNext: core.TheirReportField},
+		{desc: "run, missing callsign in exchange", esm: true, workmode: core.Run, active: core.TheirXchangeField, theirReport: "599", theirXchange: "012", expectedSend: -1, expectedNext: core.CallsignField},
+		{desc: "s&p, empty callsign sends nothing", esm: true, workmode: core.SearchPounce, active: core.CallsignField, theirReport: "599", expectedSend: -1, expectedNext: core.CallsignField},
+		{desc: "s&p, callsign sends my call", esm: true, workmode: core.SearchPounce, active: core.CallsignField, callsign: "DL1ABC", theirReport: "599", expectedSend: 0, expectedNext: core.TheirXchangeField},
+		{desc: "s&p, exchange sends exchange and logs", esm: true, workmode: core.SearchPounce, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", theirXchange: "012", expectedSend: 1, expectedLog: true, expectedNext: core.CallsignField},
+		{desc: "s&p, missing exchange asks for repeat", esm: true, workmode: core.SearchPounce, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", expectedSend: -1, expectedQuery: "nr", expectedNext: core.TheirXchangeField},
+		{desc: "other field goes back to callsign", esm: true, workmode: core.Run, active: core.MyReportField, callsign: "DL1ABC", theirReport: "599", expectedSend: -1, expectedNext: core.CallsignField},
+		{desc: "without ESM, callsign sends exchange", esm: false, workmode: core.Run, active: core.CallsignField, callsign: "DL1ABC", theirReport: "599", expectedSend: 1, expectedNext: core.TheirXchangeField},
+		{desc: "without ESM, exchange sends TU and logs", esm: false, workmode: core.Run, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", theirXchange: "012", expectedSend: 2, expectedLog: true, expectedNext: core.CallsignField},
+		{desc: "without ESM, empty exchange sends exchange", esm: false, workmode: core.Run, active: core.TheirXchangeField, callsign: "DL1ABC", theirReport: "599", expectedSend: 1, expectedNext: core.TheirXchangeField},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, log, _, _, controller, config := setupEntryTest()
+			config.requireTheirXchange = true
+			controller.ContestChanged(config.Contest())
+			keyer := new(mocked.Keyer)
+			keyer.On("WorkmodeChanged", mock.Anything).Maybe()
+			controller.SetKeyer(keyer)
+			controller.SetESM(tc.esm)
+			controller.SetWorkmode(tc.workmode)
+			controller.input.callsign = tc.callsign
+			controller.input.theirReport = tc.theirReport
+			controller.input.theirXchange = tc.theirXchange
+			controller.SetActiveField(tc.active)
+
+			if tc.expectedSend >= 0 {
+				keyer.On("Send", tc.expectedSend).Once()
+			}
+			if tc.expectedQuery != "" {
+				keyer.On("SendQuestion", tc.expectedQuery).Once()
+			}
+			log.Activate()
+			if tc.expectedLog {
+				log.On("Log", mock.Anything).Once()
+				log.On("NextNumber").Return(core.QSONumber(2))
+			}
+
+			actual := controller.GotoNextField()
+
+			assert.Equal(t, tc.expectedNext, actual)
+			keyer.AssertExpectations(t)
+			log.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEntryController_AutoCQ(t *testing.T) {
+	_, _, _, view, controller, _ := setupEntryTest()
+	keyer := new(mocked.Keyer)
+	keyer.On("WorkmodeChanged", core.Run).Once()
+	keyer.On("AutoCQ").Once()
+	keyer.On("StopAutoCQ").Once()
+	controller.SetKeyer(keyer)
+	controller.SetWorkmode(core.Run)
+
+	controller.AutoCQ()
+
+	view.Activate()
+	view.On("ShowWorkmode", "Run CQ 4s").Once()
+	view.On("ShowWorkmode", "Run").Once()
+	view.On("ClearMessage").Maybe()
+	controller.AutoCQChanged(true, 3600*time.Millisecond)
+	controller.Enter("D")
+	controller.AutoCQChanged(false, 0)
+
+	keyer.AssertExpectations(t)
+	view.AssertExpectations(t)
+}
+
 func TestEntryController_EnterNewCallsign(t *testing.T) {
 	_, _, qsoList, view, controller, _ := setupEntryTest()
 	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
 	qsoList.On("FindDuplicateQSOs", mock.Anything, mock.Anything, mock.Anything).Return([]core.QSO{})
 
 	view.Activate()
@@ -141,6 +232,7 @@ func TestEntryController_EnterDuplicateCallsign(t *testing.T) {
 	}
 
 	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
 	qsoList.On("FindDuplicateQSOs", dl1abc, core.Band160m, core.ModeCW).Return([]core.QSO{qso}).Twice()
 
 	view.Activate()
@@ -156,6 +248,100 @@ func TestEntryController_EnterDuplicateCallsign(t *testing.T) {
 	view.AssertExpectations(t)
 }
 
+func TestEntryController_PredictXchange(t *testing.T) {
+	_, _, qsoList, view, controller, _ := setupEntryTest()
+	controller.SetXchangePredictor(testXchangePredictor{"DL1ABC": "Hans", "DL2ABC": "Otto"})
+	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
+	qsoList.On("FindDuplicateQSOs", mock.Anything, mock.Anything, mock.Anything).Return([]core.QSO{})
+
+	view.Activate()
+	view.On("ClearMessage").Maybe()
+	view.On("SetTheirXchange", "Hans").Once()
+	view.On("SetTheirXchange", "").Once()
+	view.On("SetTheirXchange", "Otto").Once()
+	view.On("SetXchangeMismatchMarker", true).Once()
+	view.On("SetXchangeMismatchMarker", false).Once()
+
+	controller.Enter("DL1ABC")
+	assert.Equal(t, "Hans", controller.input.theirXchange)
+	controller.Enter("DL1AB")
+	assert.Equal(t, "", controller.input.theirXchange)
+	controller.Enter("DL2ABC")
+	assert.Equal(t, "Otto", controller.input.theirXchange)
+
+	controller.SetActiveField(core.TheirXchangeField)
+	controller.Enter("Fritz")
+	controller.SetActiveField(core.CallsignField)
+	controller.Enter("DL1ABC")
+	assert.Equal(t, "Fritz", controller.input.theirXchange, "entered exchange is kept")
+	controller.SetActiveField(core.TheirXchangeField)
+	controller.Enter("hans")
+
+	view.AssertExpectations(t)
+}
+
+func TestEntryController_PredictXchangeFromPreviousQSO(t *testing.T) {
+	_, _, qsoList, view, controller, _ := setupEntryTest()
+	controller.SetXchangePredictor(testXchangePredictor{"DL1ABC": "B01"})
+	dl1abc := callsign.MustParse("DL1ABC")
+	qsoList.Activate()
+	qsoList.On("FindDuplicateQSOs", mock.Anything, mock.Anything, mock.Anything).Return([]core.QSO{})
+	qsoList.On("Find", dl1abc, core.NoBand, core.NoMode).Return([]core.QSO{
+		{Callsign: dl1abc, Band: core.Band80m, TheirXchange: "B11"},
+		{Callsign: dl1abc, Band: core.Band40m, TheirXchange: "B12"},
+		{Callsign: dl1abc, Band: core.Band20m},
+	})
+
+	view.Activate()
+	view.On("ClearMessage").Maybe()
+	view.On("SetTheirXchange", "B12").Once()
+	view.On("SetXchangeMismatchMarker", true).Once()
+
+	controller.Enter("DL1ABC")
+	controller.SetActiveField(core.TheirXchangeField)
+	controller.Enter("B13")
+
+	view.AssertExpectations(t)
+}
+
+func TestEntryController_SwitchViewKeepsInput(t *testing.T) {
+	_, _, qsoList, view, controller, _ := setupEntryTest()
+	controller.SwitchView(nil)
+	controller.Enter("DL1ABC")
+
+	dl1abc, _ := callsign.Parse("DL1ABC")
+	qso := core.QSO{
+		Callsign: dl1abc,
+		Band:     core.Band160m,
+		Mode:     core.ModeCW,
+		MyNumber: 1,
+	}
+	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
+	qsoList.On("FindDuplicateQSOs", dl1abc, core.Band160m, core.ModeCW).Return([]core.QSO{qso}).Twice()
+
+	view.Activate()
+	view.On("SetCallsign", "DL1ABC").Once()
+	view.On("SetTheirReport", "599").Once()
+	view.On("SetTheirXchange", "").Once()
+	view.On("SetBand", "160m").Once()
+	view.On("SetMode", "CW").Once()
+	view.On("EnableExchangeFields", true, true).Once()
+	view.On("SetFrequency", mock.Anything).Once()
+	view.On("SetActiveField", core.CallsignField).Once()
+	view.On("SetEditingMarker", false).Once()
+	view.On("ShowWorkmode", "SP").Once()
+	view.On("ClearMessage").Once()
+	view.On("ShowMessage", mock.Anything).Once()
+	view.On("SetDuplicateMarker", true).Once()
+
+	controller.SwitchView(view)
+
+	qsoList.AssertExpectations(t)
+	view.AssertExpectations(t)
+}
+
 func TestEntryController_EnterFrequency(t *testing.T) {
 	_, _, _, view, controller, _ := setupEntryTest()
 
@@ -191,6 +377,7 @@ func TestEntryController_LogNewQSO(t *testing.T) {
 	log.On("NextNumber").Return(core.QSONumber(1))
 	log.On("Log", qso).Once()
 	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
 	qsoList.On("FindDuplicateQSOs", dl1abc, mock.Anything, mock.Anything).Return([]core.QSO{})
 	qsoList.On("SelectLastQSO").Twice()
 
@@ -218,6 +405,141 @@ func TestEntryController_LogNewQSO(t *testing.T) {
 	assert.Equal(t, core.CallsignField, controller.activeField)
 }
 
+func TestEntryController_LogSplitQSO(t *testing.T) {
+	clock, log, qsoList, _, controller, _ := setupEntryTest()
+	controller.SetVFO(&testVFO{vfos: core.VFOs{A: 7155000, B: 7055000, Split: true}})
+	controller.SetFrequency(7155000)
+	controller.SetBand(core.Band40m)
+
+	dl1abc, _ := callsign.Parse("DL1ABC")
+	qso := core.QSO{
+		Callsign:     dl1abc,
+		Time:         clock.Now(),
+		Frequency:    7155000,
+		TXFrequency:  7055000,
+		Band:         core.Band40m,
+		Mode:         core.ModeCW,
+		TheirReport:  core.RST("599"),
+		TheirXchange: "012",
+		MyReport:     core.RST("599"),
+		MyNumber:     1,
+	}
+
+	log.Activate()
+	log.On("NextNumber").Return(core.QSONumber(1))
+	log.On("Log", qso).Once()
+	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
+	qsoList.On("FindDuplicateQSOs", dl1abc, mock.Anything, mock.Anything).Return([]core.QSO{})
+	qsoList.On("SelectLastQSO").Twice()
+
+	controller.Clear()
+	controller.Enter("DL1ABC")
+	controller.SetActiveField(core.TheirXchangeField)
+	controller.Enter("012")
+
+	controller.Log()
+
+	log.AssertExpectations(t)
+}
+
+func TestEntryController_LogQSOOutsideOfBand(t *testing.T) {
+	_, log, qsoList, view, controller, _ := setupEntryTest()
+	controller.SetFrequency(7250000)
+	controller.SetBand(core.Band40m)
+	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
+	qsoList.On("FindDuplicateQSOs", mock.Anything, mock.Anything, mock.Anything).Return([]core.QSO{})
+	log.Activate()
+
+	view.Activate()
+	view.On("ClearMessage").Once()
+	view.On("ShowMessage", mock.Anything).Once()
+
+	controller.Enter("DL1ABC")
+	controller.Log()
+
+	view.AssertExpectations(t)
+	log.AssertNotCalled(t, "Log", mock.Anything)
+}
+
+func TestEntryController_LogQSOWithinBandOfRegion(t *testing.T) {
+	_, log, qsoList, _, controller, _ := setupEntryTest()
+	controller.StationChanged(core.Station{Region: core.IARURegion2})
+	controller.SetFrequency(7250000)
+	controller.SetBand(core.Band40m)
+	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
+	qsoList.On("FindDuplicateQSOs", mock.Anything, mock.Anything, mock.Anything).Return([]core.QSO{})
+	qsoList.On("SelectLastQSO").Once()
+	log.Activate()
+	log.On("NextNumber").Return(core.QSONumber(1))
+	log.On("Log", mock.Anything).Once()
+
+	controller.Enter("DL1ABC")
+	controller.Log()
+
+	log.AssertExpectations(t)
+}
+
+func TestEntryController_RadioChangesAreEmittedAsync(t *testing.T) {
+	settings := &testSettings{myCall: "DL0ABC"}
+	var pending []func()
+	controller := NewController(settings, clock.New(), new(mocked.QSOList), func(f func()) {
+		pending = append(pending, f)
+	})
+	var frequency core.Frequency
+	var band core.Band
+	var mode core.Mode
+	controller.Notify(FrequencyChangedListenerFunc(func(f core.Frequency) { frequency = f }))
+	controller.Notify(BandChangedListenerFunc(func(b core.Band) { band = b }))
+	controller.Notify(ModeChangedListenerFunc(func(m core.Mode) { mode = m }))
+
+	controller.SetFrequency(7020000)
+	controller.SetBand(core.Band40m)
+	controller.SetMode(core.ModeCW)
+
+	assert.Equal(t, core.Frequency(0), frequency)
+	assert.Equal(t, core.NoBand, band)
+	assert.Equal(t, core.NoMode, mode)
+
+	for _, f := range pending {
+		f()
+	}
+	assert.Equal(t, core.Frequency(7020000), frequency)
+	assert.Equal(t, core.Band40m, band)
+	assert.Equal(t, core.ModeCW, mode)
+}
+
+func TestEntryController_RestoreLastFrequencyOnBandChange(t *testing.T) {
+	_, _, _, _, controller, _ := setupEntryTest()
+	vfo := new(testVFO)
+	controller.SetVFO(vfo)
+	controller.SetBandMemory(bandmemory.New())
+
+	controller.SetBand(core.Band20m)
+	controller.SetFrequency(14025000)
+	controller.ToggleWorkmode()
+
+	controller.SetActiveField(core.BandField)
+	controller.Enter("40m")
+	assert.Equal(t, core.Band40m, vfo.band, "no frequency on 40m yet, let the VFO decide")
+	assert.Equal(t, core.Run, controller.workmode)
+
+	controller.SetFrequency(14030000)
+	controller.SetFrequency(7010000)
+	controller.ToggleWorkmode()
+
+	controller.Enter("20m")
+	assert.Equal(t, core.Frequency(14025000), vfo.frequency)
+	assert.Equal(t, core.Frequency(14025000), controller.selectedFrequency)
+	assert.Equal(t, core.Run, controller.workmode)
+
+	controller.Enter("40m")
+	assert.Equal(t, core.Frequency(7010000), vfo.frequency)
+	assert.Equal(t, core.SearchPounce, controller.workmode)
+}
+
 func TestEntryController_LogWithWrongCallsign(t *testing.T) {
 	_, log, _, view, controller, _ := setupEntryTest()
 	log.Activate()
@@ -234,6 +556,26 @@ func TestEntryController_LogWithWrongCallsign(t *testing.T) {
 	assert.Equal(t, core.CallsignField, controller.activeField)
 }
 
+func TestEntryController_LogWithoutNumberLeft(t *testing.T) {
+	_, log, _, view, controller, _ := setupEntryTest()
+	controller.SetFrequency(7010000)
+	controller.SetBand(core.Band40m)
+	controller.SetMode(core.ModeCW)
+	log.Activate()
+	log.On("NextNumber").Return(core.QSONumber(0))
+	controller.Enter("DL1ABC")
+
+	view.Activate()
+	view.On("SetActiveField", core.MyNumberField).Once()
+	view.On("ShowMessage", mock.Anything).Once()
+
+	controller.Log()
+
+	view.AssertExpectations(t)
+	log.AssertNotCalled(t, "Log", mock.Anything)
+	assert.Equal(t, core.MyNumberField, controller.activeField)
+}
+
 func TestEntryController_LogWithInvalidTheirReport(t *testing.T) {
 	_, log, _, view, controller, _ := setupEntryTest()
 
@@ -313,6 +655,7 @@ func TestEntryController_LogWithInvalidMyReport(t *testing.T) {
 func TestEntryController_EnterCallsignCheckForDuplicateAndShowMessage(t *testing.T) {
 	clock, _, qsoList, view, controller, _ := setupEntryTest()
 	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
 	view.Activate()
 
 	dl1ab, _ := callsign.Parse("DL1AB")
@@ -367,6 +710,7 @@ func TestEntryController_LogDuplicateQSO(t *testing.T) {
 	log.On("NextNumber").Return(core.QSONumber(2))
 	log.On("Log", dupe).Once()
 	qsoList.Activate()
+	qsoList.On("Find", mock.Anything, core.NoBand, core.NoMode).Return([]core.QSO{}).Maybe()
 	qsoList.On("FindDuplicateQSOs", dl1abc, mock.Anything, mock.Anything).Return([]core.QSO{qso})
 	qsoList.On("SelectLastQSO").Twice()
 
@@ -539,3 +883,26 @@ func (s *testSettings) Contest() core.Contest {
 }
 
 func testIgnoreAsync(f func()) {}
+
+type testVFO struct {
+	vfos      core.VFOs
+	frequency core.Frequency
+	band      core.Band
+}
+
+func (v *testVFO) Active() bool    { return true }
+func (v *testVFO) VFOs() core.VFOs { return v.vfos }
+func (v *testVFO) SetFrequency(frequency core.Frequency) {
+	v.frequency = frequency
+}
+func (v *testVFO) SetBand(band core.Band) {
+	v.band = band
+}
+func (v *testVFO) SetMode(core.Mode) {}
+
+type testXchangePredictor map[string]string
+
+func (p testXchangePredictor) PredictXchange(call string) (string, bool) {
+	result, ok := p[call]
+	return result, ok
+}
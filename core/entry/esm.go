@@ -0,0 +1,111 @@
+package entry
+
+import (
+	"github.com/ftl/hamradio/callsign"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// In ESM (Enter Sends Message) mode, the Enter key sends the keyer macro that fits the current state of the QSO,
+// depending on the workmode, the active field and the input:
+//
+//   Run: empty callsign -> CQ (F1), callsign -> exchange (F2), complete exchange -> TU (F3) and log
+//   S&P: callsign -> my call (F1), complete exchange -> exchange (F2) and log
+//
+// If the callsign is invalid or the exchange is incomplete, Enter asks the other station to repeat the missing data.
+
+// the indices of the keyer macros that are used in ESM mode
+const (
+	cqMacro         = 0
+	myCallMacro     = 0
+	runXchangeMacro = 1
+	spXchangeMacro  = 1
+	tuMacro         = 2
+	xchangeQuestion = "nr"
+)
+
+func (c *Controller) enterSendsMessage() {
+	switch c.activeField {
+	case core.CallsignField:
+		c.esmCallsign()
+	case core.TheirReportField, core.TheirXchangeField:
+		c.esmXchange()
+	default:
+		c.activeField = core.CallsignField
+		c.view.SetActiveField(c.activeField)
+	}
+}
+
+// esmCallsign handles Enter in the callsign field.
+func (c *Controller) esmCallsign() {
+	if c.input.callsign == "" {
+		if c.workmode == core.Run {
+			c.FButton(cqMacro)
+		}
+		return
+	}
+	if f, ok := parseKilohertz(c.input.callsign); ok {
+		c.frequencySelected(f)
+		return
+	}
+	if _, err := callsign.Parse(c.input.callsign); err != nil {
+		c.askForRepeat(c.input.callsign)
+		return
+	}
+
+	c.leaveCallsignField()
+	if c.workmode == core.Run {
+		c.FButton(runXchangeMacro)
+	} else {
+		c.FButton(myCallMacro)
+	}
+	c.activeField = c.lastExchangeField()
+	c.view.SetActiveField(c.activeField)
+}
+
+// esmXchange handles Enter in the exchange fields.
+func (c *Controller) esmXchange() {
+	if _, err := callsign.Parse(c.input.callsign); err != nil {
+		c.activeField = core.CallsignField
+		c.view.SetActiveField(c.activeField)
+		if c.input.callsign != "" {
+			c.askForRepeat(c.input.callsign)
+		}
+		return
+	}
+	if field, missing := c.missingXchange(); missing {
+		c.activeField = field
+		c.view.SetActiveField(c.activeField)
+		c.askForRepeat(xchangeQuestion)
+		return
+	}
+
+	if c.workmode == core.Run {
+		c.FButton(tuMacro)
+	} else {
+		c.FButton(spXchangeMacro)
+	}
+	if c.input.callsign == "" {
+		// the macro already logged the QSO
+		return
+	}
+	c.Log()
+}
+
+// missingXchange returns the first exchange field that does not contain valid data yet.
+func (c *Controller) missingXchange() (core.EntryField, bool) {
+	if c.input.theirReport == "" {
+		return core.TheirReportField, true
+	}
+	if c.enableTheirXchange && c.requireTheirXchange && c.input.theirXchange == "" {
+		return core.TheirXchangeField, true
+	}
+	return c.activeField, false
+}
+
+func (c *Controller) askForRepeat(q string) {
+	if c.keyer == nil || c.editing {
+		return
+	}
+	c.keyer.SendQuestion(q)
+}
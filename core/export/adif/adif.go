@@ -40,26 +40,32 @@ var qrg = map[core.Band]string{
 
 func record(w io.Writer, qso core.QSO) error {
 	var frequency string
-	if qso.Frequency == 0 {
+	if qso.TransmitFrequency() == 0 {
 		frequency = qrg[qso.Band]
 	} else {
-		frequency = fmt.Sprintf("%5.3f", qso.Frequency/1000000.0)
+		frequency = fmt.Sprintf("%5.3f", qso.TransmitFrequency()/1000000.0)
 	}
-	fields := []struct {
+	type field struct {
 		name string
 		data string
-	}{
+	}
+	fields := []field{
 		{"QSO_DATE", qso.Time.In(time.UTC).Format("20060102")},
 		{"TIME_ON", qso.Time.In(time.UTC).Format("1504")},
 		{"TIME_OFF", qso.Time.In(time.UTC).Format("1504")},
 		{"CALL", qso.Callsign.String()},
 		{"FREQ", frequency},
+	}
+	if qso.Split() {
+		fields = append(fields, field{"FREQ_RX", fmt.Sprintf("%5.3f", qso.Frequency/1000000.0)})
+	}
+	fields = append(fields, []field{
 		{"BAND", qso.Band.String()},
 		{"MODE", qso.Mode.String()},
 		{"RST_SENT", qso.MyReport.String()},
 		{"RST_RCVD", qso.TheirReport.String()},
 		{"COMMENT", fmt.Sprintf("%03d %s %03d %s", qso.MyNumber, qso.MyXchange, qso.TheirNumber, qso.TheirXchange)},
-	}
+	}...)
 	for _, field := range fields {
 		err := data(w, field.name, "", field.data)
 		if err != nil {
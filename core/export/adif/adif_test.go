@@ -57,6 +57,24 @@ func TestRecord(t *testing.T) {
 			},
 			expected: "<QSO_DATE:8>20090530<TIME_ON:4>0002<TIME_OFF:4>0002<CALL:4>S50A<FREQ:5>3.550<BAND:3>80m<MODE:4>RTTY<RST_SENT:3>599<RST_RCVD:3>589<COMMENT:15>001 ABC 004 DEF<EOR>\n",
 		},
+		{
+			desc: "40m CW split",
+			qso: core.QSO{
+				Callsign:     theirCall,
+				Time:         time.Date(2009, time.May, 30, 0, 2, 0, 0, time.UTC),
+				Frequency:    7155000,
+				TXFrequency:  7055000,
+				Band:         core.Band40m,
+				Mode:         core.ModeCW,
+				MyReport:     core.RST("599"),
+				MyNumber:     core.QSONumber(1),
+				MyXchange:    "ABC",
+				TheirReport:  core.RST("589"),
+				TheirNumber:  core.QSONumber(4),
+				TheirXchange: "DEF",
+			},
+			expected: "<QSO_DATE:8>20090530<TIME_ON:4>0002<TIME_OFF:4>0002<CALL:4>S50A<FREQ:5>7.055<FREQ_RX:5>7.155<BAND:3>40m<MODE:2>CW<RST_SENT:3>599<RST_RCVD:3>589<COMMENT:15>001 ABC 004 DEF<EOR>\n",
+		},
 		{
 			desc: "40m CW",
 			qso: core.QSO{
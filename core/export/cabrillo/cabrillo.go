@@ -23,11 +23,11 @@ func Export(w io.Writer, t *template.Template, settings core.Settings, claimedSc
 		fmt.Sprintf("GRID-LOCATOR: %s", settings.Station().Locator),
 		fmt.Sprintf("CLAIMED-SCORE: %d", claimedScore),
 		"SPECIFIC:",
-		"CATEGORY-ASSISTED: (ASSISTED|NON-ASSISTED)",
-		"CATEGORY-BAND: ALL",
-		"CATEGORY-MODE: (CW|DIGI|FM|RTTY|SSB|MIXED)",
-		"CATEGORY-OPERATOR: (SINGLE-OP|MULTI-OP|CHECKLOG)",
-		"CATEGORY-POWER: (HIGH|LOW|QRP)",
+		categoryLine("ASSISTED", settings.Contest().CategoryAssisted, "(ASSISTED|NON-ASSISTED)"),
+		categoryLine("BAND", settings.Contest().CategoryBand, "ALL"),
+		categoryLine("MODE", settings.Contest().CategoryMode, "(CW|DIGI|FM|RTTY|SSB|MIXED)"),
+		categoryLine("OPERATOR", settings.Contest().CategoryOperator, "(SINGLE-OP|MULTI-OP|CHECKLOG)"),
+		categoryLine("POWER", settings.Contest().CategoryPower, "(HIGH|LOW|QRP)"),
 		"CLUB:",
 		"NAME:",
 		"EMAIL:",
@@ -57,6 +57,14 @@ func Export(w io.Writer, t *template.Template, settings core.Settings, claimedSc
 	return nil
 }
 
+// categoryLine returns the header line for the given category. If the category is not set, the hint is used as value.
+func categoryLine(category, value, hint string) string {
+	if value == "" {
+		value = hint
+	}
+	return fmt.Sprintf("CATEGORY-%s: %s", category, value)
+}
+
 var qrg = map[core.Band]string{
 	core.NoBand:   "",
 	core.Band160m: "1800",
@@ -82,10 +90,10 @@ var mode = map[core.Mode]string{
 
 func writeQSO(w io.Writer, t *template.Template, mycall callsign.Callsign, qso core.QSO) error {
 	var frequency string
-	if qso.Frequency == 0 {
+	if qso.TransmitFrequency() == 0 {
 		frequency = qrg[qso.Band]
 	} else {
-		frequency = fmt.Sprintf("%5.0f", qso.Frequency/1000.0)
+		frequency = fmt.Sprintf("%5.0f", qso.TransmitFrequency()/1000.0)
 	}
 	fillins := map[string]string{
 		"QRG":          frequency,
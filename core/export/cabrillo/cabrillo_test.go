@@ -54,6 +54,24 @@ func TestQsoLine(t *testing.T) {
 			},
 			expected: "QSO: 14000 PH 2009-05-30 0002 AA1ZZZ 59 001 XXX S50A 58 004 YYY\n",
 		},
+		{
+			desc: "40m CW split",
+			qso: core.QSO{
+				Callsign:     theirCall,
+				Time:         time.Date(2009, time.May, 30, 0, 2, 0, 0, time.UTC),
+				Frequency:    7155000,
+				TXFrequency:  7055000,
+				Band:         core.Band40m,
+				Mode:         core.ModeCW,
+				MyReport:     core.RST("599"),
+				MyNumber:     core.QSONumber(1),
+				MyXchange:    "ABC",
+				TheirReport:  core.RST("589"),
+				TheirNumber:  core.QSONumber(4),
+				TheirXchange: "DEF",
+			},
+			expected: "QSO:  7055 CW 2009-05-30 0002 AA1ZZZ 599 001 ABC S50A 589 004 DEF\n",
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
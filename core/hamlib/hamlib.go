@@ -3,12 +3,16 @@ package hamlib
 import (
 	"context"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ftl/hamradio/bandplan"
 	"github.com/ftl/rigproxy/pkg/client"
+	"github.com/ftl/rigproxy/pkg/protocol"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
 )
 
 func New(address string) *Client {
@@ -25,7 +29,9 @@ func New(address string) *Client {
 }
 
 type Client struct {
-	conn *client.Conn
+	connLock  sync.RWMutex
+	conn      *client.Conn
+	connected bool
 
 	listeners []interface{}
 
@@ -34,15 +40,19 @@ type Client struct {
 	pollingTimeout  time.Duration
 	retryInterval   time.Duration
 	requestTimeout  time.Duration
-	connected       bool
-	closed          chan struct{}
 	done            chan struct{}
+	doneOnce        sync.Once
 
-	bandplan   bandplan.Bandplan
-	controller VFOController
+	bandplanLock sync.RWMutex
+	bandplan     bandplan.Bandplan
+	controller   VFOController
 
-	incoming vfoSettings
-	outgoing vfoSettings
+	incomingLock sync.Mutex
+	incoming     vfoSettings
+	outgoing     vfoSettings
+
+	vfosLock sync.RWMutex
+	vfos     core.VFOs
 }
 
 type VFOController interface {
@@ -96,52 +106,119 @@ func (c *Client) Connect() error {
 }
 
 func (c *Client) connect(whenClosed func()) error {
-	var err error
-
-	c.conn, err = client.Open(c.address)
+	conn, err := client.Open(c.address)
 	if err != nil {
 		return err
 	}
 
-	c.closed = make(chan struct{})
+	c.connLock.Lock()
+	c.conn = conn
 	c.connected = true
-	c.emitStatusChanged(c.connected)
+	c.connLock.Unlock()
+	c.emitStatusChanged(true)
 
-	c.conn.StartPolling(c.pollingInterval, c.pollingTimeout,
+	conn.StartPolling(c.pollingInterval, c.pollingTimeout,
 		client.PollCommand(client.OnFrequency(c.setIncomingFrequency)),
 		client.PollCommand(client.OnModeAndPassband(c.setIncomingModeAndPassband)),
+		client.PollCommandFunc(c.setIncomingSplit, "get_split_vfo"),
+		client.PollCommandFunc(c.setIncomingSplitFrequency, "get_split_freq"),
 	)
 
-	c.conn.WhenClosed(func() {
+	conn.WhenClosed(func() {
+		c.connLock.Lock()
 		c.connected = false
-		c.emitStatusChanged(c.connected)
+		c.connLock.Unlock()
+		c.emitStatusChanged(false)
 
 		if whenClosed != nil {
 			whenClosed()
 		}
-
-		close(c.closed)
 	})
 
 	return nil
 }
 
+// Disconnect closes the connection to the rigctld server. A client that is kept open does not reconnect afterwards.
 func (c *Client) Disconnect() {
-	c.conn.Close()
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+
+	conn := c.currentConn()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// currentConn returns the current connection to the rigctld server or nil, if the client is not connected.
+func (c *Client) currentConn() *client.Conn {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	if !c.connected {
+		return nil
+	}
+	return c.conn
 }
 
 func (c *Client) Active() bool {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
 	return c.connected
 }
 
-func (c *Client) withRequestTimeout() context.Context {
-	ctx, cancel := context.WithTimeout(context.Background(), c.requestTimeout)
-	defer cancel()
-	return ctx
+func (c *Client) withRequestTimeout() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.requestTimeout)
+}
+
+// VFOs returns the current state of VFO A and B and the split mode. VFO A is the currently selected VFO of the
+// radio, VFO B is the VFO used for transmitting in split mode.
+func (c *Client) VFOs() core.VFOs {
+	c.vfosLock.RLock()
+	defer c.vfosLock.RUnlock()
+	return c.vfos
+}
+
+func (c *Client) updateVFOs(update func(*core.VFOs)) {
+	c.vfosLock.Lock()
+	defer c.vfosLock.Unlock()
+	update(&c.vfos)
+}
+
+func (c *Client) setIncomingSplit(response protocol.Response) {
+	if len(response.Data) == 0 {
+		return
+	}
+	split := response.Data[0] == "1"
+	c.updateVFOs(func(vfos *core.VFOs) {
+		if vfos.Split != split {
+			log.Printf("incoming split: %t", split)
+		}
+		vfos.Split = split
+	})
+}
+
+func (c *Client) setIncomingSplitFrequency(response protocol.Response) {
+	if len(response.Data) == 0 {
+		return
+	}
+	frequency, err := strconv.ParseFloat(response.Data[0], 64)
+	if err != nil {
+		log.Printf("cannot parse split frequency: %v", err)
+		return
+	}
+	c.updateVFOs(func(vfos *core.VFOs) {
+		vfos.B = core.Frequency(frequency)
+	})
 }
 
 func (c *Client) setIncomingFrequency(frequency client.Frequency) {
 	incomingFrequency := core.Frequency(frequency)
+	c.updateVFOs(func(vfos *core.VFOs) {
+		vfos.A = incomingFrequency
+	})
+
+	c.incomingLock.Lock()
+	defer c.incomingLock.Unlock()
 	if c.incoming.frequency == incomingFrequency {
 		return
 	}
@@ -149,7 +226,7 @@ func (c *Client) setIncomingFrequency(frequency client.Frequency) {
 	c.controller.SetFrequency(c.incoming.frequency)
 	log.Printf("incoming frequency: %s", c.incoming.frequency)
 
-	band := c.bandplan.ByFrequency(frequency)
+	band := c.currentBandplan().ByFrequency(frequency)
 	incomingBand := toCoreBand(band.Name)
 	if incomingBand == c.incoming.band {
 		return
@@ -161,6 +238,9 @@ func (c *Client) setIncomingFrequency(frequency client.Frequency) {
 
 func (c *Client) setIncomingModeAndPassband(mode client.Mode, _ client.Frequency) {
 	incomingMode := toCoreMode(mode)
+
+	c.incomingLock.Lock()
+	defer c.incomingLock.Unlock()
 	if incomingMode == c.incoming.mode {
 		return
 	}
@@ -174,7 +254,13 @@ func (c *Client) SetFrequency(f core.Frequency) {
 		return
 	}
 	c.outgoing.frequency = f
-	c.conn.SetFrequency(c.withRequestTimeout(), client.Frequency(f))
+	conn := c.currentConn()
+	if conn == nil {
+		return
+	}
+	ctx, cancel := c.withRequestTimeout()
+	defer cancel()
+	conn.SetFrequency(ctx, client.Frequency(f))
 
 	log.Printf("outgoing frequency: %s", f)
 }
@@ -186,19 +272,53 @@ func (c *Client) SetBand(band core.Band) {
 	c.outgoing.band = band
 
 	outgoingBandName := toBandplanBandName(c.outgoing.band)
-	outgoingBand, ok := c.bandplan[outgoingBandName]
+	outgoingBand, ok := c.currentBandplan()[outgoingBandName]
 	if !ok {
 		log.Printf("unknown band %v", c.outgoing.band)
 		return
 	}
-	if c.conn == nil || c.conn.Closed() {
+	conn := c.currentConn()
+	if conn == nil {
 		return
 	}
-	c.conn.SwitchToBand(c.withRequestTimeout(), outgoingBand)
+	ctx, cancel := c.withRequestTimeout()
+	defer cancel()
+	conn.SwitchToBand(ctx, outgoingBand)
 
 	log.Printf("outgoing band: %v", band)
 }
 
+// SetPTT keys or releases the transmitter. The voice keyer uses this to transmit its recordings.
+func (c *Client) SetPTT(on bool) {
+	conn := c.currentConn()
+	if conn == nil {
+		return
+	}
+	ptt := client.PTTRx
+	if on {
+		ptt = client.PTTTx
+	}
+	ctx, cancel := c.withRequestTimeout()
+	defer cancel()
+	err := conn.SetPTT(ctx, ptt)
+	if err != nil {
+		log.Printf("cannot set PTT: %v", err)
+	}
+}
+
+// StationChanged selects the bandplan of the station's IARU region.
+func (c *Client) StationChanged(station core.Station) {
+	c.bandplanLock.Lock()
+	defer c.bandplanLock.Unlock()
+	c.bandplan = bandplans.ForRegion(station.Region)
+}
+
+func (c *Client) currentBandplan() bandplan.Bandplan {
+	c.bandplanLock.RLock()
+	defer c.bandplanLock.RUnlock()
+	return c.bandplan
+}
+
 func (c *Client) SetMode(mode core.Mode) {
 	if mode == c.outgoing.mode {
 		return
@@ -206,15 +326,20 @@ func (c *Client) SetMode(mode core.Mode) {
 	c.outgoing.mode = mode
 
 	outgoingMode := toClientMode(c.outgoing.mode)
-	if c.conn == nil || c.conn.Closed() {
+	conn := c.currentConn()
+	if conn == nil {
 		return
 	}
-	c.conn.SetModeAndPassband(c.withRequestTimeout(), outgoingMode, 0)
+	ctx, cancel := c.withRequestTimeout()
+	defer cancel()
+	conn.SetModeAndPassband(ctx, outgoingMode, 0)
 
 	log.Printf("outgoing mode: %v", mode)
 }
 
 func (c *Client) Refresh() {
+	c.incomingLock.Lock()
+	defer c.incomingLock.Unlock()
 	if c.incoming.frequency != 0 {
 		log.Printf("Refreshing VFO frequency")
 		c.controller.SetFrequency(c.incoming.frequency)
@@ -0,0 +1,211 @@
+package hamlib
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/rigsim"
+)
+
+const (
+	waitFor = 2 * time.Second
+	tick    = 10 * time.Millisecond
+)
+
+func TestClient_IncomingFrequencyAndBand(t *testing.T) {
+	rig, _, client, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	assert.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	<-rigsim.Play(
+		rigsim.Do(func() { rig.SetFrequency(14020000) }),
+		rigsim.Wait(50*time.Millisecond),
+		rigsim.Do(func() { rig.SetFrequency(7020000) }),
+	)
+
+	assert.Eventually(t, controller.has(7020000, core.Band40m, core.ModeCW), waitFor, tick)
+	assert.Equal(t, []core.Band{core.Band20m, core.Band40m}, controller.bands(), "the band is only emitted when it changes")
+	assert.Equal(t, core.Frequency(7020000), client.VFOs().A)
+}
+
+func TestClient_IncomingModeMapping(t *testing.T) {
+	rig, _, _, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	testCases := []struct {
+		hamlibMode string
+		expected   core.Mode
+	}{
+		{rigsim.ModeUSB, core.ModeSSB},
+		{rigsim.ModeCW, core.ModeCW},
+		{rigsim.ModeLSB, core.ModeSSB},
+		{rigsim.ModeRTTY, core.ModeRTTY},
+		{rigsim.ModeFM, core.ModeFM},
+		{rigsim.ModePKTUSB, core.ModeDigital},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.hamlibMode, func(t *testing.T) {
+			rig.SetMode(tc.hamlibMode)
+			assert.Eventually(t, func() bool { return controller.currentMode() == tc.expected }, waitFor, tick)
+		})
+	}
+}
+
+func TestClient_IncomingSplit(t *testing.T) {
+	rig, _, client, _ := setupRig(t, 14010000, rigsim.ModeCW)
+
+	rig.SetSplitFrequency(14012000)
+	rig.SetSplit(true)
+
+	expected := core.VFOs{A: 14010000, B: 14012000, Split: true}
+	assert.Eventually(t, func() bool { return client.VFOs() == expected }, waitFor, tick)
+}
+
+func TestClient_OutgoingBandAndMode(t *testing.T) {
+	rig, _, client, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	client.SetBand(core.Band40m)
+	assert.Equal(t, core.Band40m, toCoreBand(client.bandplan.ByFrequency(hamradio.Frequency(rig.Frequency())).Name))
+
+	client.SetMode(core.ModeSSB)
+	assert.Equal(t, rigsim.ModeUSB, rig.Mode())
+
+	client.SetFrequency(7150000)
+	assert.Equal(t, core.Frequency(7150000), rig.Frequency())
+	assert.Eventually(t, controller.has(7150000, core.Band40m, core.ModeSSB), waitFor, tick)
+}
+
+func TestClient_PTT(t *testing.T) {
+	rig, _, client, _ := setupRig(t, 14200000, rigsim.ModeUSB)
+	require.Eventually(t, client.Active, waitFor, tick)
+
+	client.SetPTT(true)
+	assert.True(t, rig.PTT())
+
+	client.SetPTT(false)
+	assert.False(t, rig.PTT())
+}
+
+func TestClient_KeepOpenReconnects(t *testing.T) {
+	rig, server, client, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+	require.Equal(t, []bool{true}, controller.statuses())
+
+	server.Disconnect()
+	assert.Eventually(t, func() bool { return len(controller.statuses()) == 3 }, waitFor, tick)
+	assert.Equal(t, []bool{true, false, true}, controller.statuses())
+	assert.True(t, client.Active())
+
+	rig.SetFrequency(3510000)
+	assert.Eventually(t, controller.has(3510000, core.Band80m, core.ModeCW), waitFor, tick)
+}
+
+func TestClient_DisconnectStopsKeepOpen(t *testing.T) {
+	_, server, client, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	client.Disconnect()
+	assert.Eventually(t, func() bool { return len(controller.statuses()) == 2 }, waitFor, tick)
+	time.Sleep(3 * client.retryInterval)
+
+	assert.Equal(t, []bool{true, false}, controller.statuses())
+	assert.False(t, client.Active())
+	assert.Equal(t, 0, server.Connections())
+}
+
+func TestClient_SlowRadio(t *testing.T) {
+	rig, server, _, controller := setupRig(t, 14010000, rigsim.ModeCW)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	server.SetLatency(30 * time.Millisecond)
+	rig.SetFrequency(21010000)
+
+	assert.Eventually(t, controller.has(21010000, core.Band15m, core.ModeCW), waitFor, tick)
+}
+
+func setupRig(t *testing.T, frequency core.Frequency, mode string) (*rigsim.Rig, *rigsim.HamlibServer, *Client, *testController) {
+	t.Helper()
+	rig := rigsim.NewRig(frequency, mode)
+	server, err := rigsim.NewHamlibServer("", rig)
+	require.NoError(t, err)
+
+	client := New(server.Address())
+	client.pollingInterval = 20 * time.Millisecond
+	client.retryInterval = 50 * time.Millisecond
+	controller := new(testController)
+	client.SetVFOController(controller)
+	client.Notify(core.ServiceStatusListenerFunc(controller.statusChanged))
+	client.KeepOpen()
+
+	t.Cleanup(func() {
+		client.Disconnect()
+		server.Close()
+	})
+	return rig, server, client, controller
+}
+
+type testController struct {
+	lock          sync.Mutex
+	frequency     core.Frequency
+	bandHistory   []core.Band
+	mode          core.Mode
+	statusHistory []bool
+}
+
+func (c *testController) SetFrequency(frequency core.Frequency) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.frequency = frequency
+}
+
+func (c *testController) SetBand(band core.Band) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.bandHistory = append(c.bandHistory, band)
+}
+
+func (c *testController) SetMode(mode core.Mode) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.mode = mode
+}
+
+func (c *testController) statusChanged(_ core.Service, available bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.statusHistory = append(c.statusHistory, available)
+}
+
+func (c *testController) has(frequency core.Frequency, band core.Band, mode core.Mode) func() bool {
+	return func() bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		currentBand := core.NoBand
+		if len(c.bandHistory) > 0 {
+			currentBand = c.bandHistory[len(c.bandHistory)-1]
+		}
+		return c.frequency == frequency && currentBand == band && c.mode == mode
+	}
+}
+
+func (c *testController) currentMode() core.Mode {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.mode
+}
+
+func (c *testController) bands() []core.Band {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]core.Band{}, c.bandHistory...)
+}
+
+func (c *testController) statuses() []bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]bool{}, c.statusHistory...)
+}
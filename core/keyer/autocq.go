@@ -0,0 +1,218 @@
+package keyer
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// The auto-CQ repeats the CQ macro (the first run macro) until it is stopped. After each CQ, the keyer waits for the
+// time it takes to send the CQ plus the listening gap before the CQ is sent again.
+
+// the index of the CQ macro within the run macros
+const cqMacro = 0
+
+const defaultAutoCQGap = 5 * time.Second
+
+// AutoCQListener is notified about the auto-CQ state. While the auto-CQ is active, the listener is notified
+// every second with the remaining time until the next CQ.
+type AutoCQListener interface {
+	AutoCQChanged(active bool, remaining time.Duration)
+}
+
+type AutoCQListenerFunc func(bool, time.Duration)
+
+func (f AutoCQListenerFunc) AutoCQChanged(active bool, remaining time.Duration) {
+	f(active, remaining)
+}
+
+type autoCQ struct {
+	next time.Time
+	stop chan struct{}
+}
+
+// AutoCQ sends the CQ macro and starts to repeat it. The auto-CQ stops when anything else is sent or the keyer is stopped.
+func (k *Keyer) AutoCQ() {
+	k.StopAutoCQ()
+
+	duration, ok := k.sendCQ()
+	if !ok {
+		return
+	}
+	k.autoCQ = &autoCQ{
+		next: k.now().Add(duration + k.listeningGap()),
+		stop: make(chan struct{}),
+	}
+	go k.tickAutoCQ(k.autoCQ)
+	k.emitAutoCQChanged(true, k.autoCQ.next.Sub(k.now()))
+}
+
+// StopAutoCQ stops the auto-CQ.
+func (k *Keyer) StopAutoCQ() {
+	if k.autoCQ == nil {
+		return
+	}
+	close(k.autoCQ.stop)
+	k.autoCQ = nil
+	k.emitAutoCQChanged(false, 0)
+}
+
+// AutoCQActive indicates if the auto-CQ is active.
+func (k *Keyer) AutoCQActive() bool {
+	return k.autoCQ != nil
+}
+
+// AutoCQGap returns the time to listen between two CQs.
+func (k *Keyer) AutoCQGap() time.Duration {
+	return k.listeningGap()
+}
+
+// EnterAutoCQGap sets the time to listen between two CQs. The new gap is used beginning with the next CQ.
+func (k *Keyer) EnterAutoCQGap(gap time.Duration) {
+	if gap <= 0 {
+		k.view.ShowMessage("the auto CQ gap must be longer than zero")
+		return
+	}
+	k.autoCQGapDuration = gap
+	k.keyerChanged()
+}
+
+func (k *Keyer) listeningGap() time.Duration {
+	if k.autoCQGapDuration <= 0 {
+		return defaultAutoCQGap
+	}
+	return k.autoCQGapDuration
+}
+
+func (k *Keyer) tickAutoCQ(state *autoCQ) {
+	ticker := time.NewTicker(k.autoCQTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-state.stop:
+			return
+		case <-ticker.C:
+			k.asyncRunner(func() {
+				k.repeatCQ(state)
+			})
+		}
+	}
+}
+
+// repeatCQ sends the CQ again if the listening gap is over, otherwise the remaining time is reported.
+func (k *Keyer) repeatCQ(state *autoCQ) {
+	if k.autoCQ != state {
+		return
+	}
+	remaining := state.next.Sub(k.now())
+	if remaining > 0 {
+		k.emitAutoCQChanged(true, remaining)
+		return
+	}
+
+	duration, ok := k.sendCQ()
+	if !ok {
+		k.StopAutoCQ()
+		return
+	}
+	state.next = k.now().Add(duration + k.listeningGap())
+	k.emitAutoCQChanged(true, state.next.Sub(k.now()))
+}
+
+// sendCQ sends the CQ macro and returns the estimated time it takes to send it. In phone modes, the voice keyer
+// plays the CQ macro.
+func (k *Keyer) sendCQ() (time.Duration, bool) {
+	if k.phone() {
+		duration, err := k.voice.Play(core.Run, cqMacro, k.values())
+		if err != nil {
+			k.view.ShowMessage(err)
+			return 0, false
+		}
+		return duration, true
+	}
+	macro, ok := k.runTemplates[cqMacro]
+	if !ok || macro == nil {
+		k.view.ShowMessage("the CQ macro F1 is not defined")
+		return 0, false
+	}
+	parts, actions, err := k.executeMacro(macro, k.wpm+k.runSpeeds[cqMacro])
+	if err != nil {
+		k.view.ShowMessage(err)
+		return 0, false
+	}
+	if len(parts) == 0 {
+		k.view.ShowMessage("the CQ macro F1 is empty")
+		return 0, false
+	}
+	if !k.send(parts, cqMacro+1) {
+		return 0, false
+	}
+	for _, action := range actions {
+		action(k.actions)
+	}
+	return cwDuration(parts), true
+}
+
+func (k *Keyer) emitAutoCQChanged(active bool, remaining time.Duration) {
+	for _, listener := range k.listeners {
+		if autoCQListener, ok := listener.(AutoCQListener); ok {
+			autoCQListener.AutoCQChanged(active, remaining)
+		}
+	}
+}
+
+// the morse code of the characters, used to estimate the time it takes to send a message
+var morseCode = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.", 'H': "....", 'I': "..",
+	'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-", 'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-", '5': ".....", '6': "-....", '7': "--...",
+	'8': "---..", '9': "----.",
+	'/': "-..-.", '?': "..--..", '=': "-...-", '+': ".-.-.", '.': ".-.-.-", ',': "--..--", '-': "-....-",
+}
+
+// cwDuration estimates the time it takes to send the given parts of a message in CW, using the standard timing:
+// a dot is one unit, a dash three units, the gap between characters three units and between words seven units.
+// One unit takes 1.2 seconds divided by the speed in WpM.
+func cwDuration(parts []messagePart) time.Duration {
+	var result time.Duration
+	for _, part := range parts {
+		if part.wpm <= 0 {
+			continue
+		}
+		unit := 1200 * time.Millisecond / time.Duration(part.wpm)
+		result += time.Duration(cwUnits(part.text)) * unit
+	}
+	return result
+}
+
+func cwUnits(text string) int {
+	result := 0
+	for i, word := range strings.Fields(text) {
+		if i > 0 {
+			result += 7
+		}
+		for j, r := range word {
+			code, ok := morseCode[unicode.ToUpper(r)]
+			if !ok {
+				continue
+			}
+			if j > 0 {
+				result += 3
+			}
+			for l, element := range code {
+				if l > 0 {
+					result++
+				}
+				if element == '-' {
+					result += 3
+				} else {
+					result++
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package keyer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/mocked"
+)
+
+func TestAutoCQ_RepeatsAfterListeningGap(t *testing.T) {
+	keyer, cwClient, now := setupAutoCQTest()
+	var countdown []time.Duration
+	var active bool
+	keyer.Notify(AutoCQListenerFunc(func(a bool, remaining time.Duration) {
+		active = a
+		countdown = append(countdown, remaining)
+	}))
+	cwClient.On("Send", "cq test").Twice()
+	duration := cwDuration([]messagePart{{text: "cq test", wpm: 20}})
+
+	keyer.AutoCQ()
+	assert.True(t, active)
+	assert.Equal(t, []time.Duration{duration + 3*time.Second}, countdown)
+
+	*now = now.Add(duration + 2*time.Second)
+	keyer.repeatCQ(keyer.autoCQ)
+	assert.Equal(t, time.Second, countdown[len(countdown)-1])
+	cwClient.AssertNumberOfCalls(t, "Send", 1)
+
+	*now = now.Add(time.Second)
+	keyer.repeatCQ(keyer.autoCQ)
+	cwClient.AssertNumberOfCalls(t, "Send", 2)
+	assert.Equal(t, duration+3*time.Second, countdown[len(countdown)-1])
+
+	keyer.StopAutoCQ()
+	assert.False(t, active)
+	assert.False(t, keyer.AutoCQActive())
+	cwClient.AssertExpectations(t)
+}
+
+func TestAutoCQ_StopsWhenAnythingElseIsSent(t *testing.T) {
+	keyer, cwClient, _ := setupAutoCQTest()
+	cwClient.On("Send", "cq test").Once()
+	cwClient.On("Send", "agn?").Once()
+
+	keyer.AutoCQ()
+	assert.True(t, keyer.AutoCQActive())
+
+	keyer.SendQuestion("agn")
+	assert.False(t, keyer.AutoCQActive())
+	cwClient.AssertExpectations(t)
+}
+
+func TestAutoCQ_StopsWithKeyer(t *testing.T) {
+	keyer, cwClient, _ := setupAutoCQTest()
+	cwClient.On("Send", "cq test").Once()
+	cwClient.On("Abort").Once()
+
+	keyer.AutoCQ()
+	keyer.Stop()
+
+	assert.False(t, keyer.AutoCQActive())
+	cwClient.AssertExpectations(t)
+}
+
+func TestAutoCQ_EnterGap(t *testing.T) {
+	keyer, cwClient, now := setupAutoCQTest()
+	writer := new(testWriter)
+	keyer.SetWriter(writer)
+	cwClient.On("Send", "cq test").Twice()
+	duration := cwDuration([]messagePart{{text: "cq test", wpm: 20}})
+
+	keyer.EnterAutoCQGap(7 * time.Second)
+	keyer.EnterAutoCQGap(0)
+
+	assert.Equal(t, 7*time.Second, keyer.AutoCQGap())
+	require.Len(t, writer.written, 1)
+	assert.Equal(t, 7*time.Second, writer.written[0].AutoCQGap)
+
+	keyer.AutoCQ()
+	*now = now.Add(duration + 3*time.Second)
+	keyer.repeatCQ(keyer.autoCQ)
+	cwClient.AssertNumberOfCalls(t, "Send", 1)
+
+	*now = now.Add(4 * time.Second)
+	keyer.repeatCQ(keyer.autoCQ)
+	cwClient.AssertNumberOfCalls(t, "Send", 2)
+}
+
+func TestCWUnits(t *testing.T) {
+	assert.Equal(t, 43, cwUnits("paris"))
+	assert.Equal(t, 93, cwUnits("PARIS PARIS"))
+	assert.Equal(t, 2580*time.Millisecond, cwDuration([]messagePart{{text: "paris", wpm: 20}}))
+}
+
+func setupAutoCQTest() (*Keyer, *mocked.CWClient, *time.Time) {
+	now := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	view := new(mocked.KeyerView)
+	view.On("ShowMessage", mock.Anything).Maybe()
+	cwClient := new(mocked.CWClient)
+	cwClient.On("IsConnected").Return(true)
+	settings := core.Keyer{
+		WPM:       20,
+		RunMacros: []string{"cq test"},
+		AutoCQGap: 3 * time.Second,
+	}
+
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, settings, testAsync)
+	keyer.SetView(view)
+	keyer.now = func() time.Time { return now }
+	keyer.autoCQTick = time.Hour
+
+	return keyer, cwClient, &now
+}
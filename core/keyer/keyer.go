@@ -1,21 +1,35 @@
 package keyer
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"text/template"
+	"time"
+	"unicode"
 
 	"github.com/ftl/hamradio/callsign"
 
 	"github.com/ftl/hellocontest/core"
 )
 
+// MaxMacros is the number of macro slots, one for each function key from F1 to F12.
+const MaxMacros = 12
+
+// maxLabelLength is the maximum length of a label that is derived from the macro pattern.
+const maxLabelLength = 10
+
+// slowerStep is the speed difference in WpM when a message is sent or resent slower.
+const slowerStep = 5
+
 // View represents the visual parts of the keyer.
 type View interface {
 	ShowMessage(...interface{})
 	ShowKeyerSpeed(int)
+	ShowProgress(sent string, pending string)
+	// ShowMacroLabels shows the labels of the macros for the current workmode, the label of F1 first.
+	ShowMacroLabels([]string)
 }
 
 // CWClient defines the interface used by the Keyer to output the CW.
@@ -27,9 +41,61 @@ type CWClient interface {
 	Abort()
 }
 
+// InlineSpeedCWClient is implemented by CW clients that can change the speed within a message.
+type InlineSpeedCWClient interface {
+	// SendWithSpeed sends the given text with the given speed. After the text, the client returns to its current speed.
+	SendWithSpeed(text string, wpm int)
+}
+
+// WeightingCWClient is implemented by CW clients that can change the weighting of the CW.
+type WeightingCWClient interface {
+	// Weight sets the weighting in percent. 50 is the standard weighting, higher values make the dits and dahs
+	// longer and the spaces shorter.
+	Weight(percent int)
+}
+
+// FarnsworthCWClient is implemented by CW clients that support Farnsworth spacing.
+type FarnsworthCWClient interface {
+	// Farnsworth sends the characters with the given speed in WpM and stretches the spacing to keep the current
+	// speed. 0 disables the Farnsworth spacing.
+	Farnsworth(wpm int)
+}
+
+// CWFeatures is implemented by CW clients that forward to other clients, e.g. the SO2R router. They implement all
+// optional CW client interfaces, but report which of them are supported by the client that is currently used.
+type CWFeatures interface {
+	SupportsInlineSpeed() bool
+	SupportsWeighting() bool
+	SupportsFarnsworth() bool
+}
+
+// VoiceKeyer plays the recorded voice macros in phone modes.
+type VoiceKeyer interface {
+	// Play starts to play the voice macro with the given index and returns the time it takes to play it.
+	Play(workmode core.Workmode, index int, values core.KeyerValues) (time.Duration, error)
+	Stop()
+}
+
+// Actions are triggered by macros after their text was sent.
+type Actions interface {
+	Log()
+	SetWorkmode(core.Workmode)
+}
+
 // KeyerValueProvider provides the variable values for the Keyer templates on demand.
 type KeyerValueProvider func() core.KeyerValues
 
+// TransmissionListener is notified about each message that was sent by the keyer.
+type TransmissionListener interface {
+	Transmitted(core.Transmission)
+}
+
+type TransmissionListenerFunc func(core.Transmission)
+
+func (f TransmissionListenerFunc) Transmitted(transmission core.Transmission) {
+	f(transmission)
+}
+
 type KeyerListener interface {
 	KeyerChanged(core.Keyer)
 }
@@ -45,14 +111,18 @@ type Writer interface {
 }
 
 // New returns a new Keyer that has no patterns or templates defined yet.
-func New(settings core.Settings, client CWClient, keyer core.Keyer) *Keyer {
+func New(settings core.Settings, client CWClient, keyer core.Keyer, asyncRunner core.AsyncRunner) *Keyer {
 	result := &Keyer{
+		asyncRunner:     asyncRunner,
+		now:             time.Now,
+		autoCQTick:      time.Second,
+		speedSaveDelay:  2 * time.Second,
 		writer:          new(nullWriter),
+		view:            new(nullView),
 		stationCallsign: settings.Station().Callsign,
-		spPatterns:      make(map[int]string),
-		spTemplates:     make(map[int]*template.Template),
-		runPatterns:     make(map[int]string),
-		runTemplates:    make(map[int]*template.Template),
+		stationOperator: settings.Station().Operator,
+		actions:         new(nullActions),
+		voice:           new(nullVoiceKeyer),
 		client:          client,
 		values:          noValues,
 	}
@@ -69,32 +139,69 @@ type Keyer struct {
 	view       View
 	client     CWClient
 	values     KeyerValueProvider
+	actions    Actions
+	voice      VoiceKeyer
 	savedKeyer core.Keyer
+	rate       core.QSORate
+	macroSets  []core.MacroSet
+
+	asyncRunner core.AsyncRunner
+	now         func() time.Time
 
 	listeners []interface{}
 
-	stationCallsign callsign.Callsign
-	wpm             int
-	spPatterns      map[int]string
-	spTemplates     map[int]*template.Template
-	runPatterns     map[int]string
-	runTemplates    map[int]*template.Template
-	patterns        *map[int]string
-	templates       *map[int]*template.Template
+	stationCallsign   callsign.Callsign
+	stationOperator   callsign.Callsign
+	workmode          core.Workmode
+	wpm               int
+	weight            int
+	farnsworth        int
+	cwSettingsPending bool
+	reportCut         core.CutStyle
+	numberCut         core.CutStyle
+	xchangeCut        core.CutStyle
+	autoCQ            *autoCQ
+	autoCQGapDuration time.Duration
+	autoCQTick        time.Duration
+	speedSaveDelay    time.Duration
+	speedSaveTimer    *time.Timer
+	sending           string
+	echoed            string
+	macroSet          string
+	spPatterns        map[int]string
+	spTemplates       map[int]*template.Template
+	spLabels          map[int]string
+	spSpeeds          map[int]int
+	runPatterns       map[int]string
+	runTemplates      map[int]*template.Template
+	runLabels         map[int]string
+	runSpeeds         map[int]int
+	patterns          *map[int]string
+	templates         *map[int]*template.Template
+	labels            *map[int]string
+	speeds            *map[int]int
+	lastMessage       []messagePart
+	lastMacro         int
 }
 
 func (k *Keyer) setWorkmode(workmode core.Workmode) {
+	k.workmode = workmode
 	switch workmode {
 	case core.SearchPounce:
 		k.patterns = &k.spPatterns
 		k.templates = &k.spTemplates
+		k.labels = &k.spLabels
+		k.speeds = &k.spSpeeds
 	case core.Run:
 		k.patterns = &k.runPatterns
 		k.templates = &k.runTemplates
+		k.labels = &k.runLabels
+		k.speeds = &k.runSpeeds
 	}
 }
 
 func (k *Keyer) SetWriter(writer Writer) {
+	k.savePendingSpeed()
 	if writer == nil {
 		k.writer = new(nullWriter)
 		return
@@ -102,35 +209,152 @@ func (k *Keyer) SetWriter(writer Writer) {
 	k.writer = writer
 }
 
+// SetKeyer replaces the current keyer settings with the given settings, e.g. when a logfile is opened. The given
+// settings are not written again.
 func (k *Keyer) SetKeyer(keyer core.Keyer) {
 	k.savedKeyer = keyer
 	k.wpm = keyer.WPM
-	for i, pattern := range keyer.SPMacros {
+	k.weight = keyer.Weight
+	k.farnsworth = keyer.Farnsworth
+	k.reportCut = keyer.ReportCut
+	k.numberCut = keyer.NumberCut
+	k.xchangeCut = keyer.XchangeCut
+	k.autoCQGapDuration = keyer.AutoCQGap
+	k.macroSet = keyer.MacroSet
+	k.loadMacros(core.MacroSet{
+		SPMacros:  keyer.SPMacros,
+		SPLabels:  keyer.SPLabels,
+		SPSpeeds:  keyer.SPSpeeds,
+		RunMacros: keyer.RunMacros,
+		RunLabels: keyer.RunLabels,
+		RunSpeeds: keyer.RunSpeeds,
+	})
+	k.cwSettingsPending = true
+
+	k.view.ShowKeyerSpeed(k.wpm)
+	k.view.ShowMacroLabels(k.MacroLabels())
+	k.emitKeyerChanged(k.KeyerSettings())
+}
+
+// loadMacros replaces all macros, labels and speeds with those of the given macro set.
+func (k *Keyer) loadMacros(macroSet core.MacroSet) {
+	k.spPatterns = make(map[int]string)
+	k.spTemplates = make(map[int]*template.Template)
+	k.spLabels = make(map[int]string)
+	k.spSpeeds = make(map[int]int)
+	k.runPatterns = make(map[int]string)
+	k.runTemplates = make(map[int]*template.Template)
+	k.runLabels = make(map[int]string)
+	k.runSpeeds = make(map[int]int)
+	for i, pattern := range macroSet.SPMacros {
 		k.spPatterns[i] = pattern
-		k.spTemplates[i], _ = template.New("").Parse(pattern)
+		k.spTemplates[i] = k.loadMacro(i, pattern)
+	}
+	for i, label := range macroSet.SPLabels {
+		k.spLabels[i] = label
 	}
-	for i, pattern := range keyer.RunMacros {
+	for i, speed := range macroSet.SPSpeeds {
+		k.spSpeeds[i] = speed
+	}
+	for i, pattern := range macroSet.RunMacros {
 		k.runPatterns[i] = pattern
-		k.runTemplates[i], _ = template.New("").Parse(pattern)
+		k.runTemplates[i] = k.loadMacro(i, pattern)
+	}
+	for i, label := range macroSet.RunLabels {
+		k.runLabels[i] = label
+	}
+	for i, speed := range macroSet.RunSpeeds {
+		k.runSpeeds[i] = speed
+	}
+}
+
+func (k *Keyer) loadMacro(index int, pattern string) *template.Template {
+	macro, err := parseMacro(pattern)
+	if err != nil {
+		log.Printf("invalid macro F%d %q: %v", index+1, pattern, err)
+		return nil
 	}
+	return macro
 }
 
 func (k *Keyer) SetView(view View) {
+	if view == nil {
+		k.view = new(nullView)
+		return
+	}
 	k.view = view
+	k.view.ShowMacroLabels(k.MacroLabels())
 }
 
 func (k *Keyer) WorkmodeChanged(workmode core.Workmode) {
 	k.setWorkmode(workmode)
+	k.view.ShowMacroLabels(k.MacroLabels())
 }
 
 func (k *Keyer) StationChanged(station core.Station) {
 	k.stationCallsign = station.Callsign
+	k.stationOperator = station.Operator
 }
 
 func (k *Keyer) SetValues(values KeyerValueProvider) {
 	k.values = values
 }
 
+func (k *Keyer) SetActions(actions Actions) {
+	if actions == nil {
+		k.actions = new(nullActions)
+		return
+	}
+	k.actions = actions
+}
+
+// SetVoiceKeyer sets the voice keyer that is used instead of the CW client in phone modes.
+func (k *Keyer) SetVoiceKeyer(voice VoiceKeyer) {
+	if voice == nil {
+		k.voice = new(nullVoiceKeyer)
+		return
+	}
+	k.voice = voice
+}
+
+// SetMacroSets sets the named macro sets that can be selected.
+func (k *Keyer) SetMacroSets(macroSets []core.MacroSet) {
+	k.macroSets = macroSets
+}
+
+// MacroSets returns the names of all macro sets that can be selected.
+func (k *Keyer) MacroSets() []string {
+	result := make([]string, 0, len(k.macroSets))
+	for _, macroSet := range k.macroSets {
+		result = append(result, macroSet.Name)
+	}
+	return result
+}
+
+// ActiveMacroSet returns the name of the macro set that was selected last.
+func (k *Keyer) ActiveMacroSet() string {
+	return k.macroSet
+}
+
+// SelectMacroSet replaces all macros and labels with those of the macro set with the given name.
+func (k *Keyer) SelectMacroSet(name string) {
+	for _, macroSet := range k.macroSets {
+		if macroSet.Name != name {
+			continue
+		}
+		k.macroSet = macroSet.Name
+		k.loadMacros(macroSet)
+		k.view.ShowMacroLabels(k.MacroLabels())
+		k.keyerChanged()
+		return
+	}
+	k.view.ShowMessage(fmt.Sprintf("unknown macro set %q", name))
+}
+
+func (k *Keyer) RateUpdated(rate core.QSORate) {
+	k.rate = rate
+}
+
 func (k *Keyer) Save() {
 	keyer, modified := k.getKeyerSettings()
 	if !modified {
@@ -148,29 +372,57 @@ func (k *Keyer) KeyerSettings() core.Keyer {
 func (k *Keyer) getKeyerSettings() (core.Keyer, bool) {
 	var keyer core.Keyer
 	keyer.WPM = k.wpm
-	keyer.SPMacros = make([]string, len(k.spPatterns))
-	for i := range keyer.SPMacros {
-		pattern, ok := k.spPatterns[i]
-		if !ok {
-			continue
+	keyer.ReportCut = k.reportCut
+	keyer.NumberCut = k.numberCut
+	keyer.XchangeCut = k.xchangeCut
+	keyer.AutoCQGap = k.autoCQGapDuration
+	keyer.MacroSet = k.macroSet
+	keyer.SPMacros = toSlice(k.spPatterns)
+	keyer.SPLabels = toSlice(k.spLabels)
+	keyer.RunMacros = toSlice(k.runPatterns)
+	keyer.RunLabels = toSlice(k.runLabels)
+	keyer.Weight = k.weight
+	keyer.Farnsworth = k.farnsworth
+	keyer.SPSpeeds = toIntSlice(k.spSpeeds)
+	keyer.RunSpeeds = toIntSlice(k.runSpeeds)
+
+	modified := (fmt.Sprintf("%v", keyer) != fmt.Sprintf("%v", k.savedKeyer))
+	return keyer, modified
+}
+
+// toSlice converts the given map of macro slots to a slice. Empty slots in between are filled with empty strings.
+func toSlice(slots map[int]string) []string {
+	length := 0
+	for i := range slots {
+		if i >= length {
+			length = i + 1
 		}
-		keyer.SPMacros[i] = pattern
 	}
-	keyer.RunMacros = make([]string, len(k.runPatterns))
-	for i := range keyer.RunMacros {
-		pattern, ok := k.runPatterns[i]
-		if !ok {
-			continue
-		}
-		keyer.RunMacros[i] = pattern
+	result := make([]string, length)
+	for i, value := range slots {
+		result[i] = value
 	}
+	return result
+}
 
-	modified := (fmt.Sprintf("%v", keyer) != fmt.Sprintf("%v", k.savedKeyer))
-	return keyer, modified
+// toIntSlice converts the given map of macro slots to a slice. Empty slots in between are filled with 0.
+func toIntSlice(slots map[int]int) []int {
+	length := 0
+	for i := range slots {
+		if i >= length {
+			length = i + 1
+		}
+	}
+	result := make([]int, length)
+	for i, value := range slots {
+		result[i] = value
+	}
+	return result
 }
 
 func (k *Keyer) EnterSpeed(speed int) {
 	k.wpm = speed
+	k.saveSpeedLater()
 	if !k.client.IsConnected() {
 		return
 	}
@@ -178,6 +430,89 @@ func (k *Keyer) EnterSpeed(speed int) {
 	k.client.Speed(k.wpm)
 }
 
+// saveSpeedLater writes the speed when it was not changed for a while. Stepping through the speeds or turning the
+// speed pot of the keyer therefore only writes the final speed.
+func (k *Keyer) saveSpeedLater() {
+	if k.speedSaveTimer != nil {
+		k.speedSaveTimer.Stop()
+	}
+	k.speedSaveTimer = time.AfterFunc(k.speedSaveDelay, func() {
+		k.asyncRunner(k.savePendingSpeed)
+	})
+}
+
+// savePendingSpeed writes the speed right away if it is still waiting to be written.
+func (k *Keyer) savePendingSpeed() {
+	if k.speedSaveTimer == nil {
+		return
+	}
+	k.speedSaveTimer.Stop()
+	k.speedSaveTimer = nil
+	k.keyerChanged()
+}
+
+// SetSpeed sets the speed that was changed outside of hellocontest, e.g. with the speed pot of the keyer.
+func (k *Keyer) SetSpeed(speed int) {
+	k.EnterSpeed(speed)
+	k.view.ShowKeyerSpeed(k.wpm)
+}
+
+// EnterWeight sets the weighting of the CW in percent, 50 is the standard weighting.
+func (k *Keyer) EnterWeight(percent int) {
+	k.weight = percent
+	k.cwSettingsPending = true
+	k.keyerChanged()
+}
+
+// EnterFarnsworth sets the speed in WpM that is used for the characters when Farnsworth spacing is used, 0 disables
+// the Farnsworth spacing.
+func (k *Keyer) EnterFarnsworth(wpm int) {
+	k.farnsworth = wpm
+	k.cwSettingsPending = true
+	k.keyerChanged()
+}
+
+// EnterMacroSpeed sets the speed offset in WpM of the macro with the given index, relative to the current speed.
+func (k *Keyer) EnterMacroSpeed(index int, offset int) {
+	if index < 0 || index >= MaxMacros {
+		k.view.ShowMessage(fmt.Sprintf("there is no macro F%d", index+1))
+		return
+	}
+	(*k.speeds)[index] = offset
+	k.keyerChanged()
+}
+
+// applyCWSettings sets the weighting and the Farnsworth spacing of the CW client, if the client supports them.
+// This is done before the next message is sent whenever the settings changed.
+func (k *Keyer) applyCWSettings() {
+	k.cwSettingsPending = false
+	if k.weight > 0 {
+		if client, ok := k.client.(WeightingCWClient); k.supports(ok, CWFeatures.SupportsWeighting) {
+			client.Weight(k.weight)
+		} else {
+			log.Printf("the CW client cannot change the weighting")
+		}
+	}
+	if client, ok := k.client.(FarnsworthCWClient); k.supports(ok, CWFeatures.SupportsFarnsworth) {
+		client.Farnsworth(k.farnsworth)
+	} else if k.farnsworth > 0 {
+		log.Printf("the CW client does not support Farnsworth spacing")
+	}
+}
+
+// supports indicates if the CW client supports an optional feature. The implemented flag tells if the client
+// implements the interface of the feature. Clients that forward to other clients are asked if the currently used
+// client supports the feature.
+func (k *Keyer) supports(implemented bool, feature func(CWFeatures) bool) bool {
+	if !implemented {
+		return false
+	}
+	if features, ok := k.client.(CWFeatures); ok {
+		return feature(features)
+	}
+	return true
+}
+
 func (k *Keyer) IncreaseSpeed() {
 	k.EnterSpeed(k.wpm + 1)
 	k.view.ShowKeyerSpeed(k.wpm)
@@ -188,81 +523,284 @@ func (k *Keyer) DecreaseSpeed() {
 	k.view.ShowKeyerSpeed(k.wpm)
 }
 
+// EnterPattern sets the macro with the given index. The macro is validated with the current values, any error is
+// shown in the view.
 func (k *Keyer) EnterPattern(index int, pattern string) {
+	if index < 0 || index >= MaxMacros {
+		k.view.ShowMessage(fmt.Sprintf("there is no macro F%d", index+1))
+		return
+	}
 	(*k.patterns)[index] = pattern
-	var err error
-	(*k.templates)[index], err = template.New("").Parse(pattern)
+	macro, err := parseMacro(pattern)
+	if err == nil {
+		_, _, err = k.executeMacro(macro, k.macroSpeed(index))
+	}
 	if err != nil {
+		(*k.templates)[index] = nil
 		k.view.ShowMessage(err)
 	} else {
+		(*k.templates)[index] = macro
 		k.view.ShowMessage()
 	}
+	k.view.ShowMacroLabels(k.MacroLabels())
+	k.keyerChanged()
 }
 
 func (k *Keyer) GetPattern(index int) string {
 	return (*k.patterns)[index]
 }
 
+// EnterLabel sets the label of the macro with the given index.
+func (k *Keyer) EnterLabel(index int, label string) {
+	if index < 0 || index >= MaxMacros {
+		k.view.ShowMessage(fmt.Sprintf("there is no macro F%d", index+1))
+		return
+	}
+	(*k.labels)[index] = strings.TrimSpace(label)
+	k.view.ShowMacroLabels(k.MacroLabels())
+	k.keyerChanged()
+}
+
+// MacroLabels returns the labels of the macros for the current workmode, the label of F1 first. If a macro has no
+// label, the beginning of its pattern is used instead.
+func (k *Keyer) MacroLabels() []string {
+	patterns := toSlice(*k.patterns)
+	labels := toSlice(*k.labels)
+	length := len(patterns)
+	if len(labels) > length {
+		length = len(labels)
+	}
+	result := make([]string, length)
+	for i := range result {
+		if i < len(labels) && labels[i] != "" {
+			result[i] = labels[i]
+			continue
+		}
+		if i < len(patterns) {
+			result[i] = abbreviate(patterns[i])
+		}
+	}
+	return result
+}
+
+func abbreviate(pattern string) string {
+	result := []rune(strings.TrimSpace(pattern))
+	if len(result) <= maxLabelLength {
+		return string(result)
+	}
+	return string(result[:maxLabelLength-1]) + "…"
+}
+
+// GetText returns the text that is sent for the macro with the given index.
 func (k *Keyer) GetText(index int) (string, error) {
-	buffer := bytes.NewBufferString("")
-	template, ok := (*k.templates)[index]
+	parts, _, err := k.getMessage(index)
+	if err != nil {
+		return "", err
+	}
+	return joinParts(parts), nil
+}
+
+func (k *Keyer) getMessage(index int) ([]messagePart, []macroAction, error) {
+	macro, ok := (*k.templates)[index]
 	if !ok {
-		return "", nil
+		return nil, nil, nil
+	}
+	if macro == nil {
+		return nil, nil, fmt.Errorf("the macro F%d is invalid", index+1)
+	}
+	return k.executeMacro(macro, k.macroSpeed(index))
+}
+
+// macroSpeed returns the speed of the macro with the given index for the current workmode.
+func (k *Keyer) macroSpeed(index int) int {
+	return k.wpm + (*k.speeds)[index]
+}
+
+func (k *Keyer) executeMacro(macro *template.Template, wpm int) ([]messagePart, []macroAction, error) {
+	text, actions, err := executeMacro(macro, k.macroValues())
+	if err != nil {
+		return nil, nil, err
 	}
-	err := template.Execute(buffer, k.fillins())
+	parts, err := splitSpeedChanges(text, wpm)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	return buffer.String(), nil
+	return parts, actions, nil
 }
 
-func (k *Keyer) fillins() map[string]string {
+func (k *Keyer) macroValues() macroValues {
 	values := k.values()
-	return map[string]string{
-		"MyCall":    k.stationCallsign.String(),
-		"MyReport":  softcut(values.MyReport.String()),
-		"MyNumber":  softcut(values.MyNumber.String()),
-		"MyXchange": values.MyXchange,
-		"TheirCall": values.TheirCall,
+	return macroValues{
+		MyCall:       k.stationCallsign.String(),
+		MyReport:     cutValue{values.MyReport.String(), withDefaultCut(k.reportCut, core.SoftCut)},
+		MyNumber:     cutValue{values.MyNumber.String(), withDefaultCut(k.numberCut, core.SoftCut)},
+		MyXchange:    cutValue{values.MyXchange, withDefaultCut(k.xchangeCut, core.NoCut)},
+		TheirCall:    values.TheirCall,
+		TheirXchange: cutValue{values.TheirXchange, withDefaultCut(k.xchangeCut, core.NoCut)},
+		Band:         string(values.Band),
+		Mode:         string(values.Mode),
+		Operator:     k.stationOperator.String(),
+		Rate:         int(k.rate.LastHourRate),
+		Run:          k.workmode == core.Run,
 	}
 }
 
 func (k *Keyer) Send(index int) {
-	message, err := k.GetText(index)
+	k.StopAutoCQ()
+	if k.phone() {
+		k.play(index)
+		return
+	}
+	parts, actions, err := k.getMessage(index)
 	if err != nil {
 		k.view.ShowMessage(err)
 		return
 	}
-	k.send(message)
+	if len(parts) > 0 && !k.send(parts, index+1) {
+		return
+	}
+	for _, action := range actions {
+		action(k.actions)
+	}
+}
+
+// SendSlower sends the macro with the given index slower than usual, e.g. for weak stations.
+func (k *Keyer) SendSlower(index int) {
+	k.StopAutoCQ()
+	if k.phone() {
+		k.play(index)
+		return
+	}
+	parts, actions, err := k.getMessage(index)
+	if err != nil {
+		k.view.ShowMessage(err)
+		return
+	}
+	if len(parts) > 0 && !k.send(slowDown(parts), index+1) {
+		return
+	}
+	for _, action := range actions {
+		action(k.actions)
+	}
+}
+
+// ResendSlower sends the last message again, slower than before. The actions of the macro are not executed again.
+func (k *Keyer) ResendSlower() {
+	k.StopAutoCQ()
+	if k.phone() {
+		log.Printf("cannot resend slower in phone mode")
+		return
+	}
+	if len(k.lastMessage) == 0 {
+		k.view.ShowMessage("nothing to resend")
+		return
+	}
+	k.send(slowDown(k.lastMessage), k.lastMacro)
+}
+
+// slowDown reduces the speed of all given parts by slowerStep, but not below the minimum speed.
+func slowDown(parts []messagePart) []messagePart {
+	result := make([]messagePart, len(parts))
+	for i, part := range parts {
+		wpm := part.wpm - slowerStep
+		if wpm < minSpeed {
+			wpm = minSpeed
+		}
+		result[i] = messagePart{text: part.text, wpm: wpm}
+	}
+	return result
 }
 
 func (k *Keyer) SendQuestion(q string) {
+	k.StopAutoCQ()
+	if k.phone() {
+		log.Printf("cannot send the question %q in phone mode", q)
+		return
+	}
 	s := strings.TrimSpace(q) + "?"
-	k.send(s)
+	k.send([]messagePart{{text: s, wpm: k.wpm}}, 0)
+}
+
+// phone indicates that the current mode is a phone mode, where the voice keyer is used.
+func (k *Keyer) phone() bool {
+	mode := k.values().Mode
+	return mode == core.ModeSSB || mode == core.ModeFM
+}
+
+// play plays the voice macro with the given index.
+func (k *Keyer) play(index int) {
+	_, err := k.voice.Play(k.workmode, index, k.values())
+	if err != nil {
+		k.view.ShowMessage(err)
+	}
 }
 
-func (k *Keyer) send(s string) {
+// send sends the given parts of a message, each with its own speed. If the client cannot change the speed within
+// a message, the whole message is sent with the current speed. The macro is the number of the macro that
+// is sent, 0 if the message is not sent by a macro.
+func (k *Keyer) send(parts []messagePart, macro int) bool {
 	if !k.client.IsConnected() {
 		err := k.client.Connect()
 		if err != nil {
 			k.view.ShowMessage(err)
 			k.emitStatusChanged(false)
-			return
+			return false
 		}
 		k.emitStatusChanged(true)
 		k.client.Speed(k.wpm)
+		k.cwSettingsPending = true
+	}
+	if k.cwSettingsPending {
+		k.applyCWSettings()
 	}
 
+	s := joinParts(parts)
 	log.Printf("sending %s", s)
-	k.client.Send(s)
+	inlineSpeedClient, ok := k.client.(InlineSpeedCWClient)
+	inlineSpeed := k.supports(ok, CWFeatures.SupportsInlineSpeed)
+	for _, part := range parts {
+		if part.wpm == k.wpm {
+			k.client.Send(part.text)
+		} else if inlineSpeed {
+			inlineSpeedClient.SendWithSpeed(part.text, part.wpm)
+		} else {
+			log.Printf("the CW client cannot change the speed within a message, sending with %d WpM", k.wpm)
+			k.client.Send(part.text)
+		}
+	}
+	k.sending = strings.ToUpper(s)
+	k.echoed = ""
+	k.lastMessage = parts
+	k.lastMacro = macro
+	wpm := k.wpm
+	if inlineSpeed {
+		wpm = mainSpeed(parts)
+	}
+	k.emitTransmitted(core.Transmission{Time: k.now(), Text: s, WPM: wpm, Macro: macro})
+	return true
+}
+
+// CharacterSent shows the progress of the message that was sent last. It is called by CW clients that echo the sent
+// characters.
+func (k *Keyer) CharacterSent(r rune) {
+	i := strings.IndexRune(k.sending, unicode.ToUpper(r))
+	if i == -1 {
+		return
+	}
+	k.echoed += k.sending[:i+1]
+	k.sending = k.sending[i+1:]
+	k.view.ShowProgress(k.echoed, k.sending)
 }
 
 func (k *Keyer) Stop() {
+	k.StopAutoCQ()
+	k.voice.Stop()
 	if !k.client.IsConnected() {
 		return
 	}
 	log.Println("abort sending")
 	k.client.Abort()
+	k.sending = ""
 }
 
 func (k *Keyer) Notify(listener interface{}) {
@@ -278,11 +816,25 @@ func (k *Keyer) emitStatusChanged(available bool) {
 	}
 }
 
-func (k *Keyer) emitKeyerChanged() {
-	keyer := core.Keyer{
-		WPM: k.wpm,
-		// TODO add patterns here
+func (k *Keyer) emitTransmitted(transmission core.Transmission) {
+	for _, listener := range k.listeners {
+		if transmissionListener, ok := listener.(TransmissionListener); ok {
+			transmissionListener.Transmitted(transmission)
+		}
 	}
+}
+
+// keyerChanged notifies the listeners about the modified keyer settings and writes them if they were modified.
+func (k *Keyer) keyerChanged() {
+	keyer, modified := k.getKeyerSettings()
+	if !modified {
+		return
+	}
+	k.emitKeyerChanged(keyer)
+	k.Save()
+}
+
+func (k *Keyer) emitKeyerChanged(keyer core.Keyer) {
 	for _, listener := range k.listeners {
 		if keyerListener, ok := listener.(KeyerListener); ok {
 			keyerListener.KeyerChanged(keyer)
@@ -330,6 +882,25 @@ type nullWriter struct{}
 
 func (w *nullWriter) WriteKeyer(core.Keyer) error { return nil }
 
+type nullView struct{}
+
+func (*nullView) ShowMessage(...interface{})  {}
+func (*nullView) ShowKeyerSpeed(int)          {}
+func (*nullView) ShowProgress(string, string) {}
+func (*nullView) ShowMacroLabels([]string)    {}
+
+type nullVoiceKeyer struct{}
+
+func (*nullVoiceKeyer) Play(core.Workmode, int, core.KeyerValues) (time.Duration, error) {
+	return 0, errors.New("no voice keyer available")
+}
+func (*nullVoiceKeyer) Stop() {}
+
+type nullActions struct{}
+
+func (*nullActions) Log()                      {}
+func (*nullActions) SetWorkmode(core.Workmode) {}
+
 type nullClient struct{}
 
 func (*nullClient) Connect() error    { return nil }
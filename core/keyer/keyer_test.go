@@ -1,13 +1,16 @@
 package keyer
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ftl/hamradio/callsign"
 	"github.com/ftl/hellocontest/core"
 	"github.com/ftl/hellocontest/core/mocked"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSend(t *testing.T) {
@@ -33,7 +36,7 @@ func TestSend(t *testing.T) {
 	cwClient.On("Send", "DL1ABC DL0ZZZ t56 5nn ABC").Once()
 	cwClient.On("IsConnected").Return(true)
 
-	keyer := New(&testSettings{"DL1ABC"}, cwClient, keyerSettings)
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, keyerSettings, testAsync)
 	keyer.SetView(view)
 	keyer.SetValues(values)
 	keyer.EnterPattern(0, "{{.MyCall}} {{.TheirCall}} {{.MyNumber}} {{.MyReport}} {{.MyXchange}}")
@@ -43,6 +46,233 @@ func TestSend(t *testing.T) {
 	cwClient.AssertExpectations(t)
 }
 
+func TestSend_EmitsTransmission(t *testing.T) {
+	keyerSettings := core.Keyer{
+		SPMacros:  []string{"", "", "", ""},
+		RunMacros: []string{"", "", "", ""},
+		WPM:       25,
+	}
+	cwClient := new(mocked.CWClient)
+	cwClient.On("Send", mock.Anything)
+	cwClient.On("IsConnected").Return(true)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var transmissions []core.Transmission
+
+	view := new(mocked.KeyerView)
+	view.On("ShowMessage", mock.Anything)
+	view.On("SetSpeed", mock.Anything)
+	view.On("SetPattern", mock.Anything, mock.Anything)
+
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, keyerSettings, testAsync)
+	keyer.SetView(view)
+	keyer.now = func() time.Time { return now }
+	keyer.Notify(TransmissionListenerFunc(func(transmission core.Transmission) {
+		transmissions = append(transmissions, transmission)
+	}))
+	keyer.EnterPattern(1, "tu {{.MyCall}}")
+
+	keyer.Send(1)
+	keyer.SendQuestion("nr")
+
+	assert.Equal(t, []core.Transmission{
+		{Time: now, Text: "tu DL1ABC", WPM: 25, Macro: 2},
+		{Time: now, Text: "nr?", WPM: 25},
+	}, transmissions)
+}
+
+func TestCharacterSent_ShowsProgress(t *testing.T) {
+	view := new(mocked.KeyerView)
+	view.On("ShowProgress", "T", "U DL1ABC?").Once()
+	view.On("ShowProgress", "TU", " DL1ABC?").Once()
+	view.On("ShowProgress", "TU D", "L1ABC?").Once()
+	cwClient := new(mocked.CWClient)
+	cwClient.On("Send", "tu dl1abc?").Once()
+	cwClient.On("IsConnected").Return(true)
+
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, core.Keyer{}, testAsync)
+	keyer.SetView(view)
+	keyer.SendQuestion("tu dl1abc")
+
+	keyer.CharacterSent('T')
+	keyer.CharacterSent('U')
+	keyer.CharacterSent('D')
+	keyer.CharacterSent('#')
+
+	view.AssertExpectations(t)
+}
+
+func TestSend_PhoneModeUsesVoiceKeyer(t *testing.T) {
+	cwClient := new(mocked.CWClient)
+	voice := new(testVoiceKeyer)
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, core.Keyer{RunMacros: []string{"cq"}}, testAsync)
+	keyer.SetView(new(mocked.KeyerView))
+	keyer.SetVoiceKeyer(voice)
+	keyer.SetValues(func() core.KeyerValues {
+		return core.KeyerValues{Mode: core.ModeSSB}
+	})
+
+	keyer.Send(1)
+
+	assert.Equal(t, []int{1}, voice.played)
+	cwClient.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestEnterPattern_WritesAndEmitsKeyerSettings(t *testing.T) {
+	writer := new(testWriter)
+	var changes []core.Keyer
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{WPM: 25, RunMacros: []string{"cq", "", "tu"}}, testAsync)
+	keyer.SetWriter(writer)
+	keyer.Notify(KeyerListenerFunc(func(k core.Keyer) {
+		changes = append(changes, k)
+	}))
+
+	keyer.EnterPattern(5, "qrz?")
+	keyer.EnterLabel(5, "QRZ")
+	keyer.EnterLabel(5, "QRZ")
+
+	require.Len(t, writer.written, 2)
+	assert.Equal(t, changes, writer.written)
+	last := writer.written[1]
+	assert.Equal(t, []string{"cq", "", "tu", "", "", "qrz?"}, last.RunMacros)
+	assert.Equal(t, []string{"", "", "", "", "", "QRZ"}, last.RunLabels)
+}
+
+func TestEnterSpeed_WritesOnlyTheFinalSpeed(t *testing.T) {
+	writer := new(testWriter)
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{WPM: 25}, testAsync)
+	keyer.SetWriter(writer)
+	keyer.speedSaveDelay = 50 * time.Millisecond
+
+	keyer.IncreaseSpeed()
+	keyer.IncreaseSpeed()
+	keyer.SetSpeed(30)
+	keyer.DecreaseSpeed()
+
+	assert.Empty(t, writer.keyers(), "the speed is not written while it is changed")
+	assert.Eventually(t, func() bool { return len(writer.keyers()) == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(2 * keyer.speedSaveDelay)
+	require.Len(t, writer.keyers(), 1)
+	assert.Equal(t, 29, writer.keyers()[0].WPM)
+}
+
+func TestSetWriter_WritesThePendingSpeed(t *testing.T) {
+	writer := new(testWriter)
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{WPM: 25}, testAsync)
+	keyer.SetWriter(writer)
+	keyer.speedSaveDelay = time.Hour
+
+	keyer.IncreaseSpeed()
+	keyer.SetWriter(new(testWriter))
+
+	require.Len(t, writer.keyers(), 1)
+	assert.Equal(t, 26, writer.keyers()[0].WPM)
+}
+
+func TestEnterPattern_OnlyTwelveMacros(t *testing.T) {
+	writer := new(testWriter)
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{}, testAsync)
+	keyer.SetWriter(writer)
+
+	keyer.EnterPattern(MaxMacros, "qrz?")
+
+	assert.Empty(t, writer.written)
+	assert.Empty(t, keyer.MacroLabels())
+}
+
+func TestMacroLabels(t *testing.T) {
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{
+		SPMacros:  []string{"{{.MyCall}}", "{{.MyReport}} {{.MyNumber}}"},
+		SPLabels:  []string{"MyCall"},
+		RunMacros: []string{"cq {{.MyCall}} test", "", "tu"},
+		RunLabels: []string{"", "", "", "QRZ"},
+	}, testAsync)
+
+	assert.Equal(t, []string{"cq {{.MyC…", "", "tu", "QRZ"}, keyer.MacroLabels())
+	keyer.WorkmodeChanged(core.SearchPounce)
+	assert.Equal(t, []string{"MyCall", "{{.MyRepo…"}, keyer.MacroLabels())
+}
+
+func TestSelectMacroSet(t *testing.T) {
+	writer := new(testWriter)
+	keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{WPM: 25, RunMacros: []string{"cq", "exchange", "tu", "nr"}}, testAsync)
+	keyer.SetWriter(writer)
+	keyer.SetMacroSets([]core.MacroSet{
+		{Name: "CQWW", RunMacros: []string{"cq ww", "5nn 14"}, RunLabels: []string{"CQ", "Exch"}},
+		{Name: "WAG", RunMacros: []string{"cq wag", "5nn {{.MyNumber}}"}},
+	})
+
+	keyer.SelectMacroSet("CQWW")
+
+	assert.Equal(t, []string{"CQWW", "WAG"}, keyer.MacroSets())
+	assert.Equal(t, "CQWW", keyer.ActiveMacroSet())
+	assert.Equal(t, []string{"CQ", "Exch"}, keyer.MacroLabels())
+	require.Len(t, writer.written, 1)
+	assert.Equal(t, "CQWW", writer.written[0].MacroSet)
+	assert.Equal(t, []string{"cq ww", "5nn 14"}, writer.written[0].RunMacros)
+
+	keyer.SelectMacroSet("unknown")
+	assert.Equal(t, "CQWW", keyer.ActiveMacroSet())
+	assert.Len(t, writer.written, 1)
+}
+
+func TestSend_MacroSpeed(t *testing.T) {
+	client := new(testInlineSpeedClient)
+	keyer := New(&testSettings{"DL1ABC"}, client, core.Keyer{
+		WPM:       25,
+		RunMacros: []string{"cq", "5nn"},
+		RunSpeeds: []int{4, -3},
+	}, testAsync)
+
+	keyer.Send(0)
+	keyer.Send(1)
+	keyer.EnterMacroSpeed(1, 0)
+	keyer.Send(1)
+
+	assert.Equal(t, []messagePart{{"cq", 29}, {"5nn", 22}, {"5nn", 25}}, client.sent)
+}
+
+func TestSendSlower(t *testing.T) {
+	client := new(testInlineSpeedClient)
+	keyer := New(&testSettings{"DL1ABC"}, client, core.Keyer{
+		WPM:       25,
+		RunMacros: []string{"cq", "5nn <+5>tu"},
+	}, testAsync)
+
+	keyer.SendSlower(1)
+	keyer.ResendSlower()
+
+	assert.Equal(t, []messagePart{
+		{"5nn ", 20}, {"tu", 25},
+		{"5nn ", 15}, {"tu", 20},
+	}, client.sent)
+}
+
+func TestResendSlower_NotBelowMinimumSpeed(t *testing.T) {
+	client := new(testInlineSpeedClient)
+	keyer := New(&testSettings{"DL1ABC"}, client, core.Keyer{WPM: 12}, testAsync)
+
+	keyer.SendQuestion("nr")
+	keyer.ResendSlower()
+	keyer.ResendSlower()
+
+	assert.Equal(t, []messagePart{{"nr?", 7}, {"nr?", minSpeed}}, client.sent[1:])
+}
+
+func TestSend_AppliesWeightingAndFarnsworth(t *testing.T) {
+	client := new(testInlineSpeedClient)
+	keyer := New(&testSettings{"DL1ABC"}, client, core.Keyer{WPM: 18, Weight: 55, Farnsworth: 25}, testAsync)
+
+	keyer.SendQuestion("nr")
+	assert.Equal(t, 55, client.weight)
+	assert.Equal(t, 25, client.farnsworth)
+
+	keyer.EnterFarnsworth(0)
+	assert.Equal(t, 25, client.farnsworth, "the settings are applied with the next message")
+	keyer.SendQuestion("nr")
+	assert.Equal(t, 0, client.farnsworth)
+	assert.Equal(t, 0, keyer.KeyerSettings().Farnsworth)
+}
+
 func TestSoftcut(t *testing.T) {
 	assert.Equal(t, "t12345678n", softcut("0123456789"))
 }
@@ -51,6 +281,24 @@ func TestCut(t *testing.T) {
 	assert.Equal(t, "tauv4e6gdn", cut("0123456789"))
 }
 
+type testWriter struct {
+	lock    sync.Mutex
+	written []core.Keyer
+}
+
+func (w *testWriter) WriteKeyer(keyer core.Keyer) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.written = append(w.written, keyer)
+	return nil
+}
+
+func (w *testWriter) keyers() []core.Keyer {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]core.Keyer{}, w.written...)
+}
+
 type testSettings struct {
 	stationCallsign string
 }
@@ -58,9 +306,25 @@ type testSettings struct {
 func (s *testSettings) Station() core.Station {
 	return core.Station{
 		Callsign: callsign.MustParse(s.stationCallsign),
+		Operator: callsign.MustParse("DL2ABC"),
 	}
 }
 
 func (s *testSettings) Contest() core.Contest {
 	return core.Contest{}
 }
+
+func testAsync(f func()) {
+	f()
+}
+
+type testVoiceKeyer struct {
+	played []int
+}
+
+func (k *testVoiceKeyer) Play(_ core.Workmode, index int, _ core.KeyerValues) (time.Duration, error) {
+	k.played = append(k.played, index)
+	return time.Second, nil
+}
+
+func (k *testVoiceKeyer) Stop() {}
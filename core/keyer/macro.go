@@ -0,0 +1,206 @@
+package keyer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// The macros are Go templates (see https://golang.org/pkg/text/template/) with the fields of macroValues and the
+// functions of macroFuncs. Additionally, the speed can be changed within a macro: <+5> increases and <-5> decreases
+// the speed relative to the current speed, <30> sets the speed to 30 WpM. Each speed change lasts until the next
+// speed change or the end of the macro.
+
+// macroValues are the values that can be used in the macros.
+type macroValues struct {
+	MyCall       string
+	MyReport     cutValue
+	MyNumber     cutValue
+	MyXchange    cutValue
+	TheirCall    string
+	TheirXchange cutValue
+	Band         string
+	Mode         string
+	Operator     string
+	// Rate is the number of QSOs in the last hour.
+	Rate int
+	// Run indicates that the current workmode is run.
+	Run bool
+}
+
+// cutValue is a value that can contain digits. When the value is sent, the digits are replaced with cut numbers,
+// according to the cut style of the value.
+type cutValue struct {
+	value string
+	style core.CutStyle
+}
+
+func (v cutValue) String() string {
+	return applyCut(v.value, v.style)
+}
+
+func applyCut(s string, style core.CutStyle) string {
+	switch style {
+	case core.SoftCut:
+		return softcut(s)
+	case core.FullCut:
+		return cut(s)
+	default:
+		return s
+	}
+}
+
+func withDefaultCut(style core.CutStyle, defaultStyle core.CutStyle) core.CutStyle {
+	if style == core.DefaultCut {
+		return defaultStyle
+	}
+	return style
+}
+
+// macroAction is triggered by a macro after its text was sent.
+type macroAction func(Actions)
+
+// macroFuncs returns the functions that can be used in the macros. The functions that trigger actions add
+// these actions to the given slice.
+func macroFuncs(actions *[]macroAction) template.FuncMap {
+	addAction := func(action macroAction) {
+		if actions != nil {
+			*actions = append(*actions, action)
+		}
+	}
+	return template.FuncMap{
+		"cut":     func(v interface{}) string { return applyCut(rawValue(v), core.FullCut) },
+		"softcut": func(v interface{}) string { return applyCut(rawValue(v), core.SoftCut) },
+		"nocut":   func(v interface{}) string { return rawValue(v) },
+		"repeat": func(count int, v interface{}) (string, error) {
+			if count < 1 {
+				return "", fmt.Errorf("invalid repeat count %d", count)
+			}
+			s := fmt.Sprint(v)
+			return strings.TrimSpace(strings.Repeat(s+" ", count)), nil
+		},
+		"logQSO": func() string {
+			addAction(func(a Actions) { a.Log() })
+			return ""
+		},
+		"workmode": func(name string) (string, error) {
+			var workmode core.Workmode
+			switch strings.ToLower(name) {
+			case "run":
+				workmode = core.Run
+			case "sp", "s&p":
+				workmode = core.SearchPounce
+			default:
+				return "", fmt.Errorf("unknown workmode %q, use run or sp", name)
+			}
+			addAction(func(a Actions) { a.SetWorkmode(workmode) })
+			return "", nil
+		},
+	}
+}
+
+func rawValue(v interface{}) string {
+	switch value := v.(type) {
+	case cutValue:
+		return value.value
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseMacro(pattern string) (*template.Template, error) {
+	return template.New("").Funcs(macroFuncs(nil)).Parse(pattern)
+}
+
+// executeMacro fills in the given values and returns the resulting text and the actions that were triggered
+// by the macro.
+func executeMacro(macro *template.Template, values macroValues) (string, []macroAction, error) {
+	var actions []macroAction
+	execution, err := macro.Clone()
+	if err != nil {
+		return "", nil, err
+	}
+	execution.Funcs(macroFuncs(&actions))
+
+	buffer := bytes.NewBufferString("")
+	err = execution.Execute(buffer, values)
+	if err != nil {
+		return "", nil, err
+	}
+	return buffer.String(), actions, nil
+}
+
+// messagePart is a part of a message that is sent with a certain speed.
+type messagePart struct {
+	text string
+	wpm  int
+}
+
+// the range of the speed for inline speed changes in WpM
+const (
+	minSpeed = 5
+	maxSpeed = 99
+)
+
+var speedChangeExpression = regexp.MustCompile(`<([+-]?)(\d+)>`)
+
+// splitSpeedChanges splits the given text at the inline speed changes. The given speed is used for the text
+// before the first speed change and as base for the relative speed changes.
+func splitSpeedChanges(text string, wpm int) ([]messagePart, error) {
+	result := make([]messagePart, 0, 1)
+	currentWPM := wpm
+	start := 0
+	for _, match := range speedChangeExpression.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > start {
+			result = append(result, messagePart{text: text[start:match[0]], wpm: currentWPM})
+		}
+		start = match[1]
+
+		sign := text[match[2]:match[3]]
+		value, err := strconv.Atoi(text[match[4]:match[5]])
+		if err != nil {
+			return nil, err
+		}
+		switch sign {
+		case "+":
+			currentWPM = wpm + value
+		case "-":
+			currentWPM = wpm - value
+		default:
+			currentWPM = value
+		}
+		if currentWPM < minSpeed || currentWPM > maxSpeed {
+			return nil, fmt.Errorf("the speed change %s results in %d WpM, the speed must be between %d and %d WpM", text[match[0]:match[1]], currentWPM, minSpeed, maxSpeed)
+		}
+	}
+	if start < len(text) {
+		result = append(result, messagePart{text: text[start:], wpm: currentWPM})
+	}
+	return result, nil
+}
+
+// mainSpeed returns the speed in WpM that most characters of the given parts are sent with.
+func mainSpeed(parts []messagePart) int {
+	lengths := make(map[int]int)
+	var result int
+	for _, part := range parts {
+		lengths[part.wpm] += len(part.text)
+		if result == 0 || lengths[part.wpm] > lengths[result] {
+			result = part.wpm
+		}
+	}
+	return result
+}
+
+func joinParts(parts []messagePart) string {
+	var result strings.Builder
+	for _, part := range parts {
+		result.WriteString(part.text)
+	}
+	return result.String()
+}
@@ -0,0 +1,278 @@
+package keyer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/mocked"
+)
+
+func TestGetText(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		settings core.Keyer
+		workmode core.Workmode
+		rate     core.QSOsPerHour
+		pattern  string
+		expected string
+	}{
+		{
+			desc:     "all values",
+			pattern:  "{{.MyCall}} {{.Operator}} {{.TheirCall}} {{.TheirXchange}} {{.Band}} {{.Mode}}",
+			expected: "DL1ABC DL2ABC DL0ZZZ 109 40m CW",
+		},
+		{
+			desc:     "default cut style",
+			pattern:  "{{.MyReport}} {{.MyNumber}} {{.MyXchange}} {{.TheirXchange}}",
+			expected: "5nn t57 B10 109",
+		},
+		{
+			desc:     "configured cut style",
+			settings: core.Keyer{ReportCut: core.NoCut, NumberCut: core.FullCut, XchangeCut: core.SoftCut},
+			pattern:  "{{.MyReport}} {{.MyNumber}} {{.MyXchange}} {{.TheirXchange}}",
+			expected: "599 teg B1t 1tn",
+		},
+		{
+			desc:     "cut functions",
+			pattern:  "{{cut .MyNumber}} {{nocut .MyNumber}} {{softcut .MyXchange}} {{cut \"19\"}}",
+			expected: "teg 057 B1t an",
+		},
+		{
+			desc:     "repeat",
+			pattern:  "{{repeat 2 .MyNumber}} {{repeat 3 \"?\"}}",
+			expected: "t57 t57 ? ? ?",
+		},
+		{
+			desc:     "number twice if the rate is low",
+			rate:     20,
+			pattern:  "{{if lt .Rate 30}}{{repeat 2 .MyNumber}}{{else}}{{.MyNumber}}{{end}}",
+			expected: "t57 t57",
+		},
+		{
+			desc:     "number once if the rate is high",
+			rate:     120,
+			pattern:  "{{if lt .Rate 30}}{{repeat 2 .MyNumber}}{{else}}{{.MyNumber}}{{end}}",
+			expected: "t57",
+		},
+		{
+			desc:     "workmode",
+			workmode: core.SearchPounce,
+			pattern:  "{{if .Run}}tu{{else}}{{.MyCall}}{{end}}",
+			expected: "DL1ABC",
+		},
+		{
+			desc:     "actions are not sent",
+			pattern:  "tu {{logQSO}}{{workmode \"sp\"}}",
+			expected: "tu ",
+		},
+		{
+			desc:     "inline speed changes are not sent",
+			pattern:  "tu <+5>{{.MyCall}}<-3> test",
+			expected: "tu DL1ABC test",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			keyer := setupMacroTest(tc.settings)
+			keyer.WorkmodeChanged(tc.workmode)
+			keyer.RateUpdated(core.QSORate{LastHourRate: tc.rate})
+			keyer.EnterPattern(0, tc.pattern)
+
+			actual, err := keyer.GetText(0)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestEnterPattern_Validation(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		pattern string
+		valid   bool
+	}{
+		{"valid", "{{.MyCall}} {{repeat 2 .MyNumber}} <+5>tu{{logQSO}}", true},
+		{"syntax error", "{{.MyCall", false},
+		{"unknown value", "{{.MyLocator}}", false},
+		{"unknown function", "{{shout .MyCall}}", false},
+		{"invalid repeat count", "{{repeat 0 .MyCall}}", false},
+		{"unknown workmode", "{{workmode \"ssb\"}}", false},
+		{"speed too low", "<-23>tu", false},
+		{"speed too high", "<120>tu", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			view := new(mocked.KeyerView)
+			keyer := New(&testSettings{"DL1ABC"}, nil, core.Keyer{WPM: 25}, testAsync)
+			keyer.SetView(view)
+			view.On("ShowMessage", mock.MatchedBy(func(args []interface{}) bool {
+				return (len(args) == 0) == tc.valid
+			})).Once()
+
+			keyer.EnterPattern(0, tc.pattern)
+
+			view.AssertExpectations(t)
+			_, err := keyer.GetText(0)
+			assert.Equal(t, tc.valid, err == nil)
+		})
+	}
+}
+
+func TestSend_Actions(t *testing.T) {
+	keyer := setupMacroTest(core.Keyer{})
+	actions := new(testActions)
+	keyer.SetActions(actions)
+	keyer.EnterPattern(0, "tu {{logQSO}}{{workmode \"sp\"}}")
+
+	keyer.Send(0)
+
+	assert.Equal(t, []string{"log", "workmode S&P"}, actions.log)
+}
+
+func TestSend_InlineSpeedChanges(t *testing.T) {
+	keyer := setupMacroTest(core.Keyer{})
+	client := new(testInlineSpeedClient)
+	keyer.client = client
+	keyer.EnterPattern(0, "tu <+5>{{.MyCall}}<-3> test")
+
+	keyer.Send(0)
+
+	assert.Equal(t, []messagePart{{"tu ", 25}, {"DL1ABC", 30}, {" test", 22}}, client.sent)
+}
+
+func TestSend_InlineSpeedNotSupportedByTheCurrentClient(t *testing.T) {
+	keyer := setupMacroTest(core.Keyer{})
+	client := new(testForwardingClient)
+	keyer.client = client
+	keyer.EnterPattern(0, "tu <+5>{{.MyCall}}<-3> test")
+
+	keyer.Send(0)
+
+	assert.Equal(t, []messagePart{{"tu ", 25}, {"DL1ABC", 25}, {" test", 25}}, client.sent)
+}
+
+func TestSend_TransmissionWithTheMainSpeed(t *testing.T) {
+	keyer := setupMacroTest(core.Keyer{})
+	keyer.client = new(testInlineSpeedClient)
+	var transmissions []core.Transmission
+	keyer.Notify(TransmissionListenerFunc(func(transmission core.Transmission) {
+		transmissions = append(transmissions, transmission)
+	}))
+	keyer.EnterPattern(0, "<+5>5nn {{.MyNumber}}<-5> tu")
+
+	keyer.Send(0)
+
+	require.Len(t, transmissions, 1)
+	assert.Equal(t, 30, transmissions[0].WPM)
+}
+
+func TestSplitSpeedChanges(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []messagePart
+		invalid  bool
+	}{
+		{"", []messagePart{}, false},
+		{"tu", []messagePart{{"tu", 25}}, false},
+		{"<+5>tu", []messagePart{{"tu", 30}}, false},
+		{"a<30>b<-5>c<+0>d", []messagePart{{"a", 25}, {"b", 30}, {"c", 20}, {"d", 25}}, false},
+		{"<ar> <sk>", []messagePart{{"<ar> <sk>", 25}}, false},
+		{"<4>tu", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			actual, err := splitSpeedChanges(tc.text, 25)
+			if tc.invalid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func setupMacroTest(settings core.Keyer) *Keyer {
+	settings.WPM = 25
+	view := new(mocked.KeyerView)
+	view.On("ShowMessage", mock.Anything).Maybe()
+	cwClient := new(mocked.CWClient)
+	cwClient.On("IsConnected").Return(true).Maybe()
+	cwClient.On("Send", mock.Anything).Maybe()
+
+	keyer := New(&testSettings{"DL1ABC"}, cwClient, settings, testAsync)
+	keyer.SetView(view)
+	keyer.SetValues(func() core.KeyerValues {
+		return core.KeyerValues{
+			TheirCall:    "DL0ZZZ",
+			TheirXchange: "109",
+			MyNumber:     core.QSONumber(57),
+			MyReport:     core.RST("599"),
+			MyXchange:    "B10",
+			Band:         core.Band40m,
+			Mode:         core.ModeCW,
+		}
+	})
+	return keyer
+}
+
+type testActions struct {
+	log []string
+}
+
+func (a *testActions) Log() {
+	a.log = append(a.log, "log")
+}
+
+func (a *testActions) SetWorkmode(workmode core.Workmode) {
+	a.log = append(a.log, "workmode "+workmodeName(workmode))
+}
+
+func workmodeName(workmode core.Workmode) string {
+	if workmode == core.Run {
+		return "Run"
+	}
+	return "S&P"
+}
+
+type testInlineSpeedClient struct {
+	sent       []messagePart
+	wpm        int
+	weight     int
+	farnsworth int
+}
+
+func (c *testInlineSpeedClient) Connect() error    { return nil }
+func (c *testInlineSpeedClient) IsConnected() bool { return true }
+func (c *testInlineSpeedClient) Speed(wpm int)     { c.wpm = wpm }
+func (c *testInlineSpeedClient) Abort()            {}
+
+func (c *testInlineSpeedClient) Send(text string) {
+	c.sent = append(c.sent, messagePart{text, 25})
+}
+
+func (c *testInlineSpeedClient) SendWithSpeed(text string, wpm int) {
+	c.sent = append(c.sent, messagePart{text, wpm})
+}
+
+func (c *testInlineSpeedClient) Weight(percent int) {
+	c.weight = percent
+}
+
+func (c *testInlineSpeedClient) Farnsworth(wpm int) {
+	c.farnsworth = wpm
+}
+
+// testForwardingClient forwards to a client that supports none of the optional features.
+type testForwardingClient struct {
+	testInlineSpeedClient
+}
+
+func (c *testForwardingClient) SupportsInlineSpeed() bool { return false }
+func (c *testForwardingClient) SupportsWeighting() bool   { return false }
+func (c *testForwardingClient) SupportsFarnsworth() bool  { return false }
@@ -0,0 +1,77 @@
+package livescore
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/ftl/hamradio/dxcc"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+)
+
+// The format is described at https://contestonlinescore.com/, it is the same as the dynamicresults packet of N1MM+.
+
+const timestampFormat = "2006-01-02 15:04:05"
+
+// Results is the XML document that is posted to the live score server.
+type Results struct {
+	XMLName   xml.Name                 `xml:"dynamicresults"`
+	Contest   string                   `xml:"contest"`
+	Call      string                   `xml:"call"`
+	Ops       string                   `xml:"ops"`
+	Class     Class                    `xml:"class"`
+	Soft      string                   `xml:"soft"`
+	Version   string                   `xml:"version"`
+	QTH       QTH                      `xml:"qth"`
+	Breakdown dynamicresults.Breakdown `xml:"breakdown"`
+	Score     int                      `xml:"score"`
+	Timestamp string                   `xml:"timestamp"`
+}
+
+// Class describes the category of the entry.
+type Class struct {
+	Power       string `xml:"power,attr"`
+	Assisted    string `xml:"assisted,attr"`
+	Transmitter string `xml:"transmitter,attr"`
+	Ops         string `xml:"ops,attr"`
+	Bands       string `xml:"bands,attr"`
+	Mode        string `xml:"mode,attr"`
+	Overlay     string `xml:"overlay,attr"`
+}
+
+// QTH describes the location of the station.
+type QTH struct {
+	DXCCCountry string `xml:"dxcccountry"`
+	CQZone      int    `xml:"cqzone"`
+	IARUZone    int    `xml:"iaruzone"`
+	Grid6       string `xml:"grid6"`
+}
+
+func newResults(version string, station core.Station, entity dxcc.Prefix, contest core.Contest, score core.Score, qsos map[dynamicresults.BandMode]int, now time.Time) Results {
+	return Results{
+		Contest: contest.Name,
+		Call:    station.Callsign.String(),
+		Ops:     station.Operator.String(),
+		Class: Class{
+			Power:       contest.CategoryPower,
+			Assisted:    contest.CategoryAssisted,
+			Transmitter: contest.CategoryTransmitter,
+			Ops:         contest.CategoryOperator,
+			Bands:       contest.CategoryBand,
+			Mode:        contest.CategoryMode,
+			Overlay:     contest.CategoryOverlay,
+		},
+		Soft:    "Hello Contest",
+		Version: version,
+		QTH: QTH{
+			DXCCCountry: entity.PrimaryPrefix,
+			CQZone:      int(entity.CQZone),
+			IARUZone:    int(entity.ITUZone),
+			Grid6:       station.Locator.String(),
+		},
+		Breakdown: dynamicresults.NewBreakdown(contest, score, qsos),
+		Score:     dynamicresults.Score(contest, score),
+		Timestamp: now.UTC().Format(timestampFormat),
+	}
+}
@@ -0,0 +1,243 @@
+package livescore
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hamradio/dxcc"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+)
+
+// DefaultURL is the URL of the contestonlinescore.com server.
+const DefaultURL = "https://contestonlinescore.com/post/"
+
+// DefaultInterval is the default time between two reports.
+const DefaultInterval = 2 * time.Minute
+
+// DXCCFinder returns a list of matching prefixes for the given string and indicates if there was a match at all.
+type DXCCFinder interface {
+	Find(string) (dxcc.Prefix, bool)
+}
+
+// NewReporter returns a new reporter that posts the current score to the given URL at the given interval.
+func NewReporter(postURL string, interval time.Duration, version string, clock core.Clock, settings core.Settings, entities DXCCFinder, asyncRunner core.AsyncRunner) *Reporter {
+	result := &Reporter{
+		client:      &http.Client{Timeout: 30 * time.Second},
+		url:         postURL,
+		interval:    interval,
+		minBackoff:  10 * time.Second,
+		maxBackoff:  interval,
+		version:     version,
+		clock:       clock,
+		entities:    entities,
+		asyncRunner: asyncRunner,
+		done:        make(chan struct{}),
+		qsos:        make(map[core.QSONumber]core.QSO),
+		score:       core.Score{ScorePerBand: make(map[core.Band]core.BandScore)},
+	}
+	result.setStation(settings.Station())
+	result.contest = settings.Contest()
+	if result.maxBackoff < result.minBackoff {
+		result.maxBackoff = result.minBackoff
+	}
+	return result
+}
+
+// Reporter posts the current score regularly to a live score server. If the post fails, it is retried with
+// an increasing delay, always using the latest score.
+type Reporter struct {
+	client      *http.Client
+	url         string
+	interval    time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	version     string
+	clock       core.Clock
+	entities    DXCCFinder
+	asyncRunner core.AsyncRunner
+	done        chan struct{}
+
+	listeners []interface{}
+
+	mutex         sync.Mutex
+	station       core.Station
+	stationEntity dxcc.Prefix
+	contest       core.Contest
+	score         core.Score
+	qsos          map[core.QSONumber]core.QSO
+	changes       int
+	reported      int
+	available     bool
+}
+
+func (r *Reporter) Notify(listener interface{}) {
+	r.listeners = append(r.listeners, listener)
+}
+
+func (r *Reporter) StationChanged(station core.Station) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.setStation(station)
+	r.changes++
+}
+
+func (r *Reporter) setStation(station core.Station) {
+	r.station = station
+	r.stationEntity, _ = r.entities.Find(station.Callsign.String())
+}
+
+func (r *Reporter) ContestChanged(contest core.Contest) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.contest = contest
+	r.changes++
+}
+
+func (r *Reporter) ScoreUpdated(score core.Score) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.score = score
+	if r.stationEntity.PrimaryPrefix == "" {
+		r.setStation(r.station)
+	}
+	r.changes++
+}
+
+func (r *Reporter) QSOsCleared() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.qsos = make(map[core.QSONumber]core.QSO)
+	r.changes++
+}
+
+func (r *Reporter) QSOAdded(qso core.QSO) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.qsos[qso.MyNumber] = qso
+	r.changes++
+}
+
+func (r *Reporter) QSOUpdated(_ int, _, qso core.QSO) {
+	r.QSOAdded(qso)
+}
+
+// Start starts to report the score regularly in the background.
+func (r *Reporter) Start() {
+	go r.run()
+}
+
+// Stop stops reporting the score.
+func (r *Reporter) Stop() {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+}
+
+func (r *Reporter) run() {
+	backoff := r.minBackoff
+	timer := time.NewTimer(r.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-timer.C:
+		}
+
+		results, version, pending := r.pendingResults()
+		if !pending {
+			timer.Reset(r.interval)
+			continue
+		}
+
+		err := r.post(results)
+		if err != nil {
+			log.Printf("Cannot report the score, retrying in %v: %v", backoff, err)
+			r.setAvailable(false)
+			timer.Reset(backoff)
+			backoff *= 2
+			if backoff > r.maxBackoff {
+				backoff = r.maxBackoff
+			}
+			continue
+		}
+
+		r.setReported(version)
+		r.setAvailable(true)
+		backoff = r.minBackoff
+		timer.Reset(r.interval)
+	}
+}
+
+func (r *Reporter) pendingResults() (Results, int, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.changes == r.reported {
+		return Results{}, 0, false
+	}
+
+	qsos := make(map[dynamicresults.BandMode]int)
+	for _, qso := range r.qsos {
+		if qso.Duplicate {
+			continue
+		}
+		qsos[dynamicresults.BandMode{Band: qso.Band, Mode: qso.Mode}]++
+	}
+	return newResults(r.version, r.station, r.stationEntity, r.contest, r.score, qsos, r.clock.Now()), r.changes, true
+}
+
+func (r *Reporter) setReported(version int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reported = version
+}
+
+func (r *Reporter) post(results Results) error {
+	bytes, err := xml.Marshal(results)
+	if err != nil {
+		return err
+	}
+	form := url.Values{}
+	form.Set("xml", xml.Header+string(bytes))
+
+	resp, err := r.client.Post(r.url, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the server responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func (r *Reporter) setAvailable(available bool) {
+	r.mutex.Lock()
+	changed := r.available != available
+	r.available = available
+	r.mutex.Unlock()
+	if !changed {
+		return
+	}
+	r.asyncRunner(func() {
+		r.emitStatusChanged(available)
+	})
+}
+
+func (r *Reporter) emitStatusChanged(available bool) {
+	for _, listener := range r.listeners {
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.ScoreReportingService, available)
+		}
+	}
+}
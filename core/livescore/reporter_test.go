@@ -0,0 +1,207 @@
+package livescore
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/ftl/hamradio/dxcc"
+	"github.com/ftl/hamradio/locator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/clock"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+)
+
+var now = time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+func TestReporter_PostsLatestScore(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	reporter := setupReporter(server.URL)
+	reporter.QSOAdded(core.QSO{MyNumber: 1, Band: core.Band40m, Mode: core.ModeCW})
+	reporter.QSOAdded(core.QSO{MyNumber: 2, Band: core.Band40m, Mode: core.ModeCW})
+	reporter.QSOAdded(core.QSO{MyNumber: 3, Band: core.Band20m, Mode: core.ModeSSB})
+	reporter.QSOAdded(core.QSO{MyNumber: 4, Band: core.Band20m, Mode: core.ModeSSB, Duplicate: true})
+	reporter.ScoreUpdated(core.Score{
+		ScorePerBand: map[core.Band]core.BandScore{
+			core.Band40m: {OtherQSOs: 2, Points: 6, Multis: 2},
+			core.Band20m: {OtherQSOs: 1, Points: 3, Multis: 1},
+		},
+		TotalScore: core.BandScore{OtherQSOs: 3, Points: 9, Multis: 3},
+	})
+
+	reporter.Start()
+	defer reporter.Stop()
+
+	results := server.waitForResults(t, 1)[0]
+	assert.Equal(t, "Test Contest", results.Contest)
+	assert.Equal(t, "DL1ABC", results.Call)
+	assert.Equal(t, "DL2ABC", results.Ops)
+	assert.Equal(t, Class{Power: "LOW", Assisted: "NON-ASSISTED", Transmitter: "ONE", Ops: "SINGLE-OP", Bands: "ALL", Mode: "MIXED"}, results.Class)
+	assert.Equal(t, QTH{DXCCCountry: "DL", CQZone: 14, IARUZone: 28, Grid6: "JN59"}, results.QTH)
+	assert.Equal(t, []dynamicresults.Value{
+		{Band: "total", Mode: "ALL", Value: 3},
+		{Band: "40", Mode: "CW", Value: 2},
+		{Band: "20", Mode: "SSB", Value: 1},
+	}, results.Breakdown.QSOs)
+	assert.Equal(t, []dynamicresults.Value{
+		{Band: "total", Mode: "ALL", Value: 3},
+		{Band: "40", Mode: "ALL", Value: 2},
+		{Band: "20", Mode: "ALL", Value: 1},
+	}, results.Breakdown.Mults)
+	assert.Equal(t, 27, results.Score)
+	assert.Equal(t, "2021-03-14 12:00:00", results.Timestamp)
+}
+
+func TestReporter_OnlyPostsChanges(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	reporter := setupReporter(server.URL)
+	reporter.ScoreUpdated(core.Score{TotalScore: core.BandScore{OtherQSOs: 1, Points: 1, Multis: 1}})
+
+	reporter.Start()
+	defer reporter.Stop()
+	server.waitForResults(t, 1)
+	time.Sleep(5 * reporter.interval)
+	assert.Equal(t, 1, len(server.allResults()))
+
+	reporter.ScoreUpdated(core.Score{TotalScore: core.BandScore{OtherQSOs: 2, Points: 2, Multis: 1}})
+	results := server.waitForResults(t, 2)
+	assert.Equal(t, 2, results[1].Score)
+}
+
+func TestReporter_RetriesAndReportsStatus(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	server.failures = 2
+	reporter := setupReporter(server.URL)
+	var statusMutex sync.Mutex
+	var status []bool
+	reporter.Notify(core.ServiceStatusListenerFunc(func(service core.Service, available bool) {
+		statusMutex.Lock()
+		defer statusMutex.Unlock()
+		assert.Equal(t, core.ScoreReportingService, service)
+		status = append(status, available)
+	}))
+	reporter.ScoreUpdated(core.Score{TotalScore: core.BandScore{OtherQSOs: 1, Points: 1, Multis: 1}})
+
+	reporter.Start()
+	defer reporter.Stop()
+
+	server.waitForResults(t, 1)
+	assert.Eventually(t, func() bool {
+		statusMutex.Lock()
+		defer statusMutex.Unlock()
+		return assert.ObjectsAreEqual([]bool{true}, status)
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, server.requestCount())
+}
+
+func setupReporter(url string) *Reporter {
+	settings := &testSettings{
+		station: core.Station{
+			Callsign: callsign.MustParse("DL1ABC"),
+			Operator: callsign.MustParse("DL2ABC"),
+			Locator:  mustParseLocator("JN59"),
+		},
+		contest: core.Contest{
+			Name:                "Test Contest",
+			CountPerBand:        true,
+			CategoryOperator:    "SINGLE-OP",
+			CategoryAssisted:    "NON-ASSISTED",
+			CategoryBand:        "ALL",
+			CategoryMode:        "MIXED",
+			CategoryPower:       "LOW",
+			CategoryTransmitter: "ONE",
+		},
+	}
+	result := NewReporter(url, 20*time.Millisecond, "test", clock.Static(now), settings, new(testEntities), func(f func()) { f() })
+	result.minBackoff = 5 * time.Millisecond
+	return result
+}
+
+func mustParseLocator(s string) locator.Locator {
+	result, err := locator.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+type testServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	failures int
+	requests int
+	results  []Results
+}
+
+func newTestServer() *testServer {
+	result := new(testServer)
+	result.Server = httptest.NewServer(http.HandlerFunc(result.handle))
+	return result
+}
+
+func (s *testServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests++
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var results Results
+	err := xml.Unmarshal([]byte(r.PostFormValue("xml")), &results)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.results = append(s.results, results)
+}
+
+func (s *testServer) allResults() []Results {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Results{}, s.results...)
+}
+
+func (s *testServer) requestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+func (s *testServer) waitForResults(t *testing.T, count int) []Results {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return len(s.allResults()) >= count
+	}, time.Second, 5*time.Millisecond)
+	return s.allResults()
+}
+
+type testSettings struct {
+	station core.Station
+	contest core.Contest
+}
+
+func (s *testSettings) Station() core.Station {
+	return s.station
+}
+
+func (s *testSettings) Contest() core.Contest {
+	return s.contest
+}
+
+type testEntities struct{}
+
+func (e *testEntities) Find(string) (dxcc.Prefix, bool) {
+	return dxcc.Prefix{Prefix: "DL", PrimaryPrefix: "DL", Continent: "EU", CQZone: 14, ITUZone: 28}, true
+}
@@ -25,24 +25,34 @@ func Load(clock core.Clock, qsos []core.QSO) *Logbook {
 		clock:             clock,
 		writer:            new(nullWriter),
 		qsos:              qsos,
-		myLastNumber:      lastNumber(qsos),
+		myLastNumber:      lastNumber(qsos, 0, 0),
 		rowAddedListeners: make([]RowAddedListener, 0),
 	}
 }
 
-func lastNumber(qsos []core.QSO) int {
+// lastNumber returns the highest number in the given range. A zero boundary means the range is open on that side.
+func lastNumber(qsos []core.QSO, first, last core.QSONumber) int {
 	lastNumber := 0
 	for _, qso := range qsos {
+		if !inRange(qso.MyNumber, first, last) {
+			continue
+		}
 		lastNumber = int(math.Max(float64(lastNumber), float64(qso.MyNumber)))
 	}
 	return lastNumber
 }
 
+func inRange(number, first, last core.QSONumber) bool {
+	return (first == 0 || number >= first) && (last == 0 || number <= last)
+}
+
 type Logbook struct {
 	clock        core.Clock
 	writer       Writer
 	qsos         []core.QSO
 	myLastNumber int
+	firstNumber  core.QSONumber
+	lastNumber   core.QSONumber
 
 	rowAddedListeners []RowAddedListener
 }
@@ -82,8 +92,26 @@ func (l *Logbook) ReplayAll() {
 	}
 }
 
+// SetNumberRange restricts the numbers of the QSOs logged at this station to the given range.
+// This allows several stations to share one log without conflicting numbers. A zero boundary means
+// the range is open on that side.
+func (l *Logbook) SetNumberRange(first, last core.QSONumber) {
+	l.firstNumber = first
+	l.lastNumber = last
+	l.myLastNumber = lastNumber(l.qsos, first, last)
+}
+
+// NextNumber returns the next free number within the range of this station. If all numbers of the range are used,
+// NextNumber returns zero.
 func (l *Logbook) NextNumber() core.QSONumber {
-	return core.QSONumber(l.myLastNumber + 1)
+	if core.QSONumber(l.myLastNumber) < l.firstNumber {
+		return l.firstNumber
+	}
+	result := core.QSONumber(l.myLastNumber + 1)
+	if l.lastNumber != 0 && result > l.lastNumber {
+		return 0
+	}
+	return result
 }
 
 func (l *Logbook) lastQSO() core.QSO {
@@ -107,11 +135,23 @@ func (l *Logbook) LastXchange() string {
 
 func (l *Logbook) Log(qso core.QSO) {
 	qso.LogTimestamp = l.clock.Now()
+	l.add(qso)
+	log.Printf("QSO added: %s", qso.String())
+}
+
+// Import adds a QSO that was logged somewhere else, e.g. at another station. The log timestamp of the QSO is kept.
+func (l *Logbook) Import(qso core.QSO) {
+	l.add(qso)
+	log.Printf("QSO imported: %s", qso.String())
+}
+
+func (l *Logbook) add(qso core.QSO) {
 	l.qsos = append(l.qsos, qso)
-	l.myLastNumber = int(math.Max(float64(l.myLastNumber), float64(qso.MyNumber)))
+	if inRange(qso.MyNumber, l.firstNumber, l.lastNumber) {
+		l.myLastNumber = int(math.Max(float64(l.myLastNumber), float64(qso.MyNumber)))
+	}
 	l.writer.WriteQSO(qso)
 	l.emitRowAdded(qso)
-	log.Printf("QSO added: %s", qso.String())
 }
 
 func (l *Logbook) All() []core.QSO {
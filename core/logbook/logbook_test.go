@@ -102,3 +102,45 @@ func TestLastMode(t *testing.T) {
 	logbook.Log(core.QSO{Callsign: callsign.MustParse("DL1ABC"), MyNumber: 1, Mode: core.ModeDigital})
 	assert.Equal(t, core.ModeDigital, logbook.LastMode())
 }
+
+func TestNumberRange(t *testing.T) {
+	qsos := []core.QSO{
+		{MyNumber: 12},
+		{MyNumber: 1001},
+		{MyNumber: 1002},
+		{MyNumber: 2005},
+	}
+	logbook := Load(clock.New(), qsos)
+
+	logbook.SetNumberRange(1001, 1999)
+	assert.Equal(t, core.QSONumber(1003), logbook.NextNumber())
+
+	logbook.SetNumberRange(3001, 3999)
+	assert.Equal(t, core.QSONumber(3001), logbook.NextNumber())
+
+	logbook.Import(core.QSO{MyNumber: 2006})
+	assert.Equal(t, core.QSONumber(3001), logbook.NextNumber(), "numbers of other stations are not considered")
+	logbook.Log(core.QSO{MyNumber: 3001})
+	assert.Equal(t, core.QSONumber(3002), logbook.NextNumber())
+}
+
+func TestNextNumber_RangeExhausted(t *testing.T) {
+	logbook := New(clock.New())
+	logbook.SetNumberRange(1, 2)
+
+	logbook.Log(core.QSO{MyNumber: logbook.NextNumber()})
+	assert.Equal(t, core.QSONumber(2), logbook.NextNumber())
+	logbook.Log(core.QSO{MyNumber: logbook.NextNumber()})
+	assert.Equal(t, core.QSONumber(0), logbook.NextNumber())
+}
+
+func TestImport_KeepsLogTimestamp(t *testing.T) {
+	now := time.Date(2006, time.January, 2, 15, 4, 5, 6, time.UTC)
+	then := time.Date(2006, time.January, 2, 15, 5, 0, 0, time.UTC)
+	logbook := New(clock.Static(then))
+
+	logbook.Import(core.QSO{MyNumber: 1, LogTimestamp: now})
+
+	require.Equal(t, 1, len(logbook.All()))
+	assert.Equal(t, now, logbook.All()[0].LogTimestamp)
+}
@@ -2,6 +2,7 @@ package logbook
 
 import (
 	"log"
+	"sort"
 
 	"github.com/ftl/hamradio/callsign"
 
@@ -339,6 +340,39 @@ func (l *QSOList) Find(callsign callsign.Callsign, band core.Band, mode core.Mod
 	return result
 }
 
+// XchangeInconsistency contains all QSOs with a station that sent different exchanges.
+type XchangeInconsistency struct {
+	Callsign callsign.Callsign
+	QSOs     []core.QSO
+}
+
+// InconsistentXchanges returns the stations that sent different exchanges in their QSOs, ordered by callsign.
+// QSOs without their exchange are ignored.
+func (l *QSOList) InconsistentXchanges() []XchangeInconsistency {
+	qsosByCallsign := make(map[callsign.Callsign][]core.QSO)
+	for _, qso := range l.list {
+		if qso.TheirXchange == "" {
+			continue
+		}
+		qsosByCallsign[qso.Callsign] = append(qsosByCallsign[qso.Callsign], qso)
+	}
+
+	result := make([]XchangeInconsistency, 0)
+	for call, qsos := range qsosByCallsign {
+		xchange := core.NormalizeXchange(qsos[0].TheirXchange)
+		for _, qso := range qsos[1:] {
+			if core.NormalizeXchange(qso.TheirXchange) != xchange {
+				result = append(result, XchangeInconsistency{Callsign: call, QSOs: qsos})
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Callsign.String() < result[j].Callsign.String()
+	})
+	return result
+}
+
 func (l *QSOList) FindDuplicateQSOs(callsign callsign.Callsign, band core.Band, mode core.Mode) []core.QSO {
 	band, mode = l.dupeBandAndMode(band, mode)
 	numbers := l.dupes.Get(callsign, band, mode)
@@ -361,6 +361,26 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestInconsistentXchanges(t *testing.T) {
+	list := NewQSOList(new(testSettings))
+	aa1zzz := callsign.MustParse("AA1ZZZ")
+	a1bc := callsign.MustParse("A1BC")
+	b1cd := callsign.MustParse("B1CD")
+	list.Put(core.QSO{Callsign: aa1zzz, Band: core.Band40m, TheirXchange: "B12", MyNumber: core.QSONumber(1)})
+	list.Put(core.QSO{Callsign: a1bc, Band: core.Band40m, TheirXchange: "ma", MyNumber: core.QSONumber(2)})
+	list.Put(core.QSO{Callsign: aa1zzz, Band: core.Band20m, TheirXchange: "B13", MyNumber: core.QSONumber(3)})
+	list.Put(core.QSO{Callsign: a1bc, Band: core.Band20m, TheirXchange: " MA ", MyNumber: core.QSONumber(4)})
+	list.Put(core.QSO{Callsign: b1cd, Band: core.Band40m, TheirXchange: "NY", MyNumber: core.QSONumber(5)})
+	list.Put(core.QSO{Callsign: b1cd, Band: core.Band20m, MyNumber: core.QSONumber(6)})
+	list.Put(core.QSO{Callsign: aa1zzz, Band: core.Band10m, TheirXchange: "B12", MyNumber: core.QSONumber(7)})
+
+	inconsistencies := list.InconsistentXchanges()
+
+	require.Equal(t, 1, len(inconsistencies))
+	assert.Equal(t, aa1zzz, inconsistencies[0].Callsign)
+	assert.Equal(t, 3, len(inconsistencies[0].QSOs))
+}
+
 func TestDoNotFindEditedCallsign(t *testing.T) {
 	list := NewQSOList(new(testSettings))
 	aa1zzz := callsign.MustParse("AA1ZZZ")
@@ -284,6 +284,13 @@ func (m *EntryView) SetEditingMarker(active bool) {
 	m.Called(active)
 }
 
+func (m *EntryView) SetXchangeMismatchMarker(active bool) {
+	if !m.active {
+		return
+	}
+	m.Called(active)
+}
+
 func (m *EntryView) ShowMessage(args ...interface{}) {
 	if !m.active {
 		return
@@ -371,6 +378,58 @@ func (m *KeyerView) ShowKeyerSpeed(speed int) {
 	m.Called(speed)
 }
 
+func (m *KeyerView) ShowProgress(sent string, pending string) {
+	m.Called(sent, pending)
+}
+
+func (m *KeyerView) ShowMacroLabels([]string) {
+	return
+}
+
+type Keyer struct {
+	mock.Mock
+}
+
+func (m *Keyer) SendQuestion(q string) {
+	m.Called(q)
+}
+
+func (m *Keyer) Stop() {
+	m.Called()
+}
+
+func (m *Keyer) DecreaseSpeed() {
+	m.Called()
+}
+
+func (m *Keyer) IncreaseSpeed() {
+	m.Called()
+}
+
+func (m *Keyer) Send(index int) {
+	m.Called(index)
+}
+
+func (m *Keyer) SendSlower(index int) {
+	m.Called(index)
+}
+
+func (m *Keyer) ResendSlower() {
+	m.Called()
+}
+
+func (m *Keyer) WorkmodeChanged(workmode core.Workmode) {
+	m.Called(workmode)
+}
+
+func (m *Keyer) AutoCQ() {
+	m.Called()
+}
+
+func (m *Keyer) StopAutoCQ() {
+	m.Called()
+}
+
 type DXCCFinder struct {
 	mock.Mock
 }
@@ -379,3 +438,12 @@ func (m *DXCCFinder) Find(callsign string) (dxcc.Prefix, bool) {
 	args := m.Called(callsign)
 	return args.Get(0).(dxcc.Prefix), args.Get(1).(bool)
 }
+
+type Valuer struct {
+	mock.Mock
+}
+
+func (m *Valuer) Value(callsign callsign.Callsign, entity dxcc.Prefix, band core.Band, mode core.Mode, xchange string) (int, int) {
+	args := m.Called(callsign, entity, band, mode, xchange)
+	return args.Int(0), args.Int(1)
+}
@@ -0,0 +1,230 @@
+package n1mm
+
+import (
+	"encoding/xml"
+	"log"
+	"net"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+	"github.com/ftl/hellocontest/core/so2r"
+)
+
+// DefaultPort is the default UDP port used by N1MM+ for its broadcasts.
+const DefaultPort = 12060
+
+// NewBroadcaster returns a new broadcaster that sends the N1MM+ packets to the given UDP destinations.
+// The station name identifies this instance in the packets.
+func NewBroadcaster(clock core.Clock, settings core.Settings, stationName string, destinations ...string) *Broadcaster {
+	result := &Broadcaster{
+		clock:       clock,
+		station:     settings.Station(),
+		contest:     settings.Contest(),
+		stationName: stationName,
+		radios:      new(nullRadios),
+		sent:        make(map[core.QSONumber]core.QSO),
+	}
+	for _, destination := range destinations {
+		conn, err := net.Dial("udp", destination)
+		if err != nil {
+			log.Printf("Cannot open the N1MM broadcast destination %s: %v", destination, err)
+			continue
+		}
+		result.destinations = append(result.destinations, conn)
+	}
+	return result
+}
+
+// Radios provides the radio with the focus, the transmitting radio, and the VFOs of the radio with the focus.
+type Radios interface {
+	Focus() so2r.Radio
+	TX() so2r.Radio
+	VFOs() core.VFOs
+}
+
+// Broadcaster sends the contacts, the score, and the radio information as UDP broadcasts in the XML format
+// of N1MM+. This allows to use external tools like dashboards and score servers that consume these packets.
+type Broadcaster struct {
+	clock        core.Clock
+	destinations []net.Conn
+	station      core.Station
+	contest      core.Contest
+	stationName  string
+	radios       Radios
+
+	sent    map[core.QSONumber]core.QSO
+	cleared map[core.QSONumber]core.QSO
+
+	frequency core.Frequency
+	mode      core.Mode
+}
+
+// Close closes all connections to the UDP destinations.
+func (b *Broadcaster) Close() {
+	for _, conn := range b.destinations {
+		conn.Close()
+	}
+	b.destinations = nil
+}
+
+// SetRadios sets the radios whose state is sent in the radio info and contact packets.
+func (b *Broadcaster) SetRadios(radios Radios) {
+	if radios == nil {
+		b.radios = new(nullRadios)
+		return
+	}
+	b.radios = radios
+}
+
+func (b *Broadcaster) StationChanged(station core.Station) {
+	b.station = station
+	b.sendRadioInfo()
+}
+
+func (b *Broadcaster) ContestChanged(contest core.Contest) {
+	b.contest = contest
+}
+
+// QSOsCleared keeps track of the QSOs that were sent so far. When they are added again, e.g. when the log is
+// replayed, they are only sent again if they changed in the meantime.
+func (b *Broadcaster) QSOsCleared() {
+	if b.cleared == nil {
+		b.cleared = b.sent
+	} else {
+		for number, qso := range b.sent {
+			b.cleared[number] = qso
+		}
+	}
+	b.sent = make(map[core.QSONumber]core.QSO)
+}
+
+func (b *Broadcaster) QSOAdded(qso core.QSO) {
+	previous, wasCleared := b.cleared[qso.MyNumber]
+	delete(b.cleared, qso.MyNumber)
+	b.sent[qso.MyNumber] = qso
+
+	switch {
+	case !wasCleared:
+		b.send(contactToPacket("contactinfo", qso, b.station, b.contest, b.stationName, b.radios.Focus()))
+	case !sameContact(previous, qso):
+		b.send(contactToPacket("contactreplace", qso, b.station, b.contest, b.stationName, b.radios.Focus()))
+	}
+}
+
+func (b *Broadcaster) QSOUpdated(_ int, _, qso core.QSO) {
+	b.sent[qso.MyNumber] = qso
+	b.send(contactToPacket("contactreplace", qso, b.station, b.contest, b.stationName, b.radios.Focus()))
+}
+
+// ReplayFinished sends a contactdelete packet for every QSO that was sent before the QSOs were cleared and
+// that was not added again afterwards.
+func (b *Broadcaster) ReplayFinished() {
+	for _, qso := range b.cleared {
+		b.send(contactDeleteToPacket(qso, b.station, b.stationName, b.clock.Now()))
+	}
+	b.cleared = nil
+}
+
+func (b *Broadcaster) ScoreUpdated(score core.Score) {
+	b.send(DynamicResults{
+		Contest:   b.contest.Name,
+		Call:      b.station.Callsign.String(),
+		Ops:       b.station.Operator.String(),
+		Breakdown: dynamicresults.NewBreakdown(b.contest, score, nil),
+		Score:     dynamicresults.Score(b.contest, score),
+		Timestamp: b.clock.Now().UTC().Format(timestampFormat),
+	})
+}
+
+func (b *Broadcaster) FrequencyChanged(frequency core.Frequency) {
+	if b.frequency == frequency {
+		return
+	}
+	b.frequency = frequency
+	b.sendRadioInfo()
+}
+
+func (b *Broadcaster) ModeChanged(mode core.Mode) {
+	if b.mode == mode {
+		return
+	}
+	b.mode = mode
+	b.sendRadioInfo()
+}
+
+// FocusChanged sends the radio info of the radio that got the focus.
+func (b *Broadcaster) FocusChanged(so2r.Radio) {
+	b.sendRadioInfo()
+}
+
+// TXChanged sends the radio info with the new transmitting radio.
+func (b *Broadcaster) TXChanged(so2r.Radio) {
+	b.sendRadioInfo()
+}
+
+// sendRadioInfo sends the state of the radio with the focus.
+func (b *Broadcaster) sendRadioInfo() {
+	focus := b.radios.Focus()
+	vfos := b.radios.VFOs()
+	txFrequency := b.frequency
+	if vfos.Split {
+		txFrequency = vfos.TX()
+	}
+	b.send(RadioInfo{
+		App:           appName,
+		StationName:   b.stationName,
+		RadioNr:       radioToN1MM(focus),
+		Freq:          frequencyToN1MM(b.frequency),
+		TXFreq:        frequencyToN1MM(txFrequency),
+		Mode:          string(b.mode),
+		OpCall:        b.station.Operator.String(),
+		IsRunning:     boolToN1MM(false),
+		FocusRadioNr:  radioToN1MM(focus),
+		IsStereo:      boolToN1MM(false),
+		IsSplit:       boolToN1MM(vfos.Split),
+		ActiveRadioNr: radioToN1MM(b.radios.TX()),
+		IsConnected:   boolToN1MM(b.frequency != 0),
+	})
+}
+
+func (b *Broadcaster) send(packet interface{}) {
+	if len(b.destinations) == 0 {
+		return
+	}
+	bytes, err := xml.MarshalIndent(packet, "", "  ")
+	if err != nil {
+		log.Printf("Cannot marshal N1MM packet: %v", err)
+		return
+	}
+	bytes = append([]byte(xml.Header), bytes...)
+	for _, conn := range b.destinations {
+		_, err := conn.Write(bytes)
+		if err != nil {
+			log.Printf("Cannot send N1MM packet to %s: %v", conn.RemoteAddr(), err)
+		}
+	}
+}
+
+// sameContact compares only the fields that are part of the contact packets.
+func sameContact(a, b core.QSO) bool {
+	return a.Callsign == b.Callsign &&
+		a.Time.Equal(b.Time) &&
+		a.Frequency == b.Frequency &&
+		a.TXFrequency == b.TXFrequency &&
+		a.Band == b.Band &&
+		a.Mode == b.Mode &&
+		a.MyReport == b.MyReport &&
+		a.MyNumber == b.MyNumber &&
+		a.MyXchange == b.MyXchange &&
+		a.TheirReport == b.TheirReport &&
+		a.TheirNumber == b.TheirNumber &&
+		a.TheirXchange == b.TheirXchange &&
+		a.Points == b.Points &&
+		a.DXCC.PrimaryPrefix == b.DXCC.PrimaryPrefix
+}
+
+type nullRadios struct{}
+
+func (*nullRadios) Focus() so2r.Radio { return so2r.Radio1 }
+func (*nullRadios) TX() so2r.Radio    { return so2r.Radio1 }
+func (*nullRadios) VFOs() core.VFOs   { return core.VFOs{} }
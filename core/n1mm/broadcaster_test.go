@@ -0,0 +1,203 @@
+package n1mm
+
+import (
+	"encoding/xml"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/clock"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+	"github.com/ftl/hellocontest/core/so2r"
+)
+
+var now = time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+func TestQSOAdded_SendsContactInfo(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+
+	broadcaster.QSOAdded(testQSO(1, "DL2ABC"))
+
+	var packet Contact
+	root := receiver.receive(t, &packet)
+	assert.Equal(t, "contactinfo", root)
+	assert.Equal(t, "DL2ABC", packet.Call)
+	assert.Equal(t, "DL1ABC", packet.MyCall)
+	assert.Equal(t, "7", packet.Band)
+	assert.Equal(t, int64(702550), packet.RXFreq)
+	assert.Equal(t, "CW", packet.Mode)
+	assert.Equal(t, 1, packet.SntNr)
+	assert.Equal(t, "2021-03-14 12:00:00", packet.Timestamp)
+	assert.Equal(t, contactID(callsign.MustParse("DL1ABC"), 1), packet.ID)
+}
+
+func TestQSOUpdated_SendsContactReplace(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+	broadcaster.QSOAdded(testQSO(1, "DL2ABC"))
+	receiver.receive(t, new(Contact))
+
+	broadcaster.QSOUpdated(0, testQSO(1, "DL2ABC"), testQSO(1, "DL2ABD"))
+
+	var packet Contact
+	root := receiver.receive(t, &packet)
+	assert.Equal(t, "contactreplace", root)
+	assert.Equal(t, "DL2ABD", packet.Call)
+	assert.Equal(t, contactID(callsign.MustParse("DL1ABC"), 1), packet.ID)
+}
+
+func TestReplay_OnlySendsChanges(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+	broadcaster.QSOAdded(testQSO(1, "DL2ABC"))
+	broadcaster.QSOAdded(testQSO(2, "DL3ABC"))
+	broadcaster.QSOAdded(testQSO(3, "DL4ABC"))
+	for i := 0; i < 3; i++ {
+		receiver.receive(t, new(Contact))
+	}
+
+	broadcaster.QSOsCleared()
+	broadcaster.QSOAdded(testQSO(1, "DL2ABC"))
+	broadcaster.QSOAdded(testQSO(2, "DL3ABD"))
+	broadcaster.ReplayFinished()
+
+	var replace Contact
+	root := receiver.receive(t, &replace)
+	assert.Equal(t, "contactreplace", root)
+	assert.Equal(t, "DL3ABD", replace.Call)
+
+	var deletion ContactDelete
+	root = receiver.receive(t, &deletion)
+	assert.Equal(t, "contactdelete", root)
+	assert.Equal(t, "DL4ABC", deletion.Call)
+	assert.Equal(t, contactID(callsign.MustParse("DL1ABC"), 3), deletion.ID)
+}
+
+func TestFrequencyChanged_SendsRadioInfo(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+
+	broadcaster.FrequencyChanged(14025000)
+
+	var packet RadioInfo
+	root := receiver.receive(t, &packet)
+	assert.Equal(t, "RadioInfo", root)
+	assert.Equal(t, int64(1402500), packet.Freq)
+	assert.Equal(t, "DL1ABC", packet.OpCall)
+	assert.Equal(t, "True", packet.IsConnected)
+}
+
+func TestRadioInfo_SO2RAndSplit(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+	radios := &testRadios{focus: so2r.Radio2, tx: so2r.Radio1, vfos: core.VFOs{A: 14025000, B: 14027000, Split: true}}
+	broadcaster.SetRadios(radios)
+
+	broadcaster.FrequencyChanged(14025000)
+
+	var packet RadioInfo
+	receiver.receive(t, &packet)
+	assert.Equal(t, 2, packet.RadioNr)
+	assert.Equal(t, 2, packet.FocusRadioNr)
+	assert.Equal(t, 1, packet.ActiveRadioNr)
+	assert.Equal(t, "True", packet.IsSplit)
+	assert.Equal(t, int64(1402500), packet.Freq)
+	assert.Equal(t, int64(1402700), packet.TXFreq)
+
+	radios.tx = so2r.Radio2
+	broadcaster.TXChanged(so2r.Radio2)
+	receiver.receive(t, &packet)
+	assert.Equal(t, 2, packet.ActiveRadioNr)
+
+	broadcaster.QSOAdded(testQSO(1, "DL2ABC"))
+	var contact Contact
+	receiver.receive(t, &contact)
+	assert.Equal(t, 2, contact.RadioNr)
+}
+
+func TestScoreUpdated_SendsDynamicResults(t *testing.T) {
+	broadcaster, receiver := setupBroadcasterTest(t)
+	score := core.Score{
+		ScorePerBand: map[core.Band]core.BandScore{
+			core.Band40m: {OtherQSOs: 2, Points: 6, Multis: 2},
+		},
+		TotalScore:   core.BandScore{OtherQSOs: 2, Points: 6, Multis: 2},
+		OverallScore: core.BandScore{OtherQSOs: 2, Points: 6, Multis: 2},
+	}
+
+	broadcaster.ScoreUpdated(score)
+
+	var packet DynamicResults
+	root := receiver.receive(t, &packet)
+	assert.Equal(t, "dynamicresults", root)
+	assert.Equal(t, 12, packet.Score)
+	assert.Equal(t, []dynamicresults.Value{{Band: "total", Mode: "ALL", Value: 2}, {Band: "40", Mode: "ALL", Value: 2}}, packet.Breakdown.QSOs)
+	assert.Equal(t, []dynamicresults.Value{{Band: "total", Mode: "ALL", Value: 6}, {Band: "40", Mode: "ALL", Value: 6}}, packet.Breakdown.Points)
+}
+
+func setupBroadcasterTest(t *testing.T) (*Broadcaster, *testReceiver) {
+	conn, err := net.ListenPacket("udp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	broadcaster := NewBroadcaster(clock.Static(now), new(testSettings), "TEST", conn.LocalAddr().String())
+	t.Cleanup(broadcaster.Close)
+
+	return broadcaster, &testReceiver{conn: conn}
+}
+
+type testReceiver struct {
+	conn net.PacketConn
+}
+
+func (r *testReceiver) receive(t *testing.T, packet interface{}) string {
+	t.Helper()
+	buffer := make([]byte, 4096)
+	r.conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := r.conn.ReadFrom(buffer)
+	require.NoError(t, err)
+
+	var root struct {
+		XMLName xml.Name
+	}
+	require.NoError(t, xml.Unmarshal(buffer[:n], &root))
+	require.NoError(t, xml.Unmarshal(buffer[:n], packet))
+	return root.XMLName.Local
+}
+
+func testQSO(number core.QSONumber, call string) core.QSO {
+	return core.QSO{
+		Callsign:    callsign.MustParse(call),
+		Time:        now,
+		Frequency:   7025500,
+		Band:        core.Band40m,
+		Mode:        core.ModeCW,
+		MyReport:    "599",
+		MyNumber:    number,
+		TheirReport: "599",
+	}
+}
+
+type testSettings struct{}
+
+func (s *testSettings) Station() core.Station {
+	return core.Station{
+		Callsign: callsign.MustParse("DL1ABC"),
+		Operator: callsign.MustParse("DL1ABC"),
+	}
+}
+
+func (s *testSettings) Contest() core.Contest {
+	return core.Contest{Name: "Test", CountPerBand: true}
+}
+
+type testRadios struct {
+	focus so2r.Radio
+	tx    so2r.Radio
+	vfos  core.VFOs
+}
+
+func (r *testRadios) Focus() so2r.Radio { return r.focus }
+func (r *testRadios) TX() so2r.Radio    { return r.tx }
+func (r *testRadios) VFOs() core.VFOs   { return r.vfos }
@@ -0,0 +1,175 @@
+package n1mm
+
+import (
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/dynamicresults"
+	"github.com/ftl/hellocontest/core/score"
+	"github.com/ftl/hellocontest/core/so2r"
+)
+
+// The packet types are described in the N1MM+ documentation, see https://n1mmwp.hamdocs.com/appendices/external-udp-broadcasts/
+
+const (
+	appName         = "HelloContest"
+	timestampFormat = "2006-01-02 15:04:05"
+)
+
+// Contact is used for the contactinfo and contactreplace packets.
+type Contact struct {
+	XMLName       xml.Name
+	App           string `xml:"app"`
+	ContestName   string `xml:"contestname"`
+	ContestNr     int    `xml:"contestnr"`
+	Timestamp     string `xml:"timestamp"`
+	MyCall        string `xml:"mycall"`
+	Band          string `xml:"band"`
+	RXFreq        int64  `xml:"rxfreq"`
+	TXFreq        int64  `xml:"txfreq"`
+	Operator      string `xml:"operator"`
+	Mode          string `xml:"mode"`
+	Call          string `xml:"call"`
+	CountryPrefix string `xml:"countryprefix"`
+	WPXPrefix     string `xml:"wpxprefix"`
+	StationPrefix string `xml:"stationprefix"`
+	Continent     string `xml:"continent"`
+	Snt           string `xml:"snt"`
+	SntNr         int    `xml:"sntnr"`
+	Rcv           string `xml:"rcv"`
+	RcvNr         int    `xml:"rcvnr"`
+	Exchange1     string `xml:"exchange1"`
+	Zone          int    `xml:"zone"`
+	Points        int    `xml:"points"`
+	RadioNr       int    `xml:"radionr"`
+	IsOriginal    string `xml:"IsOriginal"`
+	StationName   string `xml:"StationName"`
+	ID            string `xml:"ID"`
+	IsClaimedQso  int    `xml:"IsClaimedQso"`
+}
+
+// ContactDelete is used for the contactdelete packet.
+type ContactDelete struct {
+	XMLName     xml.Name `xml:"contactdelete"`
+	App         string   `xml:"app"`
+	Timestamp   string   `xml:"timestamp"`
+	Call        string   `xml:"call"`
+	ContestNr   int      `xml:"contestnr"`
+	StationName string   `xml:"StationName"`
+	ID          string   `xml:"ID"`
+}
+
+// RadioInfo is used for the RadioInfo packet.
+type RadioInfo struct {
+	XMLName       xml.Name `xml:"RadioInfo"`
+	App           string   `xml:"app"`
+	StationName   string   `xml:"StationName"`
+	RadioNr       int      `xml:"RadioNr"`
+	Freq          int64    `xml:"Freq"`
+	TXFreq        int64    `xml:"TXFreq"`
+	Mode          string   `xml:"Mode"`
+	OpCall        string   `xml:"OpCall"`
+	IsRunning     string   `xml:"IsRunning"`
+	FocusRadioNr  int      `xml:"FocusRadioNr"`
+	IsStereo      string   `xml:"IsStereo"`
+	IsSplit       string   `xml:"IsSplit"`
+	ActiveRadioNr int      `xml:"ActiveRadioNr"`
+	IsConnected   string   `xml:"IsConnected"`
+}
+
+// DynamicResults is used for the dynamicresults packet.
+type DynamicResults struct {
+	XMLName   xml.Name                 `xml:"dynamicresults"`
+	Contest   string                   `xml:"contest"`
+	Call      string                   `xml:"call"`
+	Ops       string                   `xml:"ops"`
+	Breakdown dynamicresults.Breakdown `xml:"breakdown"`
+	Score     int                      `xml:"score"`
+	Timestamp string                   `xml:"timestamp"`
+}
+
+func contactToPacket(packetType string, qso core.QSO, station core.Station, contest core.Contest, stationName string, radio so2r.Radio) Contact {
+	return Contact{
+		XMLName:       xml.Name{Local: packetType},
+		App:           appName,
+		ContestName:   contest.Name,
+		Timestamp:     qso.Time.UTC().Format(timestampFormat),
+		MyCall:        station.Callsign.String(),
+		Band:          bandToN1MM(qso.Band),
+		RXFreq:        frequencyToN1MM(qso.Frequency),
+		TXFreq:        frequencyToN1MM(qso.TransmitFrequency()),
+		Operator:      station.Operator.String(),
+		Mode:          string(qso.Mode),
+		Call:          qso.Callsign.String(),
+		CountryPrefix: qso.DXCC.PrimaryPrefix,
+		WPXPrefix:     score.WPXPrefix(qso.Callsign),
+		StationPrefix: station.Callsign.String(),
+		Continent:     qso.DXCC.Continent,
+		Snt:           string(qso.MyReport),
+		SntNr:         int(qso.MyNumber),
+		Rcv:           string(qso.TheirReport),
+		RcvNr:         int(qso.TheirNumber),
+		Exchange1:     qso.TheirXchange,
+		Zone:          int(qso.DXCC.CQZone),
+		Points:        qso.Points,
+		RadioNr:       radioToN1MM(radio),
+		IsOriginal:    "True",
+		StationName:   stationName,
+		ID:            contactID(station.Callsign, qso.MyNumber),
+		IsClaimedQso:  1,
+	}
+}
+
+func contactDeleteToPacket(qso core.QSO, station core.Station, stationName string, now time.Time) ContactDelete {
+	return ContactDelete{
+		App:         appName,
+		Timestamp:   now.UTC().Format(timestampFormat),
+		Call:        qso.Callsign.String(),
+		StationName: stationName,
+		ID:          contactID(station.Callsign, qso.MyNumber),
+	}
+}
+
+// contactID derives a stable ID for the given QSO, so that consumers can match replacements and deletions.
+func contactID(mycall callsign.Callsign, number core.QSONumber) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s-%d", mycall, number))))
+}
+
+// frequencyToN1MM converts the frequency into the N1MM format, which uses a unit of 10Hz.
+func frequencyToN1MM(frequency core.Frequency) int64 {
+	return int64(frequency / 10)
+}
+
+var n1mmBands = map[core.Band]string{
+	core.Band160m: "1.8",
+	core.Band80m:  "3.5",
+	core.Band60m:  "5",
+	core.Band40m:  "7",
+	core.Band30m:  "10",
+	core.Band20m:  "14",
+	core.Band17m:  "18",
+	core.Band15m:  "21",
+	core.Band12m:  "24",
+	core.Band10m:  "28",
+}
+
+func bandToN1MM(band core.Band) string {
+	return n1mmBands[band]
+}
+
+// radioToN1MM returns the number of the given radio, N1MM+ counts the radios from 1.
+func radioToN1MM(radio so2r.Radio) int {
+	return int(radio) + 1
+}
+
+func boolToN1MM(value bool) string {
+	if value {
+		return "True"
+	}
+	return "False"
+}
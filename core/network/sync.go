@@ -0,0 +1,475 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/pb"
+)
+
+// Logbook is the local logbook that is shared with the other stations.
+type Logbook interface {
+	All() []core.QSO
+	Import(core.QSO)
+}
+
+// ConnectionRefusedListener is notified when the connection to another station is refused.
+type ConnectionRefusedListener interface {
+	ConnectionRefused(error)
+}
+
+type ConnectionRefusedListenerFunc func(error)
+
+func (f ConnectionRefusedListenerFunc) ConnectionRefused(err error) {
+	f(err)
+}
+
+// OverlappingNumbersError is the reason to refuse the connection to a station with overlapping QSO numbers.
+type OverlappingNumbersError struct {
+	Station                       string
+	FirstNumber, LastNumber       core.QSONumber
+	OwnFirstNumber, OwnLastNumber core.QSONumber
+}
+
+func (e *OverlappingNumbersError) Error() string {
+	return fmt.Sprintf("the QSO numbers of station %s (%d-%d) overlap with the own QSO numbers (%d-%d), configure disjoint number ranges",
+		e.Station, e.FirstNumber, e.LastNumber, e.OwnFirstNumber, e.OwnLastNumber)
+}
+
+// New returns a new Sync for the station with the given identifier that logs QSOs with numbers in the given range.
+// A zero boundary means the range is open on that side.
+func New(stationID string, firstNumber, lastNumber core.QSONumber, asyncRunner core.AsyncRunner) *Sync {
+	return &Sync{
+		stationID:     stationID,
+		firstNumber:   firstNumber,
+		lastNumber:    lastNumber,
+		asyncRunner:   asyncRunner,
+		retryInterval: 10 * time.Second,
+		logbook:       new(nullLogbook),
+		known:         make(map[core.QSONumber]entry),
+		connections:   make(map[*connection]bool),
+		done:          make(chan struct{}),
+	}
+}
+
+// Sync shares the QSOs of the local logbook with other stations in the local network.
+// Every station logs into its own logbook, using its own range of QSO numbers. New and edited QSOs are
+// sent to all connected stations. If two stations edited the same QSO, the latest edit wins.
+// When a connection is established, both sides introduce themselves with their range of QSO numbers. The connection
+// is refused if the ranges overlap, because the QSOs are identified by their number. Then both sides send all QSOs
+// they know, so stations can join at any time.
+type Sync struct {
+	stationID     string
+	firstNumber   core.QSONumber
+	lastNumber    core.QSONumber
+	asyncRunner   core.AsyncRunner
+	retryInterval time.Duration
+	logbook       Logbook
+
+	listeners []interface{}
+
+	mutex       sync.Mutex
+	known       map[core.QSONumber]entry
+	connections map[*connection]bool
+	listener    net.Listener
+	done        chan struct{}
+}
+
+// entry is the latest known version of a QSO and the station that provided this version.
+type entry struct {
+	qso     pb.QSO
+	station string
+}
+
+func (e entry) newerThan(other entry) bool {
+	if e.qso.LogTimestamp != other.qso.LogTimestamp {
+		return e.qso.LogTimestamp > other.qso.LogTimestamp
+	}
+	return e.station > other.station
+}
+
+// message is sent as one line of JSON over the wire.
+type message struct {
+	Station string `json:"station"`
+	QSO     pb.QSO `json:"qso"`
+}
+
+// hello is the first message sent over the wire. It introduces the station and its range of QSO numbers.
+type hello struct {
+	Station     string         `json:"station"`
+	FirstNumber core.QSONumber `json:"first_number"`
+	LastNumber  core.QSONumber `json:"last_number"`
+}
+
+// overlaps indicates if the number ranges of the two stations overlap. A zero boundary means the range is open on
+// that side.
+func (h hello) overlaps(other hello) bool {
+	lowerBound := func(n core.QSONumber) core.QSONumber {
+		if n < 1 {
+			return 1
+		}
+		return n
+	}
+	upperBound := func(n core.QSONumber) core.QSONumber {
+		if n == 0 {
+			return math.MaxInt32
+		}
+		return n
+	}
+	return lowerBound(h.FirstNumber) <= upperBound(other.LastNumber) && lowerBound(other.FirstNumber) <= upperBound(h.LastNumber)
+}
+
+type connection struct {
+	conn        net.Conn
+	writeMutex  sync.Mutex
+	description string
+}
+
+func (c *connection) send(msg interface{}) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err = c.conn.Write(append(bytes, '\n'))
+	return err
+}
+
+func (s *Sync) Notify(listener interface{}) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// SetLogbook sets the local logbook and sends all its QSOs to the connected stations.
+func (s *Sync) SetLogbook(logbook Logbook) {
+	if logbook == nil {
+		s.logbook = new(nullLogbook)
+	} else {
+		s.logbook = logbook
+	}
+
+	s.mutex.Lock()
+	s.known = make(map[core.QSONumber]entry)
+	for _, qso := range s.logbook.All() {
+		candidate := entry{qso: pb.QSOToPB(qso)}
+		known, ok := s.known[qso.MyNumber]
+		if !ok || !known.newerThan(candidate) {
+			s.known[qso.MyNumber] = candidate
+		}
+	}
+	connections := s.currentConnections()
+	s.mutex.Unlock()
+
+	for _, c := range connections {
+		s.sendAll(c)
+	}
+}
+
+// RowAdded is called for every row that is added to the local logbook.
+func (s *Sync) RowAdded(qso core.QSO) {
+	candidate := entry{qso: pb.QSOToPB(qso), station: s.stationID}
+
+	s.mutex.Lock()
+	known, ok := s.known[qso.MyNumber]
+	if ok && (reflect.DeepEqual(known.qso, candidate.qso) || known.newerThan(candidate)) {
+		s.mutex.Unlock()
+		return
+	}
+	s.known[qso.MyNumber] = candidate
+	connections := s.currentConnections()
+	s.mutex.Unlock()
+
+	s.broadcast(candidate, connections, nil)
+}
+
+// Listen accepts connections from other stations on the given address.
+func (s *Sync) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	s.listener = listener
+	s.mutex.Unlock()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				select {
+				case <-s.done:
+				default:
+					log.Printf("Cannot accept connections from other stations: %v", err)
+				}
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address this Sync is listening on, or nil if it is not listening.
+func (s *Sync) Addr() net.Addr {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Connect keeps a connection to the station at the given address open. If the connection is lost, it retries
+// to connect until the Sync is closed. If the connection is refused because of overlapping QSO numbers, it gives up.
+func (s *Sync) Connect(address string) {
+	go func() {
+		for {
+			conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+			if err == nil {
+				err = s.serve(conn)
+				if err == nil {
+					err = fmt.Errorf("connection closed")
+				}
+			}
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			var overlappingNumbers *OverlappingNumbersError
+			if errors.As(err, &overlappingNumbers) {
+				log.Printf("Giving up to connect to station %s", address)
+				return
+			}
+			log.Printf("Connection to station %s lost, waiting for retry: %v", address, err)
+
+			select {
+			case <-time.After(s.retryInterval):
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close closes all connections to the other stations.
+func (s *Sync) Close() {
+	select {
+	case <-s.done:
+		return
+	default:
+		close(s.done)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for c := range s.connections {
+		c.conn.Close()
+	}
+}
+
+// Connected indicates if at least one other station is connected.
+func (s *Sync) Connected() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.connections) > 0
+}
+
+// serve handles the connection to another station until it is closed. It returns the error that refused the
+// connection, if any.
+func (s *Sync) serve(conn net.Conn) error {
+	c := &connection{conn: conn, description: conn.RemoteAddr().String()}
+	scanner := bufio.NewScanner(conn)
+	err := s.introduce(c, scanner)
+	if err != nil {
+		log.Printf("Cannot connect to station %s: %v", c.description, err)
+		conn.Close()
+		var overlappingNumbers *OverlappingNumbersError
+		if errors.As(err, &overlappingNumbers) {
+			s.asyncRunner(func() {
+				s.emitConnectionRefused(err)
+			})
+		}
+		return err
+	}
+
+	s.mutex.Lock()
+	select {
+	case <-s.done:
+		s.mutex.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	s.connections[c] = true
+	connected := len(s.connections)
+	s.mutex.Unlock()
+	if connected == 1 {
+		s.setConnected(true)
+	}
+	log.Printf("Station %s connected", c.description)
+
+	s.sendAll(c)
+	err = s.receive(c, scanner)
+	if err != nil {
+		log.Printf("Cannot receive from station %s: %v", c.description, err)
+	}
+
+	conn.Close()
+	s.mutex.Lock()
+	delete(s.connections, c)
+	connected = len(s.connections)
+	s.mutex.Unlock()
+	if connected == 0 {
+		s.setConnected(false)
+	}
+	log.Printf("Station %s disconnected", c.description)
+	return nil
+}
+
+// introduce sends the own range of QSO numbers to the other station and checks that the range of the other station
+// does not overlap.
+func (s *Sync) introduce(c *connection, scanner *bufio.Scanner) error {
+	own := hello{Station: s.stationID, FirstNumber: s.firstNumber, LastNumber: s.lastNumber}
+	err := c.send(own)
+	if err != nil {
+		return err
+	}
+
+	if !scanner.Scan() {
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("connection closed")
+		}
+		return err
+	}
+	var other hello
+	err = json.Unmarshal(scanner.Bytes(), &other)
+	if err != nil {
+		return fmt.Errorf("invalid introduction: %v", err)
+	}
+	if own.overlaps(other) {
+		return &OverlappingNumbersError{
+			Station:        other.Station,
+			FirstNumber:    other.FirstNumber,
+			LastNumber:     other.LastNumber,
+			OwnFirstNumber: own.FirstNumber,
+			OwnLastNumber:  own.LastNumber,
+		}
+	}
+	c.description = fmt.Sprintf("%s (%s)", other.Station, c.description)
+	return nil
+}
+
+func (s *Sync) sendAll(c *connection) {
+	s.mutex.Lock()
+	entries := make([]entry, 0, len(s.known))
+	for _, e := range s.known {
+		entries = append(entries, e)
+	}
+	s.mutex.Unlock()
+
+	for _, e := range entries {
+		err := c.send(message{Station: e.station, QSO: e.qso})
+		if err != nil {
+			log.Printf("Cannot send QSOs to station %s: %v", c.description, err)
+			return
+		}
+	}
+}
+
+func (s *Sync) receive(c *connection, scanner *bufio.Scanner) error {
+	for scanner.Scan() {
+		var msg message
+		err := json.Unmarshal(scanner.Bytes(), &msg)
+		if err != nil {
+			log.Printf("Cannot parse message from station %s: %v", c.description, err)
+			continue
+		}
+		s.received(entry{qso: msg.QSO, station: msg.Station}, c)
+	}
+	return scanner.Err()
+}
+
+func (s *Sync) received(candidate entry, source *connection) {
+	qso, err := pb.ToQSO(candidate.qso)
+	if err != nil {
+		log.Printf("Cannot use QSO from station %s: %v", source.description, err)
+		return
+	}
+
+	s.mutex.Lock()
+	known, ok := s.known[qso.MyNumber]
+	if ok && (reflect.DeepEqual(known.qso, candidate.qso) || !candidate.newerThan(known)) {
+		s.mutex.Unlock()
+		return
+	}
+	s.known[qso.MyNumber] = candidate
+	connections := s.currentConnections()
+	s.mutex.Unlock()
+
+	s.broadcast(candidate, connections, source)
+	s.asyncRunner(func() {
+		s.logbook.Import(qso)
+	})
+}
+
+func (s *Sync) currentConnections() []*connection {
+	result := make([]*connection, 0, len(s.connections))
+	for c := range s.connections {
+		result = append(result, c)
+	}
+	return result
+}
+
+func (s *Sync) broadcast(e entry, connections []*connection, except *connection) {
+	msg := message{Station: e.station, QSO: e.qso}
+	for _, c := range connections {
+		if c == except {
+			continue
+		}
+		err := c.send(msg)
+		if err != nil {
+			log.Printf("Cannot send QSO to station %s: %v", c.description, err)
+		}
+	}
+}
+
+func (s *Sync) setConnected(connected bool) {
+	s.asyncRunner(func() {
+		s.emitStatusChanged(connected)
+	})
+}
+
+func (s *Sync) emitStatusChanged(available bool) {
+	for _, listener := range s.listeners {
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.NetworkService, available)
+		}
+	}
+}
+
+func (s *Sync) emitConnectionRefused(err error) {
+	for _, listener := range s.listeners {
+		if connectionRefusedListener, ok := listener.(ConnectionRefusedListener); ok {
+			connectionRefusedListener.ConnectionRefused(err)
+		}
+	}
+}
+
+type nullLogbook struct{}
+
+func (l *nullLogbook) All() []core.QSO { return nil }
+func (l *nullLogbook) Import(core.QSO) {}
@@ -0,0 +1,252 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/clock"
+	"github.com/ftl/hellocontest/core/logbook"
+)
+
+var now = time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+func TestSync_QSOsAreSharedWithAllStations(t *testing.T) {
+	a := setupStation(t, "A", 1, 999)
+	b := setupStation(t, "B", 1001, 1999)
+	c := setupStation(t, "C", 2001, 2999)
+	b.sync.Connect(a.address())
+	c.sync.Connect(b.address())
+
+	a.log("DL1ABC")
+	b.log("DL2ABC")
+	c.log("DL3ABC")
+
+	expected := []string{"1 DL1ABC", "1001 DL2ABC", "2001 DL3ABC"}
+	for _, station := range []*station{a, b, c} {
+		station.assertQSOs(t, expected...)
+	}
+}
+
+func TestSync_LateJoin(t *testing.T) {
+	a := setupStation(t, "A", 1, 999)
+	b := setupStation(t, "B", 1001, 1999)
+	a.log("DL1ABC")
+	a.log("DL2ABC")
+	b.log("DL3ABC")
+
+	b.sync.Connect(a.address())
+
+	a.assertQSOs(t, "1 DL1ABC", "2 DL2ABC", "1001 DL3ABC")
+	b.assertQSOs(t, "1 DL1ABC", "2 DL2ABC", "1001 DL3ABC")
+}
+
+func TestSync_EditsAreShared(t *testing.T) {
+	a := setupStation(t, "A", 1, 999)
+	b := setupStation(t, "B", 1001, 1999)
+	b.sync.Connect(a.address())
+	a.log("DL1ABC")
+	b.assertQSOs(t, "1 DL1ABC")
+
+	b.setTime(now.Add(time.Minute))
+	b.edit(1, "DL1ABD")
+
+	a.assertQSOs(t, "1 DL1ABD")
+	b.assertQSOs(t, "1 DL1ABD")
+}
+
+func TestSync_LatestEditWins(t *testing.T) {
+	a := setupStation(t, "A", 1, 999)
+	b := setupStation(t, "B", 1001, 1999)
+	a.log("DL1ABC")
+	b.sync.Connect(a.address())
+	b.assertQSOs(t, "1 DL1ABC")
+	b.sync.Close()
+	assert.Eventually(t, func() bool { return !a.sync.Connected() }, time.Second, 10*time.Millisecond)
+
+	b.setTime(now.Add(2 * time.Minute))
+	b.edit(1, "DL1ABE")
+	a.setTime(now.Add(time.Minute))
+	a.edit(1, "DL1ABD")
+
+	b2 := New("B", 1001, 1999, b.runAsync)
+	b2.SetLogbook(b.logbook)
+	b.logbook.OnRowAdded(b2.RowAdded)
+	b2.Connect(a.address())
+	defer b2.Close()
+
+	a.assertQSOs(t, "1 DL1ABE")
+	b.assertQSOs(t, "1 DL1ABE")
+}
+
+func TestSync_RefusesOverlappingNumberRanges(t *testing.T) {
+	a := setupStation(t, "A", 1, 999)
+	b := setupStation(t, "B", 500, 1499)
+	a.log("DL1ABC")
+	b.log("DL2ABC")
+
+	b.sync.Connect(a.address())
+
+	a.assertRefused(t, "B")
+	b.assertRefused(t, "A")
+	assert.False(t, a.sync.Connected())
+	assert.False(t, b.sync.Connected())
+	a.assertQSOs(t, "1 DL1ABC")
+	b.assertQSOs(t, "500 DL2ABC")
+}
+
+func TestSync_StopsRetryingWhenRefused(t *testing.T) {
+	a := setupStation(t, "A", 0, 0)
+	b := setupStation(t, "B", 0, 0)
+
+	b.sync.Connect(a.address())
+
+	b.assertRefused(t, "A")
+	time.Sleep(10 * b.sync.retryInterval)
+	assert.Len(t, a.refusedStations(), 1, "connection attempts")
+}
+
+func TestHello_Overlaps(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		a, b     hello
+		expected bool
+	}{
+		{"disjoint", hello{FirstNumber: 1, LastNumber: 999}, hello{FirstNumber: 1000, LastNumber: 1999}, false},
+		{"overlapping", hello{FirstNumber: 1, LastNumber: 1000}, hello{FirstNumber: 1000, LastNumber: 1999}, true},
+		{"both open", hello{}, hello{}, true},
+		{"open end after other range", hello{FirstNumber: 2000}, hello{FirstNumber: 1, LastNumber: 1999}, false},
+		{"open end within other range", hello{FirstNumber: 1000}, hello{FirstNumber: 1, LastNumber: 1999}, true},
+		{"open start", hello{LastNumber: 999}, hello{FirstNumber: 1000}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.a.overlaps(tc.b))
+			assert.Equal(t, tc.expected, tc.b.overlaps(tc.a))
+		})
+	}
+}
+
+type station struct {
+	mutex   sync.Mutex
+	clock   *clock.StaticClock
+	logbook *logbook.Logbook
+	qsoList *logbook.QSOList
+	sync    *Sync
+	refused []string
+}
+
+func setupStation(t *testing.T, id string, first, last core.QSONumber) *station {
+	result := &station{
+		clock: clock.Static(now),
+	}
+	result.logbook = logbook.New(result.clock)
+	result.logbook.SetNumberRange(first, last)
+	result.qsoList = logbook.NewQSOList(new(testSettings))
+	result.logbook.OnRowAdded(result.qsoList.Put)
+
+	result.sync = New(id, first, last, result.runAsync)
+	result.sync.retryInterval = 10 * time.Millisecond
+	result.sync.SetLogbook(result.logbook)
+	result.sync.Notify(ConnectionRefusedListenerFunc(func(err error) {
+		var overlappingNumbers *OverlappingNumbersError
+		if errors.As(err, &overlappingNumbers) {
+			result.refused = append(result.refused, overlappingNumbers.Station)
+		}
+	}))
+	result.logbook.OnRowAdded(result.sync.RowAdded)
+	require.NoError(t, result.sync.Listen("localhost:0"))
+	t.Cleanup(result.sync.Close)
+
+	return result
+}
+
+func (s *station) runAsync(f func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f()
+}
+
+func (s *station) address() string {
+	return s.sync.Addr().String()
+}
+
+func (s *station) log(call string) {
+	s.runAsync(func() {
+		s.logbook.Log(core.QSO{
+			Callsign:    callsign.MustParse(call),
+			Time:        s.clock.Now(),
+			Band:        core.Band40m,
+			Mode:        core.ModeCW,
+			MyReport:    core.RST("599"),
+			MyNumber:    s.logbook.NextNumber(),
+			TheirReport: core.RST("599"),
+		})
+	})
+}
+
+func (s *station) setTime(t time.Time) {
+	s.runAsync(func() {
+		s.clock.Set(t)
+	})
+}
+
+func (s *station) edit(number core.QSONumber, call string) {
+	s.runAsync(func() {
+		for _, qso := range s.qsoList.All() {
+			if qso.MyNumber != number {
+				continue
+			}
+			qso.Callsign = callsign.MustParse(call)
+			s.logbook.Log(qso)
+			return
+		}
+	})
+}
+
+func (s *station) assertQSOs(t *testing.T, expected ...string) {
+	t.Helper()
+	var actual []string
+	assert.Eventually(t, func() bool {
+		s.runAsync(func() {
+			actual = make([]string, 0, len(s.qsoList.All()))
+			for _, qso := range s.qsoList.All() {
+				actual = append(actual, fmt.Sprintf("%d %s", qso.MyNumber, qso.Callsign))
+			}
+		})
+		return assert.ObjectsAreEqual(expected, actual)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *station) refusedStations() []string {
+	var result []string
+	s.runAsync(func() {
+		result = append(result, s.refused...)
+	})
+	return result
+}
+
+func (s *station) assertRefused(t *testing.T, expected string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		refused := s.refusedStations()
+		return len(refused) > 0 && refused[0] == expected
+	}, time.Second, 10*time.Millisecond)
+}
+
+type testSettings struct{}
+
+func (s *testSettings) Station() core.Station {
+	return core.Station{}
+}
+
+func (s *testSettings) Contest() core.Contest {
+	return core.Contest{AllowMultiBand: true, AllowMultiMode: true}
+}
@@ -20,6 +20,7 @@ func ToQSO(pbQSO QSO) (core.QSO, error) {
 	}
 	qso.Time = time.Unix(pbQSO.Timestamp, 0)
 	qso.Frequency = core.Frequency(pbQSO.Frequency)
+	qso.TXFrequency = core.Frequency(pbQSO.TxFrequency)
 	qso.Band, err = parse.Band(pbQSO.Band)
 	if err != nil {
 		return core.QSO{}, err
@@ -49,6 +50,7 @@ func QSOToPB(qso core.QSO) QSO {
 		Callsign:     qso.Callsign.String(),
 		Timestamp:    qso.Time.Unix(),
 		Frequency:    float64(qso.Frequency),
+		TxFrequency:  float64(qso.TXFrequency),
 		Band:         qso.Band.String(),
 		Mode:         qso.Mode.String(),
 		MyReport:     qso.MyReport.String(),
@@ -79,6 +81,7 @@ func ToStation(pbStation Station) (core.Station, error) {
 		log.Printf("Cannot parse station locator: %v", err)
 		station.Locator = locator.Locator{}
 	}
+	station.Region = core.IARURegion(pbStation.Region)
 	return station, nil
 }
 
@@ -87,6 +90,7 @@ func StationToPB(station core.Station) Station {
 		Callsign: station.Callsign.String(),
 		Operator: station.Operator.String(),
 		Locator:  station.Locator.String(),
+		Region:   int32(station.Region),
 	}
 }
 
@@ -111,6 +115,13 @@ func ToContest(pbContest Contest) (core.Contest, error) {
 	contest.XchangeMultiPattern = pbContest.XchangeMultiPattern
 	contest.CountPerBand = pbContest.CountPerBand
 	contest.CabrilloQSOTemplate = pbContest.CabrilloQsoTemplate
+	contest.CategoryOperator = pbContest.CategoryOperator
+	contest.CategoryAssisted = pbContest.CategoryAssisted
+	contest.CategoryBand = pbContest.CategoryBand
+	contest.CategoryMode = pbContest.CategoryMode
+	contest.CategoryPower = pbContest.CategoryPower
+	contest.CategoryTransmitter = pbContest.CategoryTransmitter
+	contest.CategoryOverlay = pbContest.CategoryOverlay
 	return contest, nil
 }
 
@@ -135,6 +146,13 @@ func ContestToPB(contest core.Contest) Contest {
 		XchangeMultiPattern: contest.XchangeMultiPattern,
 		CountPerBand:        contest.CountPerBand,
 		CabrilloQsoTemplate: contest.CabrilloQSOTemplate,
+		CategoryOperator:    contest.CategoryOperator,
+		CategoryAssisted:    contest.CategoryAssisted,
+		CategoryBand:        contest.CategoryBand,
+		CategoryMode:        contest.CategoryMode,
+		CategoryPower:       contest.CategoryPower,
+		CategoryTransmitter: contest.CategoryTransmitter,
+		CategoryOverlay:     contest.CategoryOverlay,
 	}
 }
 
@@ -143,13 +161,57 @@ func ToKeyer(pbKeyer Keyer) (core.Keyer, error) {
 	keyer.WPM = int(pbKeyer.Wpm)
 	keyer.SPMacros = pbKeyer.SpMacros
 	keyer.RunMacros = pbKeyer.RunMacros
+	keyer.ReportCut = core.CutStyle(pbKeyer.ReportCut)
+	keyer.NumberCut = core.CutStyle(pbKeyer.NumberCut)
+	keyer.XchangeCut = core.CutStyle(pbKeyer.XchangeCut)
+	keyer.AutoCQGap = time.Duration(pbKeyer.AutoCqGap) * time.Second
+	keyer.SPLabels = pbKeyer.SpLabels
+	keyer.RunLabels = pbKeyer.RunLabels
+	keyer.MacroSet = pbKeyer.MacroSet
+	keyer.Weight = int(pbKeyer.Weight)
+	keyer.Farnsworth = int(pbKeyer.Farnsworth)
+	keyer.SPSpeeds = toInts(pbKeyer.SpSpeeds)
+	keyer.RunSpeeds = toInts(pbKeyer.RunSpeeds)
 	return keyer, nil
 }
 
 func KeyerToPB(keyer core.Keyer) Keyer {
 	return Keyer{
-		Wpm:       int32(keyer.WPM),
-		SpMacros:  keyer.SPMacros,
-		RunMacros: keyer.RunMacros,
+		Wpm:        int32(keyer.WPM),
+		SpMacros:   keyer.SPMacros,
+		RunMacros:  keyer.RunMacros,
+		ReportCut:  string(keyer.ReportCut),
+		NumberCut:  string(keyer.NumberCut),
+		XchangeCut: string(keyer.XchangeCut),
+		AutoCqGap:  int32(keyer.AutoCQGap / time.Second),
+		SpLabels:   keyer.SPLabels,
+		RunLabels:  keyer.RunLabels,
+		MacroSet:   keyer.MacroSet,
+		Weight:     int32(keyer.Weight),
+		Farnsworth: int32(keyer.Farnsworth),
+		SpSpeeds:   toInt32s(keyer.SPSpeeds),
+		RunSpeeds:  toInt32s(keyer.RunSpeeds),
 	}
 }
+
+func toInts(values []int32) []int {
+	if values == nil {
+		return nil
+	}
+	result := make([]int, len(values))
+	for i, value := range values {
+		result[i] = int(value)
+	}
+	return result
+}
+
+func toInt32s(values []int) []int32 {
+	if values == nil {
+		return nil
+	}
+	result := make([]int32, len(values))
+	for i, value := range values {
+		result[i] = int32(value)
+	}
+	return result
+}
@@ -274,6 +274,7 @@ type QSO struct {
 	MyXchange            string   `protobuf:"bytes,10,opt,name=my_xchange,json=myXchange" json:"my_xchange,omitempty"`
 	TheirXchange         string   `protobuf:"bytes,11,opt,name=their_xchange,json=theirXchange" json:"their_xchange,omitempty"`
 	Frequency            float64  `protobuf:"fixed64,12,opt,name=frequency" json:"frequency,omitempty"`
+	TxFrequency          float64  `protobuf:"fixed64,14,opt,name=tx_frequency,json=txFrequency" json:"tx_frequency,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -387,10 +388,18 @@ func (m *QSO) GetFrequency() float64 {
 	return 0
 }
 
+func (m *QSO) GetTxFrequency() float64 {
+	if m != nil {
+		return m.TxFrequency
+	}
+	return 0
+}
+
 type Station struct {
 	Callsign             string   `protobuf:"bytes,1,opt,name=callsign" json:"callsign,omitempty"`
 	Operator             string   `protobuf:"bytes,2,opt,name=operator" json:"operator,omitempty"`
 	Locator              string   `protobuf:"bytes,3,opt,name=locator" json:"locator,omitempty"`
+	Region               int32    `protobuf:"varint,4,opt,name=region" json:"region,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -441,6 +450,13 @@ func (m *Station) GetLocator() string {
 	return ""
 }
 
+func (m *Station) GetRegion() int32 {
+	if m != nil {
+		return m.Region
+	}
+	return 0
+}
+
 type Contest struct {
 	Name                    string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	EnterTheirNumber        bool     `protobuf:"varint,2,opt,name=enter_their_number,json=enterTheirNumber" json:"enter_their_number,omitempty"`
@@ -457,6 +473,13 @@ type Contest struct {
 	XchangeMultiPattern     string   `protobuf:"bytes,13,opt,name=xchange_multi_pattern,json=xchangeMultiPattern" json:"xchange_multi_pattern,omitempty"`
 	CountPerBand            bool     `protobuf:"varint,14,opt,name=count_per_band,json=countPerBand" json:"count_per_band,omitempty"`
 	CabrilloQsoTemplate     string   `protobuf:"bytes,15,opt,name=cabrillo_qso_template,json=cabrilloQsoTemplate" json:"cabrillo_qso_template,omitempty"`
+	CategoryOperator        string   `protobuf:"bytes,16,opt,name=category_operator,json=categoryOperator" json:"category_operator,omitempty"`
+	CategoryAssisted        string   `protobuf:"bytes,17,opt,name=category_assisted,json=categoryAssisted" json:"category_assisted,omitempty"`
+	CategoryBand            string   `protobuf:"bytes,18,opt,name=category_band,json=categoryBand" json:"category_band,omitempty"`
+	CategoryMode            string   `protobuf:"bytes,19,opt,name=category_mode,json=categoryMode" json:"category_mode,omitempty"`
+	CategoryPower           string   `protobuf:"bytes,20,opt,name=category_power,json=categoryPower" json:"category_power,omitempty"`
+	CategoryTransmitter     string   `protobuf:"bytes,21,opt,name=category_transmitter,json=categoryTransmitter" json:"category_transmitter,omitempty"`
+	CategoryOverlay         string   `protobuf:"bytes,22,opt,name=category_overlay,json=categoryOverlay" json:"category_overlay,omitempty"`
 	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
 	XXX_unrecognized        []byte   `json:"-"`
 	XXX_sizecache           int32    `json:"-"`
@@ -591,6 +614,55 @@ func (m *Contest) GetCabrilloQsoTemplate() string {
 	return ""
 }
 
+func (m *Contest) GetCategoryOperator() string {
+	if m != nil {
+		return m.CategoryOperator
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryAssisted() string {
+	if m != nil {
+		return m.CategoryAssisted
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryBand() string {
+	if m != nil {
+		return m.CategoryBand
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryMode() string {
+	if m != nil {
+		return m.CategoryMode
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryPower() string {
+	if m != nil {
+		return m.CategoryPower
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryTransmitter() string {
+	if m != nil {
+		return m.CategoryTransmitter
+	}
+	return ""
+}
+
+func (m *Contest) GetCategoryOverlay() string {
+	if m != nil {
+		return m.CategoryOverlay
+	}
+	return ""
+}
+
 type Multis struct {
 	Dxcc                 bool     `protobuf:"varint,1,opt,name=dxcc" json:"dxcc,omitempty"`
 	Wpx                  bool     `protobuf:"varint,2,opt,name=wpx" json:"wpx,omitempty"`
@@ -649,6 +721,17 @@ type Keyer struct {
 	Wpm                  int32    `protobuf:"varint,1,opt,name=wpm" json:"wpm,omitempty"`
 	SpMacros             []string `protobuf:"bytes,2,rep,name=sp_macros,json=spMacros" json:"sp_macros,omitempty"`
 	RunMacros            []string `protobuf:"bytes,3,rep,name=run_macros,json=runMacros" json:"run_macros,omitempty"`
+	ReportCut            string   `protobuf:"bytes,4,opt,name=report_cut,json=reportCut" json:"report_cut,omitempty"`
+	NumberCut            string   `protobuf:"bytes,5,opt,name=number_cut,json=numberCut" json:"number_cut,omitempty"`
+	XchangeCut           string   `protobuf:"bytes,6,opt,name=xchange_cut,json=xchangeCut" json:"xchange_cut,omitempty"`
+	AutoCqGap            int32    `protobuf:"varint,7,opt,name=auto_cq_gap,json=autoCqGap" json:"auto_cq_gap,omitempty"`
+	SpLabels             []string `protobuf:"bytes,8,rep,name=sp_labels,json=spLabels" json:"sp_labels,omitempty"`
+	RunLabels            []string `protobuf:"bytes,9,rep,name=run_labels,json=runLabels" json:"run_labels,omitempty"`
+	MacroSet             string   `protobuf:"bytes,10,opt,name=macro_set,json=macroSet" json:"macro_set,omitempty"`
+	Weight               int32    `protobuf:"varint,11,opt,name=weight" json:"weight,omitempty"`
+	Farnsworth           int32    `protobuf:"varint,12,opt,name=farnsworth" json:"farnsworth,omitempty"`
+	SpSpeeds             []int32  `protobuf:"varint,13,rep,packed,name=sp_speeds,json=spSpeeds" json:"sp_speeds,omitempty"`
+	RunSpeeds            []int32  `protobuf:"varint,14,rep,packed,name=run_speeds,json=runSpeeds" json:"run_speeds,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -699,6 +782,83 @@ func (m *Keyer) GetRunMacros() []string {
 	return nil
 }
 
+func (m *Keyer) GetReportCut() string {
+	if m != nil {
+		return m.ReportCut
+	}
+	return ""
+}
+
+func (m *Keyer) GetNumberCut() string {
+	if m != nil {
+		return m.NumberCut
+	}
+	return ""
+}
+
+func (m *Keyer) GetXchangeCut() string {
+	if m != nil {
+		return m.XchangeCut
+	}
+	return ""
+}
+
+func (m *Keyer) GetAutoCqGap() int32 {
+	if m != nil {
+		return m.AutoCqGap
+	}
+	return 0
+}
+
+func (m *Keyer) GetSpLabels() []string {
+	if m != nil {
+		return m.SpLabels
+	}
+	return nil
+}
+
+func (m *Keyer) GetRunLabels() []string {
+	if m != nil {
+		return m.RunLabels
+	}
+	return nil
+}
+
+func (m *Keyer) GetMacroSet() string {
+	if m != nil {
+		return m.MacroSet
+	}
+	return ""
+}
+
+func (m *Keyer) GetWeight() int32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *Keyer) GetFarnsworth() int32 {
+	if m != nil {
+		return m.Farnsworth
+	}
+	return 0
+}
+
+func (m *Keyer) GetSpSpeeds() []int32 {
+	if m != nil {
+		return m.SpSpeeds
+	}
+	return nil
+}
+
+func (m *Keyer) GetRunSpeeds() []int32 {
+	if m != nil {
+		return m.RunSpeeds
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*FileInfo)(nil), "pb.FileInfo")
 	proto.RegisterType((*Entry)(nil), "pb.Entry")
@@ -712,54 +872,71 @@ func init() {
 func init() { proto.RegisterFile("log.proto", fileDescriptor_log_c8171336caaa9927) }
 
 var fileDescriptor_log_c8171336caaa9927 = []byte{
-	// 775 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x55, 0xdd, 0x8e, 0xe3, 0x34,
-	0x14, 0x9e, 0x36, 0x4d, 0x9b, 0x9c, 0xfe, 0xd0, 0x75, 0x35, 0xda, 0xb0, 0xbb, 0x48, 0xb3, 0x01,
-	0x44, 0x2f, 0x50, 0x25, 0x8a, 0xc4, 0x05, 0x97, 0xbb, 0x02, 0x15, 0xd0, 0xb0, 0x33, 0x9e, 0x01,
-	0x21, 0x71, 0x11, 0xa5, 0x19, 0xb7, 0x13, 0x11, 0xdb, 0x19, 0xdb, 0x65, 0x9b, 0xd7, 0xe1, 0x09,
-	0x78, 0x38, 0x1e, 0x00, 0xf9, 0xd8, 0x69, 0xb7, 0x45, 0xe2, 0xce, 0xfe, 0x7e, 0x8e, 0x7d, 0x8e,
-	0xbf, 0xa6, 0x10, 0x57, 0x72, 0xbb, 0xa8, 0x95, 0x34, 0x92, 0x74, 0xeb, 0x75, 0xfa, 0x15, 0x44,
-	0xdf, 0x97, 0x15, 0xfb, 0x41, 0x6c, 0x24, 0xf9, 0x1c, 0x26, 0x1b, 0xa9, 0x78, 0x6e, 0xb2, 0x3f,
-	0x99, 0xd2, 0xa5, 0x14, 0x49, 0xe7, 0xaa, 0x33, 0x0f, 0xe9, 0xd8, 0xa1, 0xbf, 0x3a, 0x30, 0xfd,
-	0xab, 0x03, 0xe1, 0x77, 0xc2, 0xa8, 0x86, 0xbc, 0x84, 0xe0, 0x49, 0x4b, 0x54, 0x0d, 0x97, 0x83,
-	0x45, 0xbd, 0x5e, 0xdc, 0xde, 0xbd, 0x5b, 0x5d, 0x50, 0x8b, 0x92, 0x2f, 0x60, 0xa0, 0x4d, 0x6e,
-	0x6c, 0x99, 0x2e, 0x0a, 0x86, 0x56, 0x70, 0xe7, 0xa0, 0xd5, 0x05, 0x6d, 0x59, 0x2b, 0x2c, 0xa4,
-	0x30, 0x4c, 0x9b, 0x24, 0x38, 0x0a, 0xdf, 0x3a, 0xc8, 0x0a, 0x3d, 0x4b, 0x5e, 0x43, 0xf8, 0x07,
-	0x6b, 0x98, 0x4a, 0x7a, 0x28, 0x8b, 0xad, 0xec, 0x27, 0x0b, 0xac, 0x2e, 0xa8, 0x63, 0xde, 0x0c,
-	0x20, 0x64, 0xf6, 0x6a, 0xe9, 0x3f, 0x5d, 0x08, 0x6e, 0xef, 0xde, 0x91, 0x17, 0x10, 0x15, 0x79,
-	0x55, 0xe9, 0x72, 0xeb, 0xba, 0x89, 0xe9, 0x61, 0x4f, 0x5e, 0x41, 0x6c, 0x4a, 0xce, 0xb4, 0xc9,
-	0x79, 0x8d, 0x77, 0x0c, 0xe8, 0x11, 0x20, 0x04, 0x7a, 0xeb, 0x5c, 0x3c, 0xe0, 0x9d, 0x62, 0x8a,
-	0x6b, 0x8b, 0x71, 0xf9, 0xc0, 0xf0, 0x02, 0x31, 0xc5, 0x35, 0x79, 0x09, 0x31, 0x6f, 0x32, 0xc5,
-	0x6a, 0xa9, 0x4c, 0x12, 0xba, 0x23, 0x78, 0x43, 0x71, 0xef, 0x49, 0xb1, 0xe3, 0x6b, 0xa6, 0x92,
-	0x3e, 0x4e, 0x33, 0xe2, 0xcd, 0xcf, 0xb8, 0x27, 0xaf, 0x61, 0x64, 0x1e, 0x59, 0xa9, 0x5a, 0xf3,
-	0x00, 0xcd, 0x43, 0xc4, 0xbc, 0xff, 0x20, 0xf1, 0x25, 0x22, 0x2c, 0xe1, 0x24, 0xbe, 0xca, 0xa7,
-	0x30, 0xae, 0xe4, 0x36, 0x3b, 0x76, 0x12, 0x63, 0x27, 0xa3, 0x4a, 0x6e, 0xef, 0x0f, 0xcd, 0x7c,
-	0x02, 0xc0, 0x9b, 0x6c, 0x5f, 0x3c, 0xe6, 0x62, 0xcb, 0x12, 0xc0, 0x83, 0x62, 0xde, 0xfc, 0xe6,
-	0x00, 0x5b, 0xc3, 0x1d, 0xd3, 0x2a, 0x86, 0xa8, 0x70, 0x67, 0xb7, 0xa2, 0x57, 0x10, 0x6f, 0x14,
-	0x7b, 0xda, 0x31, 0x51, 0x34, 0xc9, 0xe8, 0xaa, 0x33, 0xef, 0xd0, 0x23, 0xf0, 0x63, 0x2f, 0x1a,
-	0x4f, 0x27, 0xe9, 0xef, 0x30, 0xf0, 0x2f, 0xfc, 0xbf, 0x93, 0x7f, 0x01, 0x91, 0xac, 0x99, 0xca,
-	0x8d, 0x54, 0x38, 0xf8, 0x98, 0x1e, 0xf6, 0x24, 0x81, 0x41, 0x25, 0x0b, 0xa4, 0xdc, 0xe8, 0xdb,
-	0x6d, 0xfa, 0x77, 0x08, 0x03, 0x1f, 0x0b, 0xfb, 0x12, 0x22, 0xe7, 0xcc, 0x57, 0xc6, 0x35, 0xf9,
-	0x12, 0x08, 0x13, 0x86, 0xa9, 0xec, 0x64, 0x64, 0xb6, 0x7e, 0x44, 0xa7, 0xc8, 0xdc, 0x7f, 0x30,
-	0xb7, 0x05, 0xcc, 0x3e, 0x54, 0xb7, 0x9d, 0x07, 0x28, 0x7f, 0x76, 0x94, 0xb7, 0xed, 0x2f, 0xe1,
-	0xd2, 0x36, 0x5b, 0x2a, 0x76, 0xe6, 0xe8, 0xa1, 0x63, 0xe6, 0xc9, 0x13, 0xcf, 0x1c, 0xa6, 0x79,
-	0x55, 0xc9, 0xf7, 0x19, 0xdf, 0x55, 0xa6, 0xcc, 0x30, 0x4f, 0x21, 0xca, 0x27, 0x88, 0x5f, 0x5b,
-	0xf8, 0x8d, 0x4d, 0xd6, 0x99, 0x12, 0x53, 0xd6, 0x3f, 0x57, 0x5e, 0xdb, 0xbc, 0x2d, 0x60, 0xa6,
-	0x73, 0xce, 0xb2, 0x42, 0xee, 0x6c, 0xd2, 0xb3, 0x5a, 0x96, 0xc2, 0x68, 0x0c, 0x4f, 0x48, 0x9f,
-	0x59, 0xea, 0xad, 0x63, 0x6e, 0x90, 0xb0, 0xf7, 0xf6, 0x7a, 0x61, 0x4a, 0xc1, 0x84, 0x69, 0x1d,
-	0x2e, 0x4b, 0x33, 0xe7, 0xf0, 0x9c, 0xf7, 0x7c, 0x03, 0xcf, 0x75, 0xcd, 0x8a, 0x72, 0x53, 0x16,
-	0xe7, 0xe7, 0xc4, 0xe8, 0xba, 0x6c, 0xe9, 0xd3, 0xb3, 0xbe, 0x85, 0x8f, 0xff, 0xeb, 0x53, 0x6c,
-	0x53, 0xee, 0x99, 0x4e, 0xe0, 0x2a, 0x98, 0xc7, 0xf4, 0xf9, 0xb9, 0xd3, 0xd3, 0x36, 0xea, 0xd2,
-	0x3c, 0x32, 0xd5, 0x1e, 0x34, 0x74, 0x51, 0x47, 0xcc, 0x97, 0x4f, 0xa1, 0x8f, 0xe3, 0xd1, 0x18,
-	0xbf, 0xe1, 0x12, 0xec, 0x17, 0x00, 0x27, 0xa3, 0xa9, 0x67, 0x6c, 0xbb, 0xfe, 0x61, 0xfc, 0x28,
-	0xeb, 0xdc, 0x18, 0xa6, 0x44, 0x32, 0xc6, 0xa4, 0xcc, 0x3c, 0x89, 0xae, 0x1b, 0x47, 0x91, 0xcf,
-	0x60, 0x82, 0xb7, 0xcd, 0x6a, 0xa6, 0xdc, 0x23, 0x4d, 0x70, 0xf4, 0x23, 0x44, 0x6f, 0x98, 0xc2,
-	0x27, 0x5a, 0xc2, 0x65, 0x91, 0xaf, 0x55, 0x59, 0x55, 0x32, 0x7b, 0xd2, 0x32, 0x33, 0x8c, 0xd7,
-	0x55, 0x6e, 0x58, 0xf2, 0x91, 0xab, 0xdc, 0x92, 0xb7, 0x5a, 0xde, 0x7b, 0x2a, 0x5d, 0x41, 0xdf,
-	0xdd, 0xcf, 0x06, 0xf6, 0x61, 0x5f, 0x14, 0x18, 0xd8, 0x88, 0xe2, 0x9a, 0x4c, 0x21, 0x78, 0x5f,
-	0xef, 0x7d, 0x42, 0xed, 0xd2, 0x86, 0xff, 0x34, 0x88, 0xed, 0x36, 0xfd, 0x05, 0x42, 0xfc, 0xd6,
-	0x39, 0x13, 0xf7, 0x9f, 0x66, 0xbb, 0xb4, 0x1f, 0x19, 0x5d, 0x67, 0x3c, 0x2f, 0x94, 0xd4, 0x49,
-	0x17, 0xa7, 0x1c, 0xe9, 0xfa, 0x1a, 0xf7, 0xf6, 0x97, 0xaf, 0x76, 0xa2, 0x65, 0x03, 0x64, 0x63,
-	0xb5, 0x13, 0x8e, 0x5e, 0xf7, 0xf1, 0xaf, 0xe0, 0xeb, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x4a,
-	0xfb, 0x41, 0x66, 0x17, 0x06, 0x00, 0x00,
+	// 1047 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x5f, 0x6f, 0xdc, 0x44,
+	0x10, 0x6f, 0xe2, 0xdc, 0x1f, 0xcf, 0x25, 0xd7, 0xcb, 0x5e, 0xd3, 0x9a, 0xb6, 0x94, 0xf4, 0xa0,
+	0x22, 0x08, 0x14, 0xa9, 0x41, 0xe2, 0x81, 0x37, 0x1a, 0x51, 0xc2, 0x9f, 0x90, 0xc4, 0x89, 0x10,
+	0x6f, 0xd6, 0x9e, 0x33, 0x77, 0xb1, 0xb0, 0xbd, 0xce, 0xee, 0x5e, 0x73, 0xfe, 0x24, 0x3c, 0xc3,
+	0xb7, 0xe2, 0xdb, 0xa0, 0x99, 0x5d, 0xfb, 0x72, 0x41, 0xe2, 0xcd, 0xf3, 0xfb, 0xfd, 0x66, 0x77,
+	0x66, 0x76, 0x66, 0xd7, 0x10, 0xe6, 0x6a, 0x7e, 0x58, 0x69, 0x65, 0x95, 0xd8, 0xac, 0xa6, 0x93,
+	0xb7, 0xd0, 0x7f, 0x9f, 0xe5, 0xf8, 0x63, 0x39, 0x53, 0xe2, 0x0d, 0x0c, 0x67, 0x4a, 0x17, 0xd2,
+	0x26, 0x1f, 0x50, 0x9b, 0x4c, 0x95, 0xd1, 0xc6, 0xfe, 0xc6, 0x41, 0x27, 0xde, 0x71, 0xe8, 0x6f,
+	0x0e, 0x9c, 0xfc, 0xbd, 0x01, 0x9d, 0xef, 0x4b, 0xab, 0x6b, 0xf1, 0x02, 0x82, 0x5b, 0xa3, 0x58,
+	0x35, 0x38, 0xea, 0x1d, 0x56, 0xd3, 0xc3, 0x8b, 0xcb, 0xb3, 0x93, 0x47, 0x31, 0xa1, 0xe2, 0x73,
+	0xe8, 0x19, 0x2b, 0x2d, 0x2d, 0xb3, 0xc9, 0x82, 0x01, 0x09, 0x2e, 0x1d, 0x74, 0xf2, 0x28, 0x6e,
+	0x58, 0x12, 0xa6, 0xaa, 0xb4, 0x68, 0x6c, 0x14, 0xac, 0x84, 0xc7, 0x0e, 0x22, 0xa1, 0x67, 0xc5,
+	0x6b, 0xe8, 0xfc, 0x81, 0x35, 0xea, 0x68, 0x8b, 0x65, 0x21, 0xc9, 0x7e, 0x26, 0xe0, 0xe4, 0x51,
+	0xec, 0x98, 0x77, 0x3d, 0xe8, 0x20, 0x85, 0x36, 0xf9, 0x33, 0x80, 0xe0, 0xe2, 0xf2, 0x4c, 0x3c,
+	0x87, 0x7e, 0x2a, 0xf3, 0xdc, 0x64, 0x73, 0x97, 0x4d, 0x18, 0xb7, 0xb6, 0x78, 0x09, 0xa1, 0xcd,
+	0x0a, 0x34, 0x56, 0x16, 0x15, 0xc7, 0x18, 0xc4, 0x2b, 0x40, 0x08, 0xd8, 0x9a, 0xca, 0xf2, 0x9a,
+	0x63, 0x0a, 0x63, 0xfe, 0x26, 0xac, 0x50, 0xd7, 0xc8, 0x01, 0x84, 0x31, 0x7f, 0x8b, 0x17, 0x10,
+	0x16, 0x75, 0xa2, 0xb1, 0x52, 0xda, 0x46, 0x1d, 0xb7, 0x45, 0x51, 0xc7, 0x6c, 0x7b, 0xb2, 0x5c,
+	0x14, 0x53, 0xd4, 0x51, 0x97, 0xab, 0xd9, 0x2f, 0xea, 0x5f, 0xd9, 0x16, 0xaf, 0x61, 0xdb, 0xde,
+	0x60, 0xa6, 0x1b, 0xe7, 0x1e, 0x3b, 0x0f, 0x18, 0xf3, 0xfe, 0xad, 0xc4, 0x2f, 0xd1, 0xe7, 0x25,
+	0x9c, 0xc4, 0xaf, 0xf2, 0x29, 0xec, 0xe4, 0x6a, 0x9e, 0xac, 0x32, 0x09, 0x39, 0x93, 0xed, 0x5c,
+	0xcd, 0xaf, 0xda, 0x64, 0x3e, 0x06, 0x28, 0xea, 0x64, 0x99, 0xde, 0xc8, 0x72, 0x8e, 0x11, 0xf0,
+	0x46, 0x61, 0x51, 0xff, 0xee, 0x00, 0x5a, 0xc3, 0x6d, 0xd3, 0x28, 0x06, 0xac, 0x70, 0x7b, 0x37,
+	0xa2, 0x97, 0x10, 0xce, 0x34, 0xde, 0x2e, 0xb0, 0x4c, 0xeb, 0x68, 0x7b, 0x7f, 0xe3, 0x60, 0x23,
+	0x5e, 0x01, 0x1c, 0xe9, 0x32, 0x59, 0x09, 0x86, 0x2c, 0x18, 0xd8, 0xe5, 0xfb, 0x06, 0xfa, 0x69,
+	0xab, 0xbf, 0x33, 0x1a, 0x4e, 0x0c, 0xf4, 0x7c, 0x13, 0xfc, 0xef, 0xe1, 0x3c, 0x87, 0xbe, 0xaa,
+	0x50, 0x4b, 0xab, 0x34, 0x9f, 0x4d, 0x18, 0xb7, 0xb6, 0x88, 0xa0, 0x97, 0xab, 0x94, 0x29, 0x77,
+	0x3a, 0x8d, 0x29, 0x9e, 0x42, 0x57, 0xe3, 0x9c, 0x7a, 0x6e, 0x8b, 0x2b, 0xe5, 0xad, 0xc9, 0x3f,
+	0x3d, 0xe8, 0xf9, 0x8e, 0xa2, 0x43, 0x2c, 0x65, 0x81, 0x7e, 0x47, 0xfe, 0x16, 0x5f, 0x81, 0xc0,
+	0xd2, 0xa2, 0x4e, 0xd6, 0xaa, 0x4d, 0xfb, 0xf6, 0xe3, 0x11, 0x33, 0x57, 0xf7, 0x4a, 0x7e, 0x08,
+	0xe3, 0xfb, 0xea, 0xa6, 0x68, 0x01, 0xcb, 0x77, 0x57, 0xf2, 0xa6, 0x72, 0x47, 0xb0, 0x47, 0x45,
+	0xc8, 0x34, 0x3e, 0xf0, 0xd8, 0x62, 0x8f, 0xb1, 0x27, 0xd7, 0x7c, 0x0e, 0x60, 0x24, 0xf3, 0x5c,
+	0xdd, 0x25, 0xc5, 0x22, 0xb7, 0x59, 0xc2, 0xad, 0xd8, 0x61, 0xf9, 0x90, 0xf1, 0x53, 0x82, 0xdf,
+	0x51, 0x53, 0x3e, 0x50, 0x72, 0x83, 0x76, 0x1f, 0x2a, 0x4f, 0xa9, 0x55, 0x0f, 0x61, 0x6c, 0x64,
+	0x81, 0x49, 0xaa, 0x16, 0x34, 0x24, 0x49, 0xa5, 0xb2, 0xd2, 0x1a, 0xee, 0xbb, 0x4e, 0xbc, 0x4b,
+	0xd4, 0xb1, 0x63, 0xce, 0x99, 0xa0, 0xb8, 0xbd, 0xbe, 0xb4, 0x59, 0x89, 0xa5, 0x6d, 0x3c, 0x5c,
+	0x1b, 0x8e, 0x9d, 0x87, 0xe7, 0xbc, 0xcf, 0x37, 0xf0, 0xcc, 0x54, 0x98, 0x66, 0xb3, 0x2c, 0x7d,
+	0xb8, 0x4f, 0xc8, 0x5e, 0x7b, 0x0d, 0xbd, 0xbe, 0xd7, 0xb7, 0xf0, 0xd1, 0x7f, 0xfd, 0x34, 0xce,
+	0xb2, 0x25, 0x9a, 0x08, 0xf6, 0x83, 0x83, 0x30, 0x7e, 0xf6, 0xd0, 0xd3, 0xd3, 0xd4, 0x7b, 0xca,
+	0xde, 0xa0, 0x6e, 0x36, 0x1a, 0xb8, 0x29, 0x61, 0xcc, 0x2f, 0x3f, 0x81, 0x2e, 0x97, 0xc7, 0x70,
+	0xe7, 0x0e, 0x8e, 0x80, 0x2e, 0x0f, 0xae, 0x8c, 0x89, 0x3d, 0x43, 0xe9, 0xfa, 0x83, 0xf1, 0xa5,
+	0xac, 0xa4, 0xb5, 0xa8, 0xcb, 0x68, 0x87, 0x3b, 0x65, 0xec, 0x49, 0xf6, 0x3a, 0x77, 0x94, 0xf8,
+	0x0c, 0x86, 0x1c, 0x6d, 0x52, 0xa1, 0x76, 0x87, 0x34, 0xe4, 0xd2, 0x6f, 0x33, 0x7a, 0x8e, 0x9a,
+	0x8f, 0xe8, 0x08, 0xf6, 0x52, 0x39, 0xd5, 0x59, 0x9e, 0xab, 0xe4, 0xd6, 0xa8, 0xc4, 0x62, 0x51,
+	0xe5, 0xd2, 0x62, 0xf4, 0xd8, 0xad, 0xdc, 0x90, 0x17, 0x46, 0x5d, 0x79, 0x4a, 0x7c, 0x09, 0xbb,
+	0xa9, 0xb4, 0x38, 0x57, 0xba, 0x4e, 0xda, 0x49, 0x18, 0xb1, 0x7e, 0xd4, 0x10, 0x67, 0x1e, 0x5f,
+	0x13, 0x4b, 0x63, 0x32, 0x63, 0xf1, 0x3a, 0xda, 0x5d, 0x17, 0x7f, 0xe7, 0x71, 0x9a, 0xf6, 0x56,
+	0xcc, 0x21, 0x0b, 0x37, 0xed, 0x0d, 0xc8, 0x21, 0xdf, 0x17, 0x71, 0x4b, 0x8d, 0xd7, 0x45, 0xdc,
+	0x50, 0x6f, 0x60, 0xd8, 0x8a, 0x2a, 0x75, 0x87, 0x3a, 0x7a, 0xc2, 0xaa, 0xd6, 0xf5, 0x9c, 0x40,
+	0xf1, 0x16, 0x9e, 0xb4, 0x32, 0xab, 0x65, 0x69, 0x8a, 0x8c, 0xaa, 0x17, 0xed, 0x35, 0xd9, 0x3b,
+	0xee, 0x6a, 0x45, 0x89, 0x2f, 0x60, 0xb4, 0xca, 0xfe, 0x03, 0xea, 0x5c, 0xd6, 0xd1, 0x53, 0x96,
+	0x3f, 0x6e, 0x93, 0x77, 0xf0, 0xe4, 0x04, 0xba, 0xee, 0x20, 0x69, 0xb2, 0xaf, 0x97, 0x69, 0xca,
+	0x93, 0xdd, 0x8f, 0xf9, 0x5b, 0x8c, 0x20, 0xb8, 0xab, 0x96, 0x7e, 0x94, 0xe9, 0x93, 0x6e, 0x8f,
+	0xf5, 0x89, 0x6d, 0xcc, 0xc9, 0x5f, 0x01, 0x74, 0xf8, 0x41, 0x71, 0x5e, 0x85, 0x7f, 0xff, 0xe8,
+	0x93, 0x6e, 0x72, 0x53, 0x25, 0x85, 0x4c, 0xb5, 0x32, 0xd1, 0x26, 0xf7, 0x63, 0xdf, 0x54, 0xa7,
+	0x6c, 0xd3, 0xf5, 0xaa, 0x17, 0x65, 0xc3, 0x06, 0xcc, 0x86, 0x7a, 0x51, 0xde, 0xa3, 0xf9, 0x3e,
+	0x4f, 0xd2, 0x85, 0xf5, 0x8f, 0x47, 0xe8, 0x90, 0xe3, 0x85, 0x25, 0xda, 0x5d, 0x38, 0x4c, 0xbb,
+	0x27, 0x24, 0x74, 0x08, 0xd1, 0x9f, 0xc0, 0xa0, 0x69, 0x4b, 0xe2, 0xbb, 0xcc, 0x83, 0x87, 0x48,
+	0xf0, 0x0a, 0x06, 0x72, 0x61, 0x55, 0x92, 0xde, 0x26, 0x73, 0x59, 0xf9, 0x71, 0x0e, 0x09, 0x3a,
+	0xbe, 0xfd, 0x41, 0x56, 0x3e, 0xf4, 0x5c, 0x4e, 0x31, 0xa7, 0xd1, 0xf5, 0xa1, 0xff, 0xc2, 0x76,
+	0x13, 0xba, 0x67, 0xc3, 0x36, 0x74, 0x4f, 0xd3, 0x03, 0x46, 0x49, 0x24, 0x06, 0xad, 0x7f, 0x37,
+	0xfa, 0x0c, 0x5c, 0xa2, 0xa5, 0xdb, 0xf6, 0x0e, 0xb3, 0xf9, 0x8d, 0xf5, 0x13, 0xe7, 0x2d, 0xf1,
+	0x0a, 0x60, 0x26, 0x75, 0x69, 0xee, 0x94, 0xb6, 0x37, 0x3c, 0x70, 0x9d, 0xf8, 0x1e, 0xe2, 0x03,
+	0x32, 0x15, 0xe2, 0xb5, 0x89, 0x76, 0xf6, 0x03, 0x7a, 0x15, 0x4d, 0x75, 0xc9, 0x76, 0x13, 0x90,
+	0x67, 0x87, 0xcc, 0x52, 0x40, 0x8e, 0x9e, 0x76, 0xf9, 0xdf, 0xe5, 0xeb, 0x7f, 0x07, 0x00, 0xe4,
+	0xf0, 0xb2, 0x88, 0xc8, 0x08, 0x00, 0x00,
 }
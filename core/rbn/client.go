@@ -0,0 +1,238 @@
+package rbn
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// DefaultAddress is the address of the CW telnet server of the Reverse Beacon Network.
+const DefaultAddress = "telnet.reversebeacon.net:7000"
+
+// Spot is a single spot received from a skimmer.
+type Spot struct {
+	Skimmer   string
+	Frequency core.Frequency
+	Callsign  callsign.Callsign
+	Mode      core.Mode
+	SNR       int
+	WPM       int
+}
+
+// SpotListener is notified about every spot received from the telnet server.
+type SpotListener interface {
+	SpotReceived(Spot)
+}
+
+type SpotListenerFunc func(Spot)
+
+func (f SpotListenerFunc) SpotReceived(spot Spot) {
+	f(spot)
+}
+
+// SpotAdder collects spots, e.g. the bandmap.
+type SpotAdder interface {
+	Add(core.Spot)
+}
+
+// AddSpotsTo returns a listener that adds every received spot to the given adder.
+func AddSpotsTo(adder SpotAdder) SpotListener {
+	return SpotListenerFunc(func(spot Spot) {
+		adder.Add(core.Spot{
+			Callsign:  spot.Callsign,
+			Frequency: spot.Frequency,
+			Mode:      spot.Mode,
+		})
+	})
+}
+
+// NewClient returns a new client for the telnet server at the given address.
+// The login callsign is sent right after the connection is established.
+func NewClient(address string, login callsign.Callsign, asyncRunner core.AsyncRunner) *Client {
+	return &Client{
+		address:       address,
+		login:         login,
+		asyncRunner:   asyncRunner,
+		retryInterval: 30 * time.Second,
+		done:          make(chan struct{}),
+	}
+}
+
+// Client consumes the spots of a RBN or CW Skimmer telnet server.
+type Client struct {
+	address       string
+	login         callsign.Callsign
+	asyncRunner   core.AsyncRunner
+	retryInterval time.Duration
+	done          chan struct{}
+	closeOnce     sync.Once
+
+	connLock  sync.Mutex
+	conn      net.Conn
+	connected bool
+
+	listeners []interface{}
+}
+
+func (c *Client) Notify(listener interface{}) {
+	c.listeners = append(c.listeners, listener)
+}
+
+func (c *Client) KeepOpen() {
+	go func() {
+		for {
+			conn, err := c.connect()
+			if err == nil {
+				err = c.readSpots(conn)
+				c.setConnected(false)
+			}
+			select {
+			case <-c.done:
+				log.Print("Connection to skimmer server closed.")
+				return
+			default:
+			}
+			log.Printf("Connection to skimmer server lost, waiting for retry: %v", err)
+
+			select {
+			case <-time.After(c.retryInterval):
+				log.Print("Retrying to connect to skimmer server")
+			case <-c.done:
+				log.Print("Connection to skimmer server closed.")
+				return
+			}
+		}
+	}()
+}
+
+func (c *Client) Disconnect() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	c.connLock.Lock()
+	conn := c.conn
+	c.connLock.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func (c *Client) Connected() bool {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+	return c.connected
+}
+
+func (c *Client) connect() (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", c.address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(conn, "%s\r\n", c.login)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.connLock.Lock()
+	c.conn = conn
+	c.connLock.Unlock()
+	select {
+	case <-c.done:
+		conn.Close()
+		return nil, fmt.Errorf("client disconnected")
+	default:
+	}
+	c.setConnected(true)
+	return conn, nil
+}
+
+func (c *Client) readSpots(conn net.Conn) error {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		spot, ok := ParseSpot(scanner.Text())
+		if !ok {
+			continue
+		}
+		c.asyncRunner(func() {
+			c.emitSpotReceived(spot)
+		})
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = fmt.Errorf("connection closed by the server")
+	}
+	return err
+}
+
+func (c *Client) setConnected(connected bool) {
+	c.connLock.Lock()
+	c.connected = connected
+	c.connLock.Unlock()
+	c.asyncRunner(func() {
+		c.emitStatusChanged(connected)
+	})
+}
+
+func (c *Client) emitSpotReceived(spot Spot) {
+	for _, listener := range c.listeners {
+		if spotListener, ok := listener.(SpotListener); ok {
+			spotListener.SpotReceived(spot)
+		}
+	}
+}
+
+func (c *Client) emitStatusChanged(available bool) {
+	for _, listener := range c.listeners {
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.RBNService, available)
+		}
+	}
+}
+
+var spotExpression = regexp.MustCompile(`(?i)^DX de ([A-Z0-9/]+)(?:-[#0-9]+)?:\s+(\d+\.\d+)\s+([A-Z0-9/]+)\s+([A-Z]+)\s+(-?\d+) dB\s+(\d+) (?:WPM|BPS)`)
+
+// ParseSpot parses one line of the telnet output in the RBN format.
+func ParseSpot(line string) (Spot, bool) {
+	matches := spotExpression.FindStringSubmatch(strings.TrimSpace(line))
+	if len(matches) == 0 {
+		return Spot{}, false
+	}
+
+	var result Spot
+	result.Skimmer = strings.ToUpper(matches[1])
+	kHz, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return Spot{}, false
+	}
+	result.Frequency = core.Frequency(kHz * 1000)
+	result.Callsign, err = callsign.Parse(matches[3])
+	if err != nil {
+		return Spot{}, false
+	}
+	result.Mode = toCoreMode(strings.ToUpper(matches[4]))
+	result.SNR, _ = strconv.Atoi(matches[5])
+	result.WPM, _ = strconv.Atoi(matches[6])
+	return result, true
+}
+
+func toCoreMode(mode string) core.Mode {
+	switch mode {
+	case "CW":
+		return core.ModeCW
+	case "RTTY":
+		return core.ModeRTTY
+	default:
+		return core.ModeDigital
+	}
+}
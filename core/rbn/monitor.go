@@ -0,0 +1,183 @@
+package rbn
+
+import (
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/ftl/hamradio/callsign"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
+	"github.com/ftl/hellocontest/core/ticker"
+)
+
+// DefaultWindow is the default time span that is considered for the skimmer reports.
+const DefaultWindow = 10 * time.Minute
+
+type SkimmerReportListener interface {
+	SkimmerReportUpdated(core.SkimmerReport)
+}
+
+type SkimmerReportListenerFunc func(core.SkimmerReport)
+
+func (f SkimmerReportListenerFunc) SkimmerReportUpdated(report core.SkimmerReport) {
+	f(report)
+}
+
+// NewMonitor returns a new monitor that tracks the spots of the given station callsign.
+func NewMonitor(clock core.Clock, asyncRunner core.AsyncRunner, station core.Station) *Monitor {
+	result := &Monitor{
+		clock:       clock,
+		asyncRunner: asyncRunner,
+		bandplan:    bandplans.ForRegion(station.Region),
+		window:      DefaultWindow,
+		callsign:    station.Callsign,
+		receptions:  make(map[core.Band][]reception),
+	}
+	result.refreshTicker = ticker.New(func() {
+		result.asyncRunner(result.Refresh)
+	})
+	return result
+}
+
+// Monitor tracks how often, how strong and at which speed the own signal is spotted by the skimmers.
+type Monitor struct {
+	clock         core.Clock
+	asyncRunner   core.AsyncRunner
+	refreshTicker *ticker.Ticker
+	bandplan      bandplan.Bandplan
+	window        time.Duration
+
+	listeners []interface{}
+
+	callsign   callsign.Callsign
+	band       core.Band
+	receptions map[core.Band][]reception
+}
+
+type reception struct {
+	skimmer string
+	snr     int
+	wpm     int
+	time    time.Time
+}
+
+func (m *Monitor) StartAutoRefresh() {
+	m.refreshTicker.Start()
+}
+
+func (m *Monitor) Notify(listener interface{}) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *Monitor) StationChanged(station core.Station) {
+	m.bandplan = bandplans.ForRegion(station.Region)
+	if m.callsign == station.Callsign {
+		return
+	}
+	m.callsign = station.Callsign
+	m.receptions = make(map[core.Band][]reception)
+	m.emitSkimmerReportUpdated(m.Report(m.band))
+}
+
+func (m *Monitor) BandChanged(band core.Band) {
+	if m.band == band {
+		return
+	}
+	m.band = band
+	m.emitSkimmerReportUpdated(m.Report(m.band))
+}
+
+func (m *Monitor) SpotReceived(spot Spot) {
+	if spot.Callsign != m.callsign {
+		return
+	}
+	band := m.bandplan.ByFrequency(hamradio.Frequency(spot.Frequency))
+	if band.Name == bandplan.BandUnknown {
+		return
+	}
+	spottedBand := core.Band(band.Name)
+
+	m.receptions[spottedBand] = append(m.receptions[spottedBand], reception{
+		skimmer: spot.Skimmer,
+		snr:     spot.SNR,
+		wpm:     spot.WPM,
+		time:    m.clock.Now(),
+	})
+
+	if spottedBand == m.band || m.band == core.NoBand {
+		m.emitSkimmerReportUpdated(m.Report(spottedBand))
+	}
+}
+
+// Refresh removes outdated receptions and emits the report for the current band.
+func (m *Monitor) Refresh() {
+	m.emitSkimmerReportUpdated(m.Report(m.band))
+}
+
+// Report returns the report of the given band, considering only the receptions within the monitoring window.
+// For each skimmer only its latest reception is taken into account.
+func (m *Monitor) Report(band core.Band) core.SkimmerReport {
+	result := core.SkimmerReport{Band: band}
+	m.removeOutdatedReceptions()
+
+	latest := make(map[string]reception)
+	for _, r := range m.receptions[band] {
+		latest[r.skimmer] = r
+		if r.time.After(result.LastHeard) {
+			result.LastHeard = r.time
+		}
+	}
+	if len(latest) == 0 {
+		return result
+	}
+
+	var snrSum, wpmSum int
+	for _, r := range latest {
+		snrSum += r.snr
+		wpmSum += r.wpm
+	}
+	result.Skimmers = len(latest)
+	result.AverageSNR = float64(snrSum) / float64(len(latest))
+	result.AverageWPM = float64(wpmSum) / float64(len(latest))
+	return result
+}
+
+// Reports returns the reports of all bands where the own signal was spotted within the monitoring window.
+func (m *Monitor) Reports() []core.SkimmerReport {
+	result := make([]core.SkimmerReport, 0, len(m.receptions))
+	for _, band := range core.Bands {
+		report := m.Report(band)
+		if report.Skimmers > 0 {
+			result = append(result, report)
+		}
+	}
+	return result
+}
+
+func (m *Monitor) removeOutdatedReceptions() {
+	deadline := m.clock.Now().Add(-m.window)
+	for band, receptions := range m.receptions {
+		firstValid := len(receptions)
+		for i, r := range receptions {
+			if !r.time.Before(deadline) {
+				firstValid = i
+				break
+			}
+		}
+		if firstValid == len(receptions) {
+			delete(m.receptions, band)
+		} else {
+			m.receptions[band] = receptions[firstValid:]
+		}
+	}
+}
+
+func (m *Monitor) emitSkimmerReportUpdated(report core.SkimmerReport) {
+	for _, listener := range m.listeners {
+		if skimmerReportListener, ok := listener.(SkimmerReportListener); ok {
+			skimmerReportListener.SkimmerReportUpdated(report)
+		}
+	}
+}
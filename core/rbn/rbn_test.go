@@ -0,0 +1,148 @@
+package rbn
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/ftl/hamradio/dxcc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandmap"
+	"github.com/ftl/hellocontest/core/clock"
+	"github.com/ftl/hellocontest/core/mocked"
+)
+
+func TestParseSpot(t *testing.T) {
+	spot, ok := ParseSpot("DX de EA8BFK-#:  14023.5  DL1ABC         CW    15 dB  26 WPM  CQ      1201Z")
+
+	assert.True(t, ok)
+	assert.Equal(t, "EA8BFK", spot.Skimmer)
+	assert.Equal(t, core.Frequency(14023500), spot.Frequency)
+	assert.Equal(t, "DL1ABC", spot.Callsign.String())
+	assert.Equal(t, core.ModeCW, spot.Mode)
+	assert.Equal(t, 15, spot.SNR)
+	assert.Equal(t, 26, spot.WPM)
+}
+
+func TestParseSpot_Invalid(t *testing.T) {
+	_, ok := ParseSpot("Please enter your call:")
+	assert.False(t, ok)
+}
+
+func TestMonitor_Report(t *testing.T) {
+	clock := &testClock{now: time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)}
+	monitor := NewMonitor(clock, func(f func()) { f() }, core.Station{Callsign: callsign.MustParse("DL1ABC")})
+	monitor.BandChanged(core.Band20m)
+	var lastReport core.SkimmerReport
+	monitor.Notify(SkimmerReportListenerFunc(func(report core.SkimmerReport) {
+		lastReport = report
+	}))
+
+	monitor.SpotReceived(Spot{Skimmer: "EA8BFK", Frequency: 14023500, Callsign: callsign.MustParse("DL1ABC"), SNR: 10, WPM: 26})
+	monitor.SpotReceived(Spot{Skimmer: "DK9IP", Frequency: 14023600, Callsign: callsign.MustParse("DL1ABC"), SNR: 20, WPM: 24})
+	monitor.SpotReceived(Spot{Skimmer: "EA8BFK", Frequency: 14023500, Callsign: callsign.MustParse("DL1ABC"), SNR: 12, WPM: 26})
+	monitor.SpotReceived(Spot{Skimmer: "OH6BG", Frequency: 7012000, Callsign: callsign.MustParse("DL1ABC"), SNR: 30, WPM: 25})
+	monitor.SpotReceived(Spot{Skimmer: "W3LPL", Frequency: 14023000, Callsign: callsign.MustParse("DL0XYZ"), SNR: 30, WPM: 25})
+
+	assert.Equal(t, core.Band20m, lastReport.Band)
+	assert.Equal(t, 2, lastReport.Skimmers)
+	assert.Equal(t, 16.0, lastReport.AverageSNR)
+	assert.Equal(t, 25.0, lastReport.AverageWPM)
+	assert.Equal(t, 2, len(monitor.Reports()))
+
+	clock.now = clock.now.Add(DefaultWindow + time.Second)
+	assert.Equal(t, 0, monitor.Report(core.Band20m).Skimmers)
+}
+
+func TestClient_ReplayFromServer(t *testing.T) {
+	address, login := startReplayServer(t)
+
+	spots := make(chan Spot, 10)
+	client := NewClient(address, callsign.MustParse("DL1ABC"), func(f func()) { f() })
+	client.Notify(SpotListenerFunc(func(spot Spot) {
+		spots <- spot
+	}))
+	client.KeepOpen()
+	defer client.Disconnect()
+
+	assert.Equal(t, "DL1ABC", <-login)
+	received := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		select {
+		case spot := <-spots:
+			received = append(received, spot.Skimmer)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for spots")
+		}
+	}
+	assert.Equal(t, []string{"EA8BFK", "OH6BG", "DK9IP", "W3LPL"}, received)
+}
+
+func TestClient_AddsSpotsToBandmap(t *testing.T) {
+	address, _ := startReplayServer(t)
+	entities := new(mocked.DXCCFinder)
+	entities.On("Find", mock.Anything).Return(dxcc.Prefix{Prefix: "DL", PrimaryPrefix: "DL"}, true)
+	valuer := new(mocked.Valuer)
+	valuer.On("Value", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, 0)
+	spots := bandmap.New(clock.New(), entities, new(mocked.QSOList), valuer)
+	spots.StationChanged(core.Station{Callsign: callsign.MustParse("DL1ABC")})
+	updated := make(chan []core.AnnotatedSpot, 10)
+	spots.Notify(bandmap.HuntingListUpdatedListenerFunc(func(entries []core.AnnotatedSpot) {
+		updated <- entries
+	}))
+
+	client := NewClient(address, callsign.MustParse("DL1ABC"), func(f func()) { f() })
+	client.Notify(AddSpotsTo(spots))
+	client.KeepOpen()
+	defer client.Disconnect()
+
+	select {
+	case entries := <-updated:
+		require.Len(t, entries, 1)
+		assert.Equal(t, "DL0XYZ", entries[0].Callsign.String())
+		assert.Equal(t, core.Frequency(7012000), entries[0].Frequency)
+		assert.Equal(t, core.Band40m, entries[0].Band)
+		assert.Equal(t, core.ModeCW, entries[0].Mode)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the hunting list")
+	}
+}
+
+// startReplayServer starts a telnet server that sends the content of testdata/replay.txt to the first client. The
+// login of the client is sent to the returned channel.
+func startReplayServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	replay, err := ioutil.ReadFile("testdata/replay.txt")
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	login := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		login <- strings.TrimSpace(line)
+		conn.Write(replay)
+	}()
+	return listener.Addr().String(), login
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
@@ -0,0 +1,405 @@
+package rigsim
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// NewCATServer starts a simulator that provides access to the given radio through the serial CAT protocol of a
+// Kenwood, Yaesu or Icom radio. The simulator uses a pseudo terminal, the client connects to the serial port
+// returned by Port. The CI-V address is only used with the Icom CI-V protocol.
+func NewCATServer(protocol core.CATProtocol, civAddress int, rig *Rig) (*CATServer, error) {
+	var handler catHandler
+	switch protocol {
+	case core.KenwoodCAT:
+		handler = newKenwoodHandler(rig)
+	case core.YaesuCAT:
+		handler = newYaesuHandler(rig)
+	case core.IcomCIV:
+		handler = &civHandler{rig: rig, address: byte(civAddress)}
+	default:
+		return nil, fmt.Errorf("unknown CAT protocol %q", protocol)
+	}
+
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CATServer{
+		handler: handler,
+		master:  master,
+		slave:   slave,
+		powerOn: true,
+	}
+	go result.serve()
+
+	return result, nil
+}
+
+// CATServer simulates the serial CAT interface of a radio.
+type CATServer struct {
+	handler catHandler
+	master  *os.File
+	slave   *os.File
+
+	lock    sync.Mutex
+	latency time.Duration
+	powerOn bool
+}
+
+type catHandler interface {
+	readRequest(*bufio.Reader) ([]byte, error)
+	handle(request []byte) []byte
+}
+
+// Port returns the name of the serial port to connect to.
+func (s *CATServer) Port() string {
+	return s.slave.Name()
+}
+
+// SetLatency delays every response by the given duration.
+func (s *CATServer) SetLatency(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latency = latency
+}
+
+// PowerOff lets the radio ignore all requests, like a radio that is switched off.
+func (s *CATServer) PowerOff() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.powerOn = false
+}
+
+// PowerOn lets the radio respond to requests again.
+func (s *CATServer) PowerOn() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.powerOn = true
+}
+
+func (s *CATServer) currentSettings() (time.Duration, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency, s.powerOn
+}
+
+// Close stops the simulator and closes the serial port.
+func (s *CATServer) Close() {
+	s.master.Close()
+	s.slave.Close()
+}
+
+func (s *CATServer) serve() {
+	r := bufio.NewReader(s.master)
+	for {
+		request, err := s.handler.readRequest(r)
+		if err != nil {
+			return
+		}
+		latency, powerOn := s.currentSettings()
+		if !powerOn {
+			continue
+		}
+		response := s.handler.handle(request)
+		if len(response) == 0 {
+			continue
+		}
+		time.Sleep(latency)
+		_, err = s.master.Write(response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// asciiHandler simulates the ASCII CAT protocol of Kenwood and of the newer Yaesu radios.
+type asciiHandler struct {
+	rig             *Rig
+	frequencyDigits int
+	modeCommand     string
+	modeCodes       map[string]string
+	modes           map[string]string
+	rxCommand       string
+}
+
+func newKenwoodHandler(rig *Rig) *asciiHandler {
+	return &asciiHandler{
+		rig:             rig,
+		frequencyDigits: 11,
+		modeCommand:     "MD",
+		rxCommand:       "RX",
+		modeCodes: map[string]string{
+			ModeLSB:    "1",
+			ModeUSB:    "2",
+			ModeCW:     "3",
+			ModeFM:     "4",
+			"AM":       "5",
+			ModeRTTY:   "6",
+			"CWR":      "7",
+			ModePKTLSB: "1",
+			ModePKTUSB: "2",
+		},
+		modes: map[string]string{
+			"1": ModeLSB,
+			"2": ModeUSB,
+			"3": ModeCW,
+			"4": ModeFM,
+			"5": "AM",
+			"6": ModeRTTY,
+			"7": "CWR",
+			"9": "RTTYR",
+		},
+	}
+}
+
+func newYaesuHandler(rig *Rig) *asciiHandler {
+	return &asciiHandler{
+		rig:             rig,
+		frequencyDigits: 9,
+		modeCommand:     "MD0",
+		rxCommand:       "TX0",
+		modeCodes: map[string]string{
+			ModeLSB:    "1",
+			ModeUSB:    "2",
+			ModeCW:     "3",
+			ModeFM:     "4",
+			"AM":       "5",
+			ModeRTTY:   "6",
+			"CWR":      "7",
+			ModePKTLSB: "8",
+			ModePKTUSB: "C",
+		},
+		modes: map[string]string{
+			"1": ModeLSB,
+			"2": ModeUSB,
+			"3": ModeCW,
+			"4": ModeFM,
+			"5": "AM",
+			"6": ModeRTTY,
+			"7": "CWR",
+			"8": ModePKTLSB,
+			"9": "RTTYR",
+			"C": ModePKTUSB,
+		},
+	}
+}
+
+func (h *asciiHandler) readRequest(r *bufio.Reader) ([]byte, error) {
+	request, err := r.ReadBytes(';')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(request), nil
+}
+
+func (h *asciiHandler) handle(request []byte) []byte {
+	command := strings.TrimSuffix(string(request), ";")
+	state := h.rig.State()
+	switch {
+	case command == "FA":
+		return h.frequencyResponse("FA", state.VFOs.A)
+	case strings.HasPrefix(command, "FA"):
+		if frequency, ok := h.parseFrequency(command[2:]); ok {
+			h.rig.SetFrequency(frequency)
+			return nil
+		}
+	case command == "FB":
+		return h.frequencyResponse("FB", state.VFOs.B)
+	case strings.HasPrefix(command, "FB"):
+		if frequency, ok := h.parseFrequency(command[2:]); ok {
+			h.rig.SetSplitFrequency(frequency)
+			return nil
+		}
+	case command == h.modeCommand:
+		if code, ok := h.modeCodes[state.Mode]; ok {
+			return []byte(h.modeCommand + code + ";")
+		}
+	case strings.HasPrefix(command, h.modeCommand):
+		if mode, ok := h.modes[command[len(h.modeCommand):]]; ok {
+			h.rig.SetMode(mode)
+			return nil
+		}
+	case command == "FT":
+		if state.VFOs.Split {
+			return []byte("FT1;")
+		}
+		return []byte("FT0;")
+	case strings.HasPrefix(command, "FT"):
+		h.rig.SetSplit(command[2:] == "1")
+		return nil
+	case command == h.rxCommand:
+		h.rig.SetPTT(false)
+		return nil
+	case strings.HasPrefix(command, "TX"):
+		h.rig.SetPTT(true)
+		return nil
+	}
+	return []byte("?;")
+}
+
+func (h *asciiHandler) frequencyResponse(command string, frequency core.Frequency) []byte {
+	return []byte(fmt.Sprintf("%s%0*d;", command, h.frequencyDigits, int(frequency)))
+}
+
+func (h *asciiHandler) parseFrequency(digits string) (core.Frequency, bool) {
+	if len(digits) != h.frequencyDigits {
+		return 0, false
+	}
+	frequency, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return core.Frequency(frequency), true
+}
+
+var civModeCodes = map[string]byte{
+	ModeLSB:    0x00,
+	ModeUSB:    0x01,
+	"AM":       0x02,
+	ModeCW:     0x03,
+	ModeRTTY:   0x04,
+	ModeFM:     0x05,
+	"CWR":      0x07,
+	"RTTYR":    0x08,
+	ModePKTLSB: 0x00,
+	ModePKTUSB: 0x01,
+}
+
+var civModes = map[byte]string{
+	0x00: ModeLSB,
+	0x01: ModeUSB,
+	0x02: "AM",
+	0x03: ModeCW,
+	0x04: ModeRTTY,
+	0x05: ModeFM,
+	0x07: "CWR",
+	0x08: "RTTYR",
+}
+
+const (
+	civPreamble   byte = 0xFE
+	civEndOfFrame byte = 0xFD
+	civOK         byte = 0xFB
+	civNG         byte = 0xFA
+)
+
+// civHandler simulates Icom's CI-V protocol. Like on a CI-V bus, every request is echoed back.
+type civHandler struct {
+	rig     *Rig
+	address byte
+}
+
+func (h *civHandler) readRequest(r *bufio.Reader) ([]byte, error) {
+	for {
+		request, err := r.ReadBytes(civEndOfFrame)
+		if err != nil {
+			return nil, err
+		}
+		start := bytes.Index(request, []byte{civPreamble, civPreamble})
+		if start != -1 {
+			return request[start:], nil
+		}
+	}
+}
+
+func (h *civHandler) handle(request []byte) []byte {
+	frame := request[2 : len(request)-1]
+	if len(frame) < 3 || frame[0] != h.address {
+		return request
+	}
+	controller, command, data := frame[1], frame[2], frame[3:]
+	response := func(data ...byte) []byte {
+		result := append([]byte{}, request...)
+		result = append(result, civPreamble, civPreamble, controller, h.address)
+		result = append(result, data...)
+		return append(result, civEndOfFrame)
+	}
+
+	state := h.rig.State()
+	switch command {
+	case 0x03:
+		return response(append([]byte{command}, encodeBCD(state.VFOs.A)...)...)
+	case 0x04:
+		return response(command, civModeCodes[state.Mode], 0x01)
+	case 0x05:
+		if frequency, ok := decodeBCD(data); ok {
+			h.rig.SetFrequency(frequency)
+			return response(civOK)
+		}
+	case 0x06:
+		if len(data) == 0 {
+			break
+		}
+		if mode, ok := civModes[data[0]]; ok {
+			h.rig.SetMode(mode)
+			return response(civOK)
+		}
+	case 0x0F:
+		if len(data) == 0 {
+			split := byte(0x00)
+			if state.VFOs.Split {
+				split = 0x01
+			}
+			return response(command, split)
+		}
+		h.rig.SetSplit(data[0] == 0x01)
+		return response(civOK)
+	case 0x25:
+		if len(data) == 0 {
+			break
+		}
+		if len(data) == 1 && data[0] == 0x00 {
+			return response(append([]byte{command, 0x00}, encodeBCD(state.VFOs.A)...)...)
+		}
+		if len(data) == 1 && data[0] == 0x01 {
+			return response(append([]byte{command, 0x01}, encodeBCD(state.VFOs.B)...)...)
+		}
+		if frequency, ok := decodeBCD(data[1:]); ok && data[0] == 0x01 {
+			h.rig.SetSplitFrequency(frequency)
+			return response(civOK)
+		}
+	case 0x1C:
+		if len(data) == 2 && data[0] == 0x00 {
+			h.rig.SetPTT(data[1] == 0x01)
+			return response(civOK)
+		}
+	}
+	return response(civNG)
+}
+
+func encodeBCD(frequency core.Frequency) []byte {
+	digits := fmt.Sprintf("%010d", int(frequency))
+	result := make([]byte, 5)
+	for i := range result {
+		high := digits[8-2*i] - '0'
+		low := digits[9-2*i] - '0'
+		result[i] = high<<4 | low
+	}
+	return result
+}
+
+func decodeBCD(data []byte) (core.Frequency, bool) {
+	if len(data) != 5 {
+		return 0, false
+	}
+	digits := make([]byte, 10)
+	for i, b := range data {
+		digits[8-2*i] = '0' + b>>4
+		digits[9-2*i] = '0' + b&0x0F
+	}
+	frequency, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return 0, false
+	}
+	return core.Frequency(frequency), true
+}
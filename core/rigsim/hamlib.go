@@ -0,0 +1,250 @@
+package rigsim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// Hamlib result codes
+const (
+	resultOK             = "0"
+	resultInvalidParam   = "-1"
+	resultNotImplemented = "-4"
+)
+
+// NewHamlibServer starts a server that provides access to the given radio through the Hamlib network protocol,
+// like rigctld does. The server listens on the given address. If the address is empty, a random local port is used.
+func NewHamlibServer(address string, rig *Rig) (*HamlibServer, error) {
+	if address == "" {
+		address = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HamlibServer{
+		rig:      rig,
+		listener: listener,
+		conns:    make(map[net.Conn]bool),
+	}
+	go result.acceptLoop()
+
+	return result, nil
+}
+
+// HamlibServer is a rigctld compatible server for a simulated radio.
+type HamlibServer struct {
+	rig      *Rig
+	listener net.Listener
+
+	lock    sync.Mutex
+	conns   map[net.Conn]bool
+	latency time.Duration
+}
+
+// Address returns the address of this server.
+func (s *HamlibServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+// SetLatency delays every response by the given duration.
+func (s *HamlibServer) SetLatency(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latency = latency
+}
+
+func (s *HamlibServer) currentLatency() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency
+}
+
+// Connections returns the number of open client connections.
+func (s *HamlibServer) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.conns)
+}
+
+// Disconnect closes all open client connections. The server still accepts new connections.
+func (s *HamlibServer) Disconnect() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Close stops the server and closes all open client connections.
+func (s *HamlibServer) Close() {
+	s.listener.Close()
+	s.Disconnect()
+}
+
+func (s *HamlibServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.lock.Lock()
+		s.conns[conn] = true
+		s.lock.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+func (s *HamlibServer) serve(conn net.Conn) {
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		conn.Close()
+	}()
+
+	requests := protocol.NewRequestReader(conn)
+	for {
+		request, err := requests.ReadRequest()
+		if err == io.EOF {
+			return
+		}
+		var response string
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("rigsim: cannot read hamlib request: %v", err)
+			response = "RPRT " + resultNotImplemented
+		} else {
+			response = s.handle(request)
+		}
+
+		time.Sleep(s.currentLatency())
+		_, err = fmt.Fprintln(conn, response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *HamlibServer) handle(request protocol.Request) string {
+	response := protocol.Response{
+		Command: request.Key(),
+		Result:  resultOK,
+	}
+	addData := func(key, value string) {
+		response.Keys = append(response.Keys, key)
+		response.Data = append(response.Data, value)
+	}
+
+	if len(request.Args) < request.Command.Args {
+		response.Result = resultInvalidParam
+		return formatResponse(request, response)
+	}
+
+	state := s.rig.State()
+	switch request.Long {
+	case "get_freq":
+		addData("Frequency", formatFrequency(state.VFOs.A))
+	case "set_freq":
+		frequency, ok := parseFrequency(request.Args[0])
+		if !ok {
+			response.Result = resultInvalidParam
+			break
+		}
+		s.rig.SetFrequency(frequency)
+	case "get_mode":
+		addData("Mode", state.Mode)
+		addData("Passband", strconv.Itoa(passband(state.Mode)))
+	case "set_mode":
+		s.rig.SetMode(request.Args[0])
+	case "get_vfo":
+		addData("VFO", "VFOA")
+	case "get_split_vfo":
+		split := "0"
+		if state.VFOs.Split {
+			split = "1"
+		}
+		addData("Split", split)
+		addData("TX VFO", "VFOB")
+	case "set_split_vfo":
+		s.rig.SetSplit(request.Args[0] == "1")
+	case "get_split_freq":
+		addData("TX Frequency", formatFrequency(state.VFOs.B))
+	case "set_split_freq":
+		frequency, ok := parseFrequency(request.Args[0])
+		if !ok {
+			response.Result = resultInvalidParam
+			break
+		}
+		s.rig.SetSplitFrequency(frequency)
+	case "get_ptt":
+		ptt := "0"
+		if state.PTT {
+			ptt = "1"
+		}
+		addData("PTT", ptt)
+	case "set_ptt":
+		s.rig.SetPTT(request.Args[0] != "0")
+	case "vfo_op":
+		switch request.Args[0] {
+		case "BAND_UP":
+			s.rig.BandUp()
+		case "BAND_DOWN":
+			s.rig.BandDown()
+		default:
+			response.Result = resultNotImplemented
+		}
+	default:
+		response.Result = resultNotImplemented
+	}
+
+	return formatResponse(request, response)
+}
+
+func formatResponse(request protocol.Request, response protocol.Response) string {
+	if request.ExtendedSeparator != "" {
+		return response.ExtendedFormat(request.ExtendedSeparator)
+	}
+	if len(response.Data) == 0 || response.Result != resultOK {
+		return response.Format()
+	}
+	return response.Format() + "\nRPRT " + response.Result
+}
+
+func formatFrequency(frequency core.Frequency) string {
+	return strconv.Itoa(int(frequency))
+}
+
+func parseFrequency(s string) (core.Frequency, bool) {
+	frequency, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return core.Frequency(frequency), true
+}
+
+func passband(mode string) int {
+	switch mode {
+	case ModeCW:
+		return 500
+	case ModeFM:
+		return 12000
+	default:
+		return 2400
+	}
+}
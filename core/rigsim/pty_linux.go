@@ -0,0 +1,65 @@
+//go:build linux
+// +build linux
+
+package rigsim
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY opens a new pseudo terminal pair. The simulator uses the master side, the slave side is the serial port
+// for the client. The returned slave file keeps the slave side open, so that the client can close and reopen the
+// port without hanging up the master side.
+func openPTY() (master *os.File, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ptyNumber uint32
+	err = control(master, func(fd uintptr) error {
+		var unlock int32
+		err := ioctl(fd, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock))
+		if err != nil {
+			return err
+		}
+		return ioctl(fd, syscall.TIOCGPTN, unsafe.Pointer(&ptyNumber))
+	})
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", ptyNumber), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+func control(file *os.File, f func(fd uintptr) error) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var controlErr error
+	err = conn.Control(func(fd uintptr) {
+		controlErr = f(fd)
+	})
+	if err != nil {
+		return err
+	}
+	return controlErr
+}
+
+func ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
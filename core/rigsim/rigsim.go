@@ -0,0 +1,232 @@
+// Package rigsim simulates a radio that is controlled through the Hamlib network protocol or through TCI.
+// It is meant to exercise the radio integration without a real radio, e.g. in integration tests.
+package rigsim
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// Modes of the simulated radio, in Hamlib notation.
+const (
+	ModeCW     = "CW"
+	ModeUSB    = "USB"
+	ModeLSB    = "LSB"
+	ModeFM     = "FM"
+	ModeRTTY   = "RTTY"
+	ModePKTUSB = "PKTUSB"
+	ModePKTLSB = "PKTLSB"
+)
+
+// State is the state of the simulated radio.
+type State struct {
+	VFOs core.VFOs
+	Mode string
+	PTT  bool
+}
+
+// StateChangedListener is notified when the state of the simulated radio changes.
+type StateChangedListener interface {
+	StateChanged(State)
+}
+
+type StateChangedListenerFunc func(State)
+
+func (f StateChangedListenerFunc) StateChanged(state State) {
+	f(state)
+}
+
+// NewRig returns a new simulated radio that is tuned to the given frequency and mode on VFO A.
+func NewRig(frequency core.Frequency, mode string) *Rig {
+	return &Rig{
+		state: State{
+			VFOs: core.VFOs{A: frequency, B: frequency},
+			Mode: mode,
+		},
+	}
+}
+
+// Rig is a simulated radio with two VFOs and split operation. It is safe for concurrent use.
+type Rig struct {
+	lock  sync.Mutex
+	state State
+	cw    []string
+
+	listeners []interface{}
+}
+
+func (r *Rig) Notify(listener interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// State returns the current state of the radio.
+func (r *Rig) State() State {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.state
+}
+
+// Frequency returns the frequency of VFO A.
+func (r *Rig) Frequency() core.Frequency {
+	return r.State().VFOs.A
+}
+
+// SetFrequency tunes VFO A to the given frequency.
+func (r *Rig) SetFrequency(frequency core.Frequency) {
+	r.update(func(state *State) {
+		state.VFOs.A = frequency
+	})
+}
+
+// SplitFrequency returns the frequency of VFO B.
+func (r *Rig) SplitFrequency() core.Frequency {
+	return r.State().VFOs.B
+}
+
+// SetSplitFrequency tunes VFO B to the given frequency.
+func (r *Rig) SetSplitFrequency(frequency core.Frequency) {
+	r.update(func(state *State) {
+		state.VFOs.B = frequency
+	})
+}
+
+// Split indicates if the radio transmits on VFO B.
+func (r *Rig) Split() bool {
+	return r.State().VFOs.Split
+}
+
+// SetSplit switches the split operation on or off.
+func (r *Rig) SetSplit(split bool) {
+	r.update(func(state *State) {
+		state.VFOs.Split = split
+	})
+}
+
+// Mode returns the current mode in Hamlib notation.
+func (r *Rig) Mode() string {
+	return r.State().Mode
+}
+
+// SetMode sets the current mode, given in Hamlib notation.
+func (r *Rig) SetMode(mode string) {
+	r.update(func(state *State) {
+		state.Mode = mode
+	})
+}
+
+// PTT indicates if the radio transmits.
+func (r *Rig) PTT() bool {
+	return r.State().PTT
+}
+
+// SetPTT keys or releases the transmitter.
+func (r *Rig) SetPTT(on bool) {
+	r.update(func(state *State) {
+		state.PTT = on
+	})
+}
+
+// BandUp tunes VFO A to the center of the next higher band. On the highest band, the frequency is not changed.
+func (r *Rig) BandUp() {
+	r.update(func(state *State) {
+		bands := sortedBands()
+		for _, band := range bands {
+			if band.From > hamradio.Frequency(state.VFOs.A) {
+				state.VFOs.A = core.Frequency(band.Center())
+				return
+			}
+		}
+	})
+}
+
+// BandDown tunes VFO A to the center of the next lower band. On the lowest band, the frequency is not changed.
+func (r *Rig) BandDown() {
+	r.update(func(state *State) {
+		bands := sortedBands()
+		for i := len(bands) - 1; i >= 0; i-- {
+			if bands[i].To < hamradio.Frequency(state.VFOs.A) {
+				state.VFOs.A = core.Frequency(bands[i].Center())
+				return
+			}
+		}
+	})
+}
+
+// SendCW records the given text as transmitted in CW.
+func (r *Rig) SendCW(text string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cw = append(r.cw, text)
+}
+
+// CW returns all texts that were transmitted in CW so far.
+func (r *Rig) CW() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.cw...)
+}
+
+func (r *Rig) update(f func(*State)) {
+	r.lock.Lock()
+	before := r.state
+	f(&r.state)
+	after := r.state
+	listeners := r.listeners
+	r.lock.Unlock()
+
+	if before == after {
+		return
+	}
+	for _, listener := range listeners {
+		if stateChangedListener, ok := listener.(StateChangedListener); ok {
+			stateChangedListener.StateChanged(after)
+		}
+	}
+}
+
+func sortedBands() []bandplan.Band {
+	result := make([]bandplan.Band, 0, len(bandplan.IARURegion1))
+	for _, band := range bandplan.IARURegion1 {
+		result = append(result, band)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].From < result[j].From
+	})
+	return result
+}
+
+// Step is one step of a script.
+type Step func()
+
+// Wait pauses the script for the given duration.
+func Wait(d time.Duration) Step {
+	return func() {
+		time.Sleep(d)
+	}
+}
+
+// Do executes the given function as a step of a script, e.g. a frequency change or a disconnect.
+func Do(f func()) Step {
+	return Step(f)
+}
+
+// Play executes the given steps one after another in the background. The returned channel is closed when all steps
+// are done.
+func Play(steps ...Step) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, step := range steps {
+			step()
+		}
+	}()
+	return done
+}
@@ -0,0 +1,58 @@
+package rigsim
+
+import (
+	"testing"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestRig_BandUpAndDown(t *testing.T) {
+	rig := NewRig(7010000, ModeCW)
+
+	rig.BandUp()
+	assert.Equal(t, core.Frequency(10125000), rig.Frequency())
+
+	rig.BandDown()
+	rig.BandDown()
+	assert.Equal(t, core.Frequency(5359000), rig.Frequency())
+}
+
+func TestRig_NotifiesOnlyChanges(t *testing.T) {
+	rig := NewRig(7010000, ModeCW)
+	var states []State
+	rig.Notify(StateChangedListenerFunc(func(state State) {
+		states = append(states, state)
+	}))
+
+	rig.SetFrequency(7010000)
+	rig.SetMode(ModeUSB)
+	rig.SetSplit(true)
+
+	assert.Equal(t, []State{
+		{VFOs: core.VFOs{A: 7010000, B: 7010000}, Mode: ModeUSB},
+		{VFOs: core.VFOs{A: 7010000, B: 7010000, Split: true}, Mode: ModeUSB},
+	}, states)
+}
+
+func TestUnescapeCWText(t *testing.T) {
+	assert.Equal(t, "", unescapeCWText("_"))
+	assert.Equal(t, "5nn 001, tu; de:", unescapeCWText("5nn 001~ tu* de^"))
+}
+
+func TestHamlibServer_MissingArguments(t *testing.T) {
+	server := &HamlibServer{rig: NewRig(7010000, ModeCW)}
+	for _, command := range []string{"F", "M", "S", "V", "set_split_freq", "vfo_op"} {
+		t.Run(command, func(t *testing.T) {
+			request := protocol.Request{Command: protocol.ShortCommand(command)}
+			if len(command) > 1 {
+				request.Command = protocol.LongCommand(command)
+			}
+
+			assert.Equal(t, "RPRT "+resultInvalidParam, server.handle(request))
+		})
+	}
+	assert.Equal(t, core.Frequency(7010000), server.rig.Frequency())
+}
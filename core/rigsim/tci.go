@@ -0,0 +1,343 @@
+package rigsim
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+var tciModes = map[string]string{
+	ModeCW:     "cw",
+	ModeUSB:    "usb",
+	ModeLSB:    "lsb",
+	ModeFM:     "nfm",
+	ModeRTTY:   "digu",
+	ModePKTUSB: "digu",
+	ModePKTLSB: "digl",
+}
+
+var hamlibModes = map[string]string{
+	"cw":   ModeCW,
+	"usb":  ModeUSB,
+	"lsb":  ModeLSB,
+	"nfm":  ModeFM,
+	"digu": ModePKTUSB,
+	"digl": ModePKTLSB,
+}
+
+// NewTCIServer starts a server that provides access to the given radios through the TCI protocol. The index of a
+// radio is its TRX number. The server listens on the given address. If the address is empty, a random local port is used.
+func NewTCIServer(address string, rigs ...*Rig) (*TCIServer, error) {
+	if address == "" {
+		address = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TCIServer{
+		rigs:     rigs,
+		listener: listener,
+		conns:    make(map[*tciConn]bool),
+		states:   make([]State, len(rigs)),
+		cwSpeed:  25,
+	}
+	result.upgrader.CheckOrigin = func(*http.Request) bool { return true }
+	result.server = &http.Server{Handler: http.HandlerFunc(result.serveWebsocket)}
+	for i, rig := range rigs {
+		trx := i
+		result.states[trx] = rig.State()
+		rig.Notify(StateChangedListenerFunc(func(state State) {
+			result.stateChanged(trx, state)
+		}))
+	}
+	go result.server.Serve(listener)
+
+	return result, nil
+}
+
+// TCIServer is a TCI server for simulated radios.
+type TCIServer struct {
+	rigs     []*Rig
+	listener net.Listener
+	server   *http.Server
+	upgrader websocket.Upgrader
+
+	lock    sync.Mutex
+	conns   map[*tciConn]bool
+	states  []State
+	latency time.Duration
+	cwSpeed int
+}
+
+type tciConn struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+}
+
+func (c *tciConn) write(messages ...string) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	for _, message := range messages {
+		err := c.conn.WriteMessage(websocket.TextMessage, []byte(message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Address returns the address of this server.
+func (s *TCIServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+// SetLatency delays every reply to a command by the given duration.
+func (s *TCIServer) SetLatency(latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latency = latency
+}
+
+func (s *TCIServer) currentLatency() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency
+}
+
+// CWSpeed returns the current speed for CW macros in WpM.
+func (s *TCIServer) CWSpeed() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cwSpeed
+}
+
+// Connections returns the number of open client connections.
+func (s *TCIServer) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.conns)
+}
+
+// Disconnect closes all open client connections. The server still accepts new connections.
+func (s *TCIServer) Disconnect() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for conn := range s.conns {
+		conn.conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Close stops the server and closes all open client connections.
+func (s *TCIServer) Close() {
+	s.server.Close()
+	s.Disconnect()
+}
+
+func (s *TCIServer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("rigsim: cannot upgrade to websocket: %v", err)
+		return
+	}
+	conn := &tciConn{conn: wsConn}
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		wsConn.Close()
+	}()
+
+	s.lock.Lock()
+	s.conns[conn] = true
+	initialMessages := []string{
+		"protocol:rigsim,1.5;",
+		fmt.Sprintf("trx_count:%d;", len(s.rigs)),
+		fmt.Sprintf("cw_macros_speed:%d;", s.cwSpeed),
+	}
+	for trx, state := range s.states {
+		initialMessages = append(initialMessages, stateMessages(trx, State{}, state)...)
+	}
+	initialMessages = append(initialMessages, "ready;")
+	s.lock.Unlock()
+
+	err = conn.write(initialMessages...)
+	if err != nil {
+		return
+	}
+
+	for {
+		msgType, msg, err := wsConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+		reply := s.handle(string(msg))
+		if reply == "" {
+			continue
+		}
+		time.Sleep(s.currentLatency())
+		err = conn.write(reply)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *TCIServer) handle(command string) string {
+	command = strings.TrimSuffix(strings.TrimSpace(command), ";")
+	parts := strings.SplitN(command, ":", 2)
+	name := strings.ToLower(parts[0])
+	var args []string
+	if len(parts) == 2 {
+		args = strings.Split(parts[1], ",")
+	}
+
+	if name == "cw_macros_speed" {
+		if len(args) == 0 {
+			return fmt.Sprintf("cw_macros_speed:%d;", s.CWSpeed())
+		}
+		speed, err := strconv.Atoi(args[0])
+		if err == nil {
+			s.lock.Lock()
+			s.cwSpeed = speed
+			s.lock.Unlock()
+		}
+		return command + ";"
+	}
+	if name == "cw_macros_stop" {
+		return command + ";"
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+	trx, err := strconv.Atoi(args[0])
+	if err != nil || trx < 0 || trx >= len(s.rigs) {
+		return ""
+	}
+	rig := s.rigs[trx]
+
+	switch name {
+	case "vfo":
+		if len(args) < 2 {
+			return ""
+		}
+		vfo := args[1]
+		if len(args) == 2 {
+			return vfoMessage(trx, vfo, rig.State().VFOs)
+		}
+		frequency, ok := parseFrequency(args[2])
+		if !ok {
+			return ""
+		}
+		if vfo == "1" {
+			rig.SetSplitFrequency(frequency)
+		} else {
+			rig.SetFrequency(frequency)
+		}
+		return vfoMessage(trx, vfo, rig.State().VFOs)
+	case "modulation":
+		if len(args) > 1 {
+			rig.SetMode(toHamlibMode(args[1]))
+		}
+		return modulationMessage(trx, rig.Mode())
+	case "split_enable":
+		if len(args) > 1 {
+			rig.SetSplit(args[1] == "true" || args[1] == "1")
+		}
+		return splitEnableMessage(trx, rig.Split())
+	case "cw_macros":
+		if len(args) > 1 {
+			rig.SendCW(unescapeCWText(strings.Join(args[1:], ",")))
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+func (s *TCIServer) stateChanged(trx int, state State) {
+	s.lock.Lock()
+	messages := stateMessages(trx, s.states[trx], state)
+	s.states[trx] = state
+	conns := make([]*tciConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.lock.Unlock()
+
+	for _, conn := range conns {
+		conn.write(messages...)
+	}
+}
+
+func stateMessages(trx int, before, after State) []string {
+	var result []string
+	if before.VFOs.A != after.VFOs.A {
+		result = append(result, vfoMessage(trx, "0", after.VFOs))
+	}
+	if before.VFOs.B != after.VFOs.B {
+		result = append(result, vfoMessage(trx, "1", after.VFOs))
+	}
+	if before.Mode != after.Mode {
+		result = append(result, modulationMessage(trx, after.Mode))
+	}
+	if before.VFOs.Split != after.VFOs.Split {
+		result = append(result, splitEnableMessage(trx, after.VFOs.Split))
+	}
+	return result
+}
+
+func vfoMessage(trx int, vfo string, vfos core.VFOs) string {
+	frequency := vfos.A
+	if vfo == "1" {
+		frequency = vfos.B
+	}
+	return fmt.Sprintf("vfo:%d,%s,%d;", trx, vfo, int(frequency))
+}
+
+func modulationMessage(trx int, mode string) string {
+	return fmt.Sprintf("modulation:%d,%s;", trx, toTCIMode(mode))
+}
+
+func splitEnableMessage(trx int, split bool) string {
+	return fmt.Sprintf("split_enable:%d,%t;", trx, split)
+}
+
+func toTCIMode(mode string) string {
+	if tciMode, ok := tciModes[mode]; ok {
+		return tciMode
+	}
+	return strings.ToLower(mode)
+}
+
+func toHamlibMode(mode string) string {
+	if hamlibMode, ok := hamlibModes[strings.ToLower(mode)]; ok {
+		return hamlibMode
+	}
+	return strings.ToUpper(mode)
+}
+
+func unescapeCWText(text string) string {
+	if text == "_" {
+		return ""
+	}
+	result := strings.ReplaceAll(text, "^", ":")
+	result = strings.ReplaceAll(result, "~", ",")
+	result = strings.ReplaceAll(result, "*", ";")
+	return result
+}
@@ -0,0 +1,407 @@
+package rigsim
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WinkeyerVersion is the firmware version that the Winkeyer simulator reports when the host opens the connection.
+const WinkeyerVersion = 23
+
+const (
+	winkeyerBufferSize = 128
+	winkeyerXOFFLevel  = winkeyerBufferSize * 2 / 3
+)
+
+// the number of parameter bytes of the Winkeyer commands, indexed by the command byte
+var winkeyerParameters = map[byte]int{
+	0x01: 1, 0x02: 1, 0x03: 1, 0x04: 2, 0x05: 3, 0x06: 1, 0x09: 1, 0x0B: 1, 0x0C: 1, 0x0D: 1, 0x0E: 1,
+	0x0F: 15, 0x10: 1, 0x11: 1, 0x12: 1, 0x14: 1, 0x17: 1, 0x18: 1, 0x19: 1, 0x1A: 1, 0x1B: 2, 0x1C: 1, 0x1D: 1,
+}
+
+// NewWinkeyer starts a simulator of a K1EL Winkeyer in host mode. The simulator uses a pseudo terminal, the client
+// connects to the serial port returned by Port.
+func NewWinkeyer() (*Winkeyer, error) {
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Winkeyer{
+		master:       master,
+		slave:        slave,
+		charDuration: 10 * time.Millisecond,
+		potMin:       5,
+		potRange:     30,
+		keying:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	go result.serve()
+	go result.key()
+
+	return result, nil
+}
+
+// Winkeyer simulates a K1EL Winkeyer that is connected to a serial port. Characters are "sent" with a fixed duration
+// per character, independent of the speed.
+type Winkeyer struct {
+	master *os.File
+	slave  *os.File
+
+	writeLock sync.Mutex
+
+	lock         sync.Mutex
+	open         bool
+	echo         bool
+	wpm          int
+	weight       int
+	farnsworth   int
+	potMin       int
+	potRange     int
+	potValue     int
+	charDuration time.Duration
+	buffer       []byte
+	maxBuffered  int
+	sent         strings.Builder
+	sentWPM      []int
+	bufferedWPM  int
+	status       byte
+	generation   int
+	keying       chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// Port returns the name of the serial port to connect to.
+func (w *Winkeyer) Port() string {
+	return w.slave.Name()
+}
+
+// Close stops the simulator and closes the serial port.
+func (w *Winkeyer) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.master.Close()
+		w.slave.Close()
+	})
+}
+
+// SetCharDuration sets the time it takes to send one character.
+func (w *Winkeyer) SetCharDuration(duration time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.charDuration = duration
+}
+
+// IsOpen indicates that the host opened the connection.
+func (w *Winkeyer) IsOpen() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.open
+}
+
+// WPM returns the speed that was set by the host.
+func (w *Winkeyer) WPM() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.wpm
+}
+
+// Weight returns the weighting that was set by the host.
+func (w *Winkeyer) Weight() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.weight
+}
+
+// Farnsworth returns the Farnsworth speed that was set by the host.
+func (w *Winkeyer) Farnsworth() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.farnsworth
+}
+
+// Sent returns all characters that were sent so far.
+func (w *Winkeyer) Sent() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.sent.String()
+}
+
+// SentWith returns the characters that were sent with the given speed.
+func (w *Winkeyer) SentWith(wpm int) string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	sent := w.sent.String()
+	var result strings.Builder
+	for i, charWPM := range w.sentWPM {
+		if charWPM == wpm {
+			result.WriteByte(sent[i])
+		}
+	}
+	return result.String()
+}
+
+// MaxBuffered returns the maximum number of characters that were waiting in the buffer at the same time.
+func (w *Winkeyer) MaxBuffered() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.maxBuffered
+}
+
+// TurnSpeedPot sets the speed pot to the given speed in WpM, within the range that was set up by the host.
+func (w *Winkeyer) TurnSpeedPot(wpm int) {
+	w.lock.Lock()
+	value := wpm - w.potMin
+	if value < 0 {
+		value = 0
+	}
+	if value > w.potRange {
+		value = w.potRange
+	}
+	w.potValue = value
+	open := w.open
+	w.lock.Unlock()
+
+	if open {
+		w.write(0x80 | byte(value))
+	}
+}
+
+// Paddle simulates the operator touching the paddle: the buffer is cleared and the breakin bit is reported.
+func (w *Winkeyer) Paddle() {
+	w.lock.Lock()
+	w.clearBuffer()
+	w.lock.Unlock()
+
+	w.setStatus(0x02, true)
+	w.setStatus(0x02, false)
+}
+
+func (w *Winkeyer) serve() {
+	r := bufio.NewReader(w.master)
+	for {
+		command, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		if command >= 0x20 {
+			w.enqueue(command)
+			continue
+		}
+		if command == 0x1C || command == 0x1E {
+			w.enqueueBufferedCommand(r, command)
+			continue
+		}
+
+		parameters := make([]byte, winkeyerParameters[command])
+		if command == 0x00 {
+			parameters = make([]byte, 1)
+		}
+		for i := range parameters {
+			parameters[i], err = r.ReadByte()
+			if err != nil {
+				return
+			}
+		}
+		if command == 0x00 && parameters[0] == 0x04 {
+			echo, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			w.write(echo)
+			continue
+		}
+		w.handle(command, parameters)
+	}
+}
+
+func (w *Winkeyer) handle(command byte, parameters []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	switch command {
+	case 0x00:
+		switch parameters[0] {
+		case 0x02:
+			w.open = true
+			w.write(WinkeyerVersion)
+		case 0x03:
+			w.open = false
+		}
+	case 0x02:
+		w.wpm = int(parameters[0])
+	case 0x03:
+		w.weight = int(parameters[0])
+	case 0x05:
+		w.potMin = int(parameters[0])
+		w.potRange = int(parameters[1])
+	case 0x07:
+		w.write(0x80 | byte(w.potValue))
+	case 0x0A:
+		w.clearBuffer()
+	case 0x0D:
+		w.farnsworth = int(parameters[0])
+	case 0x0E:
+		w.echo = parameters[0]&0x04 != 0
+	case 0x15:
+		w.write(0xC0 | w.status)
+	}
+}
+
+func (w *Winkeyer) clearBuffer() {
+	w.buffer = nil
+	w.bufferedWPM = 0
+	w.generation++
+}
+
+func (w *Winkeyer) currentWPM() int {
+	if w.bufferedWPM != 0 {
+		return w.bufferedWPM
+	}
+	return w.wpm
+}
+
+// enqueueBufferedCommand puts a buffered speed change into the buffer, so that it is executed in order with the text.
+func (w *Winkeyer) enqueueBufferedCommand(r *bufio.Reader, command byte) {
+	data := []byte{command}
+	if command == 0x1C {
+		wpm, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		data = append(data, wpm)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buffer = append(w.buffer, data...)
+}
+
+func (w *Winkeyer) enqueue(c byte) {
+	w.lock.Lock()
+	w.buffer = append(w.buffer, c)
+	buffered := w.bufferedChars()
+	if buffered > w.maxBuffered {
+		w.maxBuffered = buffered
+	}
+	xoff := buffered >= winkeyerXOFFLevel
+	w.lock.Unlock()
+
+	if xoff {
+		w.setStatus(0x01, true)
+	}
+	select {
+	case w.keying <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Winkeyer) key() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.keying:
+		}
+
+		w.setStatus(0x04, true)
+		for {
+			c, generation, duration, echo, ok := w.nextChar()
+			if !ok {
+				break
+			}
+			select {
+			case <-w.done:
+				return
+			case <-time.After(duration):
+			}
+			if !w.charSent(c, generation) {
+				break
+			}
+			if echo {
+				w.write(c)
+			}
+		}
+		w.setStatus(0x04, false)
+	}
+}
+
+func (w *Winkeyer) bufferedChars() int {
+	result := 0
+	for i := 0; i < len(w.buffer); i++ {
+		switch {
+		case w.buffer[i] == 0x1C:
+			i++
+		case w.buffer[i] >= 0x20:
+			result++
+		}
+	}
+	return result
+}
+
+// nextChar returns the next character to send, the buffered commands before this character are executed.
+func (w *Winkeyer) nextChar() (byte, int, time.Duration, bool, bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for len(w.buffer) > 0 && w.buffer[0] < 0x20 {
+		switch {
+		case w.buffer[0] == 0x1C && len(w.buffer) > 1:
+			w.bufferedWPM = int(w.buffer[1])
+			w.buffer = w.buffer[2:]
+		default:
+			w.bufferedWPM = 0
+			w.buffer = w.buffer[1:]
+		}
+	}
+	if len(w.buffer) == 0 {
+		return 0, 0, 0, false, false
+	}
+	return w.buffer[0], w.generation, w.charDuration, w.echo, true
+}
+
+// charSent removes the given character from the buffer, if the buffer was not cleared in the meantime.
+func (w *Winkeyer) charSent(c byte, generation int) bool {
+	w.lock.Lock()
+	if w.generation != generation || len(w.buffer) == 0 {
+		w.lock.Unlock()
+		return false
+	}
+	w.buffer = w.buffer[1:]
+	w.sent.WriteByte(c)
+	w.sentWPM = append(w.sentWPM, w.currentWPM())
+	xon := w.bufferedChars() < winkeyerXOFFLevel
+	w.lock.Unlock()
+
+	if xon {
+		w.setStatus(0x01, false)
+	}
+	return true
+}
+
+// setStatus sets or clears the given status bits and reports the status to the host if it changed.
+func (w *Winkeyer) setStatus(bits byte, set bool) {
+	w.lock.Lock()
+	status := w.status
+	if set {
+		status |= bits
+	} else {
+		status &^= bits
+	}
+	changed := status != w.status
+	w.status = status
+	open := w.open
+	w.lock.Unlock()
+
+	if changed && open {
+		w.write(0xC0 | status)
+	}
+}
+
+func (w *Winkeyer) write(b byte) {
+	w.writeLock.Lock()
+	defer w.writeLock.Unlock()
+	w.master.Write([]byte{b})
+}
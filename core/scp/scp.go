@@ -1,28 +1,73 @@
 package scp
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/ftl/hamradio/scp"
+
+	"github.com/ftl/hellocontest/core"
 )
 
-func New() *Finder {
+// New returns a new finder for the Supercheck database. If offline is true, the local copy of the database is never
+// updated automatically. Use Start to load the database in the background.
+func New(offline bool) *Finder {
 	result := &Finder{
-		available: make(chan struct{}),
+		offline:     offline,
+		available:   make(chan struct{}),
+		loggedCalls: make(map[string]int),
 	}
 
-	go func() {
-		result.database = setupDatabase()
-		log.Print("Supercheck database available")
-		close(result.available)
-	}()
+	localFilename, err := scp.LocalFilename()
+	if err != nil {
+		log.Print(err)
+	}
+	result.localFilename = localFilename
 
 	return result
 }
 
 type Finder struct {
-	database  *scp.Database
-	available chan struct{}
+	offline       bool
+	localFilename string
+	database      *scp.Database
+	calls         []string
+	lock          sync.RWMutex
+	fileLock      sync.Mutex // serializes the loading, updating and importing of the local copy
+	loggedCalls   map[string]int
+	available     chan struct{}
+	listeners     []interface{}
+}
+
+func (f *Finder) Notify(listener interface{}) {
+	f.listeners = append(f.listeners, listener)
+}
+
+// Start loads the database in the background. Unless the finder is offline, the local copy is updated first.
+func (f *Finder) Start() {
+	go func() {
+		f.fileLock.Lock()
+		defer f.fileLock.Unlock()
+
+		var updateErr error
+		if !f.offline {
+			updateErr = f.update(false)
+		}
+		err := f.load()
+		if err == nil {
+			err = updateErr
+		}
+		f.emitDatabaseInfo(err)
+		close(f.available)
+	}()
 }
 
 func (f *Finder) Available() bool {
@@ -42,30 +87,197 @@ func (f *Finder) WhenAvailable(callback func()) {
 }
 
 func (f *Finder) Find(s string) ([]string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
 	if f.database == nil {
 		return []string{}, nil
 	}
 	return f.database.Find(s)
 }
 
-func setupDatabase() *scp.Database {
-	localFilename, err := scp.LocalFilename()
-	if err != nil {
-		log.Print(err)
+// QSOAdded adds the callsign of the given QSO to the callsigns that are used to find similar callsigns.
+func (f *Finder) QSOAdded(qso core.QSO) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.loggedCalls[qso.Callsign.String()]++
+}
+
+func (f *Finder) QSOUpdated(_ int, old, new core.QSO) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.removeLoggedCall(old.Callsign.String())
+	f.loggedCalls[new.Callsign.String()]++
+}
+
+func (f *Finder) QSOsCleared() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.loggedCalls = make(map[string]int)
+}
+
+func (f *Finder) removeLoggedCall(call string) {
+	f.loggedCalls[call]--
+	if f.loggedCalls[call] <= 0 {
+		delete(f.loggedCalls, call)
+	}
+}
+
+// FindSimilar returns the callsigns from the Supercheck database and from the log that differ from the given
+// string in exactly one character, ordered by their distance (see distance).
+func (f *Finder) FindSimilar(s string) ([]string, error) {
+	f.lock.RLock()
+	candidates := make([]string, 0, len(f.loggedCalls)+len(f.calls))
+	for call := range f.loggedCalls {
+		candidates = append(candidates, call)
+	}
+	candidates = append(candidates, f.calls...)
+	f.lock.RUnlock()
+	return findSimilar(s, candidates), nil
+}
+
+// Update downloads the current database and replaces the local copy. This is an explicit action, it also works if
+// the finder is offline.
+func (f *Finder) Update() error {
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	err := f.update(true)
+	if err == nil {
+		err = f.load()
+	}
+	f.emitDatabaseInfo(err)
+	return err
+}
+
+// Import replaces the local copy with the database from the given file in the SCP format, e.g. MASTER.SCP.
+func (f *Finder) Import(filename string) error {
+	f.fileLock.Lock()
+	defer f.fileLock.Unlock()
+
+	calls, err := f.importFile(filename)
+	if err == nil {
+		err = f.use(calls)
+	}
+	f.emitDatabaseInfo(err)
+	return err
+}
+
+func (f *Finder) update(force bool) error {
+	if f.localFilename == "" {
+		return fmt.Errorf("no local filename for the Supercheck database")
+	}
+	if force {
+		err := scp.Download(scp.DefaultURL, f.localFilename)
+		if err != nil {
+			return fmt.Errorf("update of local copy of Supercheck database failed: %v", err)
+		}
+		log.Printf("downloaded local copy of Supercheck database: %v", f.localFilename)
 		return nil
 	}
-	updated, err := scp.Update(scp.DefaultURL, localFilename)
+	updated, err := scp.Update(scp.DefaultURL, f.localFilename)
 	if err != nil {
-		log.Printf("update of local copy of Supercheck database failed: %v", err)
+		return fmt.Errorf("update of local copy of Supercheck database failed: %v", err)
 	}
 	if updated {
-		log.Printf("updated local copy of Supercheck database: %v", localFilename)
+		log.Printf("updated local copy of Supercheck database: %v", f.localFilename)
 	}
+	return nil
+}
 
-	result, err := scp.LoadLocal(localFilename)
+// importFile copies the given file into the local copy and returns the callsigns of the imported database.
+func (f *Finder) importFile(filename string) ([]string, error) {
+	if f.localFilename == "" {
+		return nil, fmt.Errorf("no local filename for the Supercheck database")
+	}
+	data, err := ioutil.ReadFile(filename)
 	if err != nil {
-		log.Printf("cannot load Supercheck database: %v", err)
-		return nil
+		return nil, err
 	}
-	return result
+	calls, err := readCalls(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("%s does not contain any callsigns", filepath.Base(filename))
+	}
+
+	err = os.MkdirAll(filepath.Dir(f.localFilename), 0755)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(f.localFilename, data, 0644)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("imported Supercheck database from %s into %s", filename, f.localFilename)
+	return calls, nil
+}
+
+func (f *Finder) load() error {
+	if f.localFilename == "" {
+		return fmt.Errorf("no local filename for the Supercheck database")
+	}
+	file, err := os.Open(f.localFilename)
+	if err != nil {
+		return fmt.Errorf("cannot load Supercheck database: %v", err)
+	}
+	defer file.Close()
+	calls, err := readCalls(file)
+	if err != nil {
+		return fmt.Errorf("cannot read the callsigns of the Supercheck database: %v", err)
+	}
+	return f.use(calls)
+}
+
+// use builds the database from the given callsigns, which were already read from the local copy. This way the
+// MASTER.SCP file is only parsed once.
+func (f *Finder) use(calls []string) error {
+	database, err := scp.Read(strings.NewReader(strings.Join(calls, "\n")))
+	if err != nil {
+		return fmt.Errorf("cannot load Supercheck database: %v", err)
+	}
+
+	f.lock.Lock()
+	f.database = database
+	f.calls = calls
+	f.lock.Unlock()
+	log.Print("Supercheck database available")
+	return nil
+}
+
+func (f *Finder) emitDatabaseInfo(err error) {
+	f.lock.RLock()
+	available := f.database != nil
+	f.lock.RUnlock()
+
+	info := core.DatabaseInfo{
+		Service: core.SCPService,
+		Err:     err,
+	}
+	if stat, statErr := os.Stat(f.localFilename); statErr == nil {
+		info.Date = stat.ModTime()
+	}
+
+	for _, listener := range f.listeners {
+		if databaseInfoListener, ok := listener.(core.DatabaseInfoListener); ok {
+			databaseInfoListener.DatabaseInfoChanged(info)
+		}
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.SCPService, available)
+		}
+	}
+}
+
+// readCalls reads all callsigns from the given reader in the SCP format.
+func readCalls(r io.Reader) ([]string, error) {
+	result := make([]string, 0)
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, strings.ToUpper(line))
+	}
+	return result, lines.Err()
 }
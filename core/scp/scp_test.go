@@ -0,0 +1,69 @@
+package scp
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "MASTER.SCP")
+	err := ioutil.WriteFile(importFilename, []byte("# test database\nDL1ABC\nDL2ABC\n"), 0644)
+	require.NoError(t, err)
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "local", "MASTER.SCP")
+	var infos []core.DatabaseInfo
+	finder.Notify(core.DatabaseInfoListenerFunc(func(info core.DatabaseInfo) {
+		infos = append(infos, info)
+	}))
+
+	err = finder.Import(importFilename)
+	require.NoError(t, err)
+
+	matches, err := finder.Find("1ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABC"}, matches)
+	similar, err := finder.FindSimilar("DL3ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABC", "DL2ABC"}, similar)
+	require.Len(t, infos, 1)
+	assert.Equal(t, core.SCPService, infos[0].Service)
+	assert.NoError(t, infos[0].Err)
+}
+
+func TestStart_LoadsTheLocalCopy(t *testing.T) {
+	localFilename := filepath.Join(t.TempDir(), "MASTER.SCP")
+	err := ioutil.WriteFile(localFilename, []byte("# test database\ndl1abc\nDL2ABC\n"), 0644)
+	require.NoError(t, err)
+	finder := New(true)
+	finder.localFilename = localFilename
+
+	finder.Start()
+	<-finder.available
+
+	matches, err := finder.Find("1ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABC"}, matches)
+	similar, err := finder.FindSimilar("DL3ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABC", "DL2ABC"}, similar)
+}
+
+func TestImport_WithoutCallsigns(t *testing.T) {
+	dir := t.TempDir()
+	importFilename := filepath.Join(dir, "empty.scp")
+	err := ioutil.WriteFile(importFilename, []byte("# nothing here\n"), 0644)
+	require.NoError(t, err)
+	finder := New(true)
+	finder.localFilename = filepath.Join(dir, "MASTER.SCP")
+
+	err = finder.Import(importFilename)
+
+	assert.Error(t, err)
+}
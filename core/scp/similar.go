@@ -0,0 +1,103 @@
+package scp
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// miscopyCost is the cost to substitute a character with one that is typically mixed up when copying CW.
+	miscopyCost = 1
+	// editCost is the cost to insert, delete or substitute any other character.
+	editCost = 2
+	// maxDistance allows exactly one inserted, deleted or substituted character (N+1).
+	maxDistance = editCost
+	// maxSimilarResults limits the number of similar callsigns.
+	maxSimilarResults = 20
+)
+
+// similarCharacters are groups of characters that are typically mixed up when copying CW, e.g. by missing a dit or a dah.
+var similarCharacters = []string{"ET", "AN", "ISH5", "TMO0", "UV4", "DB6"}
+
+// findSimilar returns the candidates that differ from the given string in one character. The candidates are ordered
+// by their distance and alphabetically.
+func findSimilar(s string, candidates []string) []string {
+	normalized := strings.ToUpper(strings.TrimSpace(s))
+	if len(normalized) < 3 {
+		return []string{}
+	}
+
+	distances := make(map[string]int)
+	for _, candidate := range candidates {
+		lengthDiff := len(candidate) - len(normalized)
+		if candidate == normalized || lengthDiff < -1 || lengthDiff > 1 {
+			continue
+		}
+		if _, ok := distances[candidate]; ok {
+			continue
+		}
+		d := distance(normalized, candidate)
+		if d <= maxDistance {
+			distances[candidate] = d
+		}
+	}
+
+	result := make([]string, 0, len(distances))
+	for candidate := range distances {
+		result = append(result, candidate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if distances[result[i]] != distances[result[j]] {
+			return distances[result[i]] < distances[result[j]]
+		}
+		return result[i] < result[j]
+	})
+	if len(result) > maxSimilarResults {
+		result = result[:maxSimilarResults]
+	}
+	return result
+}
+
+// distance returns the edit distance between the given strings. Substituting characters that are typically mixed up
+// when copying CW is cheaper than any other edit.
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j * editCost
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i * editCost
+		for j := 1; j <= len(b); j++ {
+			current[j] = minInt(
+				previous[j]+editCost,
+				current[j-1]+editCost,
+				previous[j-1]+substitutionCost(a[i-1], b[j-1]),
+			)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func substitutionCost(a, b byte) int {
+	if a == b {
+		return 0
+	}
+	for _, group := range similarCharacters {
+		if strings.IndexByte(group, a) != -1 && strings.IndexByte(group, b) != -1 {
+			return miscopyCost
+		}
+	}
+	return editCost
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package scp
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"DL1ABC", "DL1ABC", 0},
+		{"DL1ABC", "DL1ABD", 2},
+		{"DL1ABC", "DL1AB", 2},
+		{"DL1ABC", "DL1ABCD", 2},
+		{"DL1ABC", "DL1NBC", 1},
+		{"DL1ABC", "DL1ABX", 2},
+		{"K1TE", "K1TT", 1},
+		{"DL1SHS", "DL1HHS", 1},
+		{"DL1ABC", "DL2ABD", 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.expected, distance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	candidates := []string{"DL1ABC", "DL1ABD", "DL1NBC", "DL1AB", "DL2ABD", "DL1ABCD", "DL1NBC", "W1AW"}
+
+	actual := findSimilar("dl1abc", candidates)
+
+	assert.Equal(t, []string{"DL1NBC", "DL1AB", "DL1ABCD", "DL1ABD"}, actual)
+}
+
+func TestFindSimilar_TooShort(t *testing.T) {
+	assert.Empty(t, findSimilar("DL", []string{"DL1", "D"}))
+}
+
+func TestFinder_FindSimilarInLog(t *testing.T) {
+	finder := &Finder{
+		available:   make(chan struct{}),
+		loggedCalls: make(map[string]int),
+	}
+	finder.loggedCalls["DL1ABD"] = 1
+
+	actual, err := finder.FindSimilar("DL1ABC")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABD"}, actual)
+}
+
+func TestFinder_LogChangesWhileSearching(t *testing.T) {
+	finder := New(true)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			finder.QSOAdded(core.QSO{Callsign: callsign.MustParse("DL1ABD")})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		_, err := finder.FindSimilar("DL1ABC")
+		require.NoError(t, err)
+	}
+	<-done
+
+	actual, err := finder.FindSimilar("DL1ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL1ABD"}, actual)
+}
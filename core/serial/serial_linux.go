@@ -0,0 +1,92 @@
+//go:build linux
+// +build linux
+
+// Package serial opens serial ports in raw mode.
+package serial
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// cbaud is the mask of the baud rate bits in the control flags, it is not defined in the syscall package.
+const cbaud = 0x100f
+
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+}
+
+// Open opens the given serial port in raw mode with eight data bits, no parity, the given number of stop bits (1 or 2)
+// and the given baud rate.
+func Open(port string, baudRate int, stopBits int) (io.ReadWriteCloser, error) {
+	speed, ok := baudRates[baudRate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baudRate)
+	}
+
+	file, err := os.OpenFile(port, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setRawMode(file, speed, stopBits == 2)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot configure serial port %s: %w", port, err)
+	}
+
+	return file, nil
+}
+
+func setRawMode(file *os.File, speed uint32, twoStopBits bool) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var ioctlErr error
+	err = conn.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		ioctlErr = ioctl(fd, syscall.TCGETS, unsafe.Pointer(&termios))
+		if ioctlErr != nil {
+			return
+		}
+
+		termios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+		termios.Oflag &^= syscall.OPOST
+		termios.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+		termios.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.CSTOPB | cbaud
+		termios.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL | speed
+		if twoStopBits {
+			termios.Cflag |= syscall.CSTOPB
+		}
+		termios.Ispeed = speed
+		termios.Ospeed = speed
+		termios.Cc[syscall.VMIN] = 1
+		termios.Cc[syscall.VTIME] = 0
+
+		ioctlErr = ioctl(fd, syscall.TCSETS, unsafe.Pointer(&termios))
+	})
+	if err != nil {
+		return err
+	}
+	return ioctlErr
+}
+
+func ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
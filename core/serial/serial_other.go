@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+// Package serial opens serial ports in raw mode.
+package serial
+
+import (
+	"errors"
+	"io"
+)
+
+func Open(port string, baudRate int, stopBits int) (io.ReadWriteCloser, error) {
+	return nil, errors.New("serial ports are only supported on Linux")
+}
@@ -60,6 +60,7 @@ type View interface {
 	SetStationCallsign(string)
 	SetStationOperator(string)
 	SetStationLocator(string)
+	SetStationRegion(string)
 	SetContestName(string)
 	SetContestRequireTheirXchange(bool)
 	SetContestAllowMultiBand(bool)
@@ -74,6 +75,13 @@ type View interface {
 	SetContestXchangeMultiPatternResult(string)
 	SetContestCountPerBand(bool)
 	SetContestCabrilloQSOTemplate(string)
+	SetContestCategoryOperator(string)
+	SetContestCategoryAssisted(string)
+	SetContestCategoryBand(string)
+	SetContestCategoryMode(string)
+	SetContestCategoryPower(string)
+	SetContestCategoryTransmitter(string)
+	SetContestCategoryOverlay(string)
 }
 
 func New(defaultsOpener DefaultsOpener, xchangeRegexpMatcher XchangeRegexpMatcher, station core.Station, contest core.Contest) *Settings {
@@ -193,6 +201,7 @@ func (s *Settings) showSettings() {
 	s.view.SetStationCallsign(s.station.Callsign.String())
 	s.view.SetStationOperator(s.station.Operator.String())
 	s.view.SetStationLocator(s.station.Locator.String())
+	s.view.SetStationRegion(regionID(s.station.Region))
 
 	// contest
 	s.view.SetContestName(s.contest.Name)
@@ -208,6 +217,13 @@ func (s *Settings) showSettings() {
 	s.view.SetContestXchangeMultiPattern(s.contest.XchangeMultiPattern)
 	s.view.SetContestCountPerBand(s.contest.CountPerBand)
 	s.view.SetContestCabrilloQSOTemplate(s.contest.CabrilloQSOTemplate)
+	s.view.SetContestCategoryOperator(s.contest.CategoryOperator)
+	s.view.SetContestCategoryAssisted(s.contest.CategoryAssisted)
+	s.view.SetContestCategoryBand(s.contest.CategoryBand)
+	s.view.SetContestCategoryMode(s.contest.CategoryMode)
+	s.view.SetContestCategoryPower(s.contest.CategoryPower)
+	s.view.SetContestCategoryTransmitter(s.contest.CategoryTransmitter)
+	s.view.SetContestCategoryOverlay(s.contest.CategoryOverlay)
 	s.updateXchangeMultiPatternResult()
 }
 
@@ -284,6 +300,24 @@ func (s *Settings) EnterStationLocator(value string) {
 	s.station.Locator = loc
 }
 
+func (s *Settings) EnterStationRegion(value string) {
+	region, err := strconv.Atoi(value)
+	if err != nil || region < int(core.IARURegion1) || region > int(core.IARURegion3) {
+		s.view.ShowMessage(fmt.Sprintf("invalid IARU region: %s", value))
+		return
+	}
+	s.view.HideMessage()
+	s.station.Region = core.IARURegion(region)
+}
+
+// regionID returns the ID of the given region in the view. Without a region, the bandplan of region 1 is used.
+func regionID(region core.IARURegion) string {
+	if region == core.NoRegion {
+		region = core.IARURegion1
+	}
+	return strconv.Itoa(int(region))
+}
+
 func (s *Settings) EnterContestName(value string) {
 	s.contest.Name = value
 }
@@ -417,6 +451,34 @@ func (s *Settings) EnterContestCabrilloQSOTemplate(value string) {
 	s.contest.CabrilloQSOTemplate = value
 }
 
+func (s *Settings) EnterContestCategoryOperator(value string) {
+	s.contest.CategoryOperator = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryAssisted(value string) {
+	s.contest.CategoryAssisted = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryBand(value string) {
+	s.contest.CategoryBand = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryMode(value string) {
+	s.contest.CategoryMode = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryPower(value string) {
+	s.contest.CategoryPower = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryTransmitter(value string) {
+	s.contest.CategoryTransmitter = strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Settings) EnterContestCategoryOverlay(value string) {
+	s.contest.CategoryOverlay = strings.ToUpper(strings.TrimSpace(value))
+}
+
 type nullWriter struct{}
 
 func (w *nullWriter) WriteStation(core.Station) error { return nil }
@@ -430,6 +492,7 @@ func (v *nullView) HideMessage()                               {}
 func (v *nullView) SetStationCallsign(string)                  {}
 func (v *nullView) SetStationOperator(string)                  {}
 func (v *nullView) SetStationLocator(string)                   {}
+func (v *nullView) SetStationRegion(string)                    {}
 func (v *nullView) SetContestName(string)                      {}
 func (v *nullView) SetContestEnterTheirNumber(bool)            {}
 func (v *nullView) SetContestEnterTheirXchange(bool)           {}
@@ -446,3 +509,10 @@ func (v *nullView) SetContestXchangeMultiPattern(string)       {}
 func (v *nullView) SetContestXchangeMultiPatternResult(string) {}
 func (v *nullView) SetContestCountPerBand(bool)                {}
 func (v *nullView) SetContestCabrilloQSOTemplate(string)       {}
+func (v *nullView) SetContestCategoryOperator(string)          {}
+func (v *nullView) SetContestCategoryAssisted(string)          {}
+func (v *nullView) SetContestCategoryBand(string)              {}
+func (v *nullView) SetContestCategoryMode(string)              {}
+func (v *nullView) SetContestCategoryPower(string)             {}
+func (v *nullView) SetContestCategoryTransmitter(string)       {}
+func (v *nullView) SetContestCategoryOverlay(string)           {}
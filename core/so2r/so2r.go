@@ -0,0 +1,535 @@
+package so2r
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/entry"
+	"github.com/ftl/hellocontest/core/keyer"
+)
+
+// Radio identifies one of the two radios.
+type Radio int
+
+const (
+	Radio1 Radio = iota
+	Radio2
+)
+
+// Other returns the other radio.
+func (r Radio) Other() Radio {
+	if r == Radio1 {
+		return Radio2
+	}
+	return Radio1
+}
+
+func (r Radio) String() string {
+	return fmt.Sprintf("R%d", int(r)+1)
+}
+
+// View shows which radio has the focus and which radio is transmitting.
+type View interface {
+	ShowRadios(focus Radio, tx Radio)
+}
+
+// Entry is the entry functionality of one radio.
+type Entry interface {
+	SwitchView(entry.View)
+	SetCallinfo(entry.Callinfo)
+	QSOSelected(core.QSO)
+	CurrentValues() core.KeyerValues
+	Log()
+	SetWorkmode(core.Workmode)
+	AutoCQChanged(bool, time.Duration)
+	VFOs() core.VFOs
+}
+
+// FocusChangedListener is notified when the focus moves to another radio.
+type FocusChangedListener interface {
+	FocusChanged(Radio)
+}
+
+type FocusChangedListenerFunc func(Radio)
+
+func (f FocusChangedListenerFunc) FocusChanged(radio Radio) {
+	f(radio)
+}
+
+// TXChangedListener is notified when another radio becomes the transmitting radio.
+type TXChangedListener interface {
+	TXChanged(Radio)
+}
+
+type TXChangedListenerFunc func(Radio)
+
+func (f TXChangedListenerFunc) TXChanged(radio Radio) {
+	f(radio)
+}
+
+// New returns a new SO2R controller for the given entries. If radio2 is nil, only one radio is used and the focus
+// and the transmitting radio stay on radio 1.
+func New(radio1, radio2 Entry) *Controller {
+	result := &Controller{
+		view:  new(nullView),
+		focus: Radio1,
+		tx:    Radio1,
+	}
+	result.radios[Radio1] = &radio{entry: radio1, client: new(nullClient)}
+	if radio2 != nil {
+		result.radios[Radio2] = &radio{entry: radio2, client: new(nullClient)}
+	}
+	for _, r := range result.radios {
+		if r != nil {
+			r.entry.SetCallinfo(nil)
+		}
+	}
+	return result
+}
+
+// Controller switches the focus and the transmitting radio between the two radios. Only the radio with the focus
+// is connected to the entry view, the callinfo, and the listeners for frequency, band and mode changes. The other
+// radio keeps its state in the background. The keyer output is routed to the transmitting radio.
+type Controller struct {
+	view      View
+	entryView entry.View
+	callinfo  entry.Callinfo
+	keyer     entry.Keyer
+
+	radios [2]*radio
+	focus  Radio
+	tx     Radio
+
+	listeners []interface{}
+}
+
+type radio struct {
+	entry     Entry
+	client    keyer.CWClient
+	workmode  core.Workmode
+	frequency core.Frequency
+	band      core.Band
+	mode      core.Mode
+}
+
+// SetView sets the view that shows the focus and the transmitting radio.
+func (c *Controller) SetView(view View) {
+	if view == nil {
+		c.view = new(nullView)
+		return
+	}
+	c.view = view
+	c.view.ShowRadios(c.focus, c.tx)
+}
+
+// SetEntryView sets the entry view. It is connected to the entry of the radio with the focus.
+func (c *Controller) SetEntryView(view entry.View) {
+	c.entryView = view
+	c.radios[c.focus].entry.SwitchView(view)
+}
+
+// SetCallinfo sets the callinfo. It shows the information of the entry of the radio with the focus.
+func (c *Controller) SetCallinfo(callinfo entry.Callinfo) {
+	c.callinfo = callinfo
+	c.radios[c.focus].entry.SetCallinfo(callinfo)
+}
+
+// SetKeyer sets the keyer that is shared by both radios.
+func (c *Controller) SetKeyer(keyer entry.Keyer) {
+	c.keyer = keyer
+}
+
+// Keyer returns the keyer for the entry of the given radio. Sending through this keyer makes the given radio
+// the transmitting radio.
+func (c *Controller) Keyer(radio Radio) entry.Keyer {
+	return &radioKeyer{controller: c, radio: radio}
+}
+
+// SetCWClient sets the CW client of the given radio.
+func (c *Controller) SetCWClient(radio Radio, client keyer.CWClient) {
+	if c.radios[radio] == nil {
+		return
+	}
+	if client == nil {
+		c.radios[radio].client = new(nullClient)
+		return
+	}
+	c.radios[radio].client = client
+}
+
+// CWClient returns the CW client that is used by the keyer. It routes the CW output to the transmitting radio.
+func (c *Controller) CWClient() keyer.CWClient {
+	return &router{controller: c}
+}
+
+// RadioListener returns the listener for the frequency, band and mode changes of the given radio.
+// Only the changes of the radio with the focus are forwarded to the listeners of this controller.
+func (c *Controller) RadioListener(radio Radio) interface{} {
+	return &radioListener{controller: c, radio: radio}
+}
+
+func (c *Controller) Notify(listener interface{}) {
+	c.listeners = append(c.listeners, listener)
+}
+
+// Focus returns the radio with the focus.
+func (c *Controller) Focus() Radio {
+	return c.focus
+}
+
+// TX returns the transmitting radio.
+func (c *Controller) TX() Radio {
+	return c.tx
+}
+
+// VFOs returns the current state of the VFOs of the radio with the focus.
+func (c *Controller) VFOs() core.VFOs {
+	return c.radios[c.focus].entry.VFOs()
+}
+
+// SO2R indicates if two radios are available.
+func (c *Controller) SO2R() bool {
+	return c.radios[Radio2] != nil
+}
+
+// SetFocus moves the focus to the given radio.
+func (c *Controller) SetFocus(radio Radio) {
+	if radio == c.focus || c.radios[radio] == nil {
+		return
+	}
+	previous := c.radios[c.focus]
+	previous.entry.SwitchView(nil)
+	previous.entry.SetCallinfo(nil)
+
+	c.focus = radio
+	focused := c.radios[c.focus]
+	focused.entry.SetCallinfo(c.callinfo)
+	if c.entryView != nil {
+		focused.entry.SwitchView(c.entryView)
+	}
+
+	c.view.ShowRadios(c.focus, c.tx)
+	c.emitFocusChanged(c.focus)
+	if focused.frequency != 0 {
+		c.emitFrequencyChanged(focused.frequency)
+	}
+	if focused.band != core.NoBand {
+		c.emitBandChanged(focused.band)
+	}
+	if focused.mode != core.NoMode {
+		c.emitModeChanged(focused.mode)
+	}
+}
+
+// SwapFocus moves the focus to the other radio.
+func (c *Controller) SwapFocus() {
+	c.SetFocus(c.focus.Other())
+}
+
+// SetTX makes the given radio the transmitting radio. The current transmission and the auto-CQ are stopped.
+func (c *Controller) SetTX(radio Radio) {
+	if radio == c.tx || c.radios[radio] == nil {
+		return
+	}
+	if c.keyer != nil {
+		c.keyer.StopAutoCQ()
+	}
+	c.radios[c.tx].client.Abort()
+	c.tx = radio
+	c.view.ShowRadios(c.focus, c.tx)
+	c.emitTXChanged(c.tx)
+}
+
+// ToggleTX makes the other radio the transmitting radio.
+func (c *Controller) ToggleTX() {
+	c.SetTX(c.tx.Other())
+}
+
+// QSOSelected forwards the selection of a QSO in the log to the entry of the radio with the focus.
+func (c *Controller) QSOSelected(qso core.QSO) {
+	c.radios[c.focus].entry.QSOSelected(qso)
+}
+
+// CurrentValues returns the keyer values of the transmitting radio.
+func (c *Controller) CurrentValues() core.KeyerValues {
+	return c.radios[c.tx].entry.CurrentValues()
+}
+
+// Log logs the QSO of the transmitting radio. This action is triggered by the keyer macros.
+func (c *Controller) Log() {
+	c.radios[c.tx].entry.Log()
+}
+
+// SetWorkmode sets the workmode of the transmitting radio. This action is triggered by the keyer macros.
+func (c *Controller) SetWorkmode(workmode core.Workmode) {
+	c.radios[c.tx].entry.SetWorkmode(workmode)
+}
+
+// AutoCQChanged shows the countdown of the auto-CQ in the entry of the transmitting radio.
+func (c *Controller) AutoCQChanged(active bool, remaining time.Duration) {
+	c.radios[c.tx].entry.AutoCQChanged(active, remaining)
+}
+
+func (c *Controller) emitFocusChanged(radio Radio) {
+	for _, listener := range c.listeners {
+		if focusChangedListener, ok := listener.(FocusChangedListener); ok {
+			focusChangedListener.FocusChanged(radio)
+		}
+	}
+}
+
+func (c *Controller) emitTXChanged(radio Radio) {
+	for _, listener := range c.listeners {
+		if txChangedListener, ok := listener.(TXChangedListener); ok {
+			txChangedListener.TXChanged(radio)
+		}
+	}
+}
+
+func (c *Controller) emitFrequencyChanged(frequency core.Frequency) {
+	for _, listener := range c.listeners {
+		if frequencyChangedListener, ok := listener.(entry.FrequencyChangedListener); ok {
+			frequencyChangedListener.FrequencyChanged(frequency)
+		}
+	}
+}
+
+func (c *Controller) emitBandChanged(band core.Band) {
+	for _, listener := range c.listeners {
+		if bandChangedListener, ok := listener.(entry.BandChangedListener); ok {
+			bandChangedListener.BandChanged(band)
+		}
+	}
+}
+
+func (c *Controller) emitModeChanged(mode core.Mode) {
+	for _, listener := range c.listeners {
+		if modeChangedListener, ok := listener.(entry.ModeChangedListener); ok {
+			modeChangedListener.ModeChanged(mode)
+		}
+	}
+}
+
+// radioKeyer makes its radio the transmitting radio before sending, using the workmode of its radio.
+type radioKeyer struct {
+	controller *Controller
+	radio      Radio
+}
+
+func (k *radioKeyer) activate() bool {
+	if k.controller.keyer == nil || k.controller.radios[k.radio] == nil {
+		return false
+	}
+	k.controller.SetTX(k.radio)
+	k.controller.keyer.WorkmodeChanged(k.controller.radios[k.radio].workmode)
+	return true
+}
+
+func (k *radioKeyer) SendQuestion(q string) {
+	if k.activate() {
+		k.controller.keyer.SendQuestion(q)
+	}
+}
+
+func (k *radioKeyer) Send(index int) {
+	if k.activate() {
+		k.controller.keyer.Send(index)
+	}
+}
+
+func (k *radioKeyer) SendSlower(index int) {
+	if k.activate() {
+		k.controller.keyer.SendSlower(index)
+	}
+}
+
+// ResendSlower sends the last message again at a lower speed on the radio of this keyer.
+func (k *radioKeyer) ResendSlower() {
+	if k.activate() {
+		k.controller.keyer.ResendSlower()
+	}
+}
+
+// AutoCQ starts the auto-CQ on the radio of this keyer.
+func (k *radioKeyer) AutoCQ() {
+	if k.activate() {
+		k.controller.keyer.AutoCQ()
+	}
+}
+
+func (k *radioKeyer) StopAutoCQ() {
+	if k.controller.keyer != nil {
+		k.controller.keyer.StopAutoCQ()
+	}
+}
+
+func (k *radioKeyer) Stop() {
+	if k.controller.keyer != nil {
+		k.controller.keyer.Stop()
+	}
+}
+
+func (k *radioKeyer) DecreaseSpeed() {
+	if k.controller.keyer != nil {
+		k.controller.keyer.DecreaseSpeed()
+	}
+}
+
+func (k *radioKeyer) IncreaseSpeed() {
+	if k.controller.keyer != nil {
+		k.controller.keyer.IncreaseSpeed()
+	}
+}
+
+func (k *radioKeyer) WorkmodeChanged(workmode core.Workmode) {
+	if k.controller.radios[k.radio] == nil {
+		return
+	}
+	k.controller.radios[k.radio].workmode = workmode
+	if k.controller.keyer != nil && k.controller.tx == k.radio {
+		k.controller.keyer.WorkmodeChanged(workmode)
+	}
+}
+
+// router routes the CW output to the client of the transmitting radio.
+type router struct {
+	controller *Controller
+}
+
+func (r *router) txClient() keyer.CWClient {
+	return r.controller.radios[r.controller.tx].client
+}
+
+// clients returns the distinct clients of both radios.
+func (r *router) clients() []keyer.CWClient {
+	result := make([]keyer.CWClient, 0, len(r.controller.radios))
+	for _, radio := range r.controller.radios {
+		if radio == nil {
+			continue
+		}
+		if len(result) > 0 && result[0] == radio.client {
+			continue
+		}
+		result = append(result, radio.client)
+	}
+	return result
+}
+
+func (r *router) Connect() error {
+	return r.txClient().Connect()
+}
+
+func (r *router) IsConnected() bool {
+	return r.txClient().IsConnected()
+}
+
+func (r *router) Speed(wpm int) {
+	for _, client := range r.clients() {
+		if client.IsConnected() {
+			client.Speed(wpm)
+		}
+	}
+}
+
+// Weight sets the weighting of all clients that are able to change the weighting.
+func (r *router) Weight(percent int) {
+	for _, client := range r.clients() {
+		if weightingClient, ok := client.(keyer.WeightingCWClient); ok && client.IsConnected() {
+			weightingClient.Weight(percent)
+		}
+	}
+}
+
+// Farnsworth sets the Farnsworth speed of all clients that support Farnsworth spacing.
+func (r *router) Farnsworth(wpm int) {
+	for _, client := range r.clients() {
+		if farnsworthClient, ok := client.(keyer.FarnsworthCWClient); ok && client.IsConnected() {
+			farnsworthClient.Farnsworth(wpm)
+		}
+	}
+}
+
+func (r *router) Send(text string) {
+	r.txClient().Send(text)
+}
+
+// SendWithSpeed sends the text with the given speed, if the client of the transmitting radio is able to change the
+// speed within a message. Otherwise the text is sent with the current speed, the keyer checks this with
+// SupportsInlineSpeed before.
+func (r *router) SendWithSpeed(text string, wpm int) {
+	if client, ok := r.txClient().(keyer.InlineSpeedCWClient); ok {
+		client.SendWithSpeed(text, wpm)
+		return
+	}
+	r.txClient().Send(text)
+}
+
+// SupportsInlineSpeed indicates if the client of the transmitting radio is able to change the speed within a message.
+func (r *router) SupportsInlineSpeed() bool {
+	_, ok := r.txClient().(keyer.InlineSpeedCWClient)
+	return ok
+}
+
+// SupportsWeighting indicates if the client of the transmitting radio is able to change the weighting.
+func (r *router) SupportsWeighting() bool {
+	_, ok := r.txClient().(keyer.WeightingCWClient)
+	return ok
+}
+
+// SupportsFarnsworth indicates if the client of the transmitting radio supports Farnsworth spacing.
+func (r *router) SupportsFarnsworth() bool {
+	_, ok := r.txClient().(keyer.FarnsworthCWClient)
+	return ok
+}
+
+func (r *router) Abort() {
+	for _, client := range r.clients() {
+		if client.IsConnected() {
+			client.Abort()
+		}
+	}
+}
+
+// radioListener keeps track of the frequency, band and mode of its radio.
+type radioListener struct {
+	controller *Controller
+	radio      Radio
+}
+
+func (l *radioListener) focused() bool {
+	return l.controller.focus == l.radio
+}
+
+func (l *radioListener) FrequencyChanged(frequency core.Frequency) {
+	l.controller.radios[l.radio].frequency = frequency
+	if l.focused() {
+		l.controller.emitFrequencyChanged(frequency)
+	}
+}
+
+func (l *radioListener) BandChanged(band core.Band) {
+	l.controller.radios[l.radio].band = band
+	if l.focused() {
+		l.controller.emitBandChanged(band)
+	}
+}
+
+func (l *radioListener) ModeChanged(mode core.Mode) {
+	l.controller.radios[l.radio].mode = mode
+	if l.focused() {
+		l.controller.emitModeChanged(mode)
+	}
+}
+
+type nullView struct{}
+
+func (n *nullView) ShowRadios(Radio, Radio) {}
+
+type nullClient struct{}
+
+func (*nullClient) Connect() error    { return nil }
+func (*nullClient) IsConnected() bool { return true }
+func (*nullClient) Speed(int)         {}
+func (*nullClient) Send(text string)  {}
+func (*nullClient) Abort()            {}
@@ -0,0 +1,285 @@
+package so2r
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/entry"
+)
+
+func TestNew_FocusAndTXOnRadio1(t *testing.T) {
+	radio1 := &testEntry{callsign: "DL1ABC"}
+	radio2 := &testEntry{callsign: "DL2ABC"}
+	c := New(radio1, radio2)
+
+	assert.Equal(t, Radio1, c.Focus())
+	assert.Equal(t, Radio1, c.TX())
+	assert.True(t, c.SO2R())
+	assert.Equal(t, "DL1ABC", c.CurrentValues().TheirCall)
+}
+
+func TestSingleRadio_FocusStaysOnRadio1(t *testing.T) {
+	radio1 := &testEntry{}
+	c := New(radio1, nil)
+
+	c.SwapFocus()
+	c.ToggleTX()
+
+	assert.False(t, c.SO2R())
+	assert.Equal(t, Radio1, c.Focus())
+	assert.Equal(t, Radio1, c.TX())
+}
+
+func TestSwapFocus_SwitchesViewAndCallinfo(t *testing.T) {
+	radio1 := &testEntry{}
+	radio2 := &testEntry{}
+	c := New(radio1, radio2)
+	view := new(testView)
+	c.SetView(view)
+	entryView := new(testEntryView)
+	callinfo := new(testCallinfo)
+	c.SetEntryView(entryView)
+	c.SetCallinfo(callinfo)
+	var focusChanges []Radio
+	c.Notify(FocusChangedListenerFunc(func(radio Radio) {
+		focusChanges = append(focusChanges, radio)
+	}))
+
+	c.SwapFocus()
+
+	assert.Equal(t, Radio2, c.Focus())
+	assert.Equal(t, Radio1, c.TX(), "TX stays on radio 1")
+	assert.Nil(t, radio1.view)
+	assert.Nil(t, radio1.callinfo)
+	assert.Equal(t, entryView, radio2.view)
+	assert.Equal(t, callinfo, radio2.callinfo)
+	assert.Equal(t, []Radio{Radio2}, focusChanges)
+	assert.Equal(t, Radio2, view.focus)
+	assert.Equal(t, Radio1, view.tx)
+
+	c.SwapFocus()
+
+	assert.Equal(t, Radio1, c.Focus())
+	assert.Equal(t, entryView, radio1.view)
+	assert.Nil(t, radio2.view)
+	assert.Equal(t, []Radio{Radio2, Radio1}, focusChanges)
+}
+
+func TestQSOSelected_OnlyForFocusedRadio(t *testing.T) {
+	radio1 := &testEntry{}
+	radio2 := &testEntry{}
+	c := New(radio1, radio2)
+	c.SetFocus(Radio2)
+
+	c.QSOSelected(core.QSO{MyNumber: 1})
+
+	assert.Empty(t, radio1.selected)
+	assert.Equal(t, []core.QSONumber{1}, radio2.selected)
+}
+
+func TestRadioListener_ForwardsOnlyFocusedRadio(t *testing.T) {
+	c := New(&testEntry{}, &testEntry{})
+	var frequencies []core.Frequency
+	var bands []core.Band
+	c.Notify(entry.FrequencyChangedListenerFunc(func(frequency core.Frequency) {
+		frequencies = append(frequencies, frequency)
+	}))
+	c.Notify(entry.BandChangedListenerFunc(func(band core.Band) {
+		bands = append(bands, band)
+	}))
+	radio1 := c.RadioListener(Radio1).(entry.FrequencyChangedListener)
+	radio2 := c.RadioListener(Radio2).(entry.FrequencyChangedListener)
+
+	radio1.FrequencyChanged(7010000)
+	radio2.FrequencyChanged(14010000)
+	c.RadioListener(Radio2).(entry.BandChangedListener).BandChanged(core.Band20m)
+	assert.Equal(t, []core.Frequency{7010000}, frequencies)
+	assert.Empty(t, bands)
+
+	c.SwapFocus()
+	assert.Equal(t, []core.Frequency{7010000, 14010000}, frequencies, "the focused radio's frequency is emitted")
+	assert.Equal(t, []core.Band{core.Band20m}, bands)
+}
+
+func TestKeyer_SendingMakesRadioTheTXRadio(t *testing.T) {
+	radio1 := &testEntry{callsign: "DL1ABC"}
+	radio2 := &testEntry{callsign: "DL2ABC"}
+	c := New(radio1, radio2)
+	client1 := new(testClient)
+	client2 := new(testClient)
+	c.SetCWClient(Radio1, client1)
+	c.SetCWClient(Radio2, client2)
+	keyer := &testKeyer{client: c.CWClient(), values: c.CurrentValues}
+	c.SetKeyer(keyer)
+	var txChanges []Radio
+	c.Notify(TXChangedListenerFunc(func(radio Radio) {
+		txChanges = append(txChanges, radio)
+	}))
+
+	c.Keyer(Radio1).Send(0)
+	c.Keyer(Radio2).Send(0)
+
+	assert.Equal(t, []string{"DL1ABC"}, client1.sent)
+	assert.Equal(t, []string{"DL2ABC"}, client2.sent)
+	assert.Equal(t, Radio2, c.TX())
+	assert.Equal(t, Radio1, c.Focus(), "sending does not move the focus")
+	assert.Equal(t, []Radio{Radio2}, txChanges)
+	assert.Equal(t, 1, client1.aborted, "switching the TX radio stops the transmission")
+}
+
+func TestActions_OnTXRadio(t *testing.T) {
+	radio1 := &testEntry{workmode: core.Run}
+	radio2 := &testEntry{workmode: core.Run}
+	c := New(radio1, radio2)
+	c.SetTX(Radio2)
+
+	c.Log()
+	c.SetWorkmode(core.SearchPounce)
+
+	assert.Equal(t, 0, radio1.logged)
+	assert.Equal(t, 1, radio2.logged)
+	assert.Equal(t, core.Run, radio1.workmode)
+	assert.Equal(t, core.SearchPounce, radio2.workmode)
+}
+
+func TestKeyer_UsesWorkmodeOfSendingRadio(t *testing.T) {
+	c := New(&testEntry{}, &testEntry{})
+	keyer := &testKeyer{client: c.CWClient(), values: c.CurrentValues}
+	c.SetKeyer(keyer)
+
+	c.Keyer(Radio1).WorkmodeChanged(core.Run)
+	assert.Equal(t, core.Run, keyer.workmode)
+	c.Keyer(Radio2).WorkmodeChanged(core.SearchPounce)
+	assert.Equal(t, core.Run, keyer.workmode, "radio 2 is not transmitting")
+
+	c.Keyer(Radio2).Send(0)
+	assert.Equal(t, core.SearchPounce, keyer.workmode)
+	c.Keyer(Radio1).Send(0)
+	assert.Equal(t, core.Run, keyer.workmode)
+}
+
+func TestAutoCQ_OnTXRadio(t *testing.T) {
+	radio1 := &testEntry{}
+	radio2 := &testEntry{}
+	c := New(radio1, radio2)
+	keyer := &testKeyer{}
+	c.SetKeyer(keyer)
+
+	c.Keyer(Radio2).AutoCQ()
+	assert.Equal(t, Radio2, c.TX())
+	assert.True(t, keyer.autoCQ)
+
+	c.AutoCQChanged(true, 3*time.Second)
+	assert.False(t, radio1.autoCQ)
+	assert.True(t, radio2.autoCQ)
+
+	c.SetTX(Radio1)
+	assert.False(t, keyer.autoCQ, "the auto-CQ stops when the TX radio changes")
+}
+
+func TestCWClient_SharedClientIsUsedOnce(t *testing.T) {
+	c := New(&testEntry{}, &testEntry{})
+	client := new(testClient)
+	c.SetCWClient(Radio1, client)
+	c.SetCWClient(Radio2, client)
+
+	c.CWClient().Speed(30)
+	c.CWClient().Abort()
+
+	assert.Equal(t, []int{30}, client.speeds)
+	assert.Equal(t, 1, client.aborted)
+}
+
+type testEntry struct {
+	callsign string
+	view     entry.View
+	callinfo entry.Callinfo
+	selected []core.QSONumber
+	logged   int
+	workmode core.Workmode
+	autoCQ   bool
+}
+
+func (e *testEntry) SwitchView(view entry.View) {
+	e.view = view
+}
+
+func (e *testEntry) SetCallinfo(callinfo entry.Callinfo) {
+	e.callinfo = callinfo
+}
+
+func (e *testEntry) QSOSelected(qso core.QSO) {
+	e.selected = append(e.selected, qso.MyNumber)
+}
+
+func (e *testEntry) CurrentValues() core.KeyerValues {
+	return core.KeyerValues{TheirCall: e.callsign}
+}
+
+func (e *testEntry) Log() {
+	e.logged++
+}
+
+func (e *testEntry) SetWorkmode(workmode core.Workmode) {
+	e.workmode = workmode
+}
+
+func (e *testEntry) AutoCQChanged(active bool, _ time.Duration) {
+	e.autoCQ = active
+}
+
+func (e *testEntry) VFOs() core.VFOs {
+	return core.VFOs{}
+}
+
+type testView struct {
+	focus Radio
+	tx    Radio
+}
+
+func (v *testView) ShowRadios(focus Radio, tx Radio) {
+	v.focus = focus
+	v.tx = tx
+}
+
+type testEntryView struct {
+	entry.View
+}
+
+type testCallinfo struct{}
+
+func (c *testCallinfo) ShowInfo(string, core.Band, core.Mode, string) {}
+
+type testClient struct {
+	sent    []string
+	speeds  []int
+	aborted int
+}
+
+func (c *testClient) Connect() error    { return nil }
+func (c *testClient) IsConnected() bool { return true }
+func (c *testClient) Speed(wpm int)     { c.speeds = append(c.speeds, wpm) }
+func (c *testClient) Send(text string)  { c.sent = append(c.sent, text) }
+func (c *testClient) Abort()            { c.aborted++ }
+
+// testKeyer sends the callsign provided by the current values.
+type testKeyer struct {
+	client   interface{ Send(string) }
+	values   func() core.KeyerValues
+	workmode core.Workmode
+	autoCQ   bool
+}
+
+func (k *testKeyer) SendQuestion(q string)                  { k.client.Send(q + "?") }
+func (k *testKeyer) Stop()                                  {}
+func (k *testKeyer) DecreaseSpeed()                         {}
+func (k *testKeyer) IncreaseSpeed()                         {}
+func (k *testKeyer) Send(int)                               { k.client.Send(k.values().TheirCall) }
+func (k *testKeyer) SendSlower(int)                         { k.client.Send(k.values().TheirCall) }
+func (k *testKeyer) ResendSlower()                          {}
+func (k *testKeyer) WorkmodeChanged(workmode core.Workmode) { k.workmode = workmode }
+func (k *testKeyer) AutoCQ()                                { k.autoCQ = true }
+func (k *testKeyer) StopAutoCQ()                            { k.autoCQ = false }
@@ -166,7 +166,10 @@ func TestFileStore_V1KeyerRoundtrip(t *testing.T) {
 		WPM: 25,
 	}
 	keyer2 := core.Keyer{
-		WPM: 35,
+		WPM:       35,
+		RunMacros: []string{"cq", "", "tu"},
+		RunLabels: []string{"CQ", "", "TU"},
+		MacroSet:  "Serial Number",
 	}
 	err = fs.WriteKeyer(keyer1)
 	err = fs.WriteKeyer(keyer2)
@@ -3,9 +3,11 @@ package tci
 import (
 	"fmt"
 	"log"
+	"math"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ftl/hamradio"
@@ -13,9 +15,13 @@ import (
 	"github.com/ftl/tci/client"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/bandplans"
 )
 
-const retryInterval = 10 * time.Second
+var retryInterval = 10 * time.Second
+
+// speedStep is the change of the speed in WpM for every > or < within a CW macro.
+const speedStep = 2
 
 type VFOController interface {
 	SetFrequency(core.Frequency)
@@ -24,6 +30,11 @@ type VFOController interface {
 }
 
 func NewClient(address string) (*Client, error) {
+	return NewClientForTRX(address, 0)
+}
+
+// NewClientForTRX returns a new client that controls the given transceiver of the TCI host.
+func NewClientForTRX(address string, trx int) (*Client, error) {
 	host, err := parseTCPAddr(address)
 	if err != nil {
 		return nil, err
@@ -35,6 +46,7 @@ func NewClient(address string) (*Client, error) {
 	}
 	result.trx = &trxListener{
 		client: result,
+		trx:    trx,
 	}
 	result.client = client.KeepOpen(host, retryInterval)
 	result.client.Notify(result.trx)
@@ -43,14 +55,20 @@ func NewClient(address string) (*Client, error) {
 }
 
 type Client struct {
-	client     *client.Client
-	controller VFOController
-	bandplan   bandplan.Bandplan
+	client *client.Client
 
-	trx       *trxListener
-	connected bool
+	bandplanLock sync.RWMutex
+	bandplan     bandplan.Bandplan
 
-	listeners []interface{}
+	trx *trxListener
+	wpm int
+
+	// the TCI client connects in the background right away, so everything that is used by the trxListener needs to
+	// be guarded
+	lock       sync.RWMutex
+	controller VFOController
+	connected  bool
+	listeners  []interface{}
 }
 
 func (c *Client) Disconnect() {
@@ -59,17 +77,37 @@ func (c *Client) Disconnect() {
 
 func (c *Client) SetVFOController(controller VFOController) {
 	if controller == nil {
-		c.controller = new(nullController)
+		controller = new(nullController)
 	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	c.controller = controller
 }
 
+func (c *Client) currentController() VFOController {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.controller
+}
+
 func (c *Client) Notify(listener interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	c.listeners = append(c.listeners, listener)
 }
 
+func (c *Client) setConnected(connected bool) {
+	c.lock.Lock()
+	c.connected = connected
+	c.lock.Unlock()
+	c.emitStatusChanged(connected)
+}
+
 func (c *Client) emitStatusChanged(available bool) {
-	for _, listener := range c.listeners {
+	c.lock.RLock()
+	listeners := c.listeners
+	c.lock.RUnlock()
+	for _, listener := range listeners {
 		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
 			serviceStatusListener.StatusChanged(core.TCIService, available)
 			serviceStatusListener.StatusChanged(core.CWDaemonService, available)
@@ -78,21 +116,29 @@ func (c *Client) emitStatusChanged(available bool) {
 }
 
 func (c *Client) Connect() error {
-	if !c.connected {
+	if !c.Active() {
 		return fmt.Errorf("cannot connect to TCI host")
 	}
 	return nil
 }
 
 func (c *Client) IsConnected() bool {
-	return c.connected
+	return c.Active()
 }
 
 func (c *Client) Active() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return c.connected
 }
 
+// VFOs returns the current state of VFO A and B and the split mode.
+func (c *Client) VFOs() core.VFOs {
+	return c.trx.VFOs()
+}
+
 func (c *Client) Speed(wpm int) {
+	c.wpm = wpm
 	err := c.client.SetCWMacrosSpeed(wpm)
 	if err != nil && err != client.ErrReadTimeout {
 		log.Printf("cannot set CW speed: %v", err)
@@ -106,6 +152,18 @@ func (c *Client) Send(text string) {
 	}
 }
 
+// SendWithSpeed sends the given text with the given speed. After the text, the TCI host returns to the current speed.
+// The speed is changed within a CW macro in steps of 2 WpM, the given speed is rounded to the nearest step.
+func (c *Client) SendWithSpeed(text string, wpm int) {
+	steps := int(math.Round(float64(wpm-c.wpm) / speedStep))
+	change, restore := ">", "<"
+	if steps < 0 {
+		change, restore = "<", ">"
+		steps = -steps
+	}
+	c.Send(strings.Repeat(change, steps) + text + strings.Repeat(restore, steps))
+}
+
 func (c *Client) Abort() {
 	err := c.client.StopCW()
 	if err != nil && err != client.ErrReadTimeout {
@@ -113,6 +171,15 @@ func (c *Client) Abort() {
 	}
 }
 
+// SetPTT keys the transmitter with the audio from the virtual audio cable (VAC). The voice keyer uses this to
+// transmit its recordings, the audio must be routed from the local audio sink to the VAC.
+func (c *Client) SetPTT(on bool) {
+	err := c.client.SetTX(c.trx.trx, on, client.SignalSourceVAC)
+	if err != nil && err != client.ErrReadTimeout {
+		log.Printf("cannot set PTT: %v", err)
+	}
+}
+
 func (c *Client) SetFrequency(frequency core.Frequency) {
 	err := c.client.SetVFOFrequency(c.trx.trx, client.VFOA, int(frequency))
 	if err != nil && err != client.ErrReadTimeout {
@@ -121,14 +188,27 @@ func (c *Client) SetFrequency(frequency core.Frequency) {
 }
 
 func (c *Client) SetBand(band core.Band) {
-	bandplanBand := c.bandplan[toBandplanBandName(band)]
-	frequency := findModePortionCenter(int(bandplanBand.Center()), toBandplanMode(c.trx.mode))
-	err := c.client.SetVFOFrequency(c.trx.trx, client.VFOA, frequency)
+	bandplanBand := c.currentBandplan()[toBandplanBandName(band)]
+	frequency := bandplans.ModePortionCenter(bandplanBand, toBandplanMode(c.trx.currentMode()))
+	err := c.client.SetVFOFrequency(c.trx.trx, client.VFOA, int(frequency))
 	if err != nil && err != client.ErrReadTimeout {
 		log.Printf("cannot switch to band %s: %v", band, err)
 	}
 }
 
+// StationChanged selects the bandplan of the station's IARU region.
+func (c *Client) StationChanged(station core.Station) {
+	c.bandplanLock.Lock()
+	defer c.bandplanLock.Unlock()
+	c.bandplan = bandplans.ForRegion(station.Region)
+}
+
+func (c *Client) currentBandplan() bandplan.Bandplan {
+	c.bandplanLock.RLock()
+	defer c.bandplanLock.RUnlock()
+	return c.bandplan
+}
+
 func (c *Client) SetMode(mode core.Mode) {
 	err := c.client.SetMode(c.trx.trx, toClientMode(mode))
 	if err != nil && err != client.ErrReadTimeout {
@@ -141,45 +221,95 @@ func (c *Client) Refresh() {
 }
 
 type trxListener struct {
-	client    *Client
-	trx       int
+	client *Client
+	trx    int
+
+	lock      sync.Mutex
 	frequency core.Frequency
 	band      core.Band
 	mode      core.Mode
+
+	vfosLock sync.RWMutex
+	vfos     core.VFOs
+}
+
+func (l *trxListener) VFOs() core.VFOs {
+	l.vfosLock.RLock()
+	defer l.vfosLock.RUnlock()
+	return l.vfos
+}
+
+func (l *trxListener) updateVFOs(update func(*core.VFOs)) {
+	l.vfosLock.Lock()
+	defer l.vfosLock.Unlock()
+	update(&l.vfos)
 }
 
 func (l *trxListener) Refresh() {
-	l.client.controller.SetFrequency(l.frequency)
-	l.client.controller.SetBand(l.band)
-	l.client.controller.SetMode(l.mode)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	controller := l.client.currentController()
+	controller.SetFrequency(l.frequency)
+	controller.SetBand(l.band)
+	controller.SetMode(l.mode)
+}
+
+func (l *trxListener) currentMode() core.Mode {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.mode
 }
 
 func (l *trxListener) Connected(connected bool) {
-	l.client.connected = connected
-	l.client.emitStatusChanged(connected)
+	l.client.setConnected(connected)
 }
 
 func (l *trxListener) SetVFOFrequency(trx int, vfo client.VFO, frequency int) {
-	if trx != l.trx || vfo != client.VFOA {
+	if trx != l.trx {
 		return
 	}
 	incomingFrequency := core.Frequency(frequency)
+	if vfo == client.VFOB {
+		l.updateVFOs(func(vfos *core.VFOs) {
+			vfos.B = incomingFrequency
+		})
+		return
+	}
+	if vfo != client.VFOA {
+		return
+	}
+	l.updateVFOs(func(vfos *core.VFOs) {
+		vfos.A = incomingFrequency
+	})
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	if l.frequency == incomingFrequency {
 		return
 	}
 	l.frequency = incomingFrequency
-	l.client.controller.SetFrequency(l.frequency)
+	controller := l.client.currentController()
+	controller.SetFrequency(l.frequency)
 	log.Printf("incoming frequency: %s", l.frequency)
 
-	band := l.client.bandplan.ByFrequency(hamradio.Frequency(frequency))
+	band := l.client.currentBandplan().ByFrequency(hamradio.Frequency(frequency))
 	incomingBand := toCoreBand(band.Name)
 	if incomingBand == l.band {
 		return
 	}
 	l.band = incomingBand
-	l.client.controller.SetBand(l.band)
+	controller.SetBand(l.band)
 	log.Printf("incoming band: %v", l.band)
+}
 
+func (l *trxListener) SetSplitEnable(trx int, enabled bool) {
+	if trx != l.trx {
+		return
+	}
+	l.updateVFOs(func(vfos *core.VFOs) {
+		vfos.Split = enabled
+	})
+	log.Printf("incoming split: %t", enabled)
 }
 
 func (l *trxListener) SetMode(trx int, mode client.Mode) {
@@ -187,11 +317,14 @@ func (l *trxListener) SetMode(trx int, mode client.Mode) {
 		return
 	}
 	incomingMode := toCoreMode(mode)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	if incomingMode == l.mode {
 		return
 	}
 	l.mode = incomingMode
-	l.client.controller.SetMode(l.mode)
+	l.client.currentController().SetMode(l.mode)
 	log.Printf("incoming mode %v", incomingMode)
 }
 
@@ -254,31 +387,6 @@ func toBandplanMode(mode core.Mode) bandplan.Mode {
 	}
 }
 
-func findModePortionCenter(f int, mode bandplan.Mode) int {
-	frequency := hamradio.Frequency(f)
-	band := bandplan.IARURegion1.ByFrequency(frequency)
-	var modePortion bandplan.Portion
-	var currentPortion bandplan.Portion
-	for _, portion := range band.Portions {
-		if (portion.Mode == mode && portion.From < frequency) || modePortion.Mode != mode {
-			modePortion = portion
-		}
-		if portion.Contains(frequency) {
-			currentPortion = portion
-		}
-		if modePortion.Mode == mode && currentPortion.Mode != "" {
-			break
-		}
-	}
-	if currentPortion.Mode == mode {
-		return int(currentPortion.Center())
-	}
-	if modePortion.Mode == mode {
-		return int(modePortion.Center())
-	}
-	return int(band.Center())
-}
-
 func parseTCPAddr(arg string) (*net.TCPAddr, error) {
 	host, port := splitHostPort(arg)
 	if host == "" {
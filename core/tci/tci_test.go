@@ -0,0 +1,244 @@
+package tci
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/rigsim"
+)
+
+const (
+	waitFor = 2 * time.Second
+	tick    = 10 * time.Millisecond
+)
+
+func init() {
+	retryInterval = 50 * time.Millisecond
+}
+
+func TestClient_IncomingFrequencyAndBand(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	_, client, controller := setupTCI(t, 0, rig)
+	assert.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	<-rigsim.Play(
+		rigsim.Do(func() { rig.SetFrequency(14020000) }),
+		rigsim.Wait(50*time.Millisecond),
+		rigsim.Do(func() { rig.SetFrequency(7020000) }),
+	)
+
+	assert.Eventually(t, controller.has(7020000, core.Band40m, core.ModeCW), waitFor, tick)
+	assert.Equal(t, []core.Band{core.Band20m, core.Band40m}, controller.bands(), "the band is only emitted when it changes")
+	assert.Equal(t, core.Frequency(7020000), client.VFOs().A)
+}
+
+func TestClient_IncomingModeMapping(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	_, _, controller := setupTCI(t, 0, rig)
+	testCases := []struct {
+		hamlibMode string
+		expected   core.Mode
+	}{
+		{rigsim.ModeUSB, core.ModeSSB},
+		{rigsim.ModeCW, core.ModeCW},
+		{rigsim.ModeLSB, core.ModeSSB},
+		{rigsim.ModeFM, core.ModeFM},
+		{rigsim.ModeRTTY, core.ModeDigital},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.hamlibMode, func(t *testing.T) {
+			rig.SetMode(tc.hamlibMode)
+			assert.Eventually(t, func() bool { return controller.currentMode() == tc.expected }, waitFor, tick)
+		})
+	}
+}
+
+func TestClient_IncomingSplit(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	_, client, _ := setupTCI(t, 0, rig)
+
+	rig.SetSplitFrequency(14012000)
+	rig.SetSplit(true)
+
+	expected := core.VFOs{A: 14010000, B: 14012000, Split: true}
+	assert.Eventually(t, func() bool { return client.VFOs() == expected }, waitFor, tick)
+}
+
+func TestClient_OutgoingBandAndMode(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	_, client, controller := setupTCI(t, 0, rig)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	client.SetBand(core.Band40m)
+	band := client.bandplan.ByFrequency(hamradio.Frequency(rig.Frequency()))
+	assert.Equal(t, core.Band40m, toCoreBand(band.Name))
+	assert.Eventually(t, func() bool { return controller.currentBand() == core.Band40m }, waitFor, tick)
+
+	client.SetMode(core.ModeSSB)
+	assert.Equal(t, rigsim.ModeUSB, rig.Mode())
+	assert.Eventually(t, func() bool { return controller.currentMode() == core.ModeSSB }, waitFor, tick)
+
+	client.SetMode(core.ModeRTTY)
+	assert.Equal(t, rigsim.ModePKTUSB, rig.Mode())
+
+	client.SetFrequency(7150000)
+	assert.Equal(t, core.Frequency(7150000), rig.Frequency())
+}
+
+func TestClient_FollowsOnlyItsTRX(t *testing.T) {
+	rig1 := rigsim.NewRig(14010000, rigsim.ModeCW)
+	rig2 := rigsim.NewRig(7010000, rigsim.ModeCW)
+	_, client, controller := setupTCI(t, 1, rig1, rig2)
+	require.Eventually(t, controller.has(7010000, core.Band40m, core.ModeCW), waitFor, tick)
+
+	rig1.SetFrequency(21010000)
+	rig2.SetMode(rigsim.ModeUSB)
+	assert.Eventually(t, controller.has(7010000, core.Band40m, core.ModeSSB), waitFor, tick)
+
+	client.SetFrequency(7020000)
+	assert.Equal(t, core.Frequency(7020000), rig2.Frequency())
+	assert.Equal(t, core.Frequency(21010000), rig1.Frequency())
+}
+
+func TestClient_ReconnectsAfterDisconnect(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	server, client, controller := setupTCI(t, 0, rig)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+	require.Equal(t, []bool{true}, controller.statuses())
+
+	server.Disconnect()
+	assert.Eventually(t, func() bool { return len(controller.statuses()) == 3 }, waitFor, tick)
+	assert.Equal(t, []bool{true, false, true}, controller.statuses())
+	assert.NoError(t, client.Connect())
+
+	rig.SetFrequency(3510000)
+	assert.Eventually(t, controller.has(3510000, core.Band80m, core.ModeCW), waitFor, tick)
+}
+
+func TestClient_SendCW(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	server, client, controller := setupTCI(t, 0, rig)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+	server.SetLatency(20 * time.Millisecond)
+
+	client.Speed(30)
+	client.Send("cq test dl1abc")
+
+	assert.Equal(t, 30, server.CWSpeed())
+	assert.Eventually(t, func() bool { return len(rig.CW()) == 1 }, waitFor, tick)
+	assert.Equal(t, []string{"cq test dl1abc"}, rig.CW())
+}
+
+func TestClient_SendCWWithSpeed(t *testing.T) {
+	rig := rigsim.NewRig(14010000, rigsim.ModeCW)
+	server, client, controller := setupTCI(t, 0, rig)
+	require.Eventually(t, controller.has(14010000, core.Band20m, core.ModeCW), waitFor, tick)
+
+	client.Speed(24)
+	client.SendWithSpeed("5nn", 28)
+	client.SendWithSpeed("tu", 20)
+
+	assert.Equal(t, 24, server.CWSpeed(), "the current speed is not changed")
+	assert.Eventually(t, func() bool { return len(rig.CW()) == 2 }, waitFor, tick)
+	assert.Equal(t, []string{">>5nn<<", "<<tu>>"}, rig.CW())
+}
+
+func setupTCI(t *testing.T, trx int, rigs ...*rigsim.Rig) (*rigsim.TCIServer, *Client, *testController) {
+	t.Helper()
+	server, err := rigsim.NewTCIServer("", rigs...)
+	require.NoError(t, err)
+
+	client, err := NewClientForTRX(server.Address(), trx)
+	require.NoError(t, err)
+	controller := new(testController)
+	client.SetVFOController(controller)
+	client.Notify(core.ServiceStatusListenerFunc(controller.statusChanged))
+
+	t.Cleanup(func() {
+		// the TCI client cannot be closed while connected, so the server goes first
+		server.Close()
+		assert.Eventually(t, func() bool { return !client.client.Connected() }, waitFor, tick)
+		client.Disconnect()
+	})
+	return server, client, controller
+}
+
+type testController struct {
+	lock          sync.Mutex
+	frequency     core.Frequency
+	bandHistory   []core.Band
+	mode          core.Mode
+	statusHistory []bool
+}
+
+func (c *testController) SetFrequency(frequency core.Frequency) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.frequency = frequency
+}
+
+func (c *testController) SetBand(band core.Band) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.bandHistory = append(c.bandHistory, band)
+}
+
+func (c *testController) SetMode(mode core.Mode) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.mode = mode
+}
+
+func (c *testController) statusChanged(service core.Service, available bool) {
+	if service != core.TCIService {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.statusHistory = append(c.statusHistory, available)
+}
+
+func (c *testController) has(frequency core.Frequency, band core.Band, mode core.Mode) func() bool {
+	return func() bool {
+		return c.currentFrequency() == frequency && c.currentBand() == band && c.currentMode() == mode
+	}
+}
+
+func (c *testController) currentFrequency() core.Frequency {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.frequency
+}
+
+func (c *testController) currentBand() core.Band {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.bandHistory) == 0 {
+		return core.NoBand
+	}
+	return c.bandHistory[len(c.bandHistory)-1]
+}
+
+func (c *testController) currentMode() core.Mode {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.mode
+}
+
+func (c *testController) bands() []core.Band {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]core.Band{}, c.bandHistory...)
+}
+
+func (c *testController) statuses() []bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]bool{}, c.statusHistory...)
+}
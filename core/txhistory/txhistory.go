@@ -0,0 +1,236 @@
+// Package txhistory keeps the history of all messages that were sent by the keyer.
+package txhistory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+// FileSuffix is appended to the name of the logfile to get the name of the file that contains the transmit history.
+const FileSuffix = ".tx.csv"
+
+var header = []string{"time", "radio", "macro", "wpm", "text"}
+
+// Filename returns the name of the file that contains the transmit history for the given logfile.
+func Filename(logfile string) string {
+	return strings.TrimSuffix(logfile, filepath.Ext(logfile)) + FileSuffix
+}
+
+type View interface {
+	Show()
+	Hide()
+
+	ShowTransmissions([]core.Transmission)
+}
+
+// New returns a new empty transmit history.
+func New() *History {
+	return &History{
+		view: new(nullView),
+	}
+}
+
+// History keeps the transmissions in memory. If a file is set, each transmission is also appended to this file.
+type History struct {
+	view          View
+	transmissions []core.Transmission
+	radio         string
+	filename      string
+}
+
+func (h *History) SetView(view View) {
+	if view == nil {
+		h.view = new(nullView)
+		return
+	}
+	h.view = view
+	h.view.ShowTransmissions(h.transmissions)
+}
+
+func (h *History) Show() {
+	h.view.Show()
+	h.view.ShowTransmissions(h.transmissions)
+}
+
+func (h *History) Hide() {
+	h.view.Hide()
+}
+
+// SetRadio sets the radio that is used for the following transmissions.
+func (h *History) SetRadio(radio string) {
+	h.radio = radio
+}
+
+// Transmissions returns all transmissions, the oldest first.
+func (h *History) Transmissions() []core.Transmission {
+	result := make([]core.Transmission, len(h.transmissions))
+	copy(result, h.transmissions)
+	return result
+}
+
+// Transmitted adds the given transmission to the history.
+func (h *History) Transmitted(transmission core.Transmission) {
+	if transmission.Radio == "" {
+		transmission.Radio = h.radio
+	}
+	h.transmissions = append(h.transmissions, transmission)
+	h.view.ShowTransmissions(h.transmissions)
+
+	if h.filename == "" {
+		return
+	}
+	err := h.appendToFile(transmission)
+	if err != nil {
+		log.Printf("cannot write the transmit history to %s: %v", h.filename, err)
+	}
+}
+
+// Load replaces the history with the content of the given file and appends all following transmissions to
+// this file. If the file does not exist yet, the history is empty. If the filename is empty, the history is only
+// cleared and not persisted.
+func (h *History) Load(filename string) error {
+	h.transmissions = nil
+	h.filename = filename
+	defer h.view.ShowTransmissions(h.transmissions)
+	if filename == "" {
+		return nil
+	}
+
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.transmissions, err = read(f)
+	return err
+}
+
+// SaveAs writes the whole history to the given file and appends all following transmissions to this file.
+func (h *History) SaveAs(filename string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = h.Export(f)
+	if err != nil {
+		return err
+	}
+	h.filename = filename
+	return nil
+}
+
+// Export writes the whole history as CSV to the given writer.
+func (h *History) Export(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, transmission := range h.transmissions {
+		err = writer.Write(record(transmission))
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (h *History) appendToFile(transmission core.Transmission) error {
+	f, err := os.OpenFile(h.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(f)
+	if stat.Size() == 0 {
+		err = writer.Write(header)
+		if err != nil {
+			return err
+		}
+	}
+	err = writer.Write(record(transmission))
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func record(transmission core.Transmission) []string {
+	return []string{
+		transmission.Time.UTC().Format(time.RFC3339),
+		transmission.Radio,
+		strconv.Itoa(transmission.Macro),
+		strconv.Itoa(transmission.WPM),
+		transmission.Text,
+	}
+}
+
+func read(r io.Reader) ([]core.Transmission, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(header)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]core.Transmission, 0, len(records))
+	for i, record := range records {
+		if i == 0 && record[0] == header[0] {
+			continue
+		}
+		transmission, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		result = append(result, transmission)
+	}
+	return result, nil
+}
+
+func parseRecord(record []string) (core.Transmission, error) {
+	var result core.Transmission
+	var err error
+	result.Time, err = time.Parse(time.RFC3339, record[0])
+	if err != nil {
+		return core.Transmission{}, err
+	}
+	result.Radio = record[1]
+	result.Macro, err = strconv.Atoi(record[2])
+	if err != nil {
+		return core.Transmission{}, err
+	}
+	result.WPM, err = strconv.Atoi(record[3])
+	if err != nil {
+		return core.Transmission{}, err
+	}
+	result.Text = record[4]
+	return result, nil
+}
+
+type nullView struct{}
+
+func (*nullView) Show()                                 {}
+func (*nullView) Hide()                                 {}
+func (*nullView) ShowTransmissions([]core.Transmission) {}
@@ -0,0 +1,85 @@
+package txhistory
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+func TestFilename(t *testing.T) {
+	assert.Equal(t, "/logs/contest.tx.csv", Filename("/logs/contest.log"))
+	assert.Equal(t, "current.tx.csv", Filename("current.log"))
+}
+
+func TestTransmitted_UsesCurrentRadio(t *testing.T) {
+	history := New()
+	history.SetRadio("R2")
+
+	history.Transmitted(core.Transmission{Text: "cq"})
+	history.Transmitted(core.Transmission{Text: "tu", Radio: "R1"})
+
+	transmissions := history.Transmissions()
+	require.Len(t, transmissions, 2)
+	assert.Equal(t, "R2", transmissions[0].Radio)
+	assert.Equal(t, "R1", transmissions[1].Radio)
+}
+
+func TestExport(t *testing.T) {
+	history := New()
+	history.Transmitted(core.Transmission{Time: time.Date(2026, 10, 18, 12, 34, 56, 0, time.UTC), Text: "dl1abc 5nn 001", WPM: 28, Radio: "R1", Macro: 2})
+	buffer := new(bytes.Buffer)
+
+	err := history.Export(buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, "time,radio,macro,wpm,text\n2026-10-18T12:34:56Z,R1,2,28,dl1abc 5nn 001\n", buffer.String())
+}
+
+func TestLoad_ContinuesPersistedHistory(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "contest.tx.csv")
+	history := New()
+	err := history.Load(filename)
+	require.NoError(t, err)
+	first := core.Transmission{Time: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), Text: "cq test", WPM: 30, Radio: "R1", Macro: 1}
+	second := core.Transmission{Time: time.Date(2026, 10, 18, 12, 1, 0, 0, time.UTC), Text: "nr?", WPM: 30, Radio: "R2"}
+	history.Transmitted(first)
+	history.Transmitted(second)
+
+	reloaded := New()
+	err = reloaded.Load(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.Transmission{first, second}, reloaded.Transmissions())
+}
+
+func TestLoad_WithoutFileOnlyClears(t *testing.T) {
+	history := New()
+	history.Transmitted(core.Transmission{Text: "cq"})
+
+	err := history.Load("")
+	require.NoError(t, err)
+
+	assert.Empty(t, history.Transmissions())
+}
+
+func TestSaveAs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "contest.tx.csv")
+	history := New()
+	transmission := core.Transmission{Time: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), Text: "cq test", WPM: 30, Radio: "R1", Macro: 1}
+	history.Transmitted(transmission)
+
+	err := history.SaveAs(filename)
+	require.NoError(t, err)
+	history.Transmitted(transmission)
+
+	reloaded := New()
+	err = reloaded.Load(filename)
+	require.NoError(t, err)
+	assert.Len(t, reloaded.Transmissions(), 2)
+}
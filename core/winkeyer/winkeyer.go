@@ -0,0 +1,529 @@
+// Package winkeyer sends CW through a K1EL Winkeyer (WK2 or WK3) that is connected to a serial port, using the
+// Winkeyer host mode protocol.
+package winkeyer
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/serial"
+)
+
+const (
+	baudRate = 1200
+	stopBits = 2
+
+	// maxInFlight is the maximum number of characters that were written to the Winkeyer but not yet sent.
+	// This keeps the Winkeyer's buffer small, so that an abort or a change of speed takes effect quickly.
+	maxInFlight = 16
+
+	minSpeed = 5
+	maxSpeed = 99
+
+	minWeight = 10
+	maxWeight = 90
+
+	minFarnsworth = 10
+	maxFarnsworth = 99
+
+	// the range of the speed pot in WpM
+	potMin   = 10
+	potRange = 40
+)
+
+// the commands of the Winkeyer host mode protocol
+const (
+	cmdAdmin       byte = 0x00
+	cmdSpeed       byte = 0x02
+	cmdWeight      byte = 0x03
+	cmdSetupPot    byte = 0x05
+	cmdClearBuffer byte = 0x0A
+	cmdFarnsworth  byte = 0x0D
+	cmdMode        byte = 0x0E
+
+	cmdBufferedSpeed       byte = 0x1C
+	cmdCancelBufferedSpeed byte = 0x1E
+
+	adminHostOpen  byte = 0x02
+	adminHostClose byte = 0x03
+
+	modeSerialEcho byte = 0x04
+)
+
+// the bits of the status byte
+const (
+	statusXOFF    byte = 0x01
+	statusBreakin byte = 0x02
+	statusBusy    byte = 0x04
+	statusKeydown byte = 0x08
+	statusWait    byte = 0x10
+)
+
+// Status is the status reported by the Winkeyer.
+type Status struct {
+	// XOFF indicates that the Winkeyer's buffer is more than 2/3 full.
+	XOFF bool
+	// Breakin indicates that the operator uses the paddle.
+	Breakin bool
+	// Busy indicates that the Winkeyer is sending.
+	Busy bool
+	// Keydown indicates that the Winkeyer is in tune mode.
+	Keydown bool
+	// Wait indicates that the Winkeyer waits for an internal event to finish.
+	Wait bool
+}
+
+func toStatus(b byte) Status {
+	return Status{
+		XOFF:    b&statusXOFF != 0,
+		Breakin: b&statusBreakin != 0,
+		Busy:    b&statusBusy != 0,
+		Keydown: b&statusKeydown != 0,
+		Wait:    b&statusWait != 0,
+	}
+}
+
+// EchoListener is notified about every character that the Winkeyer sent.
+type EchoListener interface {
+	CharacterSent(rune)
+}
+
+type EchoListenerFunc func(rune)
+
+func (f EchoListenerFunc) CharacterSent(r rune) {
+	f(r)
+}
+
+// SpeedPotListener is notified when the operator turns the speed pot of the Winkeyer.
+type SpeedPotListener interface {
+	SpeedPotChanged(wpm int)
+}
+
+type SpeedPotListenerFunc func(int)
+
+func (f SpeedPotListenerFunc) SpeedPotChanged(wpm int) {
+	f(wpm)
+}
+
+// StatusListener is notified when the status of the Winkeyer changes.
+type StatusListener interface {
+	WinkeyerStatusChanged(Status)
+}
+
+type StatusListenerFunc func(Status)
+
+func (f StatusListenerFunc) WinkeyerStatusChanged(status Status) {
+	f(status)
+}
+
+func New(port string) *Client {
+	return &Client{
+		port:    port,
+		timeout: 2 * time.Second,
+	}
+}
+
+type Client struct {
+	port      string
+	timeout   time.Duration
+	listeners []interface{}
+
+	// writeLock serializes the writes to the serial port, it is acquired before lock.
+	writeLock sync.Mutex
+
+	lock     sync.Mutex
+	conn     io.ReadWriteCloser
+	closed   chan struct{}
+	writable chan struct{}
+	version  byte
+	wpm      int
+	status   Status
+	pending  []byte
+	inFlight int
+}
+
+func (c *Client) Notify(listener interface{}) {
+	c.listeners = append(c.listeners, listener)
+}
+
+// Connect opens the serial port and the host mode of the Winkeyer.
+func (c *Client) Connect() error {
+	if c.IsConnected() {
+		return nil
+	}
+
+	conn, err := serial.Open(c.port, baudRate, stopBits)
+	if err != nil {
+		return fmt.Errorf("cannot open the Winkeyer port: %w", err)
+	}
+	closed := make(chan struct{})
+	version := make(chan byte, 1)
+
+	c.lock.Lock()
+	c.conn = conn
+	c.closed = closed
+	c.writable = make(chan struct{}, 1)
+	c.status = Status{}
+	c.pending = nil
+	c.inFlight = 0
+	wpm := c.wpm
+	c.lock.Unlock()
+
+	go c.readLoop(conn, closed, version)
+
+	err = c.write(cmdAdmin, adminHostOpen)
+	if err != nil {
+		c.Disconnect()
+		return err
+	}
+	select {
+	case v := <-version:
+		c.lock.Lock()
+		c.version = v
+		c.lock.Unlock()
+		log.Printf("Winkeyer version %d connected at %s", v, c.port)
+	case <-time.After(c.timeout):
+		c.Disconnect()
+		return fmt.Errorf("the Winkeyer at %s does not respond", c.port)
+	}
+
+	err = c.write(cmdMode, modeSerialEcho)
+	if err == nil {
+		err = c.write(cmdSetupPot, potMin, potRange, 0)
+	}
+	if err == nil && wpm > 0 {
+		err = c.write(cmdSpeed, byte(wpm))
+	}
+	if err != nil {
+		c.Disconnect()
+		return err
+	}
+
+	go c.writeLoop(closed)
+	c.emitStatusChanged(true)
+	return nil
+}
+
+func (c *Client) IsConnected() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.conn != nil
+}
+
+// Version returns the firmware version of the connected Winkeyer.
+func (c *Client) Version() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return int(c.version)
+}
+
+// Disconnect closes the host mode of the Winkeyer and the serial port.
+func (c *Client) Disconnect() {
+	if !c.IsConnected() {
+		return
+	}
+	c.write(cmdAdmin, adminHostClose)
+	c.close()
+}
+
+func (c *Client) close() {
+	c.lock.Lock()
+	conn := c.conn
+	closed := c.closed
+	c.conn = nil
+	c.lock.Unlock()
+	if conn == nil {
+		return
+	}
+
+	close(closed)
+	conn.Close()
+	c.emitStatusChanged(false)
+}
+
+func (c *Client) Speed(wpm int) {
+	wpm = limitSpeed(wpm)
+	c.lock.Lock()
+	c.wpm = wpm
+	c.lock.Unlock()
+
+	err := c.write(cmdSpeed, byte(wpm))
+	if err != nil {
+		log.Printf("cannot set the Winkeyer speed: %v", err)
+	}
+}
+
+func limitSpeed(wpm int) int {
+	return limit(wpm, minSpeed, maxSpeed)
+}
+
+// Weight sets the weighting in percent, 50 is the standard weighting.
+func (c *Client) Weight(percent int) {
+	err := c.write(cmdWeight, byte(limit(percent, minWeight, maxWeight)))
+	if err != nil {
+		log.Printf("cannot set the Winkeyer weighting: %v", err)
+	}
+}
+
+// Farnsworth sets the speed of the characters when Farnsworth spacing is used. The Winkeyer uses Farnsworth spacing
+// only if this speed is higher than the current speed, 0 disables the Farnsworth spacing.
+func (c *Client) Farnsworth(wpm int) {
+	if wpm != 0 {
+		wpm = limit(wpm, minFarnsworth, maxFarnsworth)
+	}
+	err := c.write(cmdFarnsworth, byte(wpm))
+	if err != nil {
+		log.Printf("cannot set the Winkeyer Farnsworth speed: %v", err)
+	}
+}
+
+func limit(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// Send queues the given text for sending. The text is written to the Winkeyer piece by piece, as it sends the
+// characters.
+func (c *Client) Send(text string) {
+	c.lock.Lock()
+	c.pending = append(c.pending, toWinkeyerText(text)...)
+	c.lock.Unlock()
+	c.signalWritable()
+}
+
+// SendWithSpeed queues the given text for sending with the given speed. After the text, the Winkeyer returns to
+// its current speed.
+func (c *Client) SendWithSpeed(text string, wpm int) {
+	c.lock.Lock()
+	c.pending = append(c.pending, cmdBufferedSpeed, byte(limitSpeed(wpm)))
+	c.pending = append(c.pending, toWinkeyerText(text)...)
+	c.pending = append(c.pending, cmdCancelBufferedSpeed)
+	c.lock.Unlock()
+	c.signalWritable()
+}
+
+// Abort clears all text that was not yet sent, including the buffer of the Winkeyer.
+func (c *Client) Abort() {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.lock.Lock()
+	c.pending = nil
+	c.inFlight = 0
+	conn := c.conn
+	c.lock.Unlock()
+	if conn == nil {
+		return
+	}
+
+	_, err := conn.Write([]byte{cmdClearBuffer})
+	if err != nil {
+		log.Printf("cannot abort sending on the Winkeyer: %v", err)
+	}
+}
+
+// toWinkeyerText converts the given text into the characters that the Winkeyer understands.
+func toWinkeyerText(text string) []byte {
+	result := make([]byte, 0, len(text))
+	for _, r := range strings.ToUpper(text) {
+		switch {
+		case r == '\n' || r == '\t':
+			result = append(result, ' ')
+		case r >= 0x20 && r < 0x7F:
+			result = append(result, byte(r))
+		}
+	}
+	return result
+}
+
+func (c *Client) write(data ...byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.lock.Lock()
+	conn := c.conn
+	c.lock.Unlock()
+	if conn == nil {
+		return fmt.Errorf("the Winkeyer is not connected")
+	}
+
+	_, err := conn.Write(data)
+	return err
+}
+
+func (c *Client) signalWritable() {
+	c.lock.Lock()
+	writable := c.writable
+	c.lock.Unlock()
+	if writable == nil {
+		return
+	}
+	select {
+	case writable <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop writes the pending text to the Winkeyer, as long as it has room in its buffer.
+func (c *Client) writeLoop(closed chan struct{}) {
+	c.lock.Lock()
+	writable := c.writable
+	c.lock.Unlock()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-writable:
+		}
+
+		err := c.writePending()
+		if err != nil {
+			log.Printf("cannot write to the Winkeyer: %v", err)
+			c.close()
+			return
+		}
+	}
+}
+
+func (c *Client) writePending() error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.lock.Lock()
+	if c.status.XOFF || c.conn == nil {
+		c.lock.Unlock()
+		return nil
+	}
+	n := 0
+	chars := 0
+	for n < len(c.pending) && c.inFlight+chars < maxInFlight {
+		if c.pending[n] == cmdBufferedSpeed {
+			// the buffered speed change is not echoed, and it must be kept together with its parameter
+			n += 2
+			continue
+		}
+		if c.pending[n] >= 0x20 {
+			chars++
+		}
+		n++
+	}
+	if n > len(c.pending) {
+		n = len(c.pending)
+	}
+	if n == 0 {
+		c.lock.Unlock()
+		return nil
+	}
+	data := c.pending[:n]
+	c.pending = c.pending[n:]
+	c.inFlight += chars
+	conn := c.conn
+	c.lock.Unlock()
+
+	_, err := conn.Write(data)
+	return err
+}
+
+func (c *Client) readLoop(conn io.Reader, closed chan struct{}, version chan<- byte) {
+	buffer := make([]byte, 1)
+	awaitingVersion := true
+	for {
+		_, err := conn.Read(buffer)
+		if err != nil {
+			select {
+			case <-closed:
+			default:
+				log.Printf("cannot read from the Winkeyer: %v", err)
+				c.close()
+			}
+			return
+		}
+		b := buffer[0]
+
+		switch {
+		case awaitingVersion:
+			awaitingVersion = false
+			version <- b
+		case b&0xE0 == 0xC0:
+			c.statusReceived(toStatus(b))
+		case b&0xC0 == 0x80:
+			c.emitSpeedPotChanged(potMin + int(b&0x3F))
+		default:
+			c.echoReceived(b)
+		}
+	}
+}
+
+func (c *Client) statusReceived(status Status) {
+	c.lock.Lock()
+	changed := status != c.status
+	c.status = status
+	if status.Breakin {
+		// the Winkeyer clears its buffer when the operator uses the paddle
+		c.pending = nil
+		c.inFlight = 0
+	}
+	c.lock.Unlock()
+
+	if !changed {
+		return
+	}
+	if !status.XOFF {
+		c.signalWritable()
+	}
+	c.emitWinkeyerStatusChanged(status)
+}
+
+func (c *Client) echoReceived(b byte) {
+	c.lock.Lock()
+	if c.inFlight > 0 {
+		c.inFlight--
+	}
+	c.lock.Unlock()
+
+	c.signalWritable()
+	c.emitCharacterSent(rune(b))
+}
+
+func (c *Client) emitStatusChanged(available bool) {
+	for _, listener := range c.listeners {
+		if serviceStatusListener, ok := listener.(core.ServiceStatusListener); ok {
+			serviceStatusListener.StatusChanged(core.CWDaemonService, available)
+		}
+	}
+}
+
+func (c *Client) emitWinkeyerStatusChanged(status Status) {
+	for _, listener := range c.listeners {
+		if statusListener, ok := listener.(StatusListener); ok {
+			statusListener.WinkeyerStatusChanged(status)
+		}
+	}
+}
+
+func (c *Client) emitSpeedPotChanged(wpm int) {
+	for _, listener := range c.listeners {
+		if speedPotListener, ok := listener.(SpeedPotListener); ok {
+			speedPotListener.SpeedPotChanged(wpm)
+		}
+	}
+}
+
+func (c *Client) emitCharacterSent(r rune) {
+	for _, listener := range c.listeners {
+		if echoListener, ok := listener.(EchoListener); ok {
+			echoListener.CharacterSent(r)
+		}
+	}
+}
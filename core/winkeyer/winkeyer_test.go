@@ -0,0 +1,220 @@
+package winkeyer
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/rigsim"
+)
+
+const (
+	waitFor = 2 * time.Second
+	tick    = 10 * time.Millisecond
+)
+
+func TestClient_Connect(t *testing.T) {
+	sim, client, listener := setupWinkeyer(t)
+
+	assert.True(t, client.IsConnected())
+	assert.True(t, sim.IsOpen())
+	assert.Equal(t, rigsim.WinkeyerVersion, client.Version())
+	assert.Equal(t, []bool{true}, listener.available())
+
+	client.Disconnect()
+
+	assert.False(t, client.IsConnected())
+	assert.Eventually(t, func() bool { return !sim.IsOpen() }, waitFor, tick)
+	assert.Equal(t, []bool{true, false}, listener.available())
+}
+
+func TestClient_ConnectWithoutWinkeyer(t *testing.T) {
+	client := New("/dev/does-not-exist")
+
+	err := client.Connect()
+
+	assert.Error(t, err)
+	assert.False(t, client.IsConnected())
+}
+
+func TestClient_Speed(t *testing.T) {
+	sim, client, _ := setupWinkeyer(t)
+
+	client.Speed(32)
+	assert.Eventually(t, func() bool { return sim.WPM() == 32 }, waitFor, tick)
+
+	client.Speed(200)
+	assert.Eventually(t, func() bool { return sim.WPM() == maxSpeed }, waitFor, tick)
+}
+
+func TestClient_WeightAndFarnsworth(t *testing.T) {
+	sim, client, _ := setupWinkeyer(t)
+
+	client.Weight(60)
+	client.Farnsworth(30)
+	assert.Eventually(t, func() bool { return sim.Weight() == 60 && sim.Farnsworth() == 30 }, waitFor, tick)
+
+	client.Weight(5)
+	client.Farnsworth(0)
+	assert.Eventually(t, func() bool { return sim.Weight() == minWeight && sim.Farnsworth() == 0 }, waitFor, tick)
+}
+
+func TestClient_SendWithBufferManagement(t *testing.T) {
+	sim, client, listener := setupWinkeyer(t)
+	sim.SetCharDuration(time.Millisecond)
+	text := strings.Repeat("cq test dl0abc ", 10)
+	expected := strings.ToUpper(text)
+
+	client.Send(text)
+
+	assert.Eventually(t, func() bool { return sim.Sent() == expected }, 5*time.Second, tick)
+	assert.Eventually(t, func() bool { return listener.echo() == expected }, waitFor, tick)
+	assert.LessOrEqual(t, sim.MaxBuffered(), maxInFlight)
+	assert.Contains(t, listener.statuses(), Status{Busy: true})
+}
+
+func TestClient_SendWithSpeed(t *testing.T) {
+	sim, client, listener := setupWinkeyer(t)
+	client.Speed(25)
+
+	client.Send("cq ")
+	client.SendWithSpeed("dl0abc", 35)
+	client.Send(" test")
+
+	assert.Eventually(t, func() bool { return sim.Sent() == "CQ DL0ABC TEST" }, waitFor, tick)
+	assert.Equal(t, "DL0ABC", sim.SentWith(35))
+	assert.Equal(t, "CQ  TEST", sim.SentWith(25))
+	assert.Eventually(t, func() bool { return listener.echo() == "CQ DL0ABC TEST" }, waitFor, tick)
+}
+
+func TestClient_Abort(t *testing.T) {
+	sim, client, _ := setupWinkeyer(t)
+	sim.SetCharDuration(20 * time.Millisecond)
+	text := strings.Repeat("test ", 20)
+
+	client.Send(text)
+	require.Eventually(t, func() bool { return len(sim.Sent()) > 2 }, waitFor, tick)
+	client.Abort()
+	time.Sleep(50 * time.Millisecond)
+	sent := sim.Sent()
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(t, sent, sim.Sent(), "nothing is sent after abort")
+	assert.Less(t, len(sent), len(text))
+
+	client.Send("tu")
+	assert.Eventually(t, func() bool { return sim.Sent() == sent+"TU" }, waitFor, tick)
+}
+
+func TestClient_PaddleBreakin(t *testing.T) {
+	sim, client, listener := setupWinkeyer(t)
+	sim.SetCharDuration(20 * time.Millisecond)
+	text := strings.Repeat("test ", 20)
+
+	client.Send(text)
+	require.Eventually(t, func() bool { return len(sim.Sent()) > 2 }, waitFor, tick)
+	sim.Paddle()
+
+	assert.Eventually(t, func() bool {
+		for _, status := range listener.statuses() {
+			if status.Breakin {
+				return true
+			}
+		}
+		return false
+	}, waitFor, tick)
+	sent := sim.Sent()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, sent, sim.Sent(), "the pending text is dropped on breakin")
+}
+
+func TestClient_SpeedPot(t *testing.T) {
+	sim, client, listener := setupWinkeyer(t)
+	client.Speed(20)
+	require.Eventually(t, func() bool { return sim.WPM() == 20 }, waitFor, tick, "the speed pot is set up before the speed")
+
+	sim.TurnSpeedPot(28)
+
+	assert.Eventually(t, func() bool { return listener.speedPot() == 28 }, waitFor, tick)
+}
+
+func TestToWinkeyerText(t *testing.T) {
+	assert.Equal(t, []byte("CQ TEST DL0ABC?"), toWinkeyerText("cq test\ndl0abc?äö"))
+}
+
+func setupWinkeyer(t *testing.T) (*rigsim.Winkeyer, *Client, *testListener) {
+	t.Helper()
+	sim, err := rigsim.NewWinkeyer()
+	require.NoError(t, err)
+	t.Cleanup(sim.Close)
+
+	client := New(sim.Port())
+	client.timeout = 500 * time.Millisecond
+	listener := new(testListener)
+	client.Notify(listener)
+	require.NoError(t, client.Connect())
+	t.Cleanup(client.Disconnect)
+
+	return sim, client, listener
+}
+
+type testListener struct {
+	lock            sync.Mutex
+	availableEvents []bool
+	statusEvents    []Status
+	sent            strings.Builder
+	speedPotWPM     int
+}
+
+func (l *testListener) StatusChanged(service core.Service, available bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.availableEvents = append(l.availableEvents, available)
+}
+
+func (l *testListener) WinkeyerStatusChanged(status Status) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.statusEvents = append(l.statusEvents, status)
+}
+
+func (l *testListener) CharacterSent(r rune) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.sent.WriteRune(r)
+}
+
+func (l *testListener) SpeedPotChanged(wpm int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.speedPotWPM = wpm
+}
+
+func (l *testListener) available() []bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]bool{}, l.availableEvents...)
+}
+
+func (l *testListener) statuses() []Status {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]Status{}, l.statusEvents...)
+}
+
+func (l *testListener) echo() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.sent.String()
+}
+
+func (l *testListener) speedPot() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.speedPotWPM
+}
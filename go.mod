@@ -14,6 +14,7 @@ require (
 	github.com/ftl/rigproxy v0.0.0-20200812132905-1b8d78e5c89e
 	github.com/ftl/tci v0.0.0-20210202200542-96c6741d91c2
 	github.com/golang/protobuf v1.4.2
+	github.com/gorilla/websocket v1.4.2
 	github.com/gotk3/gotk3 v0.0.0-20210311170413-be85685ca6db
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.7.0
@@ -12,6 +12,8 @@ import (
 	"github.com/ftl/hellocontest/core/app"
 	"github.com/ftl/hellocontest/core/cfg"
 	"github.com/ftl/hellocontest/core/clock"
+	"github.com/ftl/hellocontest/core/keyer"
+	"github.com/ftl/hellocontest/core/so2r"
 	"github.com/ftl/hellocontest/ui/glade"
 )
 
@@ -34,16 +36,18 @@ func Run(version string, args []string) {
 }
 
 type application struct {
-	id             string
-	version        string
-	app            *gtk.Application
-	builder        *gtk.Builder
-	windowGeometry *gmtry.Geometry
-	mainWindow     *mainWindow
-	callinfoWindow *callinfoWindow
-	scoreWindow    *scoreWindow
-	rateWindow     *rateWindow
-	settingsDialog *settingsDialog
+	id              string
+	version         string
+	app             *gtk.Application
+	builder         *gtk.Builder
+	windowGeometry  *gmtry.Geometry
+	mainWindow      *mainWindow
+	callinfoWindow  *callinfoWindow
+	scoreWindow     *scoreWindow
+	rateWindow      *rateWindow
+	huntingWindow   *huntingWindow
+	txHistoryWindow *txHistoryWindow
+	settingsDialog  *settingsDialog
 
 	controller *app.Controller
 }
@@ -81,20 +85,32 @@ func (a *application) activate() {
 	a.callinfoWindow = setupCallinfoWindow(a.windowGeometry)
 	a.scoreWindow = setupScoreWindow(a.windowGeometry)
 	a.rateWindow = setupRateWindow(a.windowGeometry)
-	a.settingsDialog = setupSettingsDialog(a.controller.Settings)
+	a.huntingWindow = setupHuntingWindow(a.windowGeometry)
+	a.txHistoryWindow = setupTXHistoryWindow(a.windowGeometry)
+	a.settingsDialog = setupSettingsDialog(a.controller.Settings, a.controller.Keyer)
 
 	a.mainWindow.SetMainMenuController(a.controller)
 	a.mainWindow.SetLogbookController(a.controller.QSOList)
-	a.mainWindow.SetEntryController(a.controller.Entry)
+	a.mainWindow.SetEntryController(a.controller.FocusedEntry())
+	a.mainWindow.SetSO2RController(a.controller.SO2R)
 
 	a.controller.SetView(a.mainWindow)
 	a.controller.QSOList.Notify(a.mainWindow)
-	a.controller.Entry.SetView(a.mainWindow)
+	a.controller.SO2R.SetEntryView(a.mainWindow)
+	a.controller.SO2R.SetView(a.mainWindow)
+	a.controller.SO2R.Notify(so2r.FocusChangedListenerFunc(func(so2r.Radio) {
+		a.mainWindow.SetEntryController(a.controller.FocusedEntry())
+	}))
 	a.controller.Keyer.SetView(a.mainWindow)
+	a.controller.Keyer.Notify(keyer.KeyerListenerFunc(a.mainWindow.KeyerChanged))
+	a.controller.Keyer.Notify(keyer.KeyerListenerFunc(a.settingsDialog.KeyerChanged))
 	a.controller.ServiceStatus.Notify(a.mainWindow)
+	a.controller.RBNMonitor.Notify(a.mainWindow)
 	a.controller.Callinfo.SetView(a.callinfoWindow)
 	a.controller.Score.SetView(a.scoreWindow)
 	a.controller.Rate.SetView(a.rateWindow)
+	a.controller.Bandmap.SetView(a.huntingWindow)
+	a.controller.TXHistory.SetView(a.txHistoryWindow)
 	a.controller.Settings.SetView(a.settingsDialog)
 
 	a.mainWindow.ConnectToGeometry(a.windowGeometry)
@@ -107,6 +123,8 @@ func (a *application) activate() {
 	a.callinfoWindow.RestoreVisibility()
 	a.scoreWindow.RestoreVisibility()
 	a.rateWindow.RestoreVisibility()
+	a.huntingWindow.RestoreVisibility()
+	a.txHistoryWindow.RestoreVisibility()
 
 	a.controller.Refresh()
 }
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"html"
 	"strings"
 
 	"github.com/gotk3/gotk3/gtk"
@@ -12,8 +13,10 @@ import (
 type callinfoView struct {
 	callsignLabel   *gtk.Label
 	dxccLabel       *gtk.Label
+	callbookLabel   *gtk.Label
 	valueLabel      *gtk.Label
 	supercheckLabel *gtk.Label
+	similarLabel    *gtk.Label
 }
 
 func setupCallinfoView(builder *gtk.Builder) *callinfoView {
@@ -21,8 +24,10 @@ func setupCallinfoView(builder *gtk.Builder) *callinfoView {
 
 	result.callsignLabel = getUI(builder, "callsignLabel").(*gtk.Label)
 	result.dxccLabel = getUI(builder, "dxccLabel").(*gtk.Label)
+	result.callbookLabel = getUI(builder, "callbookLabel").(*gtk.Label)
 	result.valueLabel = getUI(builder, "valueLabel").(*gtk.Label)
 	result.supercheckLabel = getUI(builder, "supercheckLabel").(*gtk.Label)
+	result.similarLabel = getUI(builder, "similarLabel").(*gtk.Label)
 
 	addStyleClass(&result.callsignLabel.Widget, "callsignlookup")
 	return result
@@ -76,6 +81,37 @@ func (v *callinfoView) SetDXCC(name, continent string, itu, cq int, arrlComplian
 	v.dxccLabel.SetMarkup(text)
 }
 
+func (v *callinfoView) SetCallbookEntry(entry core.CallbookEntry) {
+	if v == nil {
+		return
+	}
+
+	parts := make([]string, 0, 4)
+	for _, s := range []string{entry.Name, entry.QTH, entry.Grid} {
+		if s != "" {
+			parts = append(parts, html.EscapeString(s))
+		}
+	}
+	qsl := make([]string, 0, 4)
+	if entry.QSLVia != "" {
+		qsl = append(qsl, "via "+html.EscapeString(entry.QSLVia))
+	}
+	if entry.LoTW {
+		qsl = append(qsl, "LoTW")
+	}
+	if entry.EQSL {
+		qsl = append(qsl, "eQSL")
+	}
+	if entry.Direct {
+		qsl = append(qsl, "direct")
+	}
+	if len(qsl) > 0 {
+		parts = append(parts, "<span foreground='silver'>QSL "+strings.Join(qsl, " ")+"</span>")
+	}
+
+	v.callbookLabel.SetMarkup(strings.Join(parts, ", "))
+}
+
 func (v *callinfoView) SetValue(points, multis int) {
 	if v == nil {
 		return
@@ -107,6 +143,22 @@ func (v *callinfoView) SetSupercheck(callsigns []core.AnnotatedCallsign) {
 		return
 	}
 
+	v.supercheckLabel.SetMarkup(renderAnnotatedCallsigns(callsigns))
+}
+
+func (v *callinfoView) SetSimilarCallsigns(callsigns []core.AnnotatedCallsign) {
+	if v == nil {
+		return
+	}
+
+	text := renderAnnotatedCallsigns(callsigns)
+	if text != "" {
+		text = "N+1: " + text
+	}
+	v.similarLabel.SetMarkup(text)
+}
+
+func renderAnnotatedCallsigns(callsigns []core.AnnotatedCallsign) string {
 	var text string
 	for _, callsign := range callsigns {
 		// see https://developer.gnome.org/pango/stable/pango-Markup.html for reference
@@ -131,5 +183,5 @@ func (v *callinfoView) SetSupercheck(callsigns []core.AnnotatedCallsign) {
 		}
 		text += renderedCallsign
 	}
-	v.supercheckLabel.SetMarkup(text)
+	return text
 }
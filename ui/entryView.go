@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/gotk3/gotk3/gtk"
 
 	"github.com/ftl/hellocontest/core"
+	"github.com/ftl/hellocontest/core/so2r"
 )
 
 // EntryController controls the entry of QSO data.
@@ -19,6 +21,7 @@ type EntryController interface {
 	SetActiveField(core.EntryField)
 
 	ToggleWorkmode()
+	GotoNextSpot()
 
 	Enter(string)
 	SendQuestion()
@@ -28,13 +31,23 @@ type EntryController interface {
 	KeyerDec()
 
 	FButton(fkey int)
+	FButtonSlower(fkey int)
+	ResendSlower()
+	AutoCQ()
 
 	Log()
 	Clear()
 }
 
+// SO2RController switches between the two radios.
+type SO2RController interface {
+	SwapFocus()
+	ToggleTX()
+}
+
 type entryView struct {
-	controller EntryController
+	controller     EntryController
+	so2rController SO2RController
 
 	ignoreInput bool
 
@@ -51,6 +64,8 @@ type entryView struct {
 	messageLabel *gtk.Label
 	cwspeedLabel *gtk.Label
 	wmLabel      *gtk.Label
+	radioLabel   *gtk.Label
+	macrosLabel  *gtk.Label
 }
 
 func setupEntryView(builder *gtk.Builder) *entryView {
@@ -68,6 +83,8 @@ func setupEntryView(builder *gtk.Builder) *entryView {
 	result.messageLabel = getUI(builder, "messageLabel").(*gtk.Label)
 	result.cwspeedLabel = getUI(builder, "cwspeedLabel").(*gtk.Label)
 	result.wmLabel = getUI(builder, "workmodeLabe").(*gtk.Label)
+	result.radioLabel = getUI(builder, "radioLabel").(*gtk.Label)
+	result.macrosLabel = getUI(builder, "macrosLabel").(*gtk.Label)
 
 	result.addEntryEventHandlers(&result.callsign.Widget)
 	result.addEntryEventHandlers(&result.theirReport.Widget)
@@ -95,6 +112,10 @@ func (v *entryView) SetEntryController(controller EntryController) {
 	v.controller = controller
 }
 
+func (v *entryView) SetSO2RController(controller SO2RController) {
+	v.so2rController = controller
+}
+
 func (v *entryView) addEntryEventHandlers(w *gtk.Widget) {
 	w.Connect("key_press_event", v.onEntryKeyPress)
 	w.Connect("focus_in_event", v.onEntryFocusIn)
@@ -102,6 +123,15 @@ func (v *entryView) addEntryEventHandlers(w *gtk.Widget) {
 	w.Connect("changed", v.onEntryChanged)
 }
 
+// fButton sends the macro of the given function key, holding shift sends it at a lower speed.
+func (v *entryView) fButton(keyEvent *gdk.EventKey, fkey int) {
+	if keyEvent.State()&uint(gdk.SHIFT_MASK) != 0 {
+		v.controller.FButtonSlower(fkey)
+		return
+	}
+	v.controller.FButton(fkey)
+}
+
 func (v *entryView) onEntryKeyPress(_ interface{}, event *gdk.Event) bool {
 	keyEvent := gdk.EventKeyNewFromEvent(event)
 	switch keyEvent.KeyVal() {
@@ -121,23 +151,73 @@ func (v *entryView) onEntryKeyPress(_ interface{}, event *gdk.Event) bool {
 		v.controller.KeyerInc()
 		return true
 	case gdk.KEY_Page_Down:
+		if keyEvent.State()&uint(gdk.CONTROL_MASK) != 0 {
+			v.controller.ResendSlower()
+			return true
+		}
 		v.controller.KeyerDec()
 		return true
+	case gdk.KEY_Down:
+		if keyEvent.State()&uint(gdk.CONTROL_MASK) == 0 {
+			return false
+		}
+		v.controller.GotoNextSpot()
+		return true
 	case gdk.KEY_F1:
-		v.controller.FButton(0)
+		if keyEvent.State()&uint(gdk.CONTROL_MASK) != 0 {
+			v.controller.AutoCQ()
+			return true
+		}
+		v.fButton(keyEvent, 0)
 		return true
 	case gdk.KEY_F2:
-		v.controller.FButton(1)
+		v.fButton(keyEvent, 1)
 		return true
 	case gdk.KEY_F3:
-		v.controller.FButton(2)
+		v.fButton(keyEvent, 2)
 		return true
 	case gdk.KEY_F4:
-		v.controller.FButton(3)
+		v.fButton(keyEvent, 3)
+		return true
+	case gdk.KEY_F5:
+		v.fButton(keyEvent, 4)
+		return true
+	case gdk.KEY_F6:
+		v.fButton(keyEvent, 5)
+		return true
+	case gdk.KEY_F7:
+		v.fButton(keyEvent, 6)
+		return true
+	case gdk.KEY_F8:
+		v.fButton(keyEvent, 7)
+		return true
+	case gdk.KEY_F9:
+		v.fButton(keyEvent, 8)
+		return true
+	case gdk.KEY_F10:
+		v.fButton(keyEvent, 9)
+		return true
+	case gdk.KEY_F11:
+		v.fButton(keyEvent, 10)
+		return true
+	case gdk.KEY_F12:
+		v.fButton(keyEvent, 11)
 		return true
 	case gdk.KEY_Escape:
 		v.controller.EscapeStateMachine()
 		return true
+	case gdk.KEY_Pause:
+		if v.so2rController == nil {
+			return false
+		}
+		v.so2rController.SwapFocus()
+		return true
+	case gdk.KEY_backslash:
+		if v.so2rController == nil {
+			return false
+		}
+		v.so2rController.ToggleTX()
+		return true
 	default:
 		return false
 	}
@@ -305,6 +385,14 @@ func (v *entryView) SetEditingMarker(editing bool) {
 	}
 }
 
+func (v *entryView) SetXchangeMismatchMarker(mismatch bool) {
+	if mismatch {
+		addStyleClass(&v.theirXchange.Widget, "mismatch")
+	} else {
+		removeStyleClass(&v.theirXchange.Widget, "mismatch")
+	}
+}
+
 func (v *entryView) ShowMessage(args ...interface{}) {
 	v.messageLabel.SetText(fmt.Sprint(args...))
 }
@@ -317,6 +405,31 @@ func (v *entryView) ShowKeyerSpeed(speed int) {
 	v.cwspeedLabel.SetText(fmt.Sprintf("%2d", speed))
 }
 
+// ShowProgress shows the CW message that is currently sent, the part that was already sent is shown in bold.
+func (v *entryView) ShowProgress(sent string, pending string) {
+	v.messageLabel.SetMarkup(fmt.Sprintf("<b>%s</b>%s", html.EscapeString(sent), html.EscapeString(pending)))
+}
+
+// ShowMacroLabels shows the labels of all defined macros together with their function keys.
+func (v *entryView) ShowMacroLabels(labels []string) {
+	macros := make([]string, 0, len(labels))
+	for i, label := range labels {
+		if label == "" {
+			continue
+		}
+		macros = append(macros, fmt.Sprintf("<b>F%d</b> %s", i+1, html.EscapeString(label)))
+	}
+	v.macrosLabel.SetMarkup(strings.Join(macros, "  "))
+}
+
 func (v *entryView) ShowWorkmode(text string) {
 	v.wmLabel.SetText(text)
 }
+
+func (v *entryView) ShowRadios(focus so2r.Radio, tx so2r.Radio) {
+	if focus == tx {
+		v.radioLabel.SetText(focus.String())
+	} else {
+		v.radioLabel.SetText(fmt.Sprintf("%s TX %s", focus, tx))
+	}
+}
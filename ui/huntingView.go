@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+type huntingView struct {
+	tableLabel *gtk.Label
+}
+
+func setupHuntingView(builder *gtk.Builder) *huntingView {
+	result := new(huntingView)
+
+	result.tableLabel = getUI(builder, "huntingTableLabel").(*gtk.Label)
+
+	return result
+}
+
+func (v *huntingView) ShowHuntingList(entries []core.AnnotatedSpot) {
+	if v == nil {
+		return
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// see https://developer.gnome.org/pango/stable/pango-Markup.html for reference
+		var attributes string
+		switch {
+		case entry.Multis > 0:
+			attributes = "foreground='cyan' font-weight='heavy'"
+		case entry.Points == 0:
+			attributes = "foreground='silver'"
+		}
+		line := fmt.Sprintf("%8.1f %-10s %2dP %2dM", entry.Frequency/1000.0, entry.Callsign, entry.Points, entry.Multis)
+		lines = append(lines, fmt.Sprintf("<span %s>%s</span>", attributes, line))
+	}
+
+	renderedList := fmt.Sprintf("<span allow_breaks='true' font_family='monospace'>%s</span>", strings.Join(lines, "\n"))
+	v.tableLabel.SetMarkup(renderedList)
+}
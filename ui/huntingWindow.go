@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"github.com/ftl/gmtry"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const HuntingWindowID = "hunting"
+
+type huntingWindow struct {
+	window   *gtk.Window
+	geometry *gmtry.Geometry
+
+	*huntingView
+}
+
+func setupHuntingWindow(geometry *gmtry.Geometry) *huntingWindow {
+	result := &huntingWindow{
+		geometry: geometry,
+	}
+
+	return result
+}
+
+func (w *huntingWindow) RestoreVisibility() {
+	visible := w.geometry.Get(HuntingWindowID).Visible
+	if visible {
+		w.Show()
+	} else {
+		w.Hide()
+	}
+}
+
+func (w *huntingWindow) Show() {
+	if w.window == nil {
+		builder := setupBuilder()
+		w.window = getUI(builder, "huntingWindow").(*gtk.Window)
+		w.window.SetDefaultSize(300, 500)
+		w.window.SetTitle("Hunting List")
+		w.window.Connect("destroy", w.onDestroy)
+		w.huntingView = setupHuntingView(builder)
+		connectToGeometry(w.geometry, HuntingWindowID, w.window)
+	}
+	w.window.ShowAll()
+	w.window.Present()
+}
+
+func (w *huntingWindow) Hide() {
+	if w.window == nil {
+		return
+	}
+	w.window.Close()
+}
+
+func (w *huntingWindow) Visible() bool {
+	if w.window == nil {
+		return false
+	}
+	return w.window.IsVisible()
+}
+
+func (w *huntingWindow) UseDefaultWindowGeometry() {
+	if w.window == nil {
+		return
+	}
+	w.window.Move(0, 100)
+	w.window.Resize(300, 500)
+}
+
+func (w *huntingWindow) onDestroy() {
+	w.window = nil
+	w.huntingView = nil
+}
@@ -1,7 +1,11 @@
 package ui
 
 import (
+	"log"
+
 	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/ftl/hellocontest/core"
 )
 
 // MainMenuController provides the functionality for the main menu.
@@ -13,38 +17,61 @@ type MainMenuController interface {
 	ExportCabrillo()
 	ExportADIF()
 	ExportCSV()
+	ExportTXHistory()
+	UpdateDatabases()
+	ImportDXCCPrefixes()
+	ReloadDXCCOverrides()
+	ImportSupercheckDatabase()
 	OpenSettings()
 	Quit()
 	ShowCallinfo()
 	ShowScore()
 	ShowRate()
+	ShowHuntingList()
+	ShowTXHistory()
 	ClearEntryFields()
 	GotoEntryFields()
 	EditLastQSO()
 	LogQSO()
+	ShowXchangeConsistency()
+	MacroSets() []string
+	ActiveMacroSet() string
+	SelectMacroSet(string)
 }
 
 type mainMenu struct {
 	controller MainMenuController
 
-	fileNew            *gtk.MenuItem
-	fileOpen           *gtk.MenuItem
-	fileSaveAs         *gtk.MenuItem
-	fileExportCabrillo *gtk.MenuItem
-	fileExportADIF     *gtk.MenuItem
-	fileExportCSV      *gtk.MenuItem
-	fileSettings       *gtk.MenuItem
-	fileQuit           *gtk.MenuItem
+	fileNew             *gtk.MenuItem
+	fileOpen            *gtk.MenuItem
+	fileSaveAs          *gtk.MenuItem
+	fileExportCabrillo  *gtk.MenuItem
+	fileExportADIF      *gtk.MenuItem
+	fileExportCSV       *gtk.MenuItem
+	fileExportTXHistory *gtk.MenuItem
+	fileUpdateDatabases *gtk.MenuItem
+	fileImportDXCC      *gtk.MenuItem
+	fileReloadOverrides *gtk.MenuItem
+	fileImportSCP       *gtk.MenuItem
+	fileSettings        *gtk.MenuItem
+	fileQuit            *gtk.MenuItem
 
-	editClearEntryFields *gtk.MenuItem
-	editGotoEntryFields  *gtk.MenuItem
-	editEditLastQSO      *gtk.MenuItem
-	editLogQSO           *gtk.MenuItem
+	editClearEntryFields   *gtk.MenuItem
+	editGotoEntryFields    *gtk.MenuItem
+	editEditLastQSO        *gtk.MenuItem
+	editLogQSO             *gtk.MenuItem
+	editXchangeConsistency *gtk.MenuItem
+	editMacroSet           *gtk.MenuItem
+	editMacroSetMenu       *gtk.Menu
+	macroSetItems          map[string]*gtk.CheckMenuItem
+	ignoreMacroSetChange   bool
 
-	windowCallinfo *gtk.MenuItem
-	windowScore    *gtk.MenuItem
-	windowRate     *gtk.MenuItem
-	helpAbout      *gtk.MenuItem
+	windowCallinfo  *gtk.MenuItem
+	windowScore     *gtk.MenuItem
+	windowRate      *gtk.MenuItem
+	windowHunting   *gtk.MenuItem
+	windowTXHistory *gtk.MenuItem
+	helpAbout       *gtk.MenuItem
 }
 
 func setupMainMenu(builder *gtk.Builder) *mainMenu {
@@ -56,15 +83,25 @@ func setupMainMenu(builder *gtk.Builder) *mainMenu {
 	result.fileExportCabrillo = getUI(builder, "menuFileExportCabrillo").(*gtk.MenuItem)
 	result.fileExportADIF = getUI(builder, "menuFileExportADIF").(*gtk.MenuItem)
 	result.fileExportCSV = getUI(builder, "menuFileExportCSV").(*gtk.MenuItem)
+	result.fileExportTXHistory = getUI(builder, "menuFileExportTXHistory").(*gtk.MenuItem)
+	result.fileUpdateDatabases = getUI(builder, "menuFileUpdateDatabases").(*gtk.MenuItem)
+	result.fileImportDXCC = getUI(builder, "menuFileImportDXCC").(*gtk.MenuItem)
+	result.fileReloadOverrides = getUI(builder, "menuFileReloadOverrides").(*gtk.MenuItem)
+	result.fileImportSCP = getUI(builder, "menuFileImportSCP").(*gtk.MenuItem)
 	result.fileSettings = getUI(builder, "menuFileSettings").(*gtk.MenuItem)
 	result.fileQuit = getUI(builder, "menuFileQuit").(*gtk.MenuItem)
 	result.editClearEntryFields = getUI(builder, "menuEditClearEntryFields").(*gtk.MenuItem)
 	result.editGotoEntryFields = getUI(builder, "menuEditGotoEntryFields").(*gtk.MenuItem)
 	result.editEditLastQSO = getUI(builder, "menuEditEditLastQSO").(*gtk.MenuItem)
 	result.editLogQSO = getUI(builder, "menuEditLogQSO").(*gtk.MenuItem)
+	result.editXchangeConsistency = getUI(builder, "menuEditXchangeConsistency").(*gtk.MenuItem)
+	result.editMacroSet = getUI(builder, "menuEditMacroSet").(*gtk.MenuItem)
+	result.editMacroSetMenu = getUI(builder, "submenuEditMacroSet").(*gtk.Menu)
 	result.windowCallinfo = getUI(builder, "menuWindowCallinfo").(*gtk.MenuItem)
 	result.windowScore = getUI(builder, "menuWindowScore").(*gtk.MenuItem)
 	result.windowRate = getUI(builder, "menuWindowRate").(*gtk.MenuItem)
+	result.windowHunting = getUI(builder, "menuWindowHunting").(*gtk.MenuItem)
+	result.windowTXHistory = getUI(builder, "menuWindowTXHistory").(*gtk.MenuItem)
 	result.helpAbout = getUI(builder, "menuHelpAbout").(*gtk.MenuItem)
 
 	result.fileNew.Connect("activate", result.onNew)
@@ -73,15 +110,23 @@ func setupMainMenu(builder *gtk.Builder) *mainMenu {
 	result.fileExportCabrillo.Connect("activate", result.onExportCabrillo)
 	result.fileExportADIF.Connect("activate", result.onExportADIF)
 	result.fileExportCSV.Connect("activate", result.onExportCSV)
+	result.fileExportTXHistory.Connect("activate", result.onExportTXHistory)
+	result.fileUpdateDatabases.Connect("activate", result.onUpdateDatabases)
+	result.fileImportDXCC.Connect("activate", result.onImportDXCC)
+	result.fileReloadOverrides.Connect("activate", result.onReloadOverrides)
+	result.fileImportSCP.Connect("activate", result.onImportSCP)
 	result.fileSettings.Connect("activate", result.onSettings)
 	result.fileQuit.Connect("activate", result.onQuit)
 	result.editClearEntryFields.Connect("activate", result.onClearEntryFields)
 	result.editGotoEntryFields.Connect("activate", result.onGotoEntryFields)
 	result.editEditLastQSO.Connect("activate", result.onEditLastQSO)
 	result.editLogQSO.Connect("activate", result.onLogQSO)
+	result.editXchangeConsistency.Connect("activate", result.onXchangeConsistency)
 	result.windowCallinfo.Connect("activate", result.onCallinfo)
 	result.windowScore.Connect("activate", result.onScore)
 	result.windowRate.Connect("activate", result.onRate)
+	result.windowHunting.Connect("activate", result.onHunting)
+	result.windowTXHistory.Connect("activate", result.onTXHistory)
 	result.helpAbout.Connect("activate", result.onAbout)
 
 	return result
@@ -89,6 +134,48 @@ func setupMainMenu(builder *gtk.Builder) *mainMenu {
 
 func (m *mainMenu) SetMainMenuController(controller MainMenuController) {
 	m.controller = controller
+	m.setupMacroSets()
+}
+
+// setupMacroSets fills the macro set menu with one item for each macro set. The item of the active macro set is checked.
+func (m *mainMenu) setupMacroSets() {
+	m.macroSetItems = make(map[string]*gtk.CheckMenuItem)
+	names := m.controller.MacroSets()
+	m.editMacroSet.SetSensitive(len(names) > 0)
+	for _, name := range names {
+		item, err := gtk.CheckMenuItemNewWithLabel(name)
+		if err != nil {
+			log.Printf("cannot create menu item for macro set %s: %v", name, err)
+			continue
+		}
+		item.SetDrawAsRadio(true)
+		selectedName := name
+		item.Connect("activate", func() {
+			m.onMacroSet(selectedName)
+		})
+		m.editMacroSetMenu.Append(item)
+		m.macroSetItems[name] = item
+	}
+	m.editMacroSetMenu.ShowAll()
+	m.showActiveMacroSet()
+}
+
+// KeyerChanged shows the active macro set, e.g. after another logfile was opened.
+func (m *mainMenu) KeyerChanged(core.Keyer) {
+	if m.controller == nil {
+		return
+	}
+	m.showActiveMacroSet()
+}
+
+func (m *mainMenu) showActiveMacroSet() {
+	m.ignoreMacroSetChange = true
+	defer func() { m.ignoreMacroSetChange = false }()
+
+	active := m.controller.ActiveMacroSet()
+	for name, item := range m.macroSetItems {
+		item.SetActive(name == active)
+	}
 }
 
 func (m *mainMenu) onAbout() {
@@ -119,6 +206,26 @@ func (m *mainMenu) onExportCSV() {
 	m.controller.ExportCSV()
 }
 
+func (m *mainMenu) onExportTXHistory() {
+	m.controller.ExportTXHistory()
+}
+
+func (m *mainMenu) onUpdateDatabases() {
+	m.controller.UpdateDatabases()
+}
+
+func (m *mainMenu) onImportDXCC() {
+	m.controller.ImportDXCCPrefixes()
+}
+
+func (m *mainMenu) onReloadOverrides() {
+	m.controller.ReloadDXCCOverrides()
+}
+
+func (m *mainMenu) onImportSCP() {
+	m.controller.ImportSupercheckDatabase()
+}
+
 func (m *mainMenu) onSettings() {
 	m.controller.OpenSettings()
 }
@@ -143,6 +250,18 @@ func (m *mainMenu) onLogQSO() {
 	m.controller.LogQSO()
 }
 
+func (m *mainMenu) onXchangeConsistency() {
+	m.controller.ShowXchangeConsistency()
+}
+
+func (m *mainMenu) onMacroSet(name string) {
+	if m.ignoreMacroSetChange {
+		return
+	}
+	m.controller.SelectMacroSet(name)
+	m.showActiveMacroSet()
+}
+
 func (m *mainMenu) onCallinfo() {
 	m.controller.ShowCallinfo()
 }
@@ -154,3 +273,11 @@ func (m *mainMenu) onScore() {
 func (m *mainMenu) onRate() {
 	m.controller.ShowRate()
 }
+
+func (m *mainMenu) onHunting() {
+	m.controller.ShowHuntingList()
+}
+
+func (m *mainMenu) onTXHistory() {
+	m.controller.ShowTXHistory()
+}
@@ -1,17 +1,23 @@
 package ui
 
-import "github.com/gotk3/gotk3/gtk"
+import (
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/ftl/hellocontest/core"
+)
 
 type settingsDialog struct {
 	dialog *gtk.Dialog
 
-	controller SettingsController
+	controller      SettingsController
+	keyerController KeyerSettingsController
 	*settingsView
 }
 
-func setupSettingsDialog(controller SettingsController) *settingsDialog {
+func setupSettingsDialog(controller SettingsController, keyerController KeyerSettingsController) *settingsDialog {
 	result := &settingsDialog{
-		controller: controller,
+		controller:      controller,
+		keyerController: keyerController,
 	}
 	return result
 }
@@ -27,8 +33,13 @@ func (d *settingsDialog) Show() {
 		d.dialog = getUI(builder, "settingsDialog").(*gtk.Dialog)
 		d.dialog.SetTitle("Settings")
 		d.dialog.Connect("destroy", d.onDestroy)
-		d.settingsView = setupSettingsView(builder, d.dialog, d.controller)
+		d.settingsView = setupSettingsView(builder, d.dialog, d.controller, d.keyerController)
 	}
 	d.dialog.ShowAll()
 	d.dialog.Present()
 }
+
+// KeyerChanged shows the auto CQ gap of the current keyer settings, e.g. after another logfile was opened.
+func (d *settingsDialog) KeyerChanged(core.Keyer) {
+	d.settingsView.SetKeyerAutoCQGap(d.keyerController.AutoCQGap())
+}
@@ -1,7 +1,11 @@
 package ui
 
 import (
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gotk3/gotk3/gtk"
 )
@@ -14,6 +18,7 @@ type SettingsController interface {
 	EnterStationCallsign(string)
 	EnterStationOperator(string)
 	EnterStationLocator(string)
+	EnterStationRegion(string)
 	EnterContestName(string)
 	EnterContestEnterTheirNumber(bool)
 	EnterContestEnterTheirXchange(bool)
@@ -30,6 +35,19 @@ type SettingsController interface {
 	EnterContestTestXchangeValue(string)
 	EnterContestCountPerBand(bool)
 	EnterContestCabrilloQSOTemplate(string)
+	EnterContestCategoryOperator(string)
+	EnterContestCategoryAssisted(string)
+	EnterContestCategoryBand(string)
+	EnterContestCategoryMode(string)
+	EnterContestCategoryPower(string)
+	EnterContestCategoryTransmitter(string)
+	EnterContestCategoryOverlay(string)
+}
+
+// KeyerSettingsController controls the settings of the keyer that are shown in the settings dialog.
+type KeyerSettingsController interface {
+	AutoCQGap() time.Duration
+	EnterAutoCQGap(time.Duration)
 }
 
 type fieldID string
@@ -38,6 +56,7 @@ const (
 	stationCallsign                fieldID = "stationCallsign"
 	stationOperator                fieldID = "stationOperator"
 	stationLocator                 fieldID = "stationLocator"
+	stationRegion                  fieldID = "stationRegion"
 	contestName                    fieldID = "contestName"
 	contestRequireTheirXchange     fieldID = "contestRequireTheirXchange"
 	contestAllowMultiBand          fieldID = "contestAllowMultiBand"
@@ -54,11 +73,20 @@ const (
 	contestTestXchangeMultiPattern fieldID = "contestTestXchangeMultiPattern"
 	contestCountPerBand            fieldID = "contestCountPerBand"
 	contestCabrilloQSOTemplate     fieldID = "contestCabrilloQSOTemplate"
+	contestCategoryOperator        fieldID = "contestCategoryOperator"
+	contestCategoryAssisted        fieldID = "contestCategoryAssisted"
+	contestCategoryBand            fieldID = "contestCategoryBand"
+	contestCategoryMode            fieldID = "contestCategoryMode"
+	contestCategoryPower           fieldID = "contestCategoryPower"
+	contestCategoryTransmitter     fieldID = "contestCategoryTransmitter"
+	contestCategoryOverlay         fieldID = "contestCategoryOverlay"
+	keyerAutoCQGap                 fieldID = "keyerAutoCQGap"
 )
 
 type settingsView struct {
-	parent     *gtk.Dialog
-	controller SettingsController
+	parent          *gtk.Dialog
+	controller      SettingsController
+	keyerController KeyerSettingsController
 
 	ignoreChangedEvent bool
 
@@ -70,10 +98,11 @@ type settingsView struct {
 	fields            map[fieldID]interface{}
 }
 
-func setupSettingsView(builder *gtk.Builder, parent *gtk.Dialog, controller SettingsController) *settingsView {
+func setupSettingsView(builder *gtk.Builder, parent *gtk.Dialog, controller SettingsController, keyerController KeyerSettingsController) *settingsView {
 	result := new(settingsView)
 	result.parent = parent
 	result.controller = controller
+	result.keyerController = keyerController
 	result.fields = make(map[fieldID]interface{})
 
 	result.message = getUI(builder, "settingsMessageLabel").(*gtk.Label)
@@ -88,6 +117,7 @@ func setupSettingsView(builder *gtk.Builder, parent *gtk.Dialog, controller Sett
 	result.addEntry(builder, stationCallsign)
 	result.addEntry(builder, stationOperator)
 	result.addEntry(builder, stationLocator)
+	result.addCombo(builder, stationRegion)
 	result.addEntry(builder, contestName)
 	result.addCheckButton(builder, contestRequireTheirXchange)
 	result.addCheckButton(builder, contestAllowMultiBand)
@@ -104,6 +134,15 @@ func setupSettingsView(builder *gtk.Builder, parent *gtk.Dialog, controller Sett
 	result.addEntry(builder, contestTestXchangeMultiPattern)
 	result.addCheckButton(builder, contestCountPerBand)
 	result.addEntry(builder, contestCabrilloQSOTemplate)
+	result.addEntry(builder, contestCategoryOperator)
+	result.addEntry(builder, contestCategoryAssisted)
+	result.addEntry(builder, contestCategoryBand)
+	result.addEntry(builder, contestCategoryMode)
+	result.addEntry(builder, contestCategoryPower)
+	result.addEntry(builder, contestCategoryTransmitter)
+	result.addEntry(builder, contestCategoryOverlay)
+	result.addEntry(builder, keyerAutoCQGap)
+	result.SetKeyerAutoCQGap(keyerController.AutoCQGap())
 
 	result.parent.Connect("destroy", result.onDestroy)
 
@@ -140,6 +179,15 @@ func (v *settingsView) addCheckButton(builder *gtk.Builder, id fieldID) {
 	widget.Connect("toggled", v.onFieldChanged)
 }
 
+func (v *settingsView) addCombo(builder *gtk.Builder, id fieldID) {
+	combo := getUI(builder, string(id)+"Combo").(*gtk.ComboBoxText)
+	field, _ := combo.GetName()
+	v.fields[fieldID(field)] = combo
+
+	widget := &combo.Widget
+	widget.Connect("changed", v.onFieldChanged)
+}
+
 func (v *settingsView) onFieldChanged(w interface{}) bool {
 	if v.ignoreChangedEvent {
 		return false
@@ -154,6 +202,9 @@ func (v *settingsView) onFieldChanged(w interface{}) bool {
 	case *gtk.CheckButton:
 		field, _ = widget.GetName()
 		value = widget.GetActive()
+	case *gtk.ComboBoxText:
+		field, _ = widget.GetName()
+		value = widget.GetActiveID()
 	default:
 		return false
 	}
@@ -165,6 +216,8 @@ func (v *settingsView) onFieldChanged(w interface{}) bool {
 		v.controller.EnterStationOperator(value.(string))
 	case stationLocator:
 		v.controller.EnterStationLocator(value.(string))
+	case stationRegion:
+		v.controller.EnterStationRegion(value.(string))
 	case contestName:
 		v.controller.EnterContestName(value.(string))
 	case contestRequireTheirXchange:
@@ -193,6 +246,22 @@ func (v *settingsView) onFieldChanged(w interface{}) bool {
 		v.controller.EnterContestCountPerBand(value.(bool))
 	case contestCabrilloQSOTemplate:
 		v.controller.EnterContestCabrilloQSOTemplate(value.(string))
+	case contestCategoryOperator:
+		v.controller.EnterContestCategoryOperator(value.(string))
+	case contestCategoryAssisted:
+		v.controller.EnterContestCategoryAssisted(value.(string))
+	case contestCategoryBand:
+		v.controller.EnterContestCategoryBand(value.(string))
+	case contestCategoryMode:
+		v.controller.EnterContestCategoryMode(value.(string))
+	case contestCategoryPower:
+		v.controller.EnterContestCategoryPower(value.(string))
+	case contestCategoryTransmitter:
+		v.controller.EnterContestCategoryTransmitter(value.(string))
+	case contestCategoryOverlay:
+		v.controller.EnterContestCategoryOverlay(value.(string))
+	case keyerAutoCQGap:
+		v.enterAutoCQGap(value.(string))
 	default:
 		log.Printf("enter unknown field %s: %v", field, value)
 	}
@@ -200,6 +269,16 @@ func (v *settingsView) onFieldChanged(w interface{}) bool {
 	return false
 }
 
+func (v *settingsView) enterAutoCQGap(value string) {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		v.ShowMessage("the auto CQ gap must be a number of seconds")
+		return
+	}
+	v.HideMessage()
+	v.keyerController.EnterAutoCQGap(time.Duration(seconds) * time.Second)
+}
+
 func (v *settingsView) multis() (dxcc, wpx, xchange bool) {
 	dxcc = v.fields[contestMultiDXCC].(*gtk.CheckButton).GetActive()
 	wpx = v.fields[contestMultiWPX].(*gtk.CheckButton).GetActive()
@@ -235,6 +314,12 @@ func (v *settingsView) setCheckButtonField(field fieldID, value bool) {
 	})
 }
 
+func (v *settingsView) setComboField(field fieldID, value string) {
+	v.doIgnoreChanges(func() {
+		v.fields[field].(*gtk.ComboBoxText).SetActiveID(value)
+	})
+}
+
 func (v *settingsView) doIgnoreChanges(f func()) {
 	if v == nil {
 		return
@@ -259,6 +344,10 @@ func (v *settingsView) SetStationLocator(value string) {
 	v.setEntryField(stationLocator, value)
 }
 
+func (v *settingsView) SetStationRegion(value string) {
+	v.setComboField(stationRegion, value)
+}
+
 func (v *settingsView) SetContestName(value string) {
 	v.setEntryField(contestName, value)
 }
@@ -319,3 +408,35 @@ func (v *settingsView) SetContestCountPerBand(value bool) {
 func (v *settingsView) SetContestCabrilloQSOTemplate(value string) {
 	v.setEntryField(contestCabrilloQSOTemplate, value)
 }
+
+func (v *settingsView) SetContestCategoryOperator(value string) {
+	v.setEntryField(contestCategoryOperator, value)
+}
+
+func (v *settingsView) SetContestCategoryAssisted(value string) {
+	v.setEntryField(contestCategoryAssisted, value)
+}
+
+func (v *settingsView) SetContestCategoryBand(value string) {
+	v.setEntryField(contestCategoryBand, value)
+}
+
+func (v *settingsView) SetContestCategoryMode(value string) {
+	v.setEntryField(contestCategoryMode, value)
+}
+
+func (v *settingsView) SetContestCategoryPower(value string) {
+	v.setEntryField(contestCategoryPower, value)
+}
+
+func (v *settingsView) SetContestCategoryTransmitter(value string) {
+	v.setEntryField(contestCategoryTransmitter, value)
+}
+
+func (v *settingsView) SetContestCategoryOverlay(value string) {
+	v.setEntryField(contestCategoryOverlay, value)
+}
+
+func (v *settingsView) SetKeyerAutoCQGap(value time.Duration) {
+	v.setEntryField(keyerAutoCQGap, fmt.Sprintf("%d", int(value/time.Second)))
+}
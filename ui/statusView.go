@@ -12,9 +12,17 @@ import (
 type statusView struct {
 	tciLabel    *gtk.Label
 	hamlibLabel *gtk.Label
+	catLabel    *gtk.Label
 	cwLabel     *gtk.Label
 	dxccLabel   *gtk.Label
 	scpLabel    *gtk.Label
+	rbnLabel    *gtk.Label
+	lanLabel    *gtk.Label
+	liveLabel   *gtk.Label
+
+	skimmerReportLabel *gtk.Label
+
+	databaseVersions map[core.Service]string
 }
 
 const (
@@ -24,18 +32,29 @@ const (
 
 func setupStatusView(builder *gtk.Builder) *statusView {
 	result := new(statusView)
+	result.databaseVersions = make(map[core.Service]string)
 
 	result.tciLabel = getUI(builder, "tciStatusLabel").(*gtk.Label)
 	result.hamlibLabel = getUI(builder, "hamlibStatusLabel").(*gtk.Label)
+	result.catLabel = getUI(builder, "catStatusLabel").(*gtk.Label)
 	result.cwLabel = getUI(builder, "cwStatusLabel").(*gtk.Label)
 	result.dxccLabel = getUI(builder, "dxccStatusLabel").(*gtk.Label)
 	result.scpLabel = getUI(builder, "scpStatusLabel").(*gtk.Label)
+	result.rbnLabel = getUI(builder, "rbnStatusLabel").(*gtk.Label)
+	result.lanLabel = getUI(builder, "networkStatusLabel").(*gtk.Label)
+	result.liveLabel = getUI(builder, "scoreReportingStatusLabel").(*gtk.Label)
+	result.skimmerReportLabel = getUI(builder, "skimmerReportLabel").(*gtk.Label)
 
 	setStyledText(result.tciLabel, unavailableStyle, "TCI")
 	setStyledText(result.hamlibLabel, unavailableStyle, "Hamlib")
+	setStyledText(result.catLabel, unavailableStyle, "CAT")
 	setStyledText(result.cwLabel, unavailableStyle, "CW")
 	setStyledText(result.dxccLabel, unavailableStyle, "DXCC")
 	setStyledText(result.scpLabel, unavailableStyle, "SCP")
+	setStyledText(result.rbnLabel, unavailableStyle, "RBN")
+	setStyledText(result.lanLabel, unavailableStyle, "LAN")
+	setStyledText(result.liveLabel, unavailableStyle, "Live")
+	result.skimmerReportLabel.SetText("")
 
 	return result
 }
@@ -54,26 +73,72 @@ func (v *statusView) StatusChanged(service core.Service, available bool) {
 	} else {
 		style = unavailableStyle
 	}
+	if version, ok := v.databaseVersions[service]; ok {
+		text += " " + version
+	}
 	setStyledText(label, style, text)
 }
 
+// DatabaseInfoChanged remembers the version of the database that is shown with the next status change of the service.
+// The tooltip shows the date of the database or the reason why it is not available.
+func (v *statusView) DatabaseInfoChanged(info core.DatabaseInfo) {
+	label, _ := v.serviceLabel(info.Service)
+	if label == nil {
+		log.Printf("unknown service %d", info.Service)
+		return
+	}
+
+	version := info.Version
+	if version == "" && !info.Date.IsZero() {
+		version = info.Date.Format("2006-01-02")
+	}
+	if version != "" {
+		v.databaseVersions[info.Service] = version
+	}
+
+	var tooltip string
+	switch {
+	case info.Err != nil:
+		tooltip = info.Err.Error()
+	case !info.Date.IsZero():
+		tooltip = fmt.Sprintf("updated %s", info.Date.Format("2006-01-02 15:04"))
+	}
+	label.SetTooltipText(tooltip)
+}
+
 func (v *statusView) serviceLabel(service core.Service) (*gtk.Label, string) {
 	switch service {
 	case core.TCIService:
 		return v.tciLabel, "TCI"
 	case core.HamlibService:
 		return v.hamlibLabel, "Hamlib"
+	case core.CATService:
+		return v.catLabel, "CAT"
 	case core.CWDaemonService:
 		return v.cwLabel, "CW"
 	case core.DXCCService:
 		return v.dxccLabel, "DXCC"
 	case core.SCPService:
 		return v.scpLabel, "SCP"
+	case core.RBNService:
+		return v.rbnLabel, "RBN"
+	case core.NetworkService:
+		return v.lanLabel, "LAN"
+	case core.ScoreReportingService:
+		return v.liveLabel, "Live"
 	default:
 		return nil, ""
 	}
 }
 
+func (v *statusView) SkimmerReportUpdated(report core.SkimmerReport) {
+	if report.Skimmers == 0 {
+		v.skimmerReportLabel.SetText("")
+		return
+	}
+	v.skimmerReportLabel.SetText(fmt.Sprintf("heard by %d skimmers, avg %.0f dB, %.0f WPM", report.Skimmers, report.AverageSNR, report.AverageWPM))
+}
+
 func setStyledText(label *gtk.Label, style, text string) {
 	label.SetMarkup(fmt.Sprintf(`<span %s>%s</span>`, style, text))
 }
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/ftl/hellocontest/core"
+)
+
+type txHistoryView struct {
+	tableLabel *gtk.Label
+}
+
+func setupTXHistoryView(builder *gtk.Builder) *txHistoryView {
+	result := new(txHistoryView)
+
+	result.tableLabel = getUI(builder, "txHistoryTableLabel").(*gtk.Label)
+
+	return result
+}
+
+func (v *txHistoryView) ShowTransmissions(transmissions []core.Transmission) {
+	if v == nil {
+		return
+	}
+
+	// the latest transmission comes first
+	lines := make([]string, 0, len(transmissions))
+	for i := len(transmissions) - 1; i >= 0; i-- {
+		transmission := transmissions[i]
+		macro := ""
+		if transmission.Macro > 0 {
+			macro = fmt.Sprintf("F%d", transmission.Macro)
+		}
+		line := fmt.Sprintf("%s %-2s %-3s %2dWPM %s", transmission.Time.UTC().Format("15:04:05"), transmission.Radio, macro, transmission.WPM, transmission.Text)
+		lines = append(lines, html.EscapeString(line))
+	}
+
+	renderedList := fmt.Sprintf("<span allow_breaks='true' font_family='monospace'>%s</span>", strings.Join(lines, "\n"))
+	v.tableLabel.SetMarkup(renderedList)
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"github.com/ftl/gmtry"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+const TXHistoryWindowID = "txhistory"
+
+type txHistoryWindow struct {
+	window   *gtk.Window
+	geometry *gmtry.Geometry
+
+	*txHistoryView
+}
+
+func setupTXHistoryWindow(geometry *gmtry.Geometry) *txHistoryWindow {
+	result := &txHistoryWindow{
+		geometry: geometry,
+	}
+
+	return result
+}
+
+func (w *txHistoryWindow) RestoreVisibility() {
+	visible := w.geometry.Get(TXHistoryWindowID).Visible
+	if visible {
+		w.Show()
+	} else {
+		w.Hide()
+	}
+}
+
+func (w *txHistoryWindow) Show() {
+	if w.window == nil {
+		builder := setupBuilder()
+		w.window = getUI(builder, "txHistoryWindow").(*gtk.Window)
+		w.window.SetDefaultSize(500, 300)
+		w.window.SetTitle("Transmit History")
+		w.window.Connect("destroy", w.onDestroy)
+		w.txHistoryView = setupTXHistoryView(builder)
+		connectToGeometry(w.geometry, TXHistoryWindowID, w.window)
+	}
+	w.window.ShowAll()
+	w.window.Present()
+}
+
+func (w *txHistoryWindow) Hide() {
+	if w.window == nil {
+		return
+	}
+	w.window.Close()
+}
+
+func (w *txHistoryWindow) Visible() bool {
+	if w.window == nil {
+		return false
+	}
+	return w.window.IsVisible()
+}
+
+func (w *txHistoryWindow) UseDefaultWindowGeometry() {
+	if w.window == nil {
+		return
+	}
+	w.window.Move(0, 100)
+	w.window.Resize(500, 300)
+}
+
+func (w *txHistoryWindow) onDestroy() {
+	w.window = nil
+	w.txHistoryView = nil
+}